@@ -9,12 +9,20 @@ package basecmd
 import (
 	"bufio"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/hyperledger/fabric-cli/cmd/common"
 	"github.com/hyperledger/fabric-cli/pkg/environment"
 	"github.com/hyperledger/fabric-cli/pkg/fabric"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk"
+	"github.com/pkg/errors"
 )
 
+// sslTargetNameOverride is the gRPC option of a peer in the SDK config that overrides the TLS server name
+const sslTargetNameOverride = "ssl-target-name-override"
+
 // FactoryProvider creates a new Factory
 type FactoryProvider func(config *environment.Config) (fabric.Factory, error)
 
@@ -49,6 +57,22 @@ func (c *Command) Channel() (fabric.Channel, error) {
 	return factory.Channel()
 }
 
+// ChannelForTarget returns a new SDK channel for the given context and channel. If contextName is empty then
+// the current context is used. If channelID is empty then the channel configured in the context is used.
+func (c *Command) ChannelForTarget(contextName, channelID string) (fabric.Channel, error) {
+	config, err := c.targetConfig(contextName, channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	factory, err := c.FactoryProvider(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return factory.Channel()
+}
+
 // ResMgmt returns a new SDK resource manager
 func (c *Command) ResMgmt() (fabric.ResourceManagement, error) {
 	factory, err := c.FactoryProvider(c.Settings.Config)
@@ -59,11 +83,140 @@ func (c *Command) ResMgmt() (fabric.ResourceManagement, error) {
 	return factory.ResourceManagement()
 }
 
+// DiscoverPeers uses Fabric discovery to return the (sorted) names of the peers in the channel of the
+// current context that belong to the given MSP. If mspID is empty then all peers in the channel are returned.
+// The name of a peer is the name under which it is configured in the SDK (see peerName).
+func (c *Command) DiscoverPeers(mspID string) ([]string, error) {
+	factory, err := c.FactoryProvider(c.Settings.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	sdk, err := factory.SDK()
+	if err != nil {
+		return nil, err
+	}
+
+	context := c.Context()
+
+	chCtx, err := sdk.ChannelContext(context.Channel, fabsdk.WithUser(context.User), fabsdk.WithOrg(context.Organization))()
+	if err != nil {
+		return nil, err
+	}
+
+	discovery, err := chCtx.ChannelService().Discovery()
+	if err != nil {
+		return nil, err
+	}
+
+	peers, err := discovery.GetPeers()
+	if err != nil {
+		return nil, errors.WithMessagef(err, "error discovering peers on channel [%s]", context.Channel)
+	}
+
+	endpointConfig := chCtx.EndpointConfig()
+
+	names := make(map[string]struct{})
+	for _, peer := range peers {
+		if mspID == "" || peer.MSPID() == mspID {
+			names[peerName(endpointConfig, peer.URL())] = struct{}{}
+		}
+	}
+
+	if len(names) == 0 {
+		return nil, errors.Errorf("no peers discovered on channel [%s] for MSP [%s]", context.Channel, mspID)
+	}
+
+	var result []string
+	for name := range names {
+		result = append(result, name)
+	}
+
+	sort.Strings(result)
+
+	return result, nil
+}
+
+// peerName returns the name of the peer with the given (discovered) URL. The URL may differ from the peer ID,
+// e.g. behind a proxy or NAT, so the name under which the peer is configured in the SDK is used, i.e. the name of
+// the peer in the network config whose URL matches or, if the URL is mapped by an entity matcher, the SSL target
+// name override of the mapped peer. If no name is configured then the host portion of the URL is returned,
+// e.g. "grpcs://peer0.org1.com:7051" => "peer0.org1.com".
+func peerName(cfg fab.EndpointConfig, url string) string {
+	address := trimScheme(url)
+
+	var names []string
+	for name, peerCfg := range cfg.NetworkConfig().Peers {
+		if strings.EqualFold(trimScheme(peerCfg.URL), address) {
+			names = append(names, name)
+		}
+	}
+
+	if len(names) > 0 {
+		// Ensure that the same name is chosen if the URL is configured for more than one peer
+		sort.Strings(names)
+
+		return names[0]
+	}
+
+	if peerCfg, ok := cfg.PeerConfig(url); ok {
+		if name, ok := peerCfg.GRPCOptions[sslTargetNameOverride].(string); ok && name != "" {
+			return name
+		}
+	}
+
+	if i := strings.LastIndex(address, ":"); i >= 0 {
+		address = address[:i]
+	}
+
+	return address
+}
+
+// trimScheme removes the scheme (e.g. grpcs://) from the given URL
+func trimScheme(url string) string {
+	if i := strings.Index(url, "://"); i >= 0 {
+		return url[i+3:]
+	}
+
+	return url
+}
+
 // Context returns the current context
 func (c *Command) Context() *environment.Context {
 	return c.Settings.Config.Contexts[c.Settings.Config.CurrentContext]
 }
 
+// targetConfig returns a copy of the configuration whose current context is set to the given
+// context and (optionally) whose channel is overridden with the given channel
+func (c *Command) targetConfig(contextName, channelID string) (*environment.Config, error) {
+	if contextName == "" {
+		contextName = c.Settings.Config.CurrentContext
+	}
+
+	context, ok := c.Settings.Config.Contexts[contextName]
+	if !ok {
+		return nil, errors.Errorf("context [%s] not found", contextName)
+	}
+
+	targetContext := *context
+	if channelID != "" {
+		targetContext.Channel = channelID
+	}
+
+	contexts := make(map[string]*environment.Context)
+	for name, ctx := range c.Settings.Config.Contexts {
+		contexts[name] = ctx
+	}
+
+	contexts[contextName] = &targetContext
+
+	return &environment.Config{
+		Networks:       c.Settings.Config.Networks,
+		Contexts:       contexts,
+		CurrentContext: contextName,
+	}, nil
+}
+
 // Fprintln displays the given args to the configured output stream
 func (c *Command) Fprintln(arg ...interface{}) error {
 	_, err := fmt.Fprintln(c.Settings.Streams.Out, arg...)
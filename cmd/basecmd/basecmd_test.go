@@ -12,6 +12,8 @@ import (
 
 	"github.com/hyperledger/fabric-cli/pkg/environment"
 	"github.com/hyperledger/fabric-cli/pkg/fabric"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	fabmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 	"github.com/trustbloc/fabric-cli-ext/cmd/mocks"
@@ -20,6 +22,7 @@ import (
 //go:generate counterfeiter -o ../mocks/channel.gen.go --fake-name Channel github.com/hyperledger/fabric-cli/pkg/fabric.Channel
 //go:generate counterfeiter -o ../mocks/factory.gen.go --fake-name Factory github.com/hyperledger/fabric-cli/pkg/fabric.Factory
 //go:generate counterfeiter -o ../mocks/resmgmt.gen.go --fake-name ResMgmt github.com/hyperledger/fabric-cli/pkg/fabric.ResourceManagement
+//go:generate counterfeiter -o ../mocks/event.gen.go --fake-name Event github.com/hyperledger/fabric-cli/pkg/fabric.Event
 
 func TestBaseCommand_Channel(t *testing.T) {
 	t.Run("With factory error", func(t *testing.T) {
@@ -53,6 +56,66 @@ func TestBaseCommand_Channel(t *testing.T) {
 	})
 }
 
+func TestBaseCommand_ChannelForTarget(t *testing.T) {
+	factory := &mocks.Factory{}
+	factory.ChannelReturns(&mocks.Channel{}, nil)
+
+	t.Run("Current context", func(t *testing.T) {
+		var cfg *environment.Config
+		p := func(config *environment.Config) (fabric.Factory, error) {
+			cfg = config
+			return factory, nil
+		}
+
+		c := newMockCmd(t, p)
+		ch, err := c.ChannelForTarget("", "")
+		require.NoError(t, err)
+		require.NotNil(t, ch)
+		require.Equal(t, "testctx", cfg.CurrentContext)
+		require.Equal(t, "mychannel", cfg.Contexts["testctx"].Channel)
+	})
+
+	t.Run("Other context and channel", func(t *testing.T) {
+		var cfg *environment.Config
+		p := func(config *environment.Config) (fabric.Factory, error) {
+			cfg = config
+			return factory, nil
+		}
+
+		c := newMockCmd(t, p)
+		c.Settings.Config.Contexts["otherctx"] = &environment.Context{Channel: "otherchannel"}
+
+		ch, err := c.ChannelForTarget("otherctx", "yourchannel")
+		require.NoError(t, err)
+		require.NotNil(t, ch)
+		require.Equal(t, "otherctx", cfg.CurrentContext)
+		require.Equal(t, "yourchannel", cfg.Contexts["otherctx"].Channel)
+
+		// The original config must not be modified
+		require.Equal(t, "otherchannel", c.Settings.Config.Contexts["otherctx"].Channel)
+		require.Equal(t, "testctx", c.Settings.Config.CurrentContext)
+	})
+
+	t.Run("Context not found", func(t *testing.T) {
+		p := func(config *environment.Config) (fabric.Factory, error) { return factory, nil }
+
+		c := newMockCmd(t, p)
+		ch, err := c.ChannelForTarget("xxx", "")
+		require.EqualError(t, err, "context [xxx] not found")
+		require.Nil(t, ch)
+	})
+
+	t.Run("With factory error", func(t *testing.T) {
+		errExpected := errors.New("factory error")
+		p := func(config *environment.Config) (fabric.Factory, error) { return nil, errExpected }
+
+		c := newMockCmd(t, p)
+		ch, err := c.ChannelForTarget("", "")
+		require.EqualError(t, err, errExpected.Error())
+		require.Nil(t, ch)
+	})
+}
+
 func TestBaseCommand_ResMgmt(t *testing.T) {
 	t.Run("With factory error", func(t *testing.T) {
 		errExpected := errors.New("factory error")
@@ -87,6 +150,88 @@ func TestBaseCommand_ResMgmt(t *testing.T) {
 	})
 }
 
+func TestBaseCommand_DiscoverPeers(t *testing.T) {
+	peer1 := &fabmocks.MockPeer{MockMSP: "Org1MSP", MockURL: "grpcs://peer1.org1.com:7051"}
+	peer0 := &fabmocks.MockPeer{MockMSP: "Org1MSP", MockURL: "peer0.org1.com:7051"}
+	peer2 := &fabmocks.MockPeer{MockMSP: "Org2MSP", MockURL: "peer0.org2.com:7051"}
+
+	factory := &mocks.Factory{}
+	p := func(config *environment.Config) (fabric.Factory, error) { return factory, nil }
+
+	t.Run("Success", func(t *testing.T) {
+		factory.SDKReturns(mocks.NewSDK(peer1, peer0, peer2), nil)
+
+		c := newMockCmd(t, p)
+		peers, err := c.DiscoverPeers("Org1MSP")
+		require.NoError(t, err)
+		require.Equal(t, []string{"peer0.org1.com", "peer1.org1.com"}, peers)
+
+		peers, err = c.DiscoverPeers("")
+		require.NoError(t, err)
+		require.Equal(t, []string{"peer0.org1.com", "peer0.org2.com", "peer1.org1.com"}, peers)
+	})
+	t.Run("Configured peer names", func(t *testing.T) {
+		// The peers are reached through a proxy so the host of the URL is not the peer ID
+		proxied0 := &fabmocks.MockPeer{MockMSP: "Org1MSP", MockURL: "grpcs://proxy.example.com:7051"}
+		proxied1 := &fabmocks.MockPeer{MockMSP: "Org1MSP", MockURL: "grpcs://proxy.example.com:8051"}
+		unconfigured := &fabmocks.MockPeer{MockMSP: "Org1MSP", MockURL: "grpcs://peer2.org1.com:7051"}
+
+		endpointConfig := &mocks.EndpointConfig{
+			Network: fab.NetworkConfig{
+				Peers: map[string]fab.PeerConfig{
+					"peer0.org1.com": {URL: "proxy.example.com:7051"},
+				},
+			},
+			MatchedPeers: map[string]*fab.PeerConfig{
+				"grpcs://proxy.example.com:8051": {
+					URL:         "grpcs://proxy.example.com:8051",
+					GRPCOptions: map[string]interface{}{"ssl-target-name-override": "peer1.org1.com"},
+				},
+			},
+		}
+
+		factory.SDKReturns(mocks.NewSDK(proxied0, proxied1, unconfigured).WithEndpointConfig(endpointConfig), nil)
+		defer factory.SDKReturns(mocks.NewSDK(peer1, peer0, peer2), nil)
+
+		peers, err := newMockCmd(t, p).DiscoverPeers("Org1MSP")
+		require.NoError(t, err)
+		require.Equal(t, []string{"peer0.org1.com", "peer1.org1.com", "peer2.org1.com"}, peers)
+	})
+	t.Run("No peers", func(t *testing.T) {
+		factory.SDKReturns(mocks.NewSDK(peer2), nil)
+
+		_, err := newMockCmd(t, p).DiscoverPeers("Org1MSP")
+		require.EqualError(t, err, "no peers discovered on channel [mychannel] for MSP [Org1MSP]")
+	})
+	t.Run("Discovery error", func(t *testing.T) {
+		factory.SDKReturns(mocks.NewSDK().WithDiscoveryError(errors.New("discovery error")), nil)
+
+		_, err := newMockCmd(t, p).DiscoverPeers("Org1MSP")
+		require.EqualError(t, err, "error discovering peers on channel [mychannel]: discovery error")
+	})
+	t.Run("Context error", func(t *testing.T) {
+		errExpected := errors.New("context error")
+		factory.SDKReturns(mocks.NewSDK().WithContextError(errExpected), nil)
+
+		_, err := newMockCmd(t, p).DiscoverPeers("Org1MSP")
+		require.EqualError(t, err, errExpected.Error())
+	})
+	t.Run("SDK error", func(t *testing.T) {
+		errExpected := errors.New("SDK error")
+		factory.SDKReturns(nil, errExpected)
+
+		_, err := newMockCmd(t, p).DiscoverPeers("Org1MSP")
+		require.EqualError(t, err, errExpected.Error())
+	})
+	t.Run("Factory error", func(t *testing.T) {
+		errExpected := errors.New("factory error")
+		p := func(config *environment.Config) (fabric.Factory, error) { return nil, errExpected }
+
+		_, err := newMockCmd(t, p).DiscoverPeers("Org1MSP")
+		require.EqualError(t, err, errExpected.Error())
+	})
+}
+
 func TestBaseCommand_Context(t *testing.T) {
 	p := func(config *environment.Config) (fabric.Factory, error) { return &mocks.Factory{}, nil }
 	c := newMockCmd(t, p)
@@ -198,7 +343,7 @@ func newMockCmdWithReaderWriter(t *testing.T, in io.Reader, out io.Writer, p Fac
 	settings.Streams.Out = out
 
 	settings.Config.CurrentContext = "testctx"
-	settings.Config.Contexts[settings.Config.CurrentContext] = &environment.Context{}
+	settings.Config.Contexts[settings.Config.CurrentContext] = &environment.Context{Channel: "mychannel"}
 
 	c := New(settings, p)
 	require.NotNil(t, c)
@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package commitcmd
 
 import (
+	"fmt"
 	"strconv"
 
 	"github.com/hyperledger/fabric-cli/cmd/commands/common"
@@ -25,10 +26,16 @@ const (
 	desc     = "Commits an approved chaincode"
 	longDesc = `
 The commitcc command allows a client to commit an approved chaincode using custom collection types, such as DCAS, off-ledger, and transient data.
+The peers to which the commit is sent may be specified with --peer or, if --peers-from-discovery is specified, they are resolved
+using Fabric discovery for the channel of the current context (optionally restricted to the MSP given by --mspid).
+If neither is specified then the peers of the current context are used.
 `
 	examples = `
 - Commit a chaincode with DCAS and off-ledger collections:
     $ ./fabric-cli extensions commitcc mycc v1 1 --collections-config [{"name":"coll1","type":"COL_DCAS","policy":"OR('Org1MSP.member','Org2MSP.member')","maxPeerCount":2,"requiredPeerCount":1,"timeToLive":"10m"},{"name":"coll2","type":"COL_OFFLEDGER","policy":"OR('IMPLICIT-ORG.member')"}]
+
+- Commit a chaincode to all peers of Org1MSP in the channel:
+    $ ./fabric-cli extensions commitcc mycc v1 1 --peers-from-discovery --mspid Org1MSP
 `
 )
 
@@ -76,6 +83,8 @@ func newCmd(settings *environment.Settings, p basecmd.FactoryProvider) *cobra.Co
 	flags.StringVar(&c.endorsementPlugin, "endorsement-plugin", "", "sets the endorsement plugin")
 	flags.StringVar(&c.validationPlugin, "validation-plugin", "", "sets the validation plugin")
 	flags.StringArrayVar(&c.peers, "peer", []string{}, "sets a peer to which to send the commit (note that this option may be specified multiple times)")
+	flags.BoolVar(&c.peersFromDiscovery, "peers-from-discovery", false, "resolves the peers to which to send the commit using Fabric discovery")
+	flags.StringVar(&c.mspID, "mspid", "", "restricts the peers resolved using Fabric discovery to the given MSP")
 
 	cmd.SetOutput(c.Settings.Streams.Out)
 
@@ -96,6 +105,8 @@ type command struct {
 	endorsementPlugin   string
 	validationPlugin    string
 	peers               []string
+	peersFromDiscovery  bool
+	mspID               string
 }
 
 // Validate checks the required parameters for run
@@ -121,6 +132,14 @@ func (c *command) Validate() error {
 		return errors.New("sequence must be greater than 0")
 	}
 
+	if c.peersFromDiscovery && len(c.peers) > 0 {
+		return errors.New("--peer and --peers-from-discovery may not both be specified")
+	}
+
+	if c.mspID != "" && !c.peersFromDiscovery {
+		return errors.New("--mspid may only be specified with --peers-from-discovery")
+	}
+
 	return nil
 }
 
@@ -145,9 +164,9 @@ func (c *command) run() error {
 		return errors.WithMessage(err, "invalid sequence")
 	}
 
-	peers := c.peers
-	if len(peers) == 0 {
-		peers = context.Peers
+	peers, err := c.getPeers(context)
+	if err != nil {
+		return err
 	}
 
 	req := resmgmt.LifecycleCommitCCRequest{
@@ -182,3 +201,24 @@ func (c *command) run() error {
 
 	return nil
 }
+
+func (c *command) getPeers(context *environment.Context) ([]string, error) {
+	if !c.peersFromDiscovery {
+		if len(c.peers) == 0 {
+			return context.Peers, nil
+		}
+
+		return c.peers, nil
+	}
+
+	peers, err := c.DiscoverPeers(c.mspID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.Fprintln(fmt.Sprintf("Resolved peers: %s", peers)); err != nil {
+		return nil, err
+	}
+
+	return peers, nil
+}
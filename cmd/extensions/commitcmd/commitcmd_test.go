@@ -7,11 +7,13 @@ SPDX-License-Identifier: Apache-2.0
 package commitcmd
 
 import (
+	"errors"
 	"io"
 	"testing"
 
 	"github.com/hyperledger/fabric-cli/pkg/environment"
 	"github.com/hyperledger/fabric-cli/pkg/fabric"
+	fabmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
 
@@ -77,6 +79,41 @@ func TestCommitCmd(t *testing.T) {
 		require.Contains(t, w.Written(), msgCCCommitted)
 	})
 
+	t.Run("Peers from discovery -> Success", func(t *testing.T) {
+		factory.SDKReturns(mocks.NewSDK(
+			&fabmocks.MockPeer{MockMSP: "Org1MSP", MockURL: "grpcs://peer1.org1.com:7051"},
+			&fabmocks.MockPeer{MockMSP: "Org2MSP", MockURL: "grpcs://peer0.org2.com:7051"},
+		), nil)
+
+		w := &mocks.Writer{}
+		c := newMockCmd(t, w, p, "cc1", "v1", "1", "--peers-from-discovery", "--mspid", "Org1MSP")
+		require.NoError(t, c.Execute())
+		require.Contains(t, w.Written(), "Resolved peers: [peer1.org1.com]")
+		require.Contains(t, w.Written(), msgCCCommitted)
+	})
+
+	t.Run("Peers from discovery -> error", func(t *testing.T) {
+		factory.SDKReturns(mocks.NewSDK().WithDiscoveryError(errors.New("discovery error")), nil)
+
+		w := &mocks.Writer{}
+		c := newMockCmd(t, w, p, "cc1", "v1", "1", "--peers-from-discovery")
+		err := c.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "discovery error")
+	})
+
+	t.Run("Peer and peers from discovery", func(t *testing.T) {
+		w := &mocks.Writer{}
+		c := newMockCmd(t, w, p, "cc1", "v1", "1", "--peer", "peer1", "--peers-from-discovery")
+		require.EqualError(t, c.Execute(), "--peer and --peers-from-discovery may not both be specified")
+	})
+
+	t.Run("MSP without peers from discovery", func(t *testing.T) {
+		w := &mocks.Writer{}
+		c := newMockCmd(t, w, p, "cc1", "v1", "1", "--mspid", "Org1MSP")
+		require.EqualError(t, c.Execute(), "--mspid may only be specified with --peers-from-discovery")
+	})
+
 	t.Run("With policy -> Success", func(t *testing.T) {
 		w := &mocks.Writer{}
 		c := newMockCmd(t, w, p, "cc1", "v1", "1", "--peer", "peer1", "--policy", "OR('Org1.member','Org2.member')")
@@ -8,14 +8,11 @@ package createidxcmd
 
 import (
 	"crypto"
-	"crypto/x509"
 	"encoding/json"
-	"encoding/pem"
 	"fmt"
-	"io/ioutil"
 	"net/http"
-	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -27,8 +24,11 @@ import (
 
 	"github.com/hyperledger/fabric-cli/pkg/environment"
 	"github.com/trustbloc/fabric-cli-ext/cmd/basecmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/fileidx"
 	"github.com/trustbloc/fabric-cli-ext/cmd/file/httpclient"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/keystore"
 	"github.com/trustbloc/fabric-cli-ext/cmd/file/model"
+	"github.com/trustbloc/fabric-cli-ext/cmd/keyutil"
 )
 
 const (
@@ -46,6 +46,18 @@ The create command allows a client to create a new file index document in Sidetr
 		  ".": "/content",
 		  "id": "file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA=="
 		}
+
+- Create a file index document whose update keys are generated and saved to a key store, to be used by subsequent updates (e.g. upload --keystore):
+    $ ./fabric-cli file createidx --path /content --url http://localhost:48326/file --recoverykeyfile ./keys/recover_public.key --keystore ~/.fabric/keystore --noprompt
+
+- Create a file index document and wait (for at most 2 minutes) until the document is anchored and may be resolved:
+    $ ./fabric-cli file createidx --path /content --url http://localhost:48326/file --recoverykeyfile ./keys/recover_public.key --updatekeyfile ./keys/update_public.key --wait=2m --noprompt
+
+- Generate a signed create request without contacting the Sidetree node (e.g. on an air-gapped machine) and save it to a file, to be submitted later with 'file submit':
+    $ ./fabric-cli file createidx --path /content --recoverykeyfile ./keys/recover_public.key --updatekeyfile ./keys/update_public.key --out ./create-request.json --noprompt
+
+- Create a file index document whose commitments are computed with SHA2-512 and which specifies the anchor origin:
+    $ ./fabric-cli file createidx --path /content --url http://localhost:48326/file --recoverykeyfile ./keys/recover_public.key --updatekeyfile ./keys/update_public.key --multihash sha2-512 --anchororigin https://orb.domain1.com --noprompt
 `
 )
 
@@ -56,9 +68,6 @@ const (
 	pathFlag  = "path"
 	pathUsage = "The base path of the endpoint that will be indexed by this document. Example: --path /schema"
 
-	authTokenFlag  = "authtoken"
-	authTokenUsage = "The bearer authorization token that may be required to access some HTTP endpoints. Example: --authtoken mytoken" //nolint: gosec
-
 	recoveryKeyFlag  = "recoverykey"
 	recoveryKeyUsage = "The public key PEM used for recovery of the document. Example: --recoverykey 'MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAEXlp4fWF5rgLthKr20tsJ0tBIE6UmrGuAC8iVG/DaedkSt7HihCx/t2BGjooduaKwEIOmPjx2zBsbkbFrYhhnVw'"
 
@@ -71,13 +80,33 @@ const (
 	updateKeyFileFlag  = "updatekeyfile"
 	updateKeyFileUsage = "The file that contains the public key PEM used for validating the signature of the next update of the document. Example: --updatekeyfile ./update_public.key"
 
+	keyStoreFlag  = "keystore"
+	keyStoreUsage = "The key store directory in which to save newly generated update keys for the document. This flag may be used instead of --updatekey(file). Example: --keystore ~/.fabric/keystore"
+
+	keyTypeFlag  = "keytype"
+	keyTypeUsage = "The type of update keys generated for the key store (--keystore). Supported types are P-256, secp256k1 and Ed25519. Example: --keytype Ed25519"
+
+	multihashFlag  = "multihash"
+	multihashUsage = "The multihash algorithm used to compute the recovery and update commitments of the document. Subsequent operations on the document must use the same algorithm (--multihash). If the Sidetree node exposes its protocol parameters then the algorithm must be allowed by the node. Supported values are sha2-256 and sha2-512. Example: --multihash sha2-512"
+
+	anchorOriginFlag  = "anchororigin"
+	anchorOriginUsage = "The anchor origin of the document, i.e. the system that knows the most recent anchor for the document. This field is optional. Example: --anchororigin https://orb.domain1.com"
+
+	typeFlag  = "type"
+	typeUsage = "The type of entity that the document represents, as set in the Sidetree suffix data. This field is optional. Example: --type 0001"
+
+	waitFlag  = "wait"
+	waitUsage = "If specified then the command waits until the document is anchored and may be resolved with the expected update commitment, and then reports how long anchoring took. An optional timeout may be given (the default is 1m). Example: --wait or --wait=2m"
+
+	outFlag  = "out"
+	outUsage = "If specified then the signed create request is written to the given file instead of being sent to the Sidetree node, so that the request may be generated offline and submitted later using 'file submit'. The URL (--url) is not required in this case. Example: --out ./create-request.json"
+
 	noPromptFlag  = "noprompt"
 	noPromptUsage = "If specified then the operation will not prompt for confirmation. Example: --noprompt"
 
 	msgAborted         = "Operation aborted"
+	msgRequestSaved    = "Create request saved to [%s]"
 	msgContinueOrAbort = "Enter Y to continue or N to abort "
-
-	sha2_256 = 18
 )
 
 var (
@@ -88,16 +117,25 @@ var (
 	errOnlyOneOfRecoveryKeyOrFileRequired = errors.New("only one of recovery key (--recoverykey) or key file (--recoverykeyfile) may be specified")
 	errUpdateKeyOrFileRequired            = errors.New("either update key (--updatekey) or key file (--updatekeyfile) is required")
 	errOnlyOneOfUpdateKeyOrFileRequired   = errors.New("only one of update key (--updatekey) or key file (--updatekeyfile) may be specified")
-	errPublicKeyNotFoundInPEM             = errors.New("public key not found in PEM")
+	errKeyStoreWithUpdateKey              = errors.New("key store (--keystore) may not be specified together with the update key (--updatekey, --updatekeyfile)")
+	errDocumentIDNotFound                 = errors.New("file index document ID not found in response")
+	errKeyStoreWithOut                    = errors.New("key store (--keystore) may not be specified together with --out since the ID of the document is only known once the request is submitted")
+	errWaitWithOut                        = errors.New("--wait may not be specified together with --out")
 )
 
 type httpClient interface {
 	Post(url string, req []byte, opts ...httpclient.RequestOpt) (*httpclient.HTTPResponse, error)
+	Get(url string, opts ...httpclient.RequestOpt) (*httpclient.HTTPResponse, error)
 }
 
 // New returns the file createidx sub-command
 func New(settings *environment.Settings) *cobra.Command {
-	return newCmd(settings, httpclient.New())
+	cfg := httpclient.NewConfig(settings)
+
+	cmd := newCmd(settings, httpclient.New(httpclient.WithConfig(cfg)))
+	cfg.AddFlags(cmd.Flags())
+
+	return cmd
 }
 
 func newCmd(settings *environment.Settings, client httpClient) *cobra.Command {
@@ -128,11 +166,19 @@ func newCmd(settings *environment.Settings, client httpClient) *cobra.Command {
 
 	cmd.Flags().StringVar(&c.url, urlFlag, "", urlUsage)
 	cmd.Flags().StringVar(&c.path, pathFlag, "", pathUsage)
-	cmd.Flags().StringVar(&c.authToken, authTokenFlag, "", authTokenUsage)
+	httpclient.AddAuthTokenFlag(cmd.Flags(), &c.authToken, "some HTTP endpoints")
 	cmd.Flags().StringVar(&c.recoveryKeyString, recoveryKeyFlag, "", recoveryKeyUsage)
 	cmd.Flags().StringVar(&c.recoveryKeyFile, recoveryKeyFileFlag, "", recoveryKeyFileUsage)
 	cmd.Flags().StringVar(&c.updateKeyString, updateKeyFlag, "", updateKeyUsage)
 	cmd.Flags().StringVar(&c.updateKeyFile, updateKeyFileFlag, "", updateKeyFileUsage)
+	cmd.Flags().StringVar(&c.keyStoreDir, keyStoreFlag, "", keyStoreUsage)
+	cmd.Flags().StringVar(&c.keyType, keyTypeFlag, keyutil.KeyTypeP256, keyTypeUsage)
+	cmd.Flags().StringVar(&c.multihash, multihashFlag, fileidx.MultihashSHA2256, multihashUsage)
+	cmd.Flags().StringVar(&c.anchorOrigin, anchorOriginFlag, "", anchorOriginUsage)
+	cmd.Flags().StringVar(&c.entityType, typeFlag, "", typeUsage)
+	cmd.Flags().DurationVar(&c.wait, waitFlag, 0, waitUsage)
+	cmd.Flags().Lookup(waitFlag).NoOptDefVal = fileidx.DefaultWaitTimeout.String()
+	cmd.Flags().StringVar(&c.out, outFlag, "", outUsage)
 	cmd.Flags().BoolVar(&c.noPrompt, noPromptFlag, false, noPromptUsage)
 
 	return cmd
@@ -152,11 +198,21 @@ type command struct {
 	recoveryKeyString string
 	updateKeyFile     string
 	updateKeyString   string
+	keyStoreDir       string
+	keyType           string
+	multihash         string
+	multihashCode     uint
+	anchorOrigin      string
+	entityType        string
+	wait              time.Duration
+	out               string
+
+	updateCommitment string
 }
 
 func (c *command) validate() error {
-	if c.url == "" {
-		return errURLRequired
+	if err := c.validateOut(); err != nil {
+		return err
 	}
 
 	if c.path == "" {
@@ -167,10 +223,25 @@ func (c *command) validate() error {
 		return errInvalidPath
 	}
 
+	multihashCode, err := fileidx.MultihashCode(c.multihash)
+	if err != nil {
+		return err
+	}
+
+	c.multihashCode = multihashCode
+
 	if err := c.validateRecoveryKey(); err != nil {
 		return err
 	}
 
+	if c.keyStoreDir != "" {
+		if c.updateKeyFile != "" || c.updateKeyString != "" {
+			return errKeyStoreWithUpdateKey
+		}
+
+		return nil
+	}
+
 	if err := c.validateUpdateKey(); err != nil {
 		return err
 	}
@@ -179,6 +250,13 @@ func (c *command) validate() error {
 }
 
 func (c *command) run() error {
+	// The protocol is checked before any keys are generated, the request is signed or the user is prompted
+	if c.out == "" {
+		if err := fileidx.CheckProtocol(c.client, fileidx.EndpointURL(c.url), c.authToken, c.multihashCode); err != nil {
+			return err
+		}
+	}
+
 	fileIdxDoc := &model.FileIndexDoc{
 		FileIndex: model.FileIndex{
 			BasePath: c.path,
@@ -193,6 +271,17 @@ func (c *command) run() error {
 		return err
 	}
 
+	var keyStoreEntry *keystore.Entry
+
+	if c.keyStoreDir != "" {
+		keyStoreEntry, err = keystore.NewEntry(c.keyType)
+		if err != nil {
+			return err
+		}
+
+		c.updateKeyString = keyStoreEntry.UpdateKey.PublicKey
+	}
+
 	req, err := c.newCreateRequest(string(docBytes))
 	if err != nil {
 		return err
@@ -209,6 +298,10 @@ func (c *command) run() error {
 		}
 	}
 
+	if c.out != "" {
+		return c.saveRequest(req)
+	}
+
 	resp, err := c.post(req)
 	if err != nil {
 		return err
@@ -223,9 +316,79 @@ func (c *command) run() error {
 		return err
 	}
 
+	if keyStoreEntry == nil && c.wait == 0 {
+		return nil
+	}
+
+	id, err := getDocID(didDocBytes)
+	if err != nil {
+		return err
+	}
+
+	if keyStoreEntry != nil {
+		if err := c.saveKeys(id, keyStoreEntry); err != nil {
+			return err
+		}
+	}
+
+	if c.wait > 0 {
+		return c.waitForAnchor(id)
+	}
+
 	return nil
 }
 
+// saveRequest writes the create request to the file specified by --out
+func (c *command) saveRequest(req []byte) error {
+	err := fileidx.WriteRequest(c.out, &fileidx.OfflineRequest{
+		Operation: fileidx.OperationCreate,
+		Request:   req,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.Fprintln(fmt.Sprintf(msgRequestSaved, c.out))
+}
+
+// saveKeys saves the update keys of the created document to the key store
+func (c *command) saveKeys(id string, entry *keystore.Entry) error {
+	if err := keystore.New(c.keyStoreDir).Put(id, entry); err != nil {
+		return errors.WithMessagef(err, "the file index document [%s] was created but the update keys could not be saved to the key store", id)
+	}
+
+	return c.Fprintln(fmt.Sprintf("\nUpdate keys saved to key store [%s]", c.keyStoreDir))
+}
+
+// waitForAnchor waits until the created document may be resolved with the expected update commitment
+func (c *command) waitForAnchor(id string) error {
+	expected := &fileidx.Expected{
+		UpdateCommitment: c.updateCommitment,
+		Mappings:         map[string]string{".": c.path},
+	}
+
+	elapsed, err := fileidx.Wait(c.client, fileidx.IdentifierURL(c.url, id), c.authToken, expected, c.wait)
+	if err != nil {
+		return err
+	}
+
+	return c.Fprintln(fmt.Sprintf("\nFile index document [%s] anchored in %s", id, elapsed.Round(time.Millisecond)))
+}
+
+// getDocID returns the ID of the given file index document
+func getDocID(didDocBytes []byte) (string, error) {
+	doc := &model.FileIndexDoc{}
+	if err := json.Unmarshal(didDocBytes, doc); err != nil {
+		return "", err
+	}
+
+	if doc.ID == "" {
+		return "", errDocumentIDNotFound
+	}
+
+	return doc.ID, nil
+}
+
 func (c *command) post(data []byte) (*httpclient.HTTPResponse, error) {
 	var reqOpts []httpclient.RequestOpt
 	if c.authToken != "" {
@@ -274,7 +437,7 @@ func (c *command) newCreateRequest(content string) ([]byte, error) {
 		return nil, err
 	}
 
-	recoveryCommitment, err := commitment.GetCommitment(recoveryKey, sha2_256)
+	recoveryCommitment, err := commitment.GetCommitment(recoveryKey, c.multihashCode)
 	if err != nil {
 		return nil, err
 	}
@@ -284,17 +447,27 @@ func (c *command) newCreateRequest(content string) ([]byte, error) {
 		return nil, err
 	}
 
-	updateCommitment, err := commitment.GetCommitment(updateKey, sha2_256)
+	updateCommitment, err := commitment.GetCommitment(updateKey, c.multihashCode)
 	if err != nil {
 		return nil, err
 	}
 
+	c.updateCommitment = updateCommitment
+
+	// The anchor origin is optional and must be omitted from the request if not specified
+	var anchorOrigin interface{}
+	if c.anchorOrigin != "" {
+		anchorOrigin = c.anchorOrigin
+	}
+
 	return client.NewCreateRequest(
 		&client.CreateRequestInfo{
 			OpaqueDocument:     doc,
 			RecoveryCommitment: recoveryCommitment,
 			UpdateCommitment:   updateCommitment,
-			MultihashCode:      sha2_256,
+			MultihashCode:      c.multihashCode,
+			AnchorOrigin:       anchorOrigin,
+			Type:               c.entityType,
 		},
 	)
 }
@@ -321,10 +494,10 @@ func (c *command) recoveryKeyJWK() (*jws.JWK, error) {
 
 func (c *command) recoveryPublicKey() (crypto.PublicKey, error) {
 	if c.recoveryKeyFile != "" {
-		return publicKeyFromFile(c.recoveryKeyFile)
+		return keyutil.PublicKeyFromFile(c.recoveryKeyFile)
 	}
 
-	return publicKeyFromPEM([]byte(c.recoveryKeyString))
+	return keyutil.PublicKeyFromPEM([]byte(c.recoveryKeyString))
 }
 
 func (c *command) updateKeyJWK() (*jws.JWK, error) {
@@ -338,10 +511,10 @@ func (c *command) updateKeyJWK() (*jws.JWK, error) {
 
 func (c *command) updatePublicKey() (crypto.PublicKey, error) {
 	if c.updateKeyFile != "" {
-		return publicKeyFromFile(c.updateKeyFile)
+		return keyutil.PublicKeyFromFile(c.updateKeyFile)
 	}
 
-	return publicKeyFromPEM([]byte(c.updateKeyString))
+	return keyutil.PublicKeyFromPEM([]byte(c.updateKeyString))
 }
 
 func (c *command) getOpaqueDocument(content string) (string, error) {
@@ -358,6 +531,28 @@ func (c *command) getOpaqueDocument(content string) (string, error) {
 	return string(bytes), nil
 }
 
+// validateOut ensures that the URL is specified unless the request is written to a file (--out), in which case
+// the options that require the Sidetree node may not be specified
+func (c *command) validateOut() error {
+	if c.out == "" {
+		if c.url == "" {
+			return errURLRequired
+		}
+
+		return nil
+	}
+
+	if c.keyStoreDir != "" {
+		return errKeyStoreWithOut
+	}
+
+	if c.wait > 0 {
+		return errWaitWithOut
+	}
+
+	return nil
+}
+
 func (c *command) validateUpdateKey() error {
 	if c.updateKeyFile == "" && c.updateKeyString == "" {
 		return errUpdateKeyOrFileRequired
@@ -381,31 +576,3 @@ func (c *command) validateRecoveryKey() error {
 
 	return nil
 }
-
-func publicKeyFromFile(file string) (crypto.PublicKey, error) {
-	keyBytes, err := ioutil.ReadFile(filepath.Clean(file))
-	if err != nil {
-		return nil, err
-	}
-
-	return publicKeyFromPEM(keyBytes)
-}
-
-func publicKeyFromPEM(pubKeyPEM []byte) (crypto.PublicKey, error) {
-	block, _ := pem.Decode(pubKeyPEM)
-	if block == nil {
-		return nil, errPublicKeyNotFoundInPEM
-	}
-
-	key, err := x509.ParsePKIXPublicKey(block.Bytes)
-	if err != nil {
-		return nil, err
-	}
-
-	publicKey, ok := key.(crypto.PublicKey)
-	if !ok {
-		return nil, errors.New("invalid public key")
-	}
-
-	return publicKey, nil
-}
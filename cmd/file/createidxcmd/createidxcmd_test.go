@@ -9,17 +9,26 @@ package createidxcmd
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/hashing"
 
 	"github.com/hyperledger/fabric-cli/pkg/environment"
 
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/fileidx"
 	"github.com/trustbloc/fabric-cli-ext/cmd/file/httpclient"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/keystore"
 	"github.com/trustbloc/fabric-cli-ext/cmd/file/model"
+	"github.com/trustbloc/fabric-cli-ext/cmd/keyutil"
 	"github.com/trustbloc/fabric-cli-ext/cmd/mocks"
 )
 
@@ -53,6 +62,10 @@ func TestCreateIDXCmd_InvalidOptions(t *testing.T) {
 		updatekeyfileFlag   = "--updatekeyfile"
 	)
 
+	t.Run("Unsupported multihash", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, urlFlag, url, pathFlag, path, "--multihash", "sha3-256").Execute(), fileidx.ErrUnsupportedMultihash.Error())
+	})
+
 	t.Run("No options", func(t *testing.T) {
 		require.EqualError(t, newMockCmd(t, nil).Execute(), errURLRequired.Error())
 	})
@@ -80,6 +93,18 @@ func TestCreateIDXCmd_InvalidOptions(t *testing.T) {
 	t.Run("Update key and file specified", func(t *testing.T) {
 		require.EqualError(t, newMockCmd(t, nil, urlFlag, url, pathFlag, path, recoverykeyFlag, recoveryPublicKey, updatekeyFlag, updatePublicKey, updatekeyfileFlag, "./key").Execute(), errOnlyOneOfUpdateKeyOrFileRequired.Error())
 	})
+
+	t.Run("Key store and update key specified", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, urlFlag, url, pathFlag, path, recoverykeyFlag, recoveryPublicKey, updatekeyFlag, updatePublicKey, "--keystore", "./keystore").Execute(), errKeyStoreWithUpdateKey.Error())
+	})
+
+	t.Run("Key store and out specified", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, pathFlag, path, recoverykeyFlag, recoveryPublicKey, "--keystore", "./keystore", "--out", "./request.json").Execute(), errKeyStoreWithOut.Error())
+	})
+
+	t.Run("Wait and out specified", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, pathFlag, path, recoverykeyFlag, recoveryPublicKey, updatekeyFlag, updatePublicKey, "--wait", "--out", "./request.json").Execute(), errWaitWithOut.Error())
+	})
 }
 
 func TestCreateIDXCmd(t *testing.T) {
@@ -100,7 +125,7 @@ func TestCreateIDXCmd(t *testing.T) {
 	args := []string{"--url", "http://localhost:80/file", "--path", "/content", "--recoverykey", recoveryPublicKey, "--updatekey", updatePublicKey, "--authtoken", "mytoken"}
 	header := map[string][]string{"Content-Type": {"application/json"}}
 
-	transport := mocks.NewTransport().WithPostResponse(
+	transport := mocks.NewTransport().WithGetResponse(protocolNotFound()).WithPostResponse(
 		&http.Response{
 			StatusCode: http.StatusOK,
 			Header:     header,
@@ -133,7 +158,7 @@ func TestCreateIDXCmd(t *testing.T) {
 		c := newMockCmdWithReaderWriter(t, &mocks.Reader{Bytes: []byte("Y\n")}, w, transport, args...)
 		err := c.Execute()
 		require.Error(t, err)
-		require.Contains(t, err.Error(), errPublicKeyNotFoundInPEM.Error())
+		require.Contains(t, err.Error(), keyutil.ErrPublicKeyNotFoundInPEM.Error())
 	})
 
 	t.Run("With invalid recovery key", func(t *testing.T) {
@@ -143,7 +168,7 @@ func TestCreateIDXCmd(t *testing.T) {
 		c := newMockCmdWithReaderWriter(t, &mocks.Reader{Bytes: []byte("Y\n")}, w, transport, args...)
 		err := c.Execute()
 		require.Error(t, err)
-		require.Contains(t, err.Error(), errPublicKeyNotFoundInPEM.Error())
+		require.Contains(t, err.Error(), keyutil.ErrPublicKeyNotFoundInPEM.Error())
 	})
 
 	t.Run("With key files", func(t *testing.T) {
@@ -175,10 +200,176 @@ func TestCreateIDXCmd(t *testing.T) {
 		})
 	})
 
+	t.Run("With secp256k1 and Ed25519 keys", func(t *testing.T) {
+		_, recoveryKey, err := keyutil.GenerateKeyPair(keyutil.KeyTypeSecp256k1)
+		require.NoError(t, err)
+
+		_, updateKey, err := keyutil.GenerateKeyPair(keyutil.KeyTypeEd25519)
+		require.NoError(t, err)
+
+		args := []string{"--url", "http://localhost:80/file", "--path", "/content", "--recoverykey", string(recoveryKey), "--updatekey", string(updateKey), "--noprompt"}
+
+		w := &mocks.Writer{}
+		require.NoError(t, newMockCmdWithReaderWriter(t, &mocks.Reader{}, w, transport, args...).Execute())
+		require.Contains(t, w.Written(), string(fileIndexBytes))
+	})
+
+	t.Run("With key store", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "createidx")
+		require.NoError(t, err)
+		defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+		args := []string{"--url", "http://localhost:80/file", "--path", "/content", "--recoverykey", recoveryPublicKey, "--keystore", dir, "--noprompt"}
+
+		w := &mocks.Writer{}
+		require.NoError(t, newMockCmdWithReaderWriter(t, &mocks.Reader{}, w, transport, args...).Execute())
+		require.Contains(t, w.Written(), string(fileIndexBytes))
+		require.Contains(t, w.Written(), "Update keys saved to key store")
+
+		entry, err := keystore.New(dir).Get(fileIdxDoc.ID)
+		require.NoError(t, err)
+		require.NotNil(t, entry.UpdateKey)
+		require.NotNil(t, entry.NextUpdateKey)
+	})
+
+	t.Run("With key store - no ID in response", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "createidx")
+		require.NoError(t, err)
+		defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+		transport := mocks.NewTransport().WithGetResponse(protocolNotFound()).WithPostResponse(
+			&http.Response{
+				StatusCode: http.StatusOK,
+				Header:     header,
+				Body:       mocks.NewResponseBody([]byte("{}")),
+			},
+		)
+
+		args := []string{"--url", "http://localhost:80/file", "--path", "/content", "--recoverykey", recoveryPublicKey, "--keystore", dir, "--noprompt"}
+
+		require.EqualError(t, newMockCmd(t, transport, args...).Execute(), errDocumentIDNotFound.Error())
+	})
+
+	t.Run("With multihash, anchor origin and type", func(t *testing.T) {
+		rt := &recordingTransport{MockTransport: transport}
+
+		args := append(args, "--multihash", fileidx.MultihashSHA2512, "--anchororigin", "https://orb.domain1.com", "--type", "0001", "--noprompt")
+
+		require.NoError(t, newMockCmd(t, rt, args...).Execute())
+		require.Equal(t, "http://localhost:80/file/protocol", rt.getURL)
+
+		createReq := &struct {
+			SuffixData struct {
+				RecoveryCommitment string `json:"recoveryCommitment"`
+				AnchorOrigin       string `json:"anchorOrigin"`
+				Type               string `json:"type"`
+			} `json:"suffixData"`
+		}{}
+		require.NoError(t, json.Unmarshal(rt.postReq, createReq))
+		require.Equal(t, "https://orb.domain1.com", createReq.SuffixData.AnchorOrigin)
+		require.Equal(t, "0001", createReq.SuffixData.Type)
+
+		code, err := hashing.GetMultihashCode(createReq.SuffixData.RecoveryCommitment)
+		require.NoError(t, err)
+		require.Equal(t, uint64(19), code)
+	})
+
+	t.Run("Without anchor origin", func(t *testing.T) {
+		rt := &recordingTransport{MockTransport: transport}
+
+		require.NoError(t, newMockCmd(t, rt, append(args, "--noprompt")...).Execute())
+		require.NotContains(t, string(rt.postReq), "anchorOrigin")
+	})
+
+	t.Run("Multihash not allowed by protocol", func(t *testing.T) {
+		transport := mocks.NewTransport().WithGetResponse(
+			&http.Response{
+				StatusCode: http.StatusOK,
+				Header:     header,
+				Body:       mocks.NewResponseBody([]byte(`{"multihashAlgorithms":[18]}`)),
+			},
+		)
+
+		// The protocol is checked before the user is prompted
+		w := &mocks.Writer{}
+		err := newMockCmdWithReaderWriter(t, &mocks.Reader{Bytes: []byte("Y\n")}, w, transport, append(args, "--multihash", fileidx.MultihashSHA2512)...).Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "multihash [sha2-512] is not allowed by the Sidetree node")
+		require.NotContains(t, w.Written(), msgContinueOrAbort)
+	})
+
+	t.Run("With --wait", func(t *testing.T) {
+		rt := &sidetreeTransport{t: t, createResp: didResolutionBytes}
+
+		argsWait := []string{"--url", "http://localhost:80/file/operations", "--path", "/content", "--recoverykey", recoveryPublicKey, "--updatekey", updatePublicKey, "--wait", "--noprompt"}
+
+		w := &mocks.Writer{}
+		require.NoError(t, newMockCmdWithReaderWriter(t, &mocks.Reader{}, w, rt, argsWait...).Execute())
+		require.Contains(t, w.Written(), string(fileIndexBytes))
+		require.Contains(t, w.Written(), "File index document [file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA==] anchored in")
+		require.Equal(t, "http://localhost:80/file/protocol", rt.getURLs[0])
+		require.Equal(t, "http://localhost:80/file/identifiers/file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA==", rt.getURLs[1])
+	})
+
+	t.Run("With --wait - timeout", func(t *testing.T) {
+		rt := &sidetreeTransport{t: t, createResp: didResolutionBytes, updateCommitment: "xxx"}
+
+		err := newMockCmd(t, rt, append(args, "--wait=10ms", "--noprompt")...).Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "timed out after 10ms waiting for file index document")
+		require.Contains(t, err.Error(), "expecting update commitment")
+	})
+
+	t.Run("With --wait - no ID in response", func(t *testing.T) {
+		rt := &sidetreeTransport{t: t, createResp: []byte("{}")}
+
+		require.EqualError(t, newMockCmd(t, rt, append(args, "--wait", "--noprompt")...).Execute(), errDocumentIDNotFound.Error())
+	})
+
+	t.Run("With --out", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "createidx")
+		require.NoError(t, err)
+		defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+		out := filepath.Join(dir, "request.json")
+
+		w := &mocks.Writer{}
+
+		// The Sidetree node isn't contacted so no transport is required
+		c := newMockCmdWithReaderWriter(t, &mocks.Reader{}, w, nil, "--path", "/content", "--recoverykey", recoveryPublicKey, "--updatekey", updatePublicKey, "--out", out, "--noprompt")
+		require.NoError(t, c.Execute())
+		require.Contains(t, w.Written(), fmt.Sprintf(msgRequestSaved, out))
+
+		r, err := fileidx.ReadRequest(out)
+		require.NoError(t, err)
+		require.Equal(t, fileidx.OperationCreate, r.Operation)
+		require.Empty(t, r.Files)
+
+		createReq := &struct {
+			Type       string `json:"type"`
+			SuffixData struct {
+				RecoveryCommitment string `json:"recoveryCommitment"`
+			} `json:"suffixData"`
+		}{}
+		require.NoError(t, json.Unmarshal(r.Request, createReq))
+		require.Equal(t, "create", createReq.Type)
+		require.NotEmpty(t, createReq.SuffixData.RecoveryCommitment)
+	})
+
+	t.Run("With --out - write error", func(t *testing.T) {
+		c := newMockCmd(t, nil, "--path", "/content", "--recoverykey", recoveryPublicKey, "--updatekey", updatePublicKey, "--out", "./xxx/request.json", "--noprompt")
+
+		err := c.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "error writing request")
+	})
+
 	t.Run("With prompt - N", func(t *testing.T) {
 		w := &mocks.Writer{}
 
-		c := newMockCmdWithReaderWriter(t, &mocks.Reader{Bytes: []byte("N\n")}, w, nil, args...)
+		transport := mocks.NewTransport().WithGetResponse(protocolNotFound())
+
+		c := newMockCmdWithReaderWriter(t, &mocks.Reader{Bytes: []byte("N\n")}, w, transport, args...)
 		require.NoError(t, c.Execute())
 		require.Contains(t, w.Written(), msgContinueOrAbort)
 		require.Contains(t, w.Written(), msgAborted)
@@ -188,14 +379,15 @@ func TestCreateIDXCmd(t *testing.T) {
 	t.Run("With prompt - output stream error", func(t *testing.T) {
 		errExpected := errors.New("output stream error")
 		w := &mocks.Writer{Err: errExpected}
-		c := newMockCmdWithReaderWriter(t, &mocks.Reader{Bytes: []byte("N\n")}, w, nil, args...)
+		transport := mocks.NewTransport().WithGetResponse(protocolNotFound())
+		c := newMockCmdWithReaderWriter(t, &mocks.Reader{Bytes: []byte("N\n")}, w, transport, args...)
 		require.EqualError(t, c.Execute(), errExpected.Error())
 	})
 
 	t.Run("With client error", func(t *testing.T) {
 		errExpected := errors.New("injected error")
 
-		transport := mocks.NewTransport().WithPostError(errExpected)
+		transport := mocks.NewTransport().WithGetResponse(protocolNotFound()).WithPostError(errExpected)
 
 		c := newMockCmd(t, transport, append(args, "--noprompt")...)
 		err := c.Execute()
@@ -206,7 +398,7 @@ func TestCreateIDXCmd(t *testing.T) {
 	t.Run("With HTTP error", func(t *testing.T) {
 		expectedResponse := "server error"
 
-		transport := mocks.NewTransport().WithPostResponse(
+		transport := mocks.NewTransport().WithGetResponse(protocolNotFound()).WithPostResponse(
 			&http.Response{
 				StatusCode: http.StatusInternalServerError,
 				Header:     header,
@@ -221,6 +413,79 @@ func TestCreateIDXCmd(t *testing.T) {
 	})
 }
 
+// recordingTransport records the URL of the last GET and the last POST request
+type recordingTransport struct {
+	*mocks.MockTransport
+	getURL  string
+	postReq []byte
+}
+
+func (m *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method == http.MethodPost {
+		reqBytes, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		m.postReq = reqBytes
+	} else {
+		m.getURL = req.URL.String()
+	}
+
+	return m.MockTransport.RoundTrip(req)
+}
+
+// sidetreeTransport simulates a Sidetree node that doesn't expose its protocol parameters and that resolves the created
+// document with the update commitment of the create request (or the given update commitment, if specified)
+type sidetreeTransport struct {
+	t                *testing.T
+	createResp       []byte
+	updateCommitment string
+	getURLs          []string
+}
+
+func (m *sidetreeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method == http.MethodPost {
+		reqBytes, err := ioutil.ReadAll(req.Body)
+		require.NoError(m.t, err)
+
+		createReq := &struct {
+			Delta struct {
+				UpdateCommitment string `json:"updateCommitment"`
+			} `json:"delta"`
+		}{}
+		require.NoError(m.t, json.Unmarshal(reqBytes, createReq))
+
+		if m.updateCommitment == "" {
+			m.updateCommitment = createReq.Delta.UpdateCommitment
+		}
+
+		return &http.Response{StatusCode: http.StatusOK, Body: mocks.NewResponseBody(m.createResp)}, nil
+	}
+
+	m.getURLs = append(m.getURLs, req.URL.String())
+
+	if strings.HasSuffix(req.URL.Path, "/protocol") {
+		return protocolNotFound(), nil
+	}
+
+	docBytes, err := json.Marshal(&model.FileIndexDoc{FileIndex: model.FileIndex{BasePath: "/content", Mappings: map[string]string{".": "/content"}}})
+	require.NoError(m.t, err)
+
+	respBytes, err := json.Marshal(&model.DIDResolution{
+		DIDDocument:    docBytes,
+		MethodMetadata: json.RawMessage(fmt.Sprintf(`{"updateCommitment":"%s","published":true}`, m.updateCommitment)),
+	})
+	require.NoError(m.t, err)
+
+	return &http.Response{StatusCode: http.StatusOK, Body: mocks.NewResponseBody(respBytes)}, nil
+}
+
+// protocolNotFound returns the response of a Sidetree node that doesn't expose its protocol parameters
+func protocolNotFound() *http.Response {
+	return &http.Response{StatusCode: http.StatusNotFound, Body: mocks.NewResponseBody(nil)}
+}
+
 func newMockCmd(t *testing.T, rt http.RoundTripper, args ...string) *cobra.Command {
 	return newMockCmdWithReaderWriter(t, &mocks.Reader{}, &mocks.Writer{}, rt, args...)
 }
@@ -0,0 +1,175 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package deactivateidxcmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/basecmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/fileidx"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/httpclient"
+)
+
+const (
+	use      = "deactivateidx"
+	desc     = "Deactivate a file index document using the recovery key"
+	longDesc = `
+The deactivateidx command deactivates a Sidetree file index document by issuing a Sidetree deactivate request signed with the
+recovery private key. A deactivated document can no longer be resolved, updated or recovered. The files themselves are not removed from DCAS.
+`
+	examples = `
+- Deactivate the given file index document:
+    $ ./fabric file deactivateidx --idxurl http://localhost:48326/file/identifiers/file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA== --signingkeyfile ./keys/recover.key
+
+- Deactivate the given file index document using a recovery key that is held in a PKCS#11 token:
+    $ ./fabric file deactivateidx --idxurl http://localhost:48326/file/identifiers/file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA== --signer 'pkcs11:lib=/usr/lib/softhsm/libsofthsm2.so;token=fabric;pin=1234;label=recover'
+`
+)
+
+const (
+	fileIndexURLFlag  = "idxurl"
+	fileIndexURLUsage = "The URL of the file index Sidetree document. Example: --idxurl http://localhost:48326/file/identifiers/file:idx:1234"
+
+	fileIndexSigningKeyFlag  = "signingkey"
+	fileIndexSigningKeyUsage = "The recovery private key PEM used for signing the deactivation of the index document. Example: --signingkey 'MHcCAQEEILmfa4yss8nsTJK2hKl+LAoiwW3p+eQzaHfITI9z8ptpoAoGCCqGSM49AwEHoUQDQgAEMd1/e/Nxh73bK12PEEcNSY9HxnP0N8er9ww9rjq1tNcsqfRjlL0bdTh9Basfn/4JrQHUHc6uS99yjQc+0u2bVg'"
+
+	fileIndexSigningKeyFileFlag  = "signingkeyfile"
+	fileIndexSigningKeyFileUsage = "The file that contains the recovery private key PEM used for signing the deactivation of the index document. Example: --signingkeyfile ./keys/recover.key"
+
+	signerFlag  = "signer"
+	signerUsage = "The signer that holds the recovery private key used for signing the deactivation of the index document, so that the private key is never exposed. This flag may be used instead of --signingkey(file). The signer is one of: a PKCS#11 token (pkcs11:lib=<library>;token=<token label>;pin=<user PIN>;label=<key label>), an external command that reads the JWS signing input from stdin and writes the base64url-encoded signature to stdout (cmd:<command> [args]) or an HTTP signing service (http(s)://<URL>). Example: --signer 'pkcs11:lib=/usr/lib/softhsm/libsofthsm2.so;token=fabric;pin=1234;label=update'"
+
+	signerPublicKeyFileFlag  = "signerpublickeyfile"
+	signerPublicKeyFileUsage = "The file that contains the public key PEM of the signer. Required for command and HTTP signers. Example: --signerpublickeyfile ./keys/signer_public.pem"
+
+	multihashFlag  = "multihash"
+	multihashUsage = "The multihash algorithm used to compute the reveal value of the deactivation. The reveal value must be computed with the same algorithm as the commitment of the previous operation on the index document (see 'file createidx --multihash'). Supported values are sha2-256 and sha2-512. Example: --multihash sha2-512"
+
+	noPromptFlag  = "noprompt"
+	noPromptUsage = "If specified then the deactivate operation will not prompt for confirmation. Example: --noprompt"
+
+	msgDocumentDeactivated = "File index document successfully deactivated!"
+	msgAborted             = "Operation aborted"
+	msgContinueOrAbort     = "Enter Y to continue or N to abort "
+)
+
+var errFileIndexURLRequired = errors.New("file index URL (--idxurl) is required")
+
+// New returns the file deactivateidx sub-command
+func New(settings *environment.Settings) *cobra.Command {
+	cfg := httpclient.NewConfig(settings)
+
+	cmd := newCmd(settings, httpclient.New(httpclient.WithConfig(cfg)))
+	cfg.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+func newCmd(settings *environment.Settings, client fileidx.HTTPClient) *cobra.Command {
+	c := &command{
+		Command: basecmd.New(settings, nil),
+		client:  client,
+	}
+
+	cmd := &cobra.Command{
+		Use:     use,
+		Short:   desc,
+		Long:    longDesc,
+		Example: examples,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return c.validate()
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return c.run()
+		},
+	}
+
+	cmd.SetOutput(c.Settings.Streams.Out)
+	cmd.SilenceUsage = true
+
+	cmd.Flags().StringVar(&c.fileIndexURL, fileIndexURLFlag, "", fileIndexURLUsage)
+	httpclient.AddAuthTokenFlag(cmd.Flags(), &c.authToken, "the URL specified by --idxurl")
+	cmd.Flags().StringVar(&c.keys.SigningKeyString, fileIndexSigningKeyFlag, "", fileIndexSigningKeyUsage)
+	cmd.Flags().StringVar(&c.keys.SigningKeyFile, fileIndexSigningKeyFileFlag, "", fileIndexSigningKeyFileUsage)
+	cmd.Flags().StringVar(&c.keys.Signer, signerFlag, "", signerUsage)
+	cmd.Flags().StringVar(&c.keys.SignerPublicKeyFile, signerPublicKeyFileFlag, "", signerPublicKeyFileUsage)
+	cmd.Flags().StringVar(&c.multihash, multihashFlag, fileidx.MultihashSHA2256, multihashUsage)
+	cmd.Flags().BoolVar(&c.noPrompt, noPromptFlag, false, noPromptUsage)
+
+	return cmd
+}
+
+// command implements the deactivateidx command
+type command struct {
+	*basecmd.Command
+	client fileidx.HTTPClient
+
+	fileIndexURL  string
+	authToken     string
+	keys          fileidx.RecoveryKeys
+	multihash     string
+	multihashCode uint
+	noPrompt      bool
+}
+
+func (c *command) validate() error {
+	if c.fileIndexURL == "" {
+		return errFileIndexURLRequired
+	}
+
+	if _, err := fileidx.UpdateURL(c.fileIndexURL); err != nil {
+		return err
+	}
+
+	multihashCode, err := fileidx.MultihashCode(c.multihash)
+	if err != nil {
+		return err
+	}
+
+	c.multihashCode = multihashCode
+
+	return c.keys.ValidateSigningKey()
+}
+
+func (c *command) run() error {
+	if err := fileidx.CheckDocumentProtocol(c.client, c.fileIndexURL, c.authToken, c.multihashCode); err != nil {
+		return err
+	}
+
+	if !c.noPrompt {
+		confirmed, e := c.confirmDeactivate()
+		if e != nil {
+			return e
+		}
+
+		if !confirmed {
+			return c.Fprintln(msgAborted)
+		}
+	}
+
+	if err := fileidx.Deactivate(c.client, c.fileIndexURL, c.authToken, &c.keys, fileidx.WithMultihash(c.multihashCode), fileidx.WithProtocolChecked()); err != nil {
+		return err
+	}
+
+	return c.Fprintln(msgDocumentDeactivated)
+}
+
+// confirmDeactivate prompts the user for confirmation of the deactivation
+func (c *command) confirmDeactivate() (bool, error) {
+	prompt := fmt.Sprintf("Deactivating file index document [%s]. This operation cannot be undone.\n%s", c.fileIndexURL, msgContinueOrAbort)
+
+	if err := c.Fprintln(prompt); err != nil {
+		return false, err
+	}
+
+	return strings.ToLower(c.Prompt()) == "y", nil
+}
@@ -0,0 +1,122 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package deactivateidxcmd
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/fileidx"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/httpclient"
+	"github.com/trustbloc/fabric-cli-ext/cmd/mocks"
+)
+
+const idxURL = "http://localhost:48326/file/identifiers/file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA"
+
+func TestNew(t *testing.T) {
+	require.NotNil(t, New(environment.NewDefaultSettings()))
+}
+
+func TestDeactivateIdxCmd_InvalidOptions(t *testing.T) {
+	t.Run("No --idxurl", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil).Execute(), errFileIndexURLRequired.Error())
+	})
+	t.Run("Invalid --idxurl", func(t *testing.T) {
+		err := newMockCmd(t, nil, "--idxurl", "http://localhost:48326/file").Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid file index URL")
+	})
+	t.Run("No signing key", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, "--idxurl", idxURL).Execute(), fileidx.ErrSigningKeyOrFileRequired.Error())
+	})
+}
+
+func TestDeactivateIdxCmd(t *testing.T) {
+	args := []string{"--idxurl", idxURL, "--signingkeyfile", "../testdata/recover2_private.key"}
+
+	t.Run("With --noprompt", func(t *testing.T) {
+		client := &mockHTTPClient{postResponse: &httpclient.HTTPResponse{StatusCode: http.StatusOK}}
+		w := &mocks.Writer{}
+		require.NoError(t, newMockCmdWithReaderWriter(t, &mocks.Reader{}, w, client, append(args, "--noprompt")...).Execute())
+		require.Contains(t, w.Written(), msgDocumentDeactivated)
+		require.Contains(t, string(client.postReq), `"type":"deactivate"`)
+	})
+	t.Run("With prompt - Y", func(t *testing.T) {
+		client := &mockHTTPClient{postResponse: &httpclient.HTTPResponse{StatusCode: http.StatusOK}}
+		w := &mocks.Writer{}
+		require.NoError(t, newMockCmdWithReaderWriter(t, &mocks.Reader{Bytes: []byte("Y\n")}, w, client, args...).Execute())
+		require.Contains(t, w.Written(), "This operation cannot be undone")
+		require.Contains(t, w.Written(), msgDocumentDeactivated)
+	})
+	t.Run("With prompt - N", func(t *testing.T) {
+		client := &mockHTTPClient{}
+		w := &mocks.Writer{}
+		require.NoError(t, newMockCmdWithReaderWriter(t, &mocks.Reader{Bytes: []byte("N\n")}, w, client, args...).Execute())
+		require.Contains(t, w.Written(), msgAborted)
+		require.Empty(t, client.postReq)
+	})
+	t.Run("With --signer", func(t *testing.T) {
+		client := &mockHTTPClient{postResponse: &httpclient.HTTPResponse{StatusCode: http.StatusOK}}
+		w := &mocks.Writer{}
+
+		// The command signer ignores the signing input and returns a fixed signature
+		signerArgs := []string{"--idxurl", idxURL, "--signer", "cmd:echo AQID", "--signerpublickeyfile", "../testdata/recover2_public.key", "--noprompt"}
+
+		require.NoError(t, newMockCmdWithReaderWriter(t, &mocks.Reader{}, w, client, signerArgs...).Execute())
+		require.Contains(t, w.Written(), msgDocumentDeactivated)
+		require.Contains(t, string(client.postReq), `"type":"deactivate"`)
+		require.Contains(t, string(client.postReq), ".AQID")
+	})
+	t.Run("Deactivate error", func(t *testing.T) {
+		client := &mockHTTPClient{postResponse: &httpclient.HTTPResponse{StatusCode: http.StatusInternalServerError, ErrorMsg: "server error"}}
+
+		err := newMockCmd(t, client, append(args, "--noprompt")...).Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "server error")
+	})
+}
+
+type mockHTTPClient struct {
+	postResponse *httpclient.HTTPResponse
+	postReq      []byte
+}
+
+func (m *mockHTTPClient) Get(string, ...httpclient.RequestOpt) (*httpclient.HTTPResponse, error) {
+	// The Sidetree node doesn't expose its protocol parameters
+	return &httpclient.HTTPResponse{StatusCode: http.StatusNotFound}, nil
+}
+
+func (m *mockHTTPClient) Post(_ string, req []byte, _ ...httpclient.RequestOpt) (*httpclient.HTTPResponse, error) {
+	m.postReq = req
+
+	return m.postResponse, nil
+}
+
+func newMockCmd(t *testing.T, client fileidx.HTTPClient, args ...string) *cobra.Command {
+	return newMockCmdWithReaderWriter(t, &mocks.Reader{}, &mocks.Writer{}, client, args...)
+}
+
+func newMockCmdWithReaderWriter(t *testing.T, in io.Reader, w io.Writer, client fileidx.HTTPClient, args ...string) *cobra.Command {
+	settings := environment.NewDefaultSettings()
+	settings.Streams.Out = w
+	settings.Streams.In = in
+
+	settings.Config.CurrentContext = "testctx"
+	settings.Config.Contexts[settings.Config.CurrentContext] = &environment.Context{}
+
+	c := newCmd(settings, client)
+	require.NotNil(t, c)
+
+	c.SetArgs(args)
+
+	return c
+}
@@ -12,13 +12,21 @@ import (
 	"github.com/hyperledger/fabric-cli/pkg/environment"
 
 	"github.com/trustbloc/fabric-cli-ext/cmd/file/createidxcmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/deactivateidxcmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/getcmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/keygencmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/lscmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/mvcmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/recoveridxcmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/rmcmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/submitcmd"
 	"github.com/trustbloc/fabric-cli-ext/cmd/file/uploadcmd"
 )
 
 const (
 	use      = "file"
 	desc     = "Manages file uploads"
-	longDesc = "The file command allows you to upload files and create file indexes as Sidetree documents"
+	longDesc = "The file command allows you to upload and download files and to manage file indexes, which are stored as Sidetree documents. Use keygen to generate key pairs for Sidetree operations. Use submit to submit requests that were generated offline (--out). Commands that connect to a Sidetree or DCAS endpoint share the same HTTP flags. These configure TLS (--cacerts, --clientcert, --clientkey, --insecureskipverify), the timeout (--httptimeout), the proxy (--proxy) and the credentials store (--credentials)."
 )
 
 // New is the entry point to the file plugin
@@ -35,6 +43,14 @@ func New(settings *environment.Settings) *cobra.Command {
 	cmd.AddCommand(
 		createidxcmd.New(settings),
 		uploadcmd.New(settings),
+		rmcmd.New(settings),
+		mvcmd.New(settings),
+		lscmd.New(settings),
+		getcmd.New(settings),
+		recoveridxcmd.New(settings),
+		deactivateidxcmd.New(settings),
+		keygencmd.New(settings),
+		submitcmd.New(settings),
 	)
 
 	return cmd
@@ -30,4 +30,17 @@ func TestNew(t *testing.T) {
 	require.Contains(t, w.Written(), "createidx")
 	// Make sure that the upload command was added
 	require.Contains(t, w.Written(), "upload")
+	// Make sure that the rm and mv commands were added
+	require.Contains(t, w.Written(), "Remove a mapping from a file index document")
+	require.Contains(t, w.Written(), "Rename a mapping in a file index document")
+	// Make sure that the ls and get commands were added
+	require.Contains(t, w.Written(), "List the mappings in a file index document")
+	require.Contains(t, w.Written(), "Download a file using the file index")
+	// Make sure that the recoveridx and deactivateidx commands were added
+	require.Contains(t, w.Written(), "Recover a file index document using the recovery key")
+	require.Contains(t, w.Written(), "Deactivate a file index document using the recovery key")
+	// Make sure that the keygen command was added
+	require.Contains(t, w.Written(), "Generate a key pair for Sidetree file index operations")
+	// Make sure that the submit command was added
+	require.Contains(t, w.Written(), "Submit a previously generated Sidetree request for a file index document")
 }
@@ -0,0 +1,156 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fileidx
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/httpclient"
+)
+
+// File is the file upload request that is stored in DCAS
+type File struct {
+	ContentType string `json:"contentType"`
+	Content     []byte `json:"content"`
+}
+
+// DCASID returns the ID under which the peer stores the given file in DCAS. The peer stores the
+// upload request, which is normalized (by unmarshalling and marshalling the JSON so that the fields are
+// sorted) and hashed using SHA-256. The ID is the base64 URL encoding of the hash.
+func DCASID(contentType string, content []byte) (string, error) {
+	reqBytes, err := json.Marshal(&File{
+		ContentType: contentType,
+		Content:     content,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(reqBytes, &doc); err != nil {
+		return "", err
+	}
+
+	normalized, err := json.Marshal(doc)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(normalized)
+
+	return base64.URLEncoding.EncodeToString(hash[:]), nil
+}
+
+// maxBackoff is the maximum time to wait before retrying an upload
+const maxBackoff = 30 * time.Second
+
+// Upload uploads the given file to the DCAS endpoint at the given URL and returns the DCAS ID of the file.
+// If retries are enabled (see WithRetries) then the upload is retried on transient errors.
+func Upload(c HTTPClient, url, authToken, contentType string, content []byte, opts ...Opt) (string, error) {
+	o := resolveOptions(opts)
+
+	reqBytes, err := json.Marshal(&File{
+		ContentType: contentType,
+		Content:     content,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := postWithRetries(o, func() (*httpclient.HTTPResponse, error) {
+		return c.Post(url, reqBytes, authOpts(authToken)...)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return uploadedID(resp)
+}
+
+// postWithRetries invokes the given post function, retrying on transient errors if retries are enabled
+func postWithRetries(o *options, post func() (*httpclient.HTTPResponse, error)) (*httpclient.HTTPResponse, error) {
+	backoff := o.retryBackoff
+
+	for attempt := 0; ; attempt++ {
+		resp, err := post()
+		if attempt < o.maxRetries && isTransient(resp, err) {
+			time.Sleep(backoff)
+
+			backoff *= 2
+			if backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+
+			continue
+		}
+
+		return resp, err
+	}
+}
+
+func uploadedID(resp *httpclient.HTTPResponse) (string, error) {
+	if resp.StatusCode != http.StatusOK {
+		if resp.StatusCode == http.StatusUnauthorized {
+			return "", errors.Errorf("status code %d: %s - Did you provide an authorization token (--contentauthtoken)?", resp.StatusCode, resp.ErrorMsg)
+		}
+
+		return "", errors.Errorf("status code %d: %s", resp.StatusCode, resp.ErrorMsg)
+	}
+
+	var fileID string
+	if err := json.Unmarshal(resp.Payload, &fileID); err != nil {
+		return "", err
+	}
+
+	return fileID, nil
+}
+
+// isTransient returns true if the request failed with a network error (e.g. connection refused) or timeout or
+// with an HTTP status that indicates that the request may succeed if it's retried
+func isTransient(resp *httpclient.HTTPResponse, err error) bool {
+	if err != nil {
+		return isNetworkError(err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusInternalServerError,
+		http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// isNetworkError returns true if the given error is a network error or a timeout. Since url.Error is itself a
+// net.Error, the error that it wraps is checked instead so that, for example, TLS verification errors aren't retried.
+func isNetworkError(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		if urlErr.Timeout() {
+			return true
+		}
+
+		err = urlErr.Err
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr)
+}
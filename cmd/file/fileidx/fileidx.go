@@ -0,0 +1,561 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fileidx
+
+import (
+	"crypto"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/trustbloc/sidetree-core-go/pkg/commitment"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+	"github.com/trustbloc/sidetree-core-go/pkg/patch"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/pubkey"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/client"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/httpclient"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/keystore"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/model"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/signer"
+	"github.com/trustbloc/fabric-cli-ext/cmd/keyutil"
+)
+
+const (
+	// multihash codes supported by Sidetree (sha2_256 is the default)
+	sha2_256 = 18
+	sha2_512 = 19
+
+	mappingsBasePath = "/fileIndex/mappings/"
+)
+
+// JSON patch operations
+const (
+	OpAdd     = "add"
+	OpReplace = "replace"
+	OpRemove  = "remove"
+	OpMove    = "move"
+)
+
+var (
+	// ErrNextUpdateKeyOrFileRequired indicates that neither the next update key nor key file was specified
+	ErrNextUpdateKeyOrFileRequired = errors.New("either next update key (--nextupdatekey) or key file (--nextupdatekeyfile) is required")
+	// ErrOnlyOneOfNextUpdateKeyOrFileRequired indicates that both the next update key and key file were specified
+	ErrOnlyOneOfNextUpdateKeyOrFileRequired = errors.New("only one of next update key (--nextupdatekey) or key file (--nextupdatekeyfile) may be specified")
+	// ErrSigningKeyOrFileRequired indicates that neither the signing key, key file nor signer was specified
+	ErrSigningKeyOrFileRequired = errors.New("either signing key (--signingkey), key file (--signingkeyfile) or signer (--signer) is required")
+	// ErrOnlyOneOfSigningKeyOrFileRequired indicates that more than one of the signing key, key file and signer were specified
+	ErrOnlyOneOfSigningKeyOrFileRequired = errors.New("only one of signing key (--signingkey), key file (--signingkeyfile) or signer (--signer) may be specified")
+	// ErrSignerPublicKeyWithoutSigner indicates that the public key of a signer was specified without the signer
+	ErrSignerPublicKeyWithoutSigner = errors.New("signer public key file (--signerpublickeyfile) may only be specified together with a signer (--signer)")
+	// ErrKeyStoreWithUpdateKeys indicates that a key store was specified together with the signing key, signer or next update key
+	ErrKeyStoreWithUpdateKeys = errors.New("key store (--keystore) may not be specified together with the signing key (--signingkey, --signingkeyfile, --signer) or next update key (--nextupdatekey, --nextupdatekeyfile)")
+	// ErrKeyStoreWithUpdateRequest indicates that a key store was specified for an update request that is submitted later
+	ErrKeyStoreWithUpdateRequest = errors.New("key store (--keystore) may not be used for an update request that is submitted later since the keys would be rotated before the request is submitted")
+)
+
+// HTTPClient is the HTTP client used to retrieve and update file index documents
+type HTTPClient interface {
+	Post(url string, req []byte, opts ...httpclient.RequestOpt) (*httpclient.HTTPResponse, error)
+	Get(url string, opts ...httpclient.RequestOpt) (*httpclient.HTTPResponse, error)
+}
+
+// Patch is a JSON patch operation on a file index document
+type Patch struct {
+	Op    string `json:"op"`
+	From  string `json:"from,omitempty"`
+	Path  string `json:"path"`
+	Value string `json:"value,omitempty"`
+}
+
+// Resolution contains a resolved file index document along with its Sidetree method metadata
+type Resolution struct {
+	FileIndex        *model.FileIndex
+	UpdateCommitment string
+	Published        bool
+}
+
+type methodMetadata struct {
+	UpdateCommitment string `json:"updateCommitment"`
+	Published        *bool  `json:"published"`
+}
+
+// UpdateKeys contains the keys used to sign an update of a file index document and
+// to create the commitment for the next update. Each key is given either as a PEM or as a file.
+// Instead of the signing key, a Signer spec (see signer.Validate) may be given so that the private
+// key is never exposed, in which case SignerPublicKeyFile holds the public key of the signer.
+// Alternatively, the keys are taken from the key store in KeyStoreDir and rotated after the update.
+// Multihash is the algorithm of the commitment and reveal value of the update (sha2-256 if not specified).
+type UpdateKeys struct {
+	SigningKeyFile      string
+	SigningKeyString    string
+	Signer              string
+	SignerPublicKeyFile string
+	NextUpdateKeyFile   string
+	NextUpdateKeyString string
+	KeyStoreDir         string
+	Multihash           string
+
+	multihashCode uint
+}
+
+// Validate ensures that the multihash algorithm is supported and that exactly one of the PEM or file is given
+// for each key (or a signer instead of the signing key) or, if a key store is specified, that no keys are given
+func (k *UpdateKeys) Validate() error {
+	k.multihashCode = sha2_256
+
+	if k.Multihash != "" {
+		multihashCode, err := MultihashCode(k.Multihash)
+		if err != nil {
+			return err
+		}
+
+		k.multihashCode = multihashCode
+	}
+
+	if k.KeyStoreDir != "" {
+		if k.SigningKeyFile != "" || k.SigningKeyString != "" || k.Signer != "" || k.SignerPublicKeyFile != "" ||
+			k.NextUpdateKeyFile != "" || k.NextUpdateKeyString != "" {
+			return ErrKeyStoreWithUpdateKeys
+		}
+
+		return nil
+	}
+
+	if err := k.signingKey().validate(); err != nil {
+		return err
+	}
+
+	return validateKey(k.NextUpdateKeyFile, k.NextUpdateKeyString, ErrNextUpdateKeyOrFileRequired, ErrOnlyOneOfNextUpdateKeyOrFileRequired)
+}
+
+// MappingPath returns the JSON patch path of the given mapping. The name is escaped according
+// to RFC 6901 so that mapping names that contain a '/' may be used.
+func MappingPath(name string) string {
+	return mappingsBasePath + strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
+
+// UpdateURL returns the Sidetree operations URL for the given file index document URL
+func UpdateURL(idxURL string) (string, error) {
+	base, err := BaseURL(idxURL)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s/operations", base), nil
+}
+
+// EndpointURL returns the Sidetree endpoint URL for the given endpoint or operations URL, e.g.
+// http://localhost:48326/file/operations => http://localhost:48326/file
+func EndpointURL(u string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(u, "/"), "/operations")
+}
+
+// IdentifierURL returns the URL of the file index document with the given ID at the given Sidetree endpoint or operations URL
+func IdentifierURL(u, id string) string {
+	return fmt.Sprintf("%s/identifiers/%s", EndpointURL(u), id)
+}
+
+// BaseURL returns the Sidetree endpoint URL (e.g. http://localhost:48326/file) for the given file index document URL
+func BaseURL(idxURL string) (string, error) {
+	pos := strings.LastIndex(idxURL, "/identifiers")
+	if pos == -1 {
+		return "", errors.Errorf("invalid file index URL: [%s] - the file index ID must be prefixed by identifiers/", idxURL)
+	}
+
+	return idxURL[0:pos], nil
+}
+
+// Get resolves the file index document at the given URL
+func Get(c HTTPClient, idxURL, authToken string) (*model.FileIndex, error) {
+	r, err := Resolve(c, idxURL, authToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.FileIndex, nil
+}
+
+// Resolve resolves the file index document at the given URL and returns the document
+// along with its Sidetree method metadata
+func Resolve(c HTTPClient, idxURL, authToken string) (*Resolution, error) {
+	resp, err := c.Get(idxURL, authOpts(authToken)...)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, errors.Errorf("file index document [%s] not found", idxURL)
+		}
+
+		if resp.StatusCode == http.StatusUnauthorized {
+			return nil, errors.Errorf("error retrieving file index document [%s]. Status code %d: %s - Did you provide an authorization token (--authtoken)?", idxURL, resp.StatusCode, resp.ErrorMsg)
+		}
+
+		return nil, errors.Errorf("error retrieving file index document [%s] status code %d: %s", idxURL, resp.StatusCode, resp.ErrorMsg)
+	}
+
+	var r model.DIDResolution
+	if errUnmarshal := json.Unmarshal(resp.Payload, &r); errUnmarshal != nil {
+		return nil, fmt.Errorf("unmarshal data return from sidtree %w", errUnmarshal)
+	}
+
+	didDocBytes := resp.Payload
+	// check if data is did resolution
+	if len(r.DIDDocument) != 0 {
+		didDocBytes = r.DIDDocument
+	}
+
+	fileIdxDoc := &model.FileIndexDoc{}
+	err = json.Unmarshal(didDocBytes, fileIdxDoc)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := &methodMetadata{}
+	if len(r.MethodMetadata) != 0 {
+		if err := json.Unmarshal(r.MethodMetadata, metadata); err != nil {
+			return nil, errors.WithMessagef(err, "invalid method metadata for file index document [%s]", idxURL)
+		}
+	}
+
+	return &Resolution{
+		FileIndex:        &fileIdxDoc.FileIndex,
+		UpdateCommitment: metadata.UpdateCommitment,
+		// A node that doesn't report whether the document is published only resolves published documents
+		Published: metadata.Published == nil || *metadata.Published,
+	}, nil
+}
+
+// Update applies the given patches to the file index document at the given URL using a signed Sidetree update request.
+// If a key store is specified then the update keys of the document are loaded from the key store and are rotated
+// once the update succeeds. The multihash algorithm is checked against the protocol parameters of the Sidetree node
+// before the request is signed.
+func Update(c HTTPClient, idxURL, authToken string, keys *UpdateKeys, patches []Patch, opts ...Opt) error {
+	o := resolveOptions(opts)
+
+	if err := o.checkProtocol(c, idxURL, authToken); err != nil {
+		return err
+	}
+
+	return withKeys(idxURL, keys, func(k *UpdateKeys) error {
+		return update(c, idxURL, authToken, k, patches, o)
+	})
+}
+
+// NewUpdateRequest returns the signed Sidetree request that applies the given patches to the file index document at
+// the given URL, without contacting the Sidetree node, so that the request may be submitted later. A key store may not
+// be used since its keys would have to be rotated before the request is submitted. (The HTTP client is only used by an
+// HTTP signer.)
+func NewUpdateRequest(c HTTPClient, idxURL string, keys *UpdateKeys, patches []Patch, opts ...Opt) ([]byte, error) {
+	if keys.KeyStoreDir != "" {
+		return nil, ErrKeyStoreWithUpdateRequest
+	}
+
+	return newUpdateRequest(c, idxURL, keys, patches, resolveOptions(opts).multihashCode)
+}
+
+// withKeys invokes the given function with the update keys. If a key store is specified then the keys are loaded
+// from the key store and are rotated once the function succeeds.
+func withKeys(idxURL string, keys *UpdateKeys, fn func(keys *UpdateKeys) error) error {
+	if keys.KeyStoreDir == "" {
+		return fn(keys)
+	}
+
+	ks := keystore.New(keys.KeyStoreDir)
+
+	entry, err := ks.Get(idxURL)
+	if err != nil {
+		return err
+	}
+
+	storeKeys := &UpdateKeys{
+		SigningKeyString:    entry.UpdateKey.PrivateKey,
+		NextUpdateKeyString: entry.NextUpdateKey.PublicKey,
+	}
+
+	if err := fn(storeKeys); err != nil {
+		return err
+	}
+
+	return ks.Rotate(idxURL)
+}
+
+func update(c HTTPClient, idxURL, authToken string, keys *UpdateKeys, patches []Patch, o *options) error {
+	updateURL, err := UpdateURL(idxURL)
+	if err != nil {
+		return err
+	}
+
+	req, err := newUpdateRequest(c, idxURL, keys, patches, o.multihashCode)
+	if err != nil {
+		return err
+	}
+
+	return post(c, updateURL, authToken, req, "updating")
+}
+
+func newUpdateRequest(c HTTPClient, idxURL string, keys *UpdateKeys, patches []Patch, multihashCode uint) ([]byte, error) {
+	uniqueSuffix, err := uniqueSuffix(idxURL)
+	if err != nil {
+		return nil, err
+	}
+
+	patchBytes, err := json.Marshal(patches)
+	if err != nil {
+		return nil, err
+	}
+
+	updatePatch, err := patch.NewJSONPatch(string(patchBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	keySigner, updateKeyPublic, err := keys.signingKey().newSigner(c)
+	if err != nil {
+		return nil, err
+	}
+
+	defer closeSigner(keySigner)
+
+	nextUpdateKeyPublic, err := keys.nextUpdateKeyPublic()
+	if err != nil {
+		return nil, err
+	}
+
+	updateCommitment, err := commitment.GetCommitment(nextUpdateKeyPublic, multihashCode)
+	if err != nil {
+		return nil, err
+	}
+
+	revealValue, err := commitment.GetRevealValue(updateKeyPublic, multihashCode)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.NewUpdateRequest(&client.UpdateRequestInfo{
+		DidSuffix:        uniqueSuffix,
+		RevealValue:      revealValue,
+		UpdateCommitment: updateCommitment,
+		UpdateKey:        updateKeyPublic,
+		Patches:          []patch.Patch{updatePatch},
+		MultihashCode:    multihashCode,
+		Signer:           keySigner,
+	})
+}
+
+// NextUpdateCommitment returns the update commitment that the file index document at the given URL will have once
+// it is updated with these keys. If a key store is specified then the commitment is computed from the next update key
+// in the key store, so this function must be called before the keys are rotated by Update.
+func (k *UpdateKeys) NextUpdateCommitment(idxURL string, multihashCode uint) (string, error) {
+	keys := k
+
+	if k.KeyStoreDir != "" {
+		entry, err := keystore.New(k.KeyStoreDir).Get(idxURL)
+		if err != nil {
+			return "", err
+		}
+
+		keys = &UpdateKeys{NextUpdateKeyString: entry.NextUpdateKey.PublicKey}
+	}
+
+	nextUpdateKeyPublic, err := keys.nextUpdateKeyPublic()
+	if err != nil {
+		return "", err
+	}
+
+	return commitment.GetCommitment(nextUpdateKeyPublic, multihashCode)
+}
+
+// MultihashCode returns the multihash code of the algorithm in Multihash. It is only valid once Validate succeeds.
+func (k *UpdateKeys) MultihashCode() uint {
+	return k.multihashCode
+}
+
+func (k *UpdateKeys) signingKey() *signingKey {
+	return &signingKey{
+		file:          k.SigningKeyFile,
+		pem:           k.SigningKeyString,
+		signer:        k.Signer,
+		publicKeyFile: k.SignerPublicKeyFile,
+	}
+}
+
+func (k *UpdateKeys) nextUpdateKeyPublic() (*jws.JWK, error) {
+	return publicKeyJWK(k.NextUpdateKeyFile, k.NextUpdateKeyString)
+}
+
+// CheckDocumentProtocol checks the multihash code against the protocol parameters of the Sidetree node that hosts
+// the given document (see CheckProtocol)
+func CheckDocumentProtocol(c HTTPClient, idxURL, authToken string, multihashCode uint) error {
+	base, err := BaseURL(idxURL)
+	if err != nil {
+		return err
+	}
+
+	return CheckProtocol(c, base, authToken, multihashCode)
+}
+
+// checkProtocol checks the multihash code against the protocol parameters unless the caller has already done so
+func (o *options) checkProtocol(c HTTPClient, idxURL, authToken string) error {
+	if o.protocolChecked {
+		return nil
+	}
+
+	return CheckDocumentProtocol(c, idxURL, authToken, o.multihashCode)
+}
+
+// post sends the given Sidetree operation request to the operations URL
+func post(c HTTPClient, url, authToken string, req []byte, op string) error {
+	resp, err := c.Post(url, req, authOpts(authToken)...)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		if resp.StatusCode == http.StatusUnauthorized {
+			return errors.Errorf("error %s file index document. Status code %d: %s - Did you provide an authorization token (--authtoken)?", op, resp.StatusCode, resp.ErrorMsg)
+		}
+
+		return errors.Errorf("error %s file index document. Status code %d: %s", op, resp.StatusCode, resp.ErrorMsg)
+	}
+
+	return nil
+}
+
+// validateKey ensures that exactly one of the given key file or PEM is specified
+func validateKey(file, pem string, errRequired, errOnlyOne error) error {
+	if file == "" && pem == "" {
+		return errRequired
+	}
+
+	if file != "" && pem != "" {
+		return errOnlyOne
+	}
+
+	return nil
+}
+
+// publicKeyJWK loads the public key from the given file or, if not specified, from the given PEM
+func publicKeyJWK(file, pem string) (*jws.JWK, error) {
+	var pubKey crypto.PublicKey
+	var err error
+
+	if file != "" {
+		pubKey, err = keyutil.PublicKeyFromFile(file)
+	} else {
+		pubKey, err = keyutil.PublicKeyFromPEM([]byte(pem))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return pubkey.GetPublicKeyJWK(pubKey)
+}
+
+// signingKey is the key used to sign a Sidetree operation request. The private key is given either as a PEM,
+// as a file or, if it may not leave its key store, as a signer spec together with the public key file.
+type signingKey struct {
+	file          string
+	pem           string
+	signer        string
+	publicKeyFile string
+}
+
+// validate ensures that exactly one of the PEM, file or signer is specified
+func (k *signingKey) validate() error {
+	if k.signer == "" {
+		if k.publicKeyFile != "" {
+			return ErrSignerPublicKeyWithoutSigner
+		}
+
+		return validateKey(k.file, k.pem, ErrSigningKeyOrFileRequired, ErrOnlyOneOfSigningKeyOrFileRequired)
+	}
+
+	if k.file != "" || k.pem != "" {
+		return ErrOnlyOneOfSigningKeyOrFileRequired
+	}
+
+	return signer.Validate(k.signer, k.publicKeyFile != "")
+}
+
+// newSigner returns a signer for the signing key along with the public key JWK. The signing algorithm is
+// deduced from the key type. The returned signer must be closed once it is no longer needed.
+func (k *signingKey) newSigner(c HTTPClient) (signer.Signer, *jws.JWK, error) {
+	s, err := k.signerForKey(c)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	publicKey, err := pubkey.GetPublicKeyJWK(s.PublicKey())
+	if err != nil {
+		closeSigner(s)
+
+		return nil, nil, errors.WithMessage(err, "unsupported signing key")
+	}
+
+	return s, publicKey, nil
+}
+
+func (k *signingKey) signerForKey(c HTTPClient) (signer.Signer, error) {
+	if k.signer != "" {
+		var publicKey crypto.PublicKey
+
+		if k.publicKeyFile != "" {
+			var err error
+
+			publicKey, err = keyutil.PublicKeyFromFile(k.publicKeyFile)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return signer.New(k.signer, publicKey, c)
+	}
+
+	var privateKey crypto.PrivateKey
+	var err error
+
+	if k.file != "" {
+		privateKey, err = keyutil.PrivateKeyFromFile(k.file)
+	} else {
+		privateKey, err = keyutil.PrivateKeyFromPEM([]byte(k.pem))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return signer.NewLocal(privateKey)
+}
+
+// closeSigner releases the resources held by the signer (e.g. a PKCS#11 session). The request
+// has already been signed at this point so an error on close is ignored.
+func closeSigner(s signer.Signer) {
+	_ = s.Close() //nolint: errcheck
+}
+
+func uniqueSuffix(id string) (string, error) {
+	p := strings.LastIndex(id, ":")
+	if p == -1 {
+		return "", errors.Errorf("unique suffix not provided in URL [%s]", id)
+	}
+
+	return id[p+1:], nil
+}
+
+func authOpts(authToken string) []httpclient.RequestOpt {
+	if authToken == "" {
+		return nil
+	}
+
+	return []httpclient.RequestOpt{httpclient.WithAuthToken(authToken)}
+}
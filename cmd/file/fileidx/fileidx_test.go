@@ -0,0 +1,419 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fileidx
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/httpclient"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/keystore"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/model"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/signer"
+	"github.com/trustbloc/fabric-cli-ext/cmd/keyutil"
+)
+
+const (
+	idxURL    = "http://localhost:48326/file/identifiers/file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA"
+	updateURL = "http://localhost:48326/file/operations"
+)
+
+var keys = &UpdateKeys{
+	SigningKeyFile:    "../testdata/update_private.key",
+	NextUpdateKeyFile: "../testdata/update2_public.key",
+}
+
+func TestDCASID(t *testing.T) {
+	content, err := ioutil.ReadFile("../uploadcmd/testdata/person.schema.json")
+	require.NoError(t, err)
+
+	id, err := DCASID("application/json", content)
+	require.NoError(t, err)
+	require.Equal(t, "TbVyraOqG00TacPQH5WwWGnxkszpYSEhBKRyX_f25JI=", id)
+}
+
+func TestUpdateKeys_Validate(t *testing.T) {
+	require.NoError(t, keys.Validate())
+	require.EqualError(t, (&UpdateKeys{}).Validate(), ErrSigningKeyOrFileRequired.Error())
+	require.EqualError(t, (&UpdateKeys{SigningKeyFile: "f", SigningKeyString: "s"}).Validate(), ErrOnlyOneOfSigningKeyOrFileRequired.Error())
+	require.EqualError(t, (&UpdateKeys{SigningKeyFile: "f"}).Validate(), ErrNextUpdateKeyOrFileRequired.Error())
+	require.EqualError(t, (&UpdateKeys{SigningKeyFile: "f", NextUpdateKeyFile: "f", NextUpdateKeyString: "s"}).Validate(), ErrOnlyOneOfNextUpdateKeyOrFileRequired.Error())
+	require.NoError(t, (&UpdateKeys{KeyStoreDir: "d"}).Validate())
+	require.EqualError(t, (&UpdateKeys{KeyStoreDir: "d", SigningKeyFile: "f"}).Validate(), ErrKeyStoreWithUpdateKeys.Error())
+	require.EqualError(t, (&UpdateKeys{KeyStoreDir: "d", NextUpdateKeyString: "s"}).Validate(), ErrKeyStoreWithUpdateKeys.Error())
+	require.EqualError(t, (&UpdateKeys{KeyStoreDir: "d", Signer: "cmd:sign"}).Validate(), ErrKeyStoreWithUpdateKeys.Error())
+	require.NoError(t, (&UpdateKeys{Signer: "cmd:sign", SignerPublicKeyFile: "f", NextUpdateKeyFile: "f"}).Validate())
+	require.EqualError(t, (&UpdateKeys{Signer: "cmd:sign", SignerPublicKeyFile: "f", SigningKeyFile: "f"}).Validate(), ErrOnlyOneOfSigningKeyOrFileRequired.Error())
+	require.EqualError(t, (&UpdateKeys{SigningKeyFile: "f", SignerPublicKeyFile: "f"}).Validate(), ErrSignerPublicKeyWithoutSigner.Error())
+	require.EqualError(t, (&UpdateKeys{Signer: "cmd:sign"}).Validate(), signer.ErrPublicKeyRequired.Error())
+	require.Error(t, (&UpdateKeys{Signer: "xxx", SignerPublicKeyFile: "f"}).Validate())
+	require.EqualError(t, (&UpdateKeys{KeyStoreDir: "d", Multihash: "md5"}).Validate(), ErrUnsupportedMultihash.Error())
+
+	k := &UpdateKeys{KeyStoreDir: "d"}
+	require.NoError(t, k.Validate())
+	require.Equal(t, uint(sha2_256), k.MultihashCode())
+
+	k = &UpdateKeys{KeyStoreDir: "d", Multihash: MultihashSHA2512}
+	require.NoError(t, k.Validate())
+	require.Equal(t, uint(sha2_512), k.MultihashCode())
+}
+
+func TestUpdateKeys_AddFlags(t *testing.T) {
+	k := &UpdateKeys{}
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	k.AddFlags(flags)
+
+	require.NoError(t, flags.Parse([]string{"--signingkeyfile", "update.key", "--nextupdatekeyfile", "next_update.pem"}))
+	require.Equal(t, &UpdateKeys{SigningKeyFile: "update.key", NextUpdateKeyFile: "next_update.pem", Multihash: MultihashSHA2256}, k)
+
+	require.NoError(t, flags.Parse([]string{"--signer", "cmd:sign", "--signerpublickeyfile", "signer.pem",
+		"--signingkey", "s", "--nextupdatekey", "n", "--keystore", "d", "--multihash", MultihashSHA2512}))
+	require.Equal(t, "cmd:sign", k.Signer)
+	require.Equal(t, "signer.pem", k.SignerPublicKeyFile)
+	require.Equal(t, "s", k.SigningKeyString)
+	require.Equal(t, "n", k.NextUpdateKeyString)
+	require.Equal(t, "d", k.KeyStoreDir)
+	require.Equal(t, MultihashSHA2512, k.Multihash)
+}
+
+func TestMappingPath(t *testing.T) {
+	require.Equal(t, "/fileIndex/mappings/schema.json", MappingPath("schema.json"))
+	require.Equal(t, "/fileIndex/mappings/v1~1schema~0.json", MappingPath("v1/schema~.json"))
+}
+
+func TestUpdateURL(t *testing.T) {
+	u, err := UpdateURL(idxURL)
+	require.NoError(t, err)
+	require.Equal(t, updateURL, u)
+
+	_, err = UpdateURL("http://localhost:48326/file/file:idx:1234")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid file index URL")
+}
+
+func TestGet(t *testing.T) {
+	fileIdxDocBytes, err := json.Marshal(&model.FileIndexDoc{FileIndex: model.FileIndex{BasePath: "/content", Mappings: map[string]string{"a.json": "id1"}}})
+	require.NoError(t, err)
+
+	didResolutionBytes, err := json.Marshal(&model.DIDResolution{DIDDocument: fileIdxDocBytes})
+	require.NoError(t, err)
+
+	t.Run("DID resolution", func(t *testing.T) {
+		c := &mockHTTPClient{getResponse: &httpclient.HTTPResponse{StatusCode: http.StatusOK, Payload: didResolutionBytes}}
+
+		fileIdx, err := Get(c, idxURL, "tk")
+		require.NoError(t, err)
+		require.Equal(t, "/content", fileIdx.BasePath)
+		require.Equal(t, "id1", fileIdx.Mappings["a.json"])
+	})
+
+	t.Run("Document", func(t *testing.T) {
+		c := &mockHTTPClient{getResponse: &httpclient.HTTPResponse{StatusCode: http.StatusOK, Payload: fileIdxDocBytes}}
+
+		fileIdx, err := Get(c, idxURL, "")
+		require.NoError(t, err)
+		require.Equal(t, "/content", fileIdx.BasePath)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		c := &mockHTTPClient{getResponse: &httpclient.HTTPResponse{StatusCode: http.StatusNotFound}}
+
+		_, err := Get(c, idxURL, "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "not found")
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		c := &mockHTTPClient{getResponse: &httpclient.HTTPResponse{StatusCode: http.StatusUnauthorized, ErrorMsg: "Unauthorized"}}
+
+		_, err := Get(c, idxURL, "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "Did you provide an authorization token")
+	})
+
+	t.Run("Server error", func(t *testing.T) {
+		c := &mockHTTPClient{getResponse: &httpclient.HTTPResponse{StatusCode: http.StatusInternalServerError, ErrorMsg: "server error"}}
+
+		_, err := Get(c, idxURL, "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "server error")
+	})
+
+	t.Run("Invalid payload", func(t *testing.T) {
+		c := &mockHTTPClient{getResponse: &httpclient.HTTPResponse{StatusCode: http.StatusOK, Payload: []byte("{")}}
+
+		_, err := Get(c, idxURL, "")
+		require.Error(t, err)
+	})
+
+	t.Run("Client error", func(t *testing.T) {
+		errExpected := errors.New("injected error")
+		c := &mockHTTPClient{getErr: errExpected}
+
+		_, err := Get(c, idxURL, "")
+		require.EqualError(t, err, errExpected.Error())
+	})
+}
+
+func TestUpdate(t *testing.T) {
+	patches := []Patch{{Op: OpMove, From: MappingPath("a.json"), Path: MappingPath("b.json")}}
+
+	t.Run("Success", func(t *testing.T) {
+		c := &mockHTTPClient{postResponse: &httpclient.HTTPResponse{StatusCode: http.StatusOK}}
+
+		require.NoError(t, Update(c, idxURL, "tk", keys, patches))
+		require.Equal(t, updateURL, c.postURL)
+		require.Equal(t, patches, getPatches(t, c.postReq))
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		c := &mockHTTPClient{postResponse: &httpclient.HTTPResponse{StatusCode: http.StatusUnauthorized, ErrorMsg: "Unauthorized"}}
+
+		err := Update(c, idxURL, "", keys, patches)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "Did you provide an authorization token")
+	})
+
+	t.Run("Server error", func(t *testing.T) {
+		c := &mockHTTPClient{postResponse: &httpclient.HTTPResponse{StatusCode: http.StatusInternalServerError, ErrorMsg: "server error"}}
+
+		err := Update(c, idxURL, "", keys, patches)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "server error")
+	})
+
+	t.Run("Client error", func(t *testing.T) {
+		errExpected := errors.New("injected error")
+		c := &mockHTTPClient{postErr: errExpected}
+
+		require.EqualError(t, Update(c, idxURL, "", keys, patches), errExpected.Error())
+	})
+
+	t.Run("Invalid URL", func(t *testing.T) {
+		err := Update(&mockHTTPClient{}, "http://localhost:48326/file", "", keys, patches)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid file index URL")
+
+		err = Update(&mockHTTPClient{}, "/file/identifiers/xxx", "", keys, patches)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unique suffix not provided")
+	})
+
+	t.Run("Key store", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "fileidx")
+		require.NoError(t, err)
+		defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+		ks := keystore.New(dir)
+		storeKeys := &UpdateKeys{KeyStoreDir: dir}
+
+		err = Update(&mockHTTPClient{}, idxURL, "", storeKeys, patches)
+		require.True(t, errors.Is(err, keystore.ErrNotFound))
+
+		entry, err := keystore.NewEntry("")
+		require.NoError(t, err)
+		require.NoError(t, ks.Put(idxURL, entry))
+
+		c := &mockHTTPClient{postResponse: &httpclient.HTTPResponse{StatusCode: http.StatusInternalServerError, ErrorMsg: "server error"}}
+		require.Error(t, Update(c, idxURL, "", storeKeys, patches))
+
+		// The keys are not rotated if the update fails
+		e, err := ks.Get(idxURL)
+		require.NoError(t, err)
+		require.Equal(t, entry, e)
+
+		c = &mockHTTPClient{postResponse: &httpclient.HTTPResponse{StatusCode: http.StatusOK}}
+		require.NoError(t, Update(c, idxURL, "", storeKeys, patches))
+
+		e, err = ks.Get(idxURL)
+		require.NoError(t, err)
+		require.Equal(t, entry.NextUpdateKey, e.UpdateKey)
+		require.NotEqual(t, entry.NextUpdateKey, e.NextUpdateKey)
+	})
+
+	t.Run("Key types", func(t *testing.T) {
+		for keyType, alg := range map[string]string{
+			keyutil.KeyTypeP256:      "ES256",
+			keyutil.KeyTypeSecp256k1: "ES256K",
+			keyutil.KeyTypeEd25519:   "EdDSA",
+		} {
+			signingKey, _, err := keyutil.GenerateKeyPair(keyType)
+			require.NoError(t, err)
+
+			_, nextUpdateKey, err := keyutil.GenerateKeyPair(keyType)
+			require.NoError(t, err)
+
+			c := &mockHTTPClient{postResponse: &httpclient.HTTPResponse{StatusCode: http.StatusOK}}
+
+			require.NoError(t, Update(c, idxURL, "", &UpdateKeys{SigningKeyString: string(signingKey), NextUpdateKeyString: string(nextUpdateKey)}, patches))
+
+			header, payload := getSignedData(t, c.postReq)
+			require.Equal(t, alg, header["alg"], keyType)
+			require.Equal(t, keyType, payload.UpdateKey.Crv)
+		}
+	})
+
+	t.Run("Signing service", func(t *testing.T) {
+		privateKey, err := keyutil.PrivateKeyFromFile(keys.SigningKeyFile)
+		require.NoError(t, err)
+
+		localSigner, err := signer.NewLocal(privateKey)
+		require.NoError(t, err)
+
+		c := &mockSigningServiceClient{
+			mockHTTPClient: mockHTTPClient{postResponse: &httpclient.HTTPResponse{StatusCode: http.StatusOK}},
+			signer:         localSigner,
+		}
+
+		signerKeys := &UpdateKeys{
+			Signer:              signingServiceURL,
+			SignerPublicKeyFile: "../testdata/update_public.key",
+			NextUpdateKeyFile:   keys.NextUpdateKeyFile,
+		}
+
+		require.NoError(t, Update(c, idxURL, "", signerKeys, patches))
+		require.Equal(t, updateURL, c.postURL)
+		require.Equal(t, patches, getPatches(t, c.postReq))
+
+		header, _ := getSignedData(t, c.postReq)
+		require.Equal(t, "ES256", header["alg"])
+
+		signerKeys.SignerPublicKeyFile = "./xxx.key"
+		require.Error(t, Update(c, idxURL, "", signerKeys, patches))
+	})
+
+	t.Run("Unsupported key type", func(t *testing.T) {
+		err := Update(&mockHTTPClient{}, idxURL, "", &UpdateKeys{SigningKeyFile: "../../ledgerconfig/testdata/rsa_private.key", NextUpdateKeyFile: keys.NextUpdateKeyFile}, patches)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported signing key")
+	})
+
+	t.Run("Key errors", func(t *testing.T) {
+		err := Update(&mockHTTPClient{}, idxURL, "", &UpdateKeys{SigningKeyFile: "./xxx.key", NextUpdateKeyFile: keys.NextUpdateKeyFile}, patches)
+		require.Error(t, err)
+
+		err = Update(&mockHTTPClient{}, idxURL, "", &UpdateKeys{SigningKeyFile: keys.SigningKeyFile, NextUpdateKeyFile: "./xxx.key"}, patches)
+		require.Error(t, err)
+
+		err = Update(&mockHTTPClient{}, idxURL, "", &UpdateKeys{SigningKeyFile: "../testdata/update_public.key", NextUpdateKeyFile: keys.NextUpdateKeyFile}, patches)
+		require.Error(t, err)
+	})
+}
+
+// getPatches returns the JSON patches in the given Sidetree update request
+func getPatches(t *testing.T, req []byte) []Patch {
+	updateReq := &struct {
+		Delta struct {
+			Patches []struct {
+				Patches []Patch `json:"patches"`
+			} `json:"patches"`
+		} `json:"delta"`
+	}{}
+
+	require.NoError(t, json.Unmarshal(req, updateReq))
+	require.Len(t, updateReq.Delta.Patches, 1)
+
+	return updateReq.Delta.Patches[0].Patches
+}
+
+// getSignedData returns the decoded JWS header and payload of the signed data in the given Sidetree update request
+func getSignedData(t *testing.T, req []byte) (map[string]interface{}, *struct{ UpdateKey jws.JWK }) {
+	updateReq := &struct {
+		SignedData string `json:"signedData"`
+	}{}
+
+	require.NoError(t, json.Unmarshal(req, updateReq))
+
+	parts := strings.Split(updateReq.SignedData, ".")
+	require.Len(t, parts, 3)
+
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	require.NoError(t, err)
+
+	header := make(map[string]interface{})
+	require.NoError(t, json.Unmarshal(headerBytes, &header))
+
+	payloadBytes, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(t, err)
+
+	payload := &struct{ UpdateKey jws.JWK }{}
+	require.NoError(t, json.Unmarshal(payloadBytes, payload))
+
+	return header, payload
+}
+
+const signingServiceURL = "https://localhost:9999/sign"
+
+// mockSigningServiceClient signs the requests that are posted to the signing service URL
+type mockSigningServiceClient struct {
+	mockHTTPClient
+	signer signer.Signer
+}
+
+func (m *mockSigningServiceClient) Post(url string, req []byte, opts ...httpclient.RequestOpt) (*httpclient.HTTPResponse, error) {
+	if url != signingServiceURL {
+		return m.mockHTTPClient.Post(url, req, opts...)
+	}
+
+	signReq := &signer.SignRequest{}
+	if err := json.Unmarshal(req, signReq); err != nil {
+		return nil, err
+	}
+
+	input, err := base64.RawURLEncoding.DecodeString(signReq.Input)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := m.signer.Sign(input)
+	if err != nil {
+		return nil, err
+	}
+
+	respBytes, err := json.Marshal(&signer.SignResponse{Signature: base64.RawURLEncoding.EncodeToString(sig)})
+	if err != nil {
+		return nil, err
+	}
+
+	return &httpclient.HTTPResponse{StatusCode: http.StatusOK, Payload: respBytes}, nil
+}
+
+type mockHTTPClient struct {
+	getResponse  *httpclient.HTTPResponse
+	getErr       error
+	postResponse *httpclient.HTTPResponse
+	postErr      error
+	postURL      string
+	postReq      []byte
+}
+
+func (m *mockHTTPClient) Get(string, ...httpclient.RequestOpt) (*httpclient.HTTPResponse, error) {
+	if m.getResponse == nil && m.getErr == nil {
+		// The Sidetree node doesn't expose its protocol parameters
+		return &httpclient.HTTPResponse{StatusCode: http.StatusNotFound}, nil
+	}
+
+	return m.getResponse, m.getErr
+}
+
+func (m *mockHTTPClient) Post(url string, req []byte, _ ...httpclient.RequestOpt) (*httpclient.HTTPResponse, error) {
+	m.postURL = url
+	m.postReq = req
+
+	return m.postResponse, m.postErr
+}
@@ -0,0 +1,49 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fileidx
+
+import (
+	"github.com/spf13/pflag"
+)
+
+const (
+	nextUpdateKeyFlag  = "nextupdatekey"
+	nextUpdateKeyUsage = "The public key PEM used for creating commitment for next update of the index document. Example: --nextupdatekey 'MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAEFMy2n9jYZChYSjdhK9vUWvPjz9tzBcEa13Ye33haxFsT//3kGxOQhI7yb3MJsDvwLtdfLL6txM3RdOrmLABBvw'"
+
+	nextUpdateKeyFileFlag  = "nextupdatekeyfile"
+	nextUpdateKeyFileUsage = "The file that contains the public key PEM used for creating commitment for next update of the index document. Example: --nextupdatekeyfile ./next_update_public.key"
+
+	signingKeyFlag  = "signingkey"
+	signingKeyUsage = "The private key PEM used for signing the update of the index document. Example: --signingkey 'MHcCAQEEILmfa4yss8nsTJK2hKl+LAoiwW3p+eQzaHfITI9z8ptpoAoGCCqGSM49AwEHoUQDQgAEMd1/e/Nxh73bK12PEEcNSY9HxnP0N8er9ww9rjq1tNcsqfRjlL0bdTh9Basfn/4JrQHUHc6uS99yjQc+0u2bVg'"
+
+	signingKeyFileFlag  = "signingkeyfile"
+	signingKeyFileUsage = "The file that contains the private key PEM used for signing the update of the index document. Example: --signingkeyfile ./keys/signing.key"
+
+	signerFlag  = "signer"
+	signerUsage = "The signer that holds the private key used for signing the update of the index document, so that the private key is never exposed. This flag may be used instead of --signingkey(file). The signer is one of: a PKCS#11 token (pkcs11:lib=<library>;token=<token label>;pin=<user PIN>;label=<key label>), an external command that reads the JWS signing input from stdin and writes the base64url-encoded signature to stdout (cmd:<command> [args]) or an HTTP signing service (http(s)://<URL>). Example: --signer 'pkcs11:lib=/usr/lib/softhsm/libsofthsm2.so;token=fabric;pin=1234;label=update'"
+
+	signerPublicKeyFileFlag  = "signerpublickeyfile"
+	signerPublicKeyFileUsage = "The file that contains the public key PEM of the signer. Required for command and HTTP signers. Example: --signerpublickeyfile ./keys/signer_public.pem"
+
+	keyStoreFlag  = "keystore"
+	keyStoreUsage = "The key store directory that holds the update keys of the index document (see 'file createidx --keystore'). The keys are rotated after a successful update and the previous keys are kept in a backup file (<suffix>.json.prev) which may be restored if the update is never anchored. This flag may be used instead of --signingkey(file) and --nextupdatekey(file). Example: --keystore ~/.fabric/keystore"
+
+	multihashFlag  = "multihash"
+	multihashUsage = "The multihash algorithm used to compute the commitment and reveal value of the update. The reveal value must be computed with the same algorithm as the commitment of the previous operation on the index document (see 'file createidx --multihash'). Supported values are sha2-256 and sha2-512. Example: --multihash sha2-512"
+)
+
+// AddFlags adds the flags that populate the update keys and the multihash algorithm to the given flag set
+func (k *UpdateKeys) AddFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&k.SigningKeyString, signingKeyFlag, "", signingKeyUsage)
+	flags.StringVar(&k.SigningKeyFile, signingKeyFileFlag, "", signingKeyFileUsage)
+	flags.StringVar(&k.Signer, signerFlag, "", signerUsage)
+	flags.StringVar(&k.SignerPublicKeyFile, signerPublicKeyFileFlag, "", signerPublicKeyFileUsage)
+	flags.StringVar(&k.NextUpdateKeyString, nextUpdateKeyFlag, "", nextUpdateKeyUsage)
+	flags.StringVar(&k.NextUpdateKeyFile, nextUpdateKeyFileFlag, "", nextUpdateKeyFileUsage)
+	flags.StringVar(&k.KeyStoreDir, keyStoreFlag, "", keyStoreUsage)
+	flags.StringVar(&k.Multihash, multihashFlag, MultihashSHA2256, multihashUsage)
+}
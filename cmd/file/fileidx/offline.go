@@ -0,0 +1,81 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fileidx
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// Operations of an offline request
+const (
+	OperationCreate = "create"
+	OperationUpdate = "update"
+)
+
+// OfflineRequest is a signed Sidetree request on a file index document that was generated without contacting
+// the Sidetree node (e.g. on an air-gapped machine) so that it may be submitted later. An update request also
+// contains the files that must be uploaded to DCAS before the request is submitted.
+type OfflineRequest struct {
+	Operation  string          `json:"operation"`
+	Request    json.RawMessage `json:"request"`
+	ContentURL string          `json:"contentUrl,omitempty"`
+	Files      []*OfflineFile  `json:"files,omitempty"`
+}
+
+// OfflineFile is a file that must be uploaded to DCAS before the offline request is submitted. The ID is the
+// DCAS ID computed locally when the request was generated.
+type OfflineFile struct {
+	Name        string `json:"name"`
+	ID          string `json:"id"`
+	ContentType string `json:"contentType"`
+	Content     []byte `json:"content"`
+}
+
+// WriteRequest writes the given offline request to the given file (readable only by the owner)
+func WriteRequest(path string, r *OfflineRequest) error {
+	reqBytes, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(filepath.Clean(path), reqBytes, 0600); err != nil {
+		return errors.WithMessagef(err, "error writing request to [%s]", path)
+	}
+
+	return nil
+}
+
+// ReadRequest reads the offline request from the given file
+func ReadRequest(path string) (*OfflineRequest, error) {
+	reqBytes, err := ioutil.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, errors.WithMessagef(err, "error reading request from [%s]", path)
+	}
+
+	r := &OfflineRequest{}
+	if err := json.Unmarshal(reqBytes, r); err != nil {
+		return nil, errors.WithMessagef(err, "invalid request in [%s]", path)
+	}
+
+	if r.Operation != OperationCreate && r.Operation != OperationUpdate {
+		return nil, errors.Errorf("invalid request in [%s] - unsupported operation [%s]", path, r.Operation)
+	}
+
+	if len(r.Request) == 0 {
+		return nil, errors.Errorf("invalid request in [%s] - no Sidetree request found", path)
+	}
+
+	if len(r.Files) > 0 && r.ContentURL == "" {
+		return nil, errors.Errorf("invalid request in [%s] - no content URL found for the files", path)
+	}
+
+	return r, nil
+}
@@ -0,0 +1,211 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fileidx
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/httpclient"
+)
+
+func TestOfflineRequest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fileidx")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+	path := filepath.Join(dir, "request.json")
+
+	t.Run("Success", func(t *testing.T) {
+		r := &OfflineRequest{
+			Operation:  OperationUpdate,
+			Request:    json.RawMessage(`{"type":"update"}`),
+			ContentURL: "http://localhost:48326/content",
+			Files:      []*OfflineFile{{Name: "a.json", ID: "id1", ContentType: "application/json", Content: []byte("{}")}},
+		}
+
+		require.NoError(t, WriteRequest(path, r))
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+		r2, err := ReadRequest(path)
+		require.NoError(t, err)
+		require.Equal(t, r, r2)
+	})
+
+	t.Run("Write error", func(t *testing.T) {
+		err := WriteRequest(filepath.Join(dir, "xxx", "request.json"), &OfflineRequest{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "error writing request")
+	})
+
+	t.Run("Read error", func(t *testing.T) {
+		_, err := ReadRequest(filepath.Join(dir, "xxx.json"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "error reading request")
+	})
+
+	t.Run("Invalid request", func(t *testing.T) {
+		for content, msg := range map[string]string{
+			`{`:                                    "invalid request",
+			`{"operation":"recover","request":{}}`: "unsupported operation [recover]",
+			`{"operation":"create"}`:               "no Sidetree request found",
+			`{"operation":"update","request":{},"files":[{"name":"a.json"}]}`: "no content URL found",
+		} {
+			require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+
+			_, err := ReadRequest(path)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), msg)
+		}
+	})
+}
+
+func TestNewUpdateRequest(t *testing.T) {
+	patches := []Patch{{Op: OpAdd, Path: MappingPath("a.json"), Value: "id1"}}
+
+	t.Run("Keys", func(t *testing.T) {
+		req, err := NewUpdateRequest(&mockHTTPClient{}, idxURL, keys, patches)
+		require.NoError(t, err)
+		require.Equal(t, patches, getPatches(t, req))
+	})
+
+	t.Run("Key store", func(t *testing.T) {
+		_, err := NewUpdateRequest(&mockHTTPClient{}, idxURL, &UpdateKeys{KeyStoreDir: "./keystore"}, patches)
+		require.EqualError(t, err, ErrKeyStoreWithUpdateRequest.Error())
+	})
+}
+
+func TestUpload(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		c := &mockHTTPClient{postResponse: &httpclient.HTTPResponse{StatusCode: http.StatusOK, Payload: []byte(`"id1"`)}}
+
+		id, err := Upload(c, "http://localhost:48326/content", "tk", "application/json", []byte("{}"))
+		require.NoError(t, err)
+		require.Equal(t, "id1", id)
+
+		file := &File{}
+		require.NoError(t, json.Unmarshal(c.postReq, file))
+		require.Equal(t, "application/json", file.ContentType)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		c := &mockHTTPClient{postResponse: &httpclient.HTTPResponse{StatusCode: http.StatusUnauthorized, ErrorMsg: "Unauthorized"}}
+
+		_, err := Upload(c, "http://localhost:48326/content", "", "application/json", []byte("{}"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "--contentauthtoken")
+	})
+
+	t.Run("Invalid response", func(t *testing.T) {
+		c := &mockHTTPClient{postResponse: &httpclient.HTTPResponse{StatusCode: http.StatusOK, Payload: []byte(`{`)}}
+
+		_, err := Upload(c, "http://localhost:48326/content", "", "application/json", []byte("{}"))
+		require.Error(t, err)
+	})
+
+	t.Run("Retries", func(t *testing.T) {
+		c := &mockUploadClient{responses: []*httpclient.HTTPResponse{
+			{StatusCode: http.StatusServiceUnavailable},
+			nil, // connection error
+			{StatusCode: http.StatusOK, Payload: []byte(`"id1"`)},
+		}}
+
+		id, err := Upload(c, "http://localhost:48326/content", "", "application/json", []byte("{}"), WithRetries(2, time.Millisecond))
+		require.NoError(t, err)
+		require.Equal(t, "id1", id)
+		require.Equal(t, 3, c.count)
+	})
+
+	t.Run("Retries exhausted", func(t *testing.T) {
+		c := &mockUploadClient{responses: []*httpclient.HTTPResponse{
+			{StatusCode: http.StatusBadGateway, ErrorMsg: "bad gateway"},
+		}}
+
+		_, err := Upload(c, "http://localhost:48326/content", "", "application/json", []byte("{}"), WithRetries(2, time.Millisecond))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "bad gateway")
+		require.Equal(t, 3, c.count)
+	})
+
+	t.Run("Not transient - error", func(t *testing.T) {
+		c := &mockUploadClient{err: errors.New("invalid request")}
+
+		_, err := Upload(c, "http://localhost:48326/content", "", "application/json", []byte("{}"), WithRetries(2, time.Millisecond))
+		require.EqualError(t, err, "invalid request")
+		require.Equal(t, 1, c.count)
+	})
+
+	t.Run("Not transient", func(t *testing.T) {
+		c := &mockUploadClient{responses: []*httpclient.HTTPResponse{
+			{StatusCode: http.StatusBadRequest, ErrorMsg: "bad request"},
+		}}
+
+		_, err := Upload(c, "http://localhost:48326/content", "", "application/json", []byte("{}"), WithRetries(2, time.Millisecond))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "bad request")
+		require.Equal(t, 1, c.count)
+	})
+}
+
+func TestIsTransient(t *testing.T) {
+	require.True(t, isTransient(nil, errConnectionRefused))
+	require.True(t, isTransient(nil, &url.Error{Op: "Post", URL: "http://localhost", Err: errConnectionRefused}))
+	require.True(t, isTransient(nil, context.DeadlineExceeded))
+	require.True(t, isTransient(&httpclient.HTTPResponse{StatusCode: http.StatusServiceUnavailable}, nil))
+
+	require.False(t, isTransient(nil, errors.New("invalid request")))
+	require.False(t, isTransient(nil, &url.Error{Op: "Post", URL: "https://localhost", Err: x509.UnknownAuthorityError{}}))
+	require.False(t, isTransient(&httpclient.HTTPResponse{StatusCode: http.StatusBadRequest}, nil))
+}
+
+// errConnectionRefused is the network error returned by mock clients to simulate a connection error
+var errConnectionRefused = &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+
+// mockUploadClient returns the given responses in sequence (repeating the last response). A nil response
+// results in a connection error. If err is set then it is returned for every request.
+type mockUploadClient struct {
+	mockHTTPClient
+	responses []*httpclient.HTTPResponse
+	err       error
+	count     int
+}
+
+func (m *mockUploadClient) Post(string, []byte, ...httpclient.RequestOpt) (*httpclient.HTTPResponse, error) {
+	if m.err != nil {
+		m.count++
+
+		return nil, m.err
+	}
+
+	resp := m.responses[len(m.responses)-1]
+	if m.count < len(m.responses) {
+		resp = m.responses[m.count]
+	}
+
+	m.count++
+
+	if resp == nil {
+		return nil, errConnectionRefused
+	}
+
+	return resp, nil
+}
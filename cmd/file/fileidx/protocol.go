@@ -0,0 +1,156 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fileidx
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+)
+
+// Multihash algorithms that may be used for commitments and reveal values
+const (
+	MultihashSHA2256 = "sha2-256"
+	MultihashSHA2512 = "sha2-512"
+)
+
+const protocolPath = "/protocol"
+
+// ErrUnsupportedMultihash indicates that the specified multihash algorithm is not supported
+var ErrUnsupportedMultihash = errors.Errorf("unsupported multihash (--multihash) - supported values are %s and %s", MultihashSHA2256, MultihashSHA2512)
+
+var multihashCodes = map[string]uint{
+	MultihashSHA2256: sha2_256,
+	MultihashSHA2512: sha2_512,
+}
+
+// MultihashCode returns the multihash code for the given algorithm name (e.g. sha2-256)
+func MultihashCode(name string) (uint, error) {
+	code, ok := multihashCodes[strings.ToLower(name)]
+	if !ok {
+		return 0, ErrUnsupportedMultihash
+	}
+
+	return code, nil
+}
+
+// Opt sets an option for a Sidetree operation on a file index document or for an upload to DCAS
+type Opt func(opts *options)
+
+type options struct {
+	multihashCode uint
+	maxRetries    int
+	retryBackoff  time.Duration
+	uploadMode    string
+
+	protocolChecked bool
+}
+
+// WithMultihash sets the multihash code used to compute the commitments and reveal values of the operation.
+// The default is sha2-256. Note that the reveal value must be computed with the same algorithm as the
+// commitment of the previous operation.
+func WithMultihash(code uint) Opt {
+	return func(opts *options) {
+		opts.multihashCode = code
+	}
+}
+
+// WithRetries sets the maximum number of times that an upload to DCAS is retried on a transient error (a network error
+// or timeout, or an HTTP status such as 503) along with the time to wait before the first retry, which doubles on each
+// retry
+func WithRetries(maxRetries int, backoff time.Duration) Opt {
+	return func(opts *options) {
+		opts.maxRetries = maxRetries
+		opts.retryBackoff = backoff
+	}
+}
+
+// WithUploadMode sets the mode in which a file is streamed to DCAS by UploadFile. The default is UploadModeJSON.
+func WithUploadMode(mode string) Opt {
+	return func(opts *options) {
+		opts.uploadMode = mode
+	}
+}
+
+// WithProtocolChecked indicates that the multihash has already been checked against the protocol parameters of the
+// Sidetree node (see CheckDocumentProtocol), e.g. before the user was prompted for confirmation, so that the check
+// isn't repeated by the operation
+func WithProtocolChecked() Opt {
+	return func(opts *options) {
+		opts.protocolChecked = true
+	}
+}
+
+func resolveOptions(opts []Opt) *options {
+	o := &options{multihashCode: sha2_256, uploadMode: UploadModeJSON}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// CheckProtocol retrieves the protocol parameters of the Sidetree node at the given base URL (e.g. http://localhost:48326/file)
+// and ensures that the given multihash code is allowed by the node. The check is skipped if the node doesn't expose its
+// protocol parameters.
+func CheckProtocol(c HTTPClient, baseURL, authToken string, multihashCode uint) error {
+	p, err := getProtocol(c, strings.TrimSuffix(baseURL, "/")+protocolPath, authToken)
+	if err != nil {
+		return err
+	}
+
+	if p == nil || len(p.MultihashAlgorithms) == 0 {
+		return nil
+	}
+
+	for _, code := range p.MultihashAlgorithms {
+		if code == multihashCode {
+			return nil
+		}
+	}
+
+	return errors.Errorf("multihash [%s] is not allowed by the Sidetree node - allowed multihash codes: %v", multihashName(multihashCode), p.MultihashAlgorithms)
+}
+
+// getProtocol returns the protocol parameters at the given URL or nil if the node doesn't expose them
+func getProtocol(c HTTPClient, url, authToken string) (*protocol.Protocol, error) {
+	resp, err := c.Get(url, authOpts(authToken)...)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusMethodNotAllowed:
+		return nil, nil
+	default:
+		return nil, errors.Errorf("error retrieving Sidetree protocol parameters from [%s]. Status code %d: %s", url, resp.StatusCode, resp.ErrorMsg)
+	}
+
+	p := &protocol.Protocol{}
+	if err := json.Unmarshal(resp.Payload, p); err != nil {
+		return nil, errors.WithMessagef(err, "invalid Sidetree protocol parameters returned from [%s]", url)
+	}
+
+	return p, nil
+}
+
+func multihashName(code uint) string {
+	for name, c := range multihashCodes {
+		if c == code {
+			return name
+		}
+	}
+
+	return fmt.Sprintf("%d", code)
+}
@@ -0,0 +1,166 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fileidx
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/hashing"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/httpclient"
+)
+
+const baseURL = "http://localhost:48326/file"
+
+func TestMultihashCode(t *testing.T) {
+	code, err := MultihashCode(MultihashSHA2256)
+	require.NoError(t, err)
+	require.Equal(t, uint(sha2_256), code)
+
+	code, err = MultihashCode("SHA2-512")
+	require.NoError(t, err)
+	require.Equal(t, uint(sha2_512), code)
+
+	_, err = MultihashCode("sha3-256")
+	require.EqualError(t, err, ErrUnsupportedMultihash.Error())
+}
+
+func TestBaseURL(t *testing.T) {
+	u, err := BaseURL(idxURL)
+	require.NoError(t, err)
+	require.Equal(t, baseURL, u)
+
+	_, err = BaseURL("http://localhost:48326/file/file:idx:1234")
+	require.Error(t, err)
+}
+
+func TestCheckProtocol(t *testing.T) {
+	t.Run("Not exposed", func(t *testing.T) {
+		require.NoError(t, CheckProtocol(&mockHTTPClient{}, baseURL, "", sha2_512))
+		require.NoError(t, CheckProtocol(&mockHTTPClient{getResponse: &httpclient.HTTPResponse{StatusCode: http.StatusMethodNotAllowed}}, baseURL, "", sha2_512))
+	})
+
+	t.Run("Allowed", func(t *testing.T) {
+		c := &mockHTTPClient{getResponse: &httpclient.HTTPResponse{StatusCode: http.StatusOK, Payload: []byte(`{"multihashAlgorithms":[18,19]}`)}}
+		require.NoError(t, CheckProtocol(c, baseURL+"/", "tk", sha2_512))
+	})
+
+	t.Run("No multihash algorithms", func(t *testing.T) {
+		c := &mockHTTPClient{getResponse: &httpclient.HTTPResponse{StatusCode: http.StatusOK, Payload: []byte(`{}`)}}
+		require.NoError(t, CheckProtocol(c, baseURL, "", sha2_512))
+	})
+
+	t.Run("Not allowed", func(t *testing.T) {
+		c := &mockHTTPClient{getResponse: &httpclient.HTTPResponse{StatusCode: http.StatusOK, Payload: []byte(`{"multihashAlgorithms":[18]}`)}}
+
+		err := CheckProtocol(c, baseURL, "", sha2_512)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "multihash [sha2-512] is not allowed by the Sidetree node")
+
+		err = CheckProtocol(c, baseURL, "", 17)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "multihash [17] is not allowed")
+	})
+
+	t.Run("Server error", func(t *testing.T) {
+		c := &mockHTTPClient{getResponse: &httpclient.HTTPResponse{StatusCode: http.StatusInternalServerError, ErrorMsg: "server error"}}
+
+		err := CheckProtocol(c, baseURL, "", sha2_256)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "error retrieving Sidetree protocol parameters")
+	})
+
+	t.Run("Invalid payload", func(t *testing.T) {
+		c := &mockHTTPClient{getResponse: &httpclient.HTTPResponse{StatusCode: http.StatusOK, Payload: []byte(`{`)}}
+
+		err := CheckProtocol(c, baseURL, "", sha2_256)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid Sidetree protocol parameters")
+	})
+
+	t.Run("Client error", func(t *testing.T) {
+		errExpected := errors.New("injected error")
+
+		require.EqualError(t, CheckProtocol(&mockHTTPClient{getErr: errExpected}, baseURL, "", sha2_256), errExpected.Error())
+	})
+}
+
+func TestUpdate_Multihash(t *testing.T) {
+	patches := []Patch{{Op: OpRemove, Path: MappingPath("a.json")}}
+
+	t.Run("sha2-512", func(t *testing.T) {
+		c := &mockHTTPClient{postResponse: &httpclient.HTTPResponse{StatusCode: http.StatusOK}}
+
+		require.NoError(t, Update(c, idxURL, "", keys, patches, WithMultihash(sha2_512)))
+
+		updateReq := &struct {
+			RevealValue string `json:"revealValue"`
+			Delta       struct {
+				UpdateCommitment string `json:"updateCommitment"`
+			} `json:"delta"`
+		}{}
+		require.NoError(t, json.Unmarshal(c.postReq, updateReq))
+
+		code, err := hashing.GetMultihashCode(updateReq.Delta.UpdateCommitment)
+		require.NoError(t, err)
+		require.Equal(t, uint64(sha2_512), code)
+
+		code, err = hashing.GetMultihashCode(updateReq.RevealValue)
+		require.NoError(t, err)
+		require.Equal(t, uint64(sha2_512), code)
+	})
+
+	t.Run("Not allowed by protocol", func(t *testing.T) {
+		c := &mockHTTPClient{
+			getResponse:  &httpclient.HTTPResponse{StatusCode: http.StatusOK, Payload: []byte(`{"multihashAlgorithms":[18]}`)},
+			postResponse: &httpclient.HTTPResponse{StatusCode: http.StatusOK},
+		}
+
+		err := Update(c, idxURL, "", keys, patches, WithMultihash(sha2_512))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "is not allowed by the Sidetree node")
+		require.Empty(t, c.postReq)
+
+		// The protocol is checked before the request is signed so the signing service isn't invoked
+		signerKeys := &UpdateKeys{
+			Signer:              signingServiceURL,
+			SignerPublicKeyFile: "../testdata/update_public.key",
+			NextUpdateKeyFile:   keys.NextUpdateKeyFile,
+		}
+
+		err = Update(c, idxURL, "", signerKeys, patches, WithMultihash(sha2_512))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "is not allowed by the Sidetree node")
+		require.Empty(t, c.postURL)
+	})
+
+	t.Run("Protocol already checked", func(t *testing.T) {
+		c := &mockHTTPClient{
+			getResponse:  &httpclient.HTTPResponse{StatusCode: http.StatusOK, Payload: []byte(`{"multihashAlgorithms":[18]}`)},
+			postResponse: &httpclient.HTTPResponse{StatusCode: http.StatusOK},
+		}
+
+		require.NoError(t, Update(c, idxURL, "", keys, patches, WithMultihash(sha2_512), WithProtocolChecked()))
+		require.NotEmpty(t, c.postReq)
+	})
+}
+
+func TestCheckDocumentProtocol(t *testing.T) {
+	c := &mockHTTPClient{getResponse: &httpclient.HTTPResponse{StatusCode: http.StatusOK, Payload: []byte(`{"multihashAlgorithms":[18]}`)}}
+
+	require.NoError(t, CheckDocumentProtocol(c, idxURL, "", sha2_256))
+
+	err := CheckDocumentProtocol(c, idxURL, "", sha2_512)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "is not allowed by the Sidetree node")
+
+	require.Error(t, CheckDocumentProtocol(c, "xxx", "", sha2_256))
+}
@@ -0,0 +1,226 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fileidx
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/trustbloc/sidetree-core-go/pkg/commitment"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/client"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/keystore"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/model"
+)
+
+var (
+	// ErrNextRecoveryKeyOrFileRequired indicates that neither the next recovery key nor key file was specified
+	ErrNextRecoveryKeyOrFileRequired = errors.New("either next recovery key (--nextrecoverykey) or key file (--nextrecoverykeyfile) is required")
+	// ErrOnlyOneOfNextRecoveryKeyOrFileRequired indicates that both the next recovery key and key file were specified
+	ErrOnlyOneOfNextRecoveryKeyOrFileRequired = errors.New("only one of next recovery key (--nextrecoverykey) or key file (--nextrecoverykeyfile) may be specified")
+	// ErrKeyStoreWithNextUpdateKey indicates that a key store was specified together with the next update key
+	ErrKeyStoreWithNextUpdateKey = errors.New("key store (--keystore) may not be specified together with the next update key (--nextupdatekey, --nextupdatekeyfile)")
+)
+
+// RecoveryKeys contains the recovery private key used to sign a recover or deactivate request of a file index
+// document and, for a recover request, the public keys used to create the commitments for the next recovery
+// and the next update. Each key is given either as a PEM or as a file. Instead of the recovery private key,
+// a Signer spec (see signer.Validate) may be given together with SignerPublicKeyFile. If KeyStoreDir is specified
+// then new update keys of type KeyType are generated for the recovered document and saved to the key store.
+type RecoveryKeys struct {
+	SigningKeyFile        string
+	SigningKeyString      string
+	Signer                string
+	SignerPublicKeyFile   string
+	NextRecoveryKeyFile   string
+	NextRecoveryKeyString string
+	NextUpdateKeyFile     string
+	NextUpdateKeyString   string
+	KeyStoreDir           string
+	KeyType               string
+}
+
+// ValidateSigningKey ensures that exactly one of the PEM, file or signer is given for the recovery private key
+func (k *RecoveryKeys) ValidateSigningKey() error {
+	return k.signingKey().validate()
+}
+
+// Validate ensures that exactly one of the PEM or file is given for each key
+func (k *RecoveryKeys) Validate() error {
+	if err := k.ValidateSigningKey(); err != nil {
+		return err
+	}
+
+	if err := validateKey(k.NextRecoveryKeyFile, k.NextRecoveryKeyString, ErrNextRecoveryKeyOrFileRequired, ErrOnlyOneOfNextRecoveryKeyOrFileRequired); err != nil {
+		return err
+	}
+
+	if k.KeyStoreDir != "" {
+		if k.NextUpdateKeyFile != "" || k.NextUpdateKeyString != "" {
+			return ErrKeyStoreWithNextUpdateKey
+		}
+
+		return nil
+	}
+
+	return validateKey(k.NextUpdateKeyFile, k.NextUpdateKeyString, ErrNextUpdateKeyOrFileRequired, ErrOnlyOneOfNextUpdateKeyOrFileRequired)
+}
+
+// Recover replaces the file index document at the given URL with the given file index using a Sidetree recover
+// request signed with the recovery key. New recovery and update commitments are set on the document.
+func Recover(c HTTPClient, idxURL, authToken string, keys *RecoveryKeys, fileIdx *model.FileIndex, opts ...Opt) error {
+	updateURL, err := UpdateURL(idxURL)
+	if err != nil {
+		return err
+	}
+
+	o := resolveOptions(opts)
+
+	if err := o.checkProtocol(c, idxURL, authToken); err != nil {
+		return err
+	}
+
+	if keys.KeyStoreDir == "" {
+		return recoverDoc(c, updateURL, idxURL, authToken, keys, fileIdx, o)
+	}
+
+	// The update commitment of the recovered document is set to the update key of a new key store entry
+	entry, err := keystore.NewEntry(keys.KeyType)
+	if err != nil {
+		return err
+	}
+
+	storeKeys := *keys
+	storeKeys.NextUpdateKeyString = entry.UpdateKey.PublicKey
+
+	if err := recoverDoc(c, updateURL, idxURL, authToken, &storeKeys, fileIdx, o); err != nil {
+		return err
+	}
+
+	return keystore.New(keys.KeyStoreDir).Put(idxURL, entry)
+}
+
+func recoverDoc(c HTTPClient, updateURL, idxURL, authToken string, keys *RecoveryKeys, fileIdx *model.FileIndex, o *options) error {
+	req, err := newRecoverRequest(c, idxURL, keys, fileIdx, o.multihashCode)
+	if err != nil {
+		return err
+	}
+
+	return post(c, updateURL, authToken, req, "recovering")
+}
+
+// Deactivate deactivates the file index document at the given URL using a Sidetree deactivate request
+// signed with the recovery key. A deactivated document may no longer be updated or recovered.
+func Deactivate(c HTTPClient, idxURL, authToken string, keys *RecoveryKeys, opts ...Opt) error {
+	updateURL, err := UpdateURL(idxURL)
+	if err != nil {
+		return err
+	}
+
+	o := resolveOptions(opts)
+
+	if err := o.checkProtocol(c, idxURL, authToken); err != nil {
+		return err
+	}
+
+	req, err := newDeactivateRequest(c, idxURL, keys, o.multihashCode)
+	if err != nil {
+		return err
+	}
+
+	return post(c, updateURL, authToken, req, "deactivating")
+}
+
+func newRecoverRequest(c HTTPClient, idxURL string, keys *RecoveryKeys, fileIdx *model.FileIndex, multihashCode uint) ([]byte, error) {
+	uniqueSuffix, err := uniqueSuffix(idxURL)
+	if err != nil {
+		return nil, err
+	}
+
+	docBytes, err := json.Marshal(&model.FileIndexDoc{FileIndex: *fileIdx})
+	if err != nil {
+		return nil, err
+	}
+
+	keySigner, recoveryKeyPublic, err := keys.signingKey().newSigner(c)
+	if err != nil {
+		return nil, err
+	}
+
+	defer closeSigner(keySigner)
+
+	nextRecoveryKeyPublic, err := publicKeyJWK(keys.NextRecoveryKeyFile, keys.NextRecoveryKeyString)
+	if err != nil {
+		return nil, err
+	}
+
+	recoveryCommitment, err := commitment.GetCommitment(nextRecoveryKeyPublic, multihashCode)
+	if err != nil {
+		return nil, err
+	}
+
+	nextUpdateKeyPublic, err := publicKeyJWK(keys.NextUpdateKeyFile, keys.NextUpdateKeyString)
+	if err != nil {
+		return nil, err
+	}
+
+	updateCommitment, err := commitment.GetCommitment(nextUpdateKeyPublic, multihashCode)
+	if err != nil {
+		return nil, err
+	}
+
+	revealValue, err := commitment.GetRevealValue(recoveryKeyPublic, multihashCode)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.NewRecoverRequest(&client.RecoverRequestInfo{
+		DidSuffix:          uniqueSuffix,
+		RevealValue:        revealValue,
+		RecoveryKey:        recoveryKeyPublic,
+		OpaqueDocument:     string(docBytes),
+		RecoveryCommitment: recoveryCommitment,
+		UpdateCommitment:   updateCommitment,
+		MultihashCode:      multihashCode,
+		Signer:             keySigner,
+	})
+}
+
+func newDeactivateRequest(c HTTPClient, idxURL string, keys *RecoveryKeys, multihashCode uint) ([]byte, error) {
+	uniqueSuffix, err := uniqueSuffix(idxURL)
+	if err != nil {
+		return nil, err
+	}
+
+	keySigner, recoveryKeyPublic, err := keys.signingKey().newSigner(c)
+	if err != nil {
+		return nil, err
+	}
+
+	defer closeSigner(keySigner)
+
+	revealValue, err := commitment.GetRevealValue(recoveryKeyPublic, multihashCode)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.NewDeactivateRequest(&client.DeactivateRequestInfo{
+		DidSuffix:   uniqueSuffix,
+		RevealValue: revealValue,
+		RecoveryKey: recoveryKeyPublic,
+		Signer:      keySigner,
+	})
+}
+
+func (k *RecoveryKeys) signingKey() *signingKey {
+	return &signingKey{
+		file:          k.SigningKeyFile,
+		pem:           k.SigningKeyString,
+		signer:        k.Signer,
+		publicKeyFile: k.SignerPublicKeyFile,
+	}
+}
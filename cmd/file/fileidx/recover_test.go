@@ -0,0 +1,200 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fileidx
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/commitment"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/httpclient"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/keystore"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/model"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/signer"
+)
+
+var recoveryKeys = &RecoveryKeys{
+	SigningKeyFile:      "../testdata/recover2_private.key",
+	NextRecoveryKeyFile: "../testdata/recover_public.key",
+	NextUpdateKeyFile:   "../testdata/update2_public.key",
+}
+
+func TestRecoveryKeys_Validate(t *testing.T) {
+	require.NoError(t, recoveryKeys.Validate())
+	require.EqualError(t, (&RecoveryKeys{}).Validate(), ErrSigningKeyOrFileRequired.Error())
+	require.EqualError(t, (&RecoveryKeys{SigningKeyFile: "f", SigningKeyString: "s"}).Validate(), ErrOnlyOneOfSigningKeyOrFileRequired.Error())
+	require.EqualError(t, (&RecoveryKeys{SigningKeyFile: "f"}).Validate(), ErrNextRecoveryKeyOrFileRequired.Error())
+	require.EqualError(t, (&RecoveryKeys{SigningKeyFile: "f", NextRecoveryKeyFile: "f", NextRecoveryKeyString: "s"}).Validate(), ErrOnlyOneOfNextRecoveryKeyOrFileRequired.Error())
+	require.EqualError(t, (&RecoveryKeys{SigningKeyFile: "f", NextRecoveryKeyFile: "f"}).Validate(), ErrNextUpdateKeyOrFileRequired.Error())
+	require.EqualError(t, (&RecoveryKeys{SigningKeyFile: "f", NextRecoveryKeyFile: "f", NextUpdateKeyFile: "f", NextUpdateKeyString: "s"}).Validate(), ErrOnlyOneOfNextUpdateKeyOrFileRequired.Error())
+	require.NoError(t, (&RecoveryKeys{SigningKeyFile: "f"}).ValidateSigningKey())
+	require.NoError(t, (&RecoveryKeys{Signer: "https://localhost/sign", SignerPublicKeyFile: "f"}).ValidateSigningKey())
+	require.EqualError(t, (&RecoveryKeys{Signer: "https://localhost/sign"}).ValidateSigningKey(), signer.ErrPublicKeyRequired.Error())
+	require.NoError(t, (&RecoveryKeys{SigningKeyFile: "f", NextRecoveryKeyFile: "f", KeyStoreDir: "d"}).Validate())
+	require.EqualError(t, (&RecoveryKeys{SigningKeyFile: "f", NextRecoveryKeyFile: "f", NextUpdateKeyFile: "f", KeyStoreDir: "d"}).Validate(), ErrKeyStoreWithNextUpdateKey.Error())
+}
+
+func TestRecover(t *testing.T) {
+	fileIdx := &model.FileIndex{BasePath: "/content", Mappings: map[string]string{".": "/content", "a.json": "id1"}}
+
+	t.Run("Success", func(t *testing.T) {
+		c := &mockHTTPClient{postResponse: &httpclient.HTTPResponse{StatusCode: http.StatusOK}}
+
+		require.NoError(t, Recover(c, idxURL, "tk", recoveryKeys, fileIdx))
+		require.Equal(t, updateURL, c.postURL)
+
+		recoverReq := &struct {
+			Type      string `json:"type"`
+			DidSuffix string `json:"didSuffix"`
+			Delta     struct {
+				Patches []struct {
+					Patches []struct {
+						Path  string          `json:"path"`
+						Value json.RawMessage `json:"value"`
+					} `json:"patches"`
+				} `json:"patches"`
+			} `json:"delta"`
+		}{}
+
+		require.NoError(t, json.Unmarshal(c.postReq, recoverReq))
+		require.Equal(t, "recover", recoverReq.Type)
+		require.Equal(t, "EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA", recoverReq.DidSuffix)
+		require.Len(t, recoverReq.Delta.Patches, 1)
+
+		var recovered *model.FileIndex
+		for _, p := range recoverReq.Delta.Patches[0].Patches {
+			if p.Path == "/fileIndex" {
+				recovered = &model.FileIndex{}
+				require.NoError(t, json.Unmarshal(p.Value, recovered))
+			}
+		}
+
+		require.NotNil(t, recovered)
+		require.Equal(t, fileIdx, recovered)
+	})
+
+	t.Run("Server error", func(t *testing.T) {
+		c := &mockHTTPClient{postResponse: &httpclient.HTTPResponse{StatusCode: http.StatusInternalServerError, ErrorMsg: "server error"}}
+
+		err := Recover(c, idxURL, "", recoveryKeys, fileIdx)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "error recovering file index document")
+	})
+
+	t.Run("Client error", func(t *testing.T) {
+		errExpected := errors.New("injected error")
+
+		require.EqualError(t, Recover(&mockHTTPClient{postErr: errExpected}, idxURL, "", recoveryKeys, fileIdx), errExpected.Error())
+	})
+
+	t.Run("Invalid URL", func(t *testing.T) {
+		err := Recover(&mockHTTPClient{}, "http://localhost:48326/file", "", recoveryKeys, fileIdx)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid file index URL")
+	})
+
+	t.Run("Same recovery key", func(t *testing.T) {
+		keys := *recoveryKeys
+		keys.NextRecoveryKeyFile = "../testdata/recover2_public.key"
+
+		err := Recover(&mockHTTPClient{}, idxURL, "", &keys, fileIdx)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "re-using public keys for commitment is not allowed")
+	})
+
+	t.Run("Key store", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "fileidx")
+		require.NoError(t, err)
+		defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+		keys := &RecoveryKeys{
+			SigningKeyFile:      recoveryKeys.SigningKeyFile,
+			NextRecoveryKeyFile: recoveryKeys.NextRecoveryKeyFile,
+			KeyStoreDir:         dir,
+		}
+
+		c := &mockHTTPClient{postResponse: &httpclient.HTTPResponse{StatusCode: http.StatusInternalServerError, ErrorMsg: "server error"}}
+		require.Error(t, Recover(c, idxURL, "", keys, fileIdx))
+
+		_, err = keystore.New(dir).Get(idxURL)
+		require.True(t, errors.Is(err, keystore.ErrNotFound))
+
+		c = &mockHTTPClient{postResponse: &httpclient.HTTPResponse{StatusCode: http.StatusOK}}
+		require.NoError(t, Recover(c, idxURL, "", keys, fileIdx))
+
+		entry, err := keystore.New(dir).Get(idxURL)
+		require.NoError(t, err)
+
+		updateKey, err := publicKeyJWK("", entry.UpdateKey.PublicKey)
+		require.NoError(t, err)
+
+		updateCommitment, err := commitment.GetCommitment(updateKey, sha2_256)
+		require.NoError(t, err)
+		require.Contains(t, string(c.postReq), updateCommitment)
+	})
+
+	t.Run("Key errors", func(t *testing.T) {
+		keys := *recoveryKeys
+		keys.SigningKeyFile = "./xxx.key"
+		require.Error(t, Recover(&mockHTTPClient{}, idxURL, "", &keys, fileIdx))
+
+		keys = *recoveryKeys
+		keys.NextRecoveryKeyFile = "./xxx.key"
+		require.Error(t, Recover(&mockHTTPClient{}, idxURL, "", &keys, fileIdx))
+
+		keys = *recoveryKeys
+		keys.NextUpdateKeyFile = "./xxx.key"
+		require.Error(t, Recover(&mockHTTPClient{}, idxURL, "", &keys, fileIdx))
+	})
+}
+
+func TestDeactivate(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		c := &mockHTTPClient{postResponse: &httpclient.HTTPResponse{StatusCode: http.StatusOK}}
+
+		require.NoError(t, Deactivate(c, idxURL, "tk", recoveryKeys))
+		require.Equal(t, updateURL, c.postURL)
+
+		deactivateReq := &struct {
+			Type      string `json:"type"`
+			DidSuffix string `json:"didSuffix"`
+		}{}
+
+		require.NoError(t, json.Unmarshal(c.postReq, deactivateReq))
+		require.Equal(t, "deactivate", deactivateReq.Type)
+		require.Equal(t, "EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA", deactivateReq.DidSuffix)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		c := &mockHTTPClient{postResponse: &httpclient.HTTPResponse{StatusCode: http.StatusUnauthorized, ErrorMsg: "Unauthorized"}}
+
+		err := Deactivate(c, idxURL, "", recoveryKeys)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "error deactivating file index document")
+		require.Contains(t, err.Error(), "Did you provide an authorization token")
+	})
+
+	t.Run("Invalid URL", func(t *testing.T) {
+		err := Deactivate(&mockHTTPClient{}, "http://localhost:48326/file", "", recoveryKeys)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid file index URL")
+
+		err = Deactivate(&mockHTTPClient{}, "/file/identifiers/xxx", "", recoveryKeys)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unique suffix not provided")
+	})
+
+	t.Run("Key error", func(t *testing.T) {
+		require.Error(t, Deactivate(&mockHTTPClient{}, idxURL, "", &RecoveryKeys{SigningKeyFile: "../testdata/recover_public.key"}))
+	})
+}
@@ -0,0 +1,261 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fileidx
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/httpclient"
+)
+
+// Modes in which a file may be uploaded to DCAS
+const (
+	// UploadModeJSON uploads the file as a JSON upload request (see File) that contains the base64-encoded content
+	UploadModeJSON = "json"
+	// UploadModeRaw uploads the content of the file as the request body with the content type of the file
+	UploadModeRaw = "raw"
+	// UploadModeMultipart uploads the file as the 'file' part of a multipart/form-data request body
+	UploadModeMultipart = "multipart"
+	// UploadModeAuto uploads the file in raw mode and falls back to JSON mode if the server rejects the raw body
+	UploadModeAuto = "auto"
+)
+
+const multipartField = "file"
+
+// ErrUnsupportedUploadMode indicates that the specified upload mode is not supported
+var ErrUnsupportedUploadMode = errors.Errorf("unsupported upload mode (--uploadmode) - supported values are %s, %s, %s and %s",
+	UploadModeJSON, UploadModeRaw, UploadModeMultipart, UploadModeAuto)
+
+// ValidateUploadMode returns an error if the given upload mode is not supported
+func ValidateUploadMode(mode string) error {
+	switch mode {
+	case UploadModeJSON, UploadModeRaw, UploadModeMultipart, UploadModeAuto:
+		return nil
+	default:
+		return ErrUnsupportedUploadMode
+	}
+}
+
+// StreamingHTTPClient is an HTTP client that may also stream the body of a POST request
+type StreamingHTTPClient interface {
+	HTTPClient
+	PostStream(url, contentType string, body io.Reader, opts ...httpclient.RequestOpt) (*httpclient.HTTPResponse, error)
+}
+
+// UploadFile streams the file at the given path to the DCAS endpoint at the given URL, in the mode given by
+// WithUploadMode, and returns the DCAS ID of the file along with the mode that was used (which differs from the
+// given mode only for UploadModeAuto). The file is read from disk on each attempt and is encoded as it's sent,
+// so it's never held in memory as a whole. If retries are enabled (see WithRetries) then the upload is retried
+// on transient errors.
+func UploadFile(c StreamingHTTPClient, url, authToken, contentType, path string, opts ...Opt) (string, string, error) {
+	o := resolveOptions(opts)
+
+	if err := ValidateUploadMode(o.uploadMode); err != nil {
+		return "", "", err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", "", err
+	}
+
+	mode := o.uploadMode
+	if mode == UploadModeAuto {
+		mode = UploadModeRaw
+	}
+
+	post := func() (*httpclient.HTTPResponse, error) {
+		return postFile(c, url, authToken, mode, contentType, path, info.Size())
+	}
+
+	resp, err := postWithRetries(o, post)
+	if err == nil && o.uploadMode == UploadModeAuto && rawRejected(resp) {
+		mode = UploadModeJSON
+
+		resp, err = postWithRetries(o, post)
+	}
+
+	if err != nil {
+		return "", "", err
+	}
+
+	id, err := uploadedID(resp)
+	if err != nil {
+		return "", "", err
+	}
+
+	return id, mode, nil
+}
+
+// DCASIDFromFile returns the DCAS ID (see DCASID) of the file at the given path. The normalized upload request
+// is hashed as it's encoded so that the file is never held in memory as a whole.
+func DCASIDFromFile(contentType, path string) (string, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return "", err
+	}
+
+	defer func() {
+		if err := f.Close(); err != nil {
+			fmt.Printf("Error closing file [%s]: %s", path, err)
+		}
+	}()
+
+	contentTypeBytes, err := json.Marshal(contentType)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+
+	// The fields of the normalized request are sorted by name
+	err = encodeContent(hash, `{"content":"`, f, `","contentType":`+string(contentTypeBytes)+`}`)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.URLEncoding.EncodeToString(hash.Sum(nil)), nil
+}
+
+// rawRejected returns true if the server responded with a status that indicates that it doesn't accept a raw body
+func rawRejected(resp *httpclient.HTTPResponse) bool {
+	return resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnsupportedMediaType
+}
+
+func postFile(c StreamingHTTPClient, url, authToken, mode, contentType, path string, size int64) (*httpclient.HTTPResponse, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+
+	body, reqContentType, length, err := newFileBody(f, mode, contentType, size)
+	if err != nil {
+		return nil, err
+	}
+
+	// Closing the body also stops the encoder if the request failed before the whole body was read. The error is
+	// ignored since the body is usually already closed by the transport.
+	defer body.Close() //nolint: errcheck
+
+	opts := authOpts(authToken)
+	if length > 0 {
+		opts = append(opts, httpclient.WithContentLength(length))
+	}
+
+	return c.PostStream(url, reqContentType, body, opts...)
+}
+
+// newFileBody returns the request body for the given file and upload mode along with the content type and the
+// length of the body (which is -1 if unknown). The file is closed when the body is closed.
+func newFileBody(f *os.File, mode, contentType string, size int64) (io.ReadCloser, string, int64, error) {
+	switch mode {
+	case UploadModeRaw:
+		return f, contentType, size, nil
+
+	case UploadModeMultipart:
+		// The boundary must be known up front since it's part of the content type of the request
+		boundary := multipart.NewWriter(nil).Boundary()
+
+		encode := func(w io.Writer, r io.Reader) error {
+			return encodeMultipart(w, boundary, filepath.Base(f.Name()), contentType, r)
+		}
+
+		return pipe(f, encode), "multipart/form-data; boundary=" + boundary, -1, nil
+
+	default:
+		contentTypeBytes, err := json.Marshal(contentType)
+		if err != nil {
+			return nil, "", 0, err
+		}
+
+		prefix := `{"contentType":` + string(contentTypeBytes) + `,"content":"`
+		suffix := `"}`
+
+		encode := func(w io.Writer, r io.Reader) error {
+			return encodeContent(w, prefix, r, suffix)
+		}
+
+		length := int64(len(prefix)+len(suffix)) + int64(base64.StdEncoding.EncodedLen(int(size)))
+
+		return pipe(f, encode), "application/json", length, nil
+	}
+}
+
+// pipe returns a reader for the output of the given encoder. The encoder runs in a separate goroutine so
+// that the file is encoded as the request body is read rather than being buffered.
+func pipe(f *os.File, encode func(w io.Writer, r io.Reader) error) io.ReadCloser {
+	pr, pw := io.Pipe()
+
+	go func() {
+		err := encode(pw, f)
+
+		if e := f.Close(); e != nil && err == nil {
+			err = e
+		}
+
+		pw.CloseWithError(err) //nolint: errcheck,gosec
+	}()
+
+	return pr
+}
+
+// encodeContent writes the prefix, the base64-encoded content (as encoded by json.Marshal for a byte array)
+// and the suffix to the given writer
+func encodeContent(w io.Writer, prefix string, r io.Reader, suffix string) error {
+	if _, err := io.WriteString(w, prefix); err != nil {
+		return err
+	}
+
+	enc := base64.NewEncoder(base64.StdEncoding, w)
+
+	if _, err := io.Copy(enc, r); err != nil {
+		return err
+	}
+
+	if err := enc.Close(); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, suffix)
+
+	return err
+}
+
+// encodeMultipart writes a multipart/form-data body, with the given boundary, that contains the content
+// as its only part
+func encodeMultipart(w io.Writer, boundary, name, contentType string, r io.Reader) error {
+	mw := multipart.NewWriter(w)
+	if err := mw.SetBoundary(boundary); err != nil {
+		return err
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, multipartField, name))
+	header.Set("Content-Type", contentType)
+
+	part, err := mw.CreatePart(header)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(part, r); err != nil {
+		return err
+	}
+
+	return mw.Close()
+}
@@ -0,0 +1,223 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fileidx
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/httpclient"
+	"github.com/trustbloc/fabric-cli-ext/cmd/mocks"
+)
+
+const contentURL = "http://localhost:48326/content"
+
+func TestDCASIDFromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fileidx")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+	for _, content := range [][]byte{{}, []byte("{}"), []byte(`{"title":"Person <a&b>"}`), make([]byte, 100000)} {
+		path := filepath.Join(dir, "file")
+		require.NoError(t, ioutil.WriteFile(path, content, 0600))
+
+		for _, contentType := range []string{"application/json", "text/html; charset=utf-8", "application/x-<test>"} {
+			expected, err := DCASID(contentType, content)
+			require.NoError(t, err)
+
+			id, err := DCASIDFromFile(contentType, path)
+			require.NoError(t, err)
+			require.Equal(t, expected, id)
+		}
+	}
+
+	_, err = DCASIDFromFile("application/json", filepath.Join(dir, "xxx"))
+	require.Error(t, err)
+}
+
+func TestUploadFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fileidx")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+	content := []byte(`{"title":"Person"}`)
+
+	path := filepath.Join(dir, "person.schema.json")
+	require.NoError(t, ioutil.WriteFile(path, content, 0600))
+
+	t.Run("JSON", func(t *testing.T) {
+		rt := &streamTransport{statusCodes: []int{http.StatusOK}}
+
+		id, mode, err := UploadFile(httpclient.New(httpclient.WithTransport(rt)), contentURL, "tk", "application/json", path)
+		require.NoError(t, err)
+		require.Equal(t, "id1", id)
+		require.Equal(t, UploadModeJSON, mode)
+		require.Equal(t, "application/json", rt.contentType)
+		require.Len(t, rt.body, int(rt.length))
+
+		file := &File{}
+		require.NoError(t, json.Unmarshal(rt.body, file))
+		require.Equal(t, "application/json", file.ContentType)
+		require.Equal(t, content, file.Content)
+	})
+
+	t.Run("Raw", func(t *testing.T) {
+		rt := &streamTransport{statusCodes: []int{http.StatusOK}}
+
+		id, mode, err := UploadFile(httpclient.New(httpclient.WithTransport(rt)), contentURL, "", "application/json", path, WithUploadMode(UploadModeRaw))
+		require.NoError(t, err)
+		require.Equal(t, "id1", id)
+		require.Equal(t, UploadModeRaw, mode)
+		require.Equal(t, "application/json", rt.contentType)
+		require.Equal(t, content, rt.body)
+	})
+
+	t.Run("Multipart", func(t *testing.T) {
+		rt := &streamTransport{statusCodes: []int{http.StatusOK}}
+
+		id, mode, err := UploadFile(httpclient.New(httpclient.WithTransport(rt)), contentURL, "", "application/json", path, WithUploadMode(UploadModeMultipart))
+		require.NoError(t, err)
+		require.Equal(t, "id1", id)
+		require.Equal(t, UploadModeMultipart, mode)
+
+		mediaType, params, err := mime.ParseMediaType(rt.contentType)
+		require.NoError(t, err)
+		require.Equal(t, "multipart/form-data", mediaType)
+
+		form, err := multipart.NewReader(bytes.NewReader(rt.body), params["boundary"]).ReadForm(int64(len(rt.body)))
+		require.NoError(t, err)
+		require.Len(t, form.File[multipartField], 1)
+
+		fh := form.File[multipartField][0]
+		require.Equal(t, "person.schema.json", fh.Filename)
+		require.Equal(t, "application/json", fh.Header.Get("Content-Type"))
+
+		f, err := fh.Open()
+		require.NoError(t, err)
+
+		partBytes, err := ioutil.ReadAll(f)
+		require.NoError(t, err)
+		require.Equal(t, content, partBytes)
+	})
+
+	t.Run("Auto - raw", func(t *testing.T) {
+		rt := &streamTransport{statusCodes: []int{http.StatusOK}}
+
+		_, mode, err := UploadFile(httpclient.New(httpclient.WithTransport(rt)), contentURL, "", "application/json", path, WithUploadMode(UploadModeAuto))
+		require.NoError(t, err)
+		require.Equal(t, UploadModeRaw, mode)
+		require.Equal(t, 1, rt.count)
+	})
+
+	t.Run("Auto - fall back to JSON", func(t *testing.T) {
+		rt := &streamTransport{statusCodes: []int{http.StatusUnsupportedMediaType, http.StatusOK}}
+
+		id, mode, err := UploadFile(httpclient.New(httpclient.WithTransport(rt)), contentURL, "", "application/json", path, WithUploadMode(UploadModeAuto))
+		require.NoError(t, err)
+		require.Equal(t, "id1", id)
+		require.Equal(t, UploadModeJSON, mode)
+		require.Equal(t, 2, rt.count)
+
+		file := &File{}
+		require.NoError(t, json.Unmarshal(rt.body, file))
+		require.Equal(t, content, file.Content)
+	})
+
+	t.Run("Retries", func(t *testing.T) {
+		rt := &streamTransport{statusCodes: []int{http.StatusServiceUnavailable, 0, http.StatusOK}}
+
+		id, _, err := UploadFile(httpclient.New(httpclient.WithTransport(rt)), contentURL, "", "application/json", path, WithRetries(2, time.Millisecond))
+		require.NoError(t, err)
+		require.Equal(t, "id1", id)
+		require.Equal(t, 3, rt.count)
+
+		// The file is streamed again on each attempt
+		file := &File{}
+		require.NoError(t, json.Unmarshal(rt.body, file))
+		require.Equal(t, content, file.Content)
+	})
+
+	t.Run("Server error", func(t *testing.T) {
+		rt := &streamTransport{statusCodes: []int{http.StatusBadRequest}}
+
+		_, _, err := UploadFile(httpclient.New(httpclient.WithTransport(rt)), contentURL, "", "application/json", path)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "Bad Request")
+	})
+
+	t.Run("Unsupported mode", func(t *testing.T) {
+		_, _, err := UploadFile(httpclient.New(), contentURL, "", "application/json", path, WithUploadMode("xxx"))
+		require.EqualError(t, err, ErrUnsupportedUploadMode.Error())
+	})
+
+	t.Run("File not found", func(t *testing.T) {
+		_, _, err := UploadFile(httpclient.New(), contentURL, "", "application/json", filepath.Join(dir, "xxx.json"))
+		require.Error(t, err)
+	})
+
+	t.Run("Request not read", func(t *testing.T) {
+		// The encoder must not block if the request fails before the body is read
+		rt := &streamTransport{statusCodes: []int{0}, skipBody: true}
+
+		_, _, err := UploadFile(httpclient.New(httpclient.WithTransport(rt)), contentURL, "", "application/json", path)
+		require.Error(t, err)
+	})
+}
+
+// streamTransport reads the body of each request and returns responses with the given status codes in sequence
+// (repeating the last status code). A status code of zero results in a connection error.
+type streamTransport struct {
+	statusCodes []int
+	skipBody    bool
+	count       int
+	contentType string
+	length      int64
+	body        []byte
+}
+
+func (m *streamTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	statusCode := m.statusCodes[len(m.statusCodes)-1]
+	if m.count < len(m.statusCodes) {
+		statusCode = m.statusCodes[m.count]
+	}
+
+	m.count++
+
+	if statusCode == 0 && m.skipBody {
+		return nil, errConnectionRefused
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	m.body = body
+	m.contentType = req.Header.Get("Content-Type")
+	m.length = req.ContentLength
+
+	if statusCode == 0 {
+		return nil, errConnectionRefused
+	}
+
+	payload := []byte(`"id1"`)
+	if statusCode != http.StatusOK {
+		payload = []byte(http.StatusText(statusCode))
+	}
+
+	return &http.Response{StatusCode: statusCode, Body: mocks.NewResponseBody(payload)}, nil
+}
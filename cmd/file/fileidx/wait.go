@@ -0,0 +1,81 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fileidx
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultWaitTimeout is the default amount of time to wait for a Sidetree operation to be anchored
+const DefaultWaitTimeout = time.Minute
+
+// pollInterval is the interval at which the file index document is resolved while waiting for an operation to be anchored
+var pollInterval = time.Second
+
+// Expected contains the state of a file index document that is expected to be resolvable once
+// a Sidetree operation on the document is anchored
+type Expected struct {
+	// UpdateCommitment is the update commitment set by the operation
+	UpdateCommitment string
+	// Mappings are the mappings (name to ID) that must be present in the document
+	Mappings map[string]string
+}
+
+// Wait polls the file index document at the given URL until the document is published with the expected
+// update commitment and mappings, or until the given timeout elapses. The time that it took for the
+// operation to be anchored (i.e. since Wait was called) is returned.
+func Wait(c HTTPClient, idxURL, authToken string, expected *Expected, timeout time.Duration) (time.Duration, error) {
+	start := time.Now()
+	deadline := start.Add(timeout)
+
+	for {
+		reason := pending(c, idxURL, authToken, expected)
+		if reason == "" {
+			return time.Since(start), nil
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return 0, errors.Errorf("timed out after %s waiting for file index document [%s] to be anchored: %s", timeout, idxURL, reason)
+		}
+
+		if remaining > pollInterval {
+			remaining = pollInterval
+		}
+
+		time.Sleep(remaining)
+	}
+}
+
+// pending resolves the file index document and returns the reason why the document doesn't yet have
+// the expected state or an empty string if it does. Errors are reported as the reason since the document
+// may not be resolvable until the operation is anchored.
+func pending(c HTTPClient, idxURL, authToken string, expected *Expected) string {
+	r, err := Resolve(c, idxURL, authToken)
+	if err != nil {
+		return err.Error()
+	}
+
+	if !r.Published {
+		return "the document is not yet published"
+	}
+
+	if expected.UpdateCommitment != "" && r.UpdateCommitment != expected.UpdateCommitment {
+		return fmt.Sprintf("expecting update commitment [%s] but got [%s]", expected.UpdateCommitment, r.UpdateCommitment)
+	}
+
+	for name, id := range expected.Mappings {
+		if r.FileIndex.Mappings[name] != id {
+			return fmt.Sprintf("expecting mapping [%s] to be [%s] but got [%s]", name, id, r.FileIndex.Mappings[name])
+		}
+	}
+
+	return ""
+}
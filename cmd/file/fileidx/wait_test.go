@@ -0,0 +1,158 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fileidx
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/commitment"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/httpclient"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/keystore"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/model"
+)
+
+func TestEndpointURL(t *testing.T) {
+	require.Equal(t, baseURL, EndpointURL(baseURL))
+	require.Equal(t, baseURL, EndpointURL(baseURL+"/"))
+	require.Equal(t, baseURL, EndpointURL(updateURL))
+	require.Equal(t, baseURL+"/identifiers/file:idx:1234", IdentifierURL(updateURL, "file:idx:1234"))
+}
+
+func TestResolve(t *testing.T) {
+	t.Run("Method metadata", func(t *testing.T) {
+		c := &mockHTTPClient{getResponse: resolutionResponse(t, map[string]string{"a.json": "id1"}, `{"updateCommitment":"uc1","published":false}`)}
+
+		r, err := Resolve(c, idxURL, "")
+		require.NoError(t, err)
+		require.Equal(t, "uc1", r.UpdateCommitment)
+		require.False(t, r.Published)
+		require.Equal(t, "id1", r.FileIndex.Mappings["a.json"])
+	})
+
+	t.Run("No published property", func(t *testing.T) {
+		c := &mockHTTPClient{getResponse: resolutionResponse(t, nil, `{"updateCommitment":"uc1"}`)}
+
+		r, err := Resolve(c, idxURL, "")
+		require.NoError(t, err)
+		require.True(t, r.Published)
+	})
+
+	t.Run("Invalid method metadata", func(t *testing.T) {
+		c := &mockHTTPClient{getResponse: resolutionResponse(t, nil, `{"published":"xxx"}`)}
+
+		_, err := Resolve(c, idxURL, "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid method metadata")
+	})
+}
+
+func TestUpdateKeys_NextUpdateCommitment(t *testing.T) {
+	nextUpdateKey, err := keys.nextUpdateKeyPublic()
+	require.NoError(t, err)
+
+	expected, err := commitment.GetCommitment(nextUpdateKey, sha2_512)
+	require.NoError(t, err)
+
+	c, err := keys.NextUpdateCommitment(idxURL, sha2_512)
+	require.NoError(t, err)
+	require.Equal(t, expected, c)
+
+	t.Run("Key store", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "fileidx")
+		require.NoError(t, err)
+		defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+		storeKeys := &UpdateKeys{KeyStoreDir: dir}
+
+		_, err = storeKeys.NextUpdateCommitment(idxURL, sha2_256)
+		require.Error(t, err)
+
+		entry, err := keystore.NewEntry("")
+		require.NoError(t, err)
+		require.NoError(t, keystore.New(dir).Put(idxURL, entry))
+
+		c, err := storeKeys.NextUpdateCommitment(idxURL, sha2_256)
+		require.NoError(t, err)
+
+		expected, err := (&UpdateKeys{NextUpdateKeyString: entry.NextUpdateKey.PublicKey}).NextUpdateCommitment(idxURL, sha2_256)
+		require.NoError(t, err)
+		require.Equal(t, expected, c)
+	})
+}
+
+func TestWait(t *testing.T) {
+	interval := pollInterval
+	pollInterval = time.Millisecond
+	defer func() { pollInterval = interval }()
+
+	expected := &Expected{UpdateCommitment: "uc2", Mappings: map[string]string{"a.json": "id2"}}
+
+	t.Run("Anchored", func(t *testing.T) {
+		c := &mockResolutionClient{responses: []*httpclient.HTTPResponse{
+			{StatusCode: http.StatusNotFound},
+			resolutionResponse(t, map[string]string{"a.json": "id2"}, `{"updateCommitment":"uc2","published":false}`),
+			resolutionResponse(t, map[string]string{"a.json": "id1"}, `{"updateCommitment":"uc1","published":true}`),
+			resolutionResponse(t, map[string]string{"a.json": "id2"}, `{"updateCommitment":"uc2","published":true}`),
+		}}
+
+		_, err := Wait(c, idxURL, "", expected, time.Second)
+		require.NoError(t, err)
+		require.Equal(t, 4, c.count)
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		c := &mockResolutionClient{responses: []*httpclient.HTTPResponse{
+			resolutionResponse(t, map[string]string{"a.json": "id1"}, `{"updateCommitment":"uc2","published":true}`),
+		}}
+
+		_, err := Wait(c, idxURL, "", expected, 10*time.Millisecond)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "timed out after 10ms waiting for file index document")
+		require.Contains(t, err.Error(), "expecting mapping [a.json] to be [id2] but got [id1]")
+	})
+
+	t.Run("Timeout - not found", func(t *testing.T) {
+		_, err := Wait(&mockHTTPClient{}, idxURL, "", expected, 0)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "not found")
+	})
+}
+
+func resolutionResponse(t *testing.T, mappings map[string]string, metadata string) *httpclient.HTTPResponse {
+	docBytes, err := json.Marshal(&model.FileIndexDoc{FileIndex: model.FileIndex{BasePath: "/content", Mappings: mappings}})
+	require.NoError(t, err)
+
+	respBytes, err := json.Marshal(&model.DIDResolution{DIDDocument: docBytes, MethodMetadata: json.RawMessage(metadata)})
+	require.NoError(t, err)
+
+	return &httpclient.HTTPResponse{StatusCode: http.StatusOK, Payload: respBytes}
+}
+
+// mockResolutionClient returns the given responses in sequence, repeating the last response
+type mockResolutionClient struct {
+	mockHTTPClient
+	responses []*httpclient.HTTPResponse
+	count     int
+}
+
+func (m *mockResolutionClient) Get(string, ...httpclient.RequestOpt) (*httpclient.HTTPResponse, error) {
+	resp := m.responses[len(m.responses)-1]
+	if m.count < len(m.responses) {
+		resp = m.responses[m.count]
+	}
+
+	m.count++
+
+	return resp, nil
+}
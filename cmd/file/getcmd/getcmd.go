@@ -0,0 +1,210 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package getcmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/basecmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/fileidx"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/httpclient"
+)
+
+const (
+	use      = "get"
+	desc     = "Download a file using the file index"
+	longDesc = `
+The get command looks up the DCAS ID of a file in a Sidetree file index document, downloads the file from the base path
+given by --url and verifies that the hash of the downloaded content matches the DCAS ID. The content is written to the
+file given by --output or, if not specified, to standard output. Nothing is written if the verification fails.
+`
+	examples = `
+- Download 'person.schema.json' from the '/content' path to a local file:
+    $ ./fabric file get --url http://localhost:48326/content --idxurl http://localhost:48326/file/identifiers/file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA== --name person.schema.json -o ./person.schema.json
+`
+)
+
+const (
+	urlFlag  = "url"
+	urlUsage = "The URL of the base path from which to download the file. Example: --url http://localhost:48326/content"
+
+	fileIndexURLFlag  = "idxurl"
+	fileIndexURLUsage = "The URL of the file index Sidetree document. Example: --idxurl http://localhost:48326/file/identifiers/file:idx:1234"
+
+	nameFlag  = "name"
+	nameUsage = "The name of the file mapping. Example: --name person.schema.json"
+
+	outputFlag      = "output"
+	outputShorthand = "o"
+	outputUsage     = "The file to which the content is written. If not specified then the content is written to standard output. Example: -o ./person.schema.json"
+)
+
+var (
+	errURLRequired          = errors.New("URL (--url) is required")
+	errFileIndexURLRequired = errors.New("file index URL (--idxurl) is required")
+	errNameRequired         = errors.New("name (--name) is required")
+)
+
+// New returns the file get sub-command
+func New(settings *environment.Settings) *cobra.Command {
+	cfg := httpclient.NewConfig(settings)
+
+	cmd := newCmd(settings, httpclient.New(httpclient.WithConfig(cfg)))
+	cfg.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+func newCmd(settings *environment.Settings, client fileidx.HTTPClient) *cobra.Command {
+	c := &command{
+		Command: basecmd.New(settings, nil),
+		client:  client,
+	}
+
+	cmd := &cobra.Command{
+		Use:     use,
+		Short:   desc,
+		Long:    longDesc,
+		Example: examples,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return c.validate()
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return c.run()
+		},
+	}
+
+	cmd.SetOutput(c.Settings.Streams.Out)
+	cmd.SilenceUsage = true
+
+	cmd.Flags().StringVar(&c.url, urlFlag, "", urlUsage)
+	cmd.Flags().StringVar(&c.fileIndexURL, fileIndexURLFlag, "", fileIndexURLUsage)
+	cmd.Flags().StringVar(&c.name, nameFlag, "", nameUsage)
+	cmd.Flags().StringVarP(&c.output, outputFlag, outputShorthand, "", outputUsage)
+	httpclient.AddAuthTokenFlag(cmd.Flags(), &c.authToken, "the URL specified by --idxurl")
+	httpclient.AddContentAuthTokenFlag(cmd.Flags(), &c.contentAuthToken, "download files from the URL specified by --url")
+
+	return cmd
+}
+
+// command implements the get command
+type command struct {
+	*basecmd.Command
+	client fileidx.HTTPClient
+
+	url              string
+	basePath         string
+	fileIndexURL     string
+	name             string
+	output           string
+	authToken        string
+	contentAuthToken string
+}
+
+func (c *command) validate() error {
+	if c.url == "" {
+		return errURLRequired
+	}
+
+	u, err := url.Parse(c.url)
+	if err != nil {
+		return errors.WithMessagef(err, "invalid URL [%s]", c.url)
+	}
+
+	if u.Path == "" {
+		return errors.New("invalid URL - no base path found")
+	}
+
+	c.basePath = u.Path
+
+	if c.fileIndexURL == "" {
+		return errFileIndexURLRequired
+	}
+
+	if c.name == "" {
+		return errNameRequired
+	}
+
+	if c.contentAuthToken == "" {
+		c.contentAuthToken = c.authToken
+	}
+
+	return nil
+}
+
+func (c *command) run() error {
+	fileIdx, err := fileidx.Get(c.client, c.fileIndexURL, c.authToken)
+	if err != nil {
+		return err
+	}
+
+	if fileIdx.BasePath != c.basePath {
+		return errors.Errorf("base path of file index doc does not match the base path of the file: [%s] != [%s]", fileIdx.BasePath, c.basePath)
+	}
+
+	id, ok := fileIdx.Mappings[c.name]
+	if !ok {
+		return errors.Errorf("mapping [%s] not found in file index document [%s]", c.name, c.fileIndexURL)
+	}
+
+	resp, err := c.download()
+	if err != nil {
+		return err
+	}
+
+	computedID, err := fileidx.DCASID(resp.ContentType, resp.Payload)
+	if err != nil {
+		return err
+	}
+
+	if computedID != id {
+		return errors.Errorf("the content of [%s] does not match the DCAS ID in the file index: [%s] != [%s]", c.name, computedID, id)
+	}
+
+	if c.output == "" {
+		_, err = c.Settings.Streams.Out.Write(resp.Payload)
+
+		return err
+	}
+
+	if err := ioutil.WriteFile(filepath.Clean(c.output), resp.Payload, 0600); err != nil {
+		return err
+	}
+
+	return c.Fprintln(fmt.Sprintf("Downloaded [%s] (%s, %s) to [%s]", c.name, id, resp.ContentType, c.output))
+}
+
+func (c *command) download() (*httpclient.HTTPResponse, error) {
+	var reqOpts []httpclient.RequestOpt
+	if c.contentAuthToken != "" {
+		reqOpts = append(reqOpts, httpclient.WithAuthToken(c.contentAuthToken))
+	}
+
+	resp, err := c.client.Get(strings.TrimSuffix(c.url, "/")+"/"+c.name, reqOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		if resp.StatusCode == http.StatusUnauthorized {
+			return nil, errors.Errorf("status code %d: %s - Did you provide an authorization token (--contentauthtoken)?", resp.StatusCode, resp.ErrorMsg)
+		}
+
+		return nil, errors.Errorf("error downloading [%s]. Status code %d: %s", c.name, resp.StatusCode, resp.ErrorMsg)
+	}
+
+	return resp, nil
+}
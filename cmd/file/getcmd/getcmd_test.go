@@ -0,0 +1,205 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package getcmd
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/fileidx"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/httpclient"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/model"
+	"github.com/trustbloc/fabric-cli-ext/cmd/mocks"
+)
+
+const (
+	contentURL  = "http://localhost:48326/content"
+	idxURL      = "http://localhost:48326/file/identifiers/file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA"
+	name        = "person.schema.json"
+	contentType = "application/json"
+)
+
+func TestNew(t *testing.T) {
+	require.NotNil(t, New(environment.NewDefaultSettings()))
+}
+
+func TestGetCmd_InvalidOptions(t *testing.T) {
+	t.Run("No --url", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, &mocks.Writer{}).Execute(), errURLRequired.Error())
+	})
+
+	t.Run("No base path", func(t *testing.T) {
+		err := newMockCmd(t, nil, &mocks.Writer{}, "--url", "http://localhost:48326").Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "no base path found")
+	})
+
+	t.Run("Invalid --url", func(t *testing.T) {
+		err := newMockCmd(t, nil, &mocks.Writer{}, "--url", ":xxx").Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid URL")
+	})
+
+	t.Run("No --idxurl", func(t *testing.T) {
+		err := newMockCmd(t, nil, &mocks.Writer{}, "--url", contentURL).Execute()
+		require.EqualError(t, err, errFileIndexURLRequired.Error())
+	})
+
+	t.Run("No --name", func(t *testing.T) {
+		err := newMockCmd(t, nil, &mocks.Writer{}, "--url", contentURL, "--idxurl", idxURL).Execute()
+		require.EqualError(t, err, errNameRequired.Error())
+	})
+}
+
+func TestGetCmd(t *testing.T) {
+	content, err := ioutil.ReadFile("../uploadcmd/testdata/person.schema.json")
+	require.NoError(t, err)
+
+	id, err := fileidx.DCASID(contentType, content)
+	require.NoError(t, err)
+
+	contentResponse := &httpclient.HTTPResponse{StatusCode: http.StatusOK, ContentType: contentType, Payload: content}
+
+	t.Run("Success -> stdout", func(t *testing.T) {
+		client := newMockClient(t, "/content", id, contentResponse)
+
+		w := &mocks.Writer{}
+		require.NoError(t, newMockCmd(t, client, w, "--url", contentURL, "--idxurl", idxURL, "--name", name).Execute())
+		require.Equal(t, string(content), string(w.Bytes))
+	})
+
+	t.Run("Success -> file", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "getcmd")
+		require.NoError(t, err)
+		defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+		out := filepath.Join(dir, name)
+
+		client := newMockClient(t, "/content", id, contentResponse)
+
+		w := &mocks.Writer{}
+		require.NoError(t, newMockCmd(t, client, w, "--url", contentURL, "--idxurl", idxURL, "--name", name, "-o", out).Execute())
+		require.Contains(t, w.Written(), "Downloaded [person.schema.json]")
+
+		written, err := ioutil.ReadFile(filepath.Clean(out))
+		require.NoError(t, err)
+		require.Equal(t, content, written)
+	})
+
+	t.Run("Hash mismatch", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "getcmd")
+		require.NoError(t, err)
+		defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+		out := filepath.Join(dir, name)
+
+		client := newMockClient(t, "/content", "xxx", contentResponse)
+
+		err = newMockCmd(t, client, &mocks.Writer{}, "--url", contentURL, "--idxurl", idxURL, "--name", name, "-o", out).Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "does not match the DCAS ID in the file index")
+
+		_, err = os.Stat(out)
+		require.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("Mapping not found", func(t *testing.T) {
+		client := newMockClient(t, "/content", id, contentResponse)
+
+		err := newMockCmd(t, client, &mocks.Writer{}, "--url", contentURL, "--idxurl", idxURL, "--name", "xxx.json").Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "mapping [xxx.json] not found")
+	})
+
+	t.Run("Base path mismatch", func(t *testing.T) {
+		client := newMockClient(t, "/other", id, contentResponse)
+
+		err := newMockCmd(t, client, &mocks.Writer{}, "--url", contentURL, "--idxurl", idxURL, "--name", name).Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "base path of file index doc does not match")
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		client := newMockClient(t, "/content", id, &httpclient.HTTPResponse{StatusCode: http.StatusUnauthorized, ErrorMsg: "Unauthorized"})
+
+		err := newMockCmd(t, client, &mocks.Writer{}, "--url", contentURL, "--idxurl", idxURL, "--name", name, "--authtoken", "tk").Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "--contentauthtoken")
+	})
+
+	t.Run("Server error", func(t *testing.T) {
+		client := newMockClient(t, "/content", id, &httpclient.HTTPResponse{StatusCode: http.StatusInternalServerError, ErrorMsg: "server error"})
+
+		err := newMockCmd(t, client, &mocks.Writer{}, "--url", contentURL, "--idxurl", idxURL, "--name", name).Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "error downloading [person.schema.json]")
+	})
+
+	t.Run("Download error", func(t *testing.T) {
+		errExpected := errors.New("injected error")
+		client := newMockClient(t, "/content", id, nil)
+		client.errors[contentURL+"/"+name] = errExpected
+
+		err := newMockCmd(t, client, &mocks.Writer{}, "--url", contentURL, "--idxurl", idxURL, "--name", name).Execute()
+		require.EqualError(t, err, errExpected.Error())
+	})
+}
+
+func newMockClient(t *testing.T, basePath, id string, contentResponse *httpclient.HTTPResponse) *mockHTTPClient {
+	fileIdxDocBytes, err := json.Marshal(&model.FileIndexDoc{FileIndex: model.FileIndex{BasePath: basePath, Mappings: map[string]string{".": basePath, name: id}}})
+	require.NoError(t, err)
+
+	return &mockHTTPClient{
+		responses: map[string]*httpclient.HTTPResponse{
+			idxURL:                  {StatusCode: http.StatusOK, Payload: fileIdxDocBytes},
+			contentURL + "/" + name: contentResponse,
+		},
+		errors: make(map[string]error),
+	}
+}
+
+type mockHTTPClient struct {
+	responses map[string]*httpclient.HTTPResponse
+	errors    map[string]error
+}
+
+func (m *mockHTTPClient) Get(url string, _ ...httpclient.RequestOpt) (*httpclient.HTTPResponse, error) {
+	if err, ok := m.errors[url]; ok {
+		return nil, err
+	}
+
+	return m.responses[url], nil
+}
+
+func (m *mockHTTPClient) Post(string, []byte, ...httpclient.RequestOpt) (*httpclient.HTTPResponse, error) {
+	panic("not implemented")
+}
+
+func newMockCmd(t *testing.T, client fileidx.HTTPClient, w io.Writer, args ...string) *cobra.Command {
+	settings := environment.NewDefaultSettings()
+	settings.Streams.Out = w
+
+	settings.Config.CurrentContext = "testctx"
+	settings.Config.Contexts[settings.Config.CurrentContext] = &environment.Context{}
+
+	c := newCmd(settings, client)
+	require.NotNil(t, c)
+
+	c.SetArgs(args)
+
+	return c
+}
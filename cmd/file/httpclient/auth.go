@@ -0,0 +1,160 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package httpclient
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Prefixes of secrets that are read from an environment variable or a file, or that are given literally
+const (
+	envPrefix     = "env:"
+	filePrefix    = "file:"
+	literalPrefix = "literal:"
+)
+
+// DefaultCredentialsFile is the name of the default credentials store in the fabric-cli home
+const DefaultCredentialsFile = "credentials.json"
+
+// Credentials contains the credentials for a host in the credentials store. Exactly one of Token, OAuth2
+// or HTTPSignature must be specified.
+type Credentials struct {
+	// Token is a static bearer token (see ResolveSecret)
+	Token string `json:"token,omitempty"`
+	// OAuth2 contains the client credentials used to acquire bearer tokens from an OAuth2 token endpoint
+	OAuth2 *OAuth2Credentials `json:"oauth2,omitempty"`
+	// HTTPSignature contains the key used to sign requests using HTTP Signatures
+	HTTPSignature *HTTPSignatureCredentials `json:"httpSignature,omitempty"`
+}
+
+// authorizer sets the authorization of HTTP requests
+type authorizer interface {
+	// authorize sets the authorization of the given request. The body is nil if the request is streamed.
+	authorize(req *http.Request, body []byte) error
+
+	// refresh discards the cached credentials (if any) so that new credentials are acquired for the next request.
+	// False is returned if the credentials can't be refreshed.
+	refresh() bool
+}
+
+// ResolveSecret returns the given secret or, if the secret is given as env:<name> or file:<path>, the value of the
+// environment variable or the (trimmed) content of the file. This allows secrets such as tokens to be passed
+// without exposing them in the shell history. A secret that itself begins with one of these prefixes may be
+// given as literal:<secret>, in which case the rest of the value is returned as is.
+func ResolveSecret(secret string) (string, error) {
+	switch {
+	case strings.HasPrefix(secret, literalPrefix):
+		return strings.TrimPrefix(secret, literalPrefix), nil
+
+	case strings.HasPrefix(secret, envPrefix):
+		name := strings.TrimPrefix(secret, envPrefix)
+
+		value := os.Getenv(name)
+		if value == "" {
+			return "", errors.Errorf("environment variable [%s] is not set", name)
+		}
+
+		return value, nil
+
+	case strings.HasPrefix(secret, filePrefix):
+		path := strings.TrimPrefix(secret, filePrefix)
+
+		value, err := ioutil.ReadFile(filepath.Clean(path))
+		if err != nil {
+			return "", errors.WithMessagef(err, "error reading secret from file [%s]", path)
+		}
+
+		return strings.TrimSpace(string(value)), nil
+
+	default:
+		return secret, nil
+	}
+}
+
+// loadCredentials loads the credentials store at the given path, which maps host names (optionally with
+// a port) to credentials. No credentials are returned if the path isn't set or the file doesn't exist.
+func loadCredentials(path string) (map[string]*Credentials, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	credentialsBytes, err := ioutil.ReadFile(filepath.Clean(path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, errors.WithMessagef(err, "error reading credentials store [%s]", path)
+	}
+
+	credentials := make(map[string]*Credentials)
+	if err := json.Unmarshal(credentialsBytes, &credentials); err != nil {
+		return nil, errors.WithMessagef(err, "invalid credentials store [%s]", path)
+	}
+
+	return credentials, nil
+}
+
+// newAuthorizer returns the authorizer for the given credentials. The given client is used to acquire OAuth2 tokens.
+func newAuthorizer(credentials *Credentials, client *http.Client) (authorizer, error) {
+	var auth authorizer
+	var err error
+
+	n := 0
+
+	if credentials.Token != "" {
+		n++
+
+		var token string
+		token, err = ResolveSecret(credentials.Token)
+		auth = &bearerAuthorizer{token: token}
+	}
+
+	if credentials.OAuth2 != nil {
+		n++
+
+		auth, err = newOAuth2Authorizer(credentials.OAuth2, client)
+	}
+
+	if credentials.HTTPSignature != nil {
+		n++
+
+		auth, err = newHTTPSignatureAuthorizer(credentials.HTTPSignature)
+	}
+
+	if n != 1 {
+		return nil, errors.New("exactly one of token, oauth2 or httpSignature must be specified")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return auth, nil
+}
+
+// bearerAuthorizer sets a static bearer token
+type bearerAuthorizer struct {
+	token string
+}
+
+func (a *bearerAuthorizer) authorize(req *http.Request, _ []byte) error {
+	req.Header.Set(authHeader, tokenPrefix+a.token)
+
+	return nil
+}
+
+func (a *bearerAuthorizer) refresh() bool {
+	return false
+}
@@ -0,0 +1,168 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package httpclient
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveSecret(t *testing.T) {
+	dir, err := ioutil.TempDir("", "httpclient")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+	tokenFile := filepath.Join(dir, "token")
+	require.NoError(t, ioutil.WriteFile(tokenFile, []byte("filetoken\n"), 0600))
+
+	require.NoError(t, os.Setenv("HTTPCLIENT_TEST_TOKEN", "envtoken"))
+	defer func() { require.NoError(t, os.Unsetenv("HTTPCLIENT_TEST_TOKEN")) }()
+
+	secret, err := ResolveSecret("mytoken")
+	require.NoError(t, err)
+	require.Equal(t, "mytoken", secret)
+
+	secret, err = ResolveSecret("env:HTTPCLIENT_TEST_TOKEN")
+	require.NoError(t, err)
+	require.Equal(t, "envtoken", secret)
+
+	secret, err = ResolveSecret("file:" + tokenFile)
+	require.NoError(t, err)
+	require.Equal(t, "filetoken", secret)
+
+	// A secret that begins with one of the prefixes may be given literally
+	secret, err = ResolveSecret("literal:env:HTTPCLIENT_TEST_TOKEN")
+	require.NoError(t, err)
+	require.Equal(t, "env:HTTPCLIENT_TEST_TOKEN", secret)
+
+	secret, err = ResolveSecret("literal:literal:mytoken")
+	require.NoError(t, err)
+	require.Equal(t, "literal:mytoken", secret)
+
+	_, err = ResolveSecret("env:HTTPCLIENT_TEST_XXX")
+	require.EqualError(t, err, "environment variable [HTTPCLIENT_TEST_XXX] is not set")
+
+	_, err = ResolveSecret("file:" + filepath.Join(dir, "xxx"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "error reading secret from file")
+}
+
+func TestClient_Auth(t *testing.T) {
+	dir, err := ioutil.TempDir("", "httpclient")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+	var authorization string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get(authHeader)
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	require.NoError(t, os.Setenv("HTTPCLIENT_TEST_TOKEN", "envtoken"))
+	defer func() { require.NoError(t, os.Unsetenv("HTTPCLIENT_TEST_TOKEN")) }()
+
+	writeCredentials := func(content string) string {
+		path := filepath.Join(dir, "credentials.json")
+		require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+
+		return path
+	}
+
+	t.Run("Token from env", func(t *testing.T) {
+		_, err := New().Get(server.URL, WithAuthToken("env:HTTPCLIENT_TEST_TOKEN"))
+		require.NoError(t, err)
+		require.Equal(t, "Bearer envtoken", authorization)
+	})
+
+	t.Run("Literal token", func(t *testing.T) {
+		_, err := New().Get(server.URL, WithAuthToken("literal:file:token"))
+		require.NoError(t, err)
+		require.Equal(t, "Bearer file:token", authorization)
+	})
+
+	t.Run("Invalid token", func(t *testing.T) {
+		_, err := New().Post(server.URL, nil, WithAuthToken("env:HTTPCLIENT_TEST_XXX"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid authorization token")
+	})
+
+	t.Run("Credentials store", func(t *testing.T) {
+		path := writeCredentials(`{"` + host + `": {"token": "env:HTTPCLIENT_TEST_TOKEN"}}`)
+
+		_, err := New(WithConfig(&Config{CredentialsFile: path})).Get(server.URL)
+		require.NoError(t, err)
+		require.Equal(t, "Bearer envtoken", authorization)
+
+		// An explicit token takes precedence
+		_, err = New(WithConfig(&Config{CredentialsFile: path})).Get(server.URL, WithAuthToken("mytoken"))
+		require.NoError(t, err)
+		require.Equal(t, "Bearer mytoken", authorization)
+	})
+
+	t.Run("Credentials store - host name", func(t *testing.T) {
+		path := writeCredentials(`{"127.0.0.1": {"token": "hosttoken"}}`)
+
+		_, err := New(WithConfig(&Config{CredentialsFile: path})).Get(server.URL)
+		require.NoError(t, err)
+		require.Equal(t, "Bearer hosttoken", authorization)
+	})
+
+	t.Run("Credentials store - no credentials for host", func(t *testing.T) {
+		path := writeCredentials(`{"example.com": {"token": "mytoken"}}`)
+
+		_, err := New(WithConfig(&Config{CredentialsFile: path})).Get(server.URL)
+		require.NoError(t, err)
+		require.Empty(t, authorization)
+	})
+
+	t.Run("Credentials store not found", func(t *testing.T) {
+		_, err := New(WithConfig(&Config{CredentialsFile: filepath.Join(dir, "xxx.json")})).Get(server.URL)
+		require.NoError(t, err)
+		require.Empty(t, authorization)
+	})
+
+	t.Run("Invalid credentials store", func(t *testing.T) {
+		path := writeCredentials(`{`)
+
+		_, err := New(WithConfig(&Config{CredentialsFile: path})).Get(server.URL)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid credentials store")
+
+		_, err = New(WithConfig(&Config{CredentialsFile: dir})).Get(server.URL)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "error reading credentials store")
+	})
+
+	t.Run("Invalid credentials", func(t *testing.T) {
+		for content, msg := range map[string]string{
+			`{}`: "exactly one of token, oauth2 or httpSignature must be specified",
+			`{"token": "mytoken", "oauth2": {"tokenUrl": "https://auth.example.com/token", "clientId": "cli"}}`: "exactly one of token, oauth2 or httpSignature must be specified",
+			`{"token": "env:HTTPCLIENT_TEST_XXX"}`: "environment variable [HTTPCLIENT_TEST_XXX] is not set",
+			`{"oauth2": {"clientId": "cli"}}`:      "the OAuth2 token URL and client ID are required",
+			`{"oauth2": {"tokenUrl": "https://auth.example.com/token", "clientId": "cli", "clientSecret": "env:HTTPCLIENT_TEST_XXX"}}`: "invalid OAuth2 client secret",
+			`{"httpSignature": {"keyId": "key1"}}`:                                                     "the HTTP signature key ID and key file are required",
+			`{"httpSignature": {"keyId": "key1", "keyFile": "` + filepath.Join(dir, "xxx.pem") + `"}}`: "error loading HTTP signature key",
+		} {
+			path := writeCredentials(`{"` + host + `": ` + content + `}`)
+
+			_, err := New(WithConfig(&Config{CredentialsFile: path})).Get(server.URL)
+			require.Error(t, err, content)
+			require.Contains(t, err.Error(), "invalid credentials for host ["+host+"]", content)
+			require.Contains(t, err.Error(), msg, content)
+		}
+	})
+}
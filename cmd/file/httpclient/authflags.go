@@ -0,0 +1,35 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package httpclient
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	authTokenFlag  = "authtoken"
+	authTokenUsage = "The bearer authorization token that may be required to access %s. %s If not specified then the credentials store (--credentials) is used. Example: --authtoken env:SIDETREE_TOKEN" //nolint: gosec
+
+	contentAuthTokenFlag  = "contentauthtoken"
+	contentAuthTokenUsage = "The bearer authorization token to %s. This is only required if it is different from --authtoken. %s Example: --contentauthtoken file:./dcas-token" //nolint: gosec
+
+	tokenSourceUsage = "The token may be read from an environment variable (env:<name>) or a file (file:<path>) so that it isn't exposed in the shell history. A token that itself begins with env:, file: or literal: must be given as literal:<token>."
+)
+
+// AddAuthTokenFlag adds the --authtoken flag to the given flag set. The target describes what the token
+// is used to access, e.g. "the URL specified by --idxurl".
+func AddAuthTokenFlag(flags *pflag.FlagSet, token *string, target string) {
+	flags.StringVar(token, authTokenFlag, "", fmt.Sprintf(authTokenUsage, target, tokenSourceUsage))
+}
+
+// AddContentAuthTokenFlag adds the --contentauthtoken flag to the given flag set. The purpose describes what
+// the token is used for, e.g. "download files from the URL specified by --url".
+func AddContentAuthTokenFlag(flags *pflag.FlagSet, token *string, purpose string) {
+	flags.StringVar(token, contentAuthTokenFlag, "", fmt.Sprintf(contentAuthTokenUsage, purpose, tokenSourceUsage))
+}
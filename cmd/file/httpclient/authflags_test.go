@@ -0,0 +1,34 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package httpclient
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddAuthTokenFlags(t *testing.T) {
+	var authToken, contentAuthToken string
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	AddAuthTokenFlag(flags, &authToken, "the URL specified by --idxurl")
+	AddContentAuthTokenFlag(flags, &contentAuthToken, "upload files to the URL specified by --url")
+
+	require.NoError(t, flags.Parse([]string{"--authtoken", "env:TOKEN", "--contentauthtoken", "file:./token"}))
+	require.Equal(t, "env:TOKEN", authToken)
+	require.Equal(t, "file:./token", contentAuthToken)
+
+	usage := flags.Lookup(authTokenFlag).Usage
+	require.Contains(t, usage, "access the URL specified by --idxurl.")
+	require.Contains(t, usage, "literal:<token>")
+
+	usage = flags.Lookup(contentAuthTokenFlag).Usage
+	require.Contains(t, usage, "token to upload files to the URL specified by --url.")
+	require.Contains(t, usage, "literal:<token>")
+}
@@ -0,0 +1,162 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+)
+
+const (
+	caCertsFlag  = "cacerts"
+	caCertsUsage = "A semi-colon separated list of files that contain the PEM-encoded certificates of the CAs that are trusted (in addition to the system's root CAs) when connecting to an HTTPS endpoint. Example: --cacerts ./tls/ca.pem;./tls/intermediate-ca.pem"
+
+	clientCertFlag  = "clientcert"
+	clientCertUsage = "The file that contains the PEM-encoded client certificate that is presented to HTTPS endpoints that require mutual TLS. Requires --clientkey. Example: --clientcert ./tls/client.pem"
+
+	clientKeyFlag  = "clientkey"
+	clientKeyUsage = "The file that contains the PEM-encoded private key of the client certificate (--clientcert). Example: --clientkey ./tls/client.key"
+
+	insecureSkipVerifyFlag  = "insecureskipverify"
+	insecureSkipVerifyUsage = "If specified then the certificates of HTTPS endpoints are not verified. This should only be used for development. Example: --insecureskipverify"
+
+	timeoutFlag  = "httptimeout"
+	timeoutUsage = "The timeout of each HTTP request, including reading the response. The default is no timeout. Example: --httptimeout 30s"
+
+	proxyFlag  = "proxy"
+	proxyUsage = "The URL of the proxy through which HTTP requests are sent. By default, the proxy is taken from the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables. Example: --proxy http://proxy.example.com:3128"
+
+	credentialsFlag  = "credentials"
+	credentialsUsage = "The credentials store, which is a JSON file that maps host names (optionally with a port) to the credentials used for requests to the host when no authorization token is given: a static bearer token ({\"token\": \"env:MY_TOKEN\"}), OAuth2 client credentials used to acquire (and refresh) access tokens ({\"oauth2\": {\"tokenUrl\": \"https://auth.example.com/token\", \"clientId\": \"cli\", \"clientSecret\": \"file:./secret\", \"scopes\": [\"dcas\"]}}) or a key used to sign requests using HTTP Signatures ({\"httpSignature\": {\"keyId\": \"cli-key\", \"keyFile\": \"./keys/cli.pem\"}}). The default is credentials.json in the fabric-cli home. Example: --credentials ~/.fabric/credentials.json"
+)
+
+var errClientKeyRequired = errors.New("--clientcert and --clientkey must be specified together")
+
+// Config contains the TLS, timeout, proxy and credentials settings of the HTTP client
+type Config struct {
+	CACerts            string
+	ClientCert         string
+	ClientKey          string
+	InsecureSkipVerify bool
+	Timeout            time.Duration
+	Proxy              string
+	CredentialsFile    string
+}
+
+// NewConfig returns a config whose credentials store is the default credentials store in the fabric-cli home
+func NewConfig(settings *environment.Settings) *Config {
+	return &Config{CredentialsFile: settings.Home.Path(DefaultCredentialsFile)}
+}
+
+// AddFlags adds the flags that populate the config to the given flag set
+func (cfg *Config) AddFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&cfg.CACerts, caCertsFlag, "", caCertsUsage)
+	flags.StringVar(&cfg.ClientCert, clientCertFlag, "", clientCertUsage)
+	flags.StringVar(&cfg.ClientKey, clientKeyFlag, "", clientKeyUsage)
+	flags.BoolVar(&cfg.InsecureSkipVerify, insecureSkipVerifyFlag, false, insecureSkipVerifyUsage)
+	flags.DurationVar(&cfg.Timeout, timeoutFlag, 0, timeoutUsage)
+	flags.StringVar(&cfg.Proxy, proxyFlag, "", proxyUsage)
+	flags.StringVar(&cfg.CredentialsFile, credentialsFlag, cfg.CredentialsFile, credentialsUsage)
+}
+
+// apply applies the config to the given HTTP client. The TLS and proxy settings are only applied if the client's
+// transport is the default transport or an http.Transport.
+func (cfg *Config) apply(client *http.Client) error {
+	client.Timeout = cfg.Timeout
+
+	if client.Transport == nil {
+		client.Transport = http.DefaultTransport.(*http.Transport).Clone()
+	}
+
+	transport, ok := client.Transport.(*http.Transport)
+	if !ok {
+		return nil
+	}
+
+	tlsConfig, err := cfg.tlsConfig()
+	if err != nil {
+		return err
+	}
+
+	transport.TLSClientConfig = tlsConfig
+
+	if cfg.Proxy != "" {
+		proxyURL, err := url.Parse(cfg.Proxy)
+		if err != nil {
+			return errors.WithMessagef(err, "invalid proxy URL [%s]", cfg.Proxy)
+		}
+
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	return nil
+}
+
+func (cfg *Config) tlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: cfg.InsecureSkipVerify, //nolint: gosec
+	}
+
+	if cfg.CACerts != "" {
+		pool, err := loadCACerts(cfg.CACerts)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.ClientCert != "" || cfg.ClientKey != "" {
+		if cfg.ClientCert == "" || cfg.ClientKey == "" {
+			return nil, errClientKeyRequired
+		}
+
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCert, cfg.ClientKey)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "error loading client certificate [%s] and key [%s]", cfg.ClientCert, cfg.ClientKey)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// loadCACerts returns the system's root CAs along with the certificates in the given semi-colon separated list of files
+func loadCACerts(files string) (*x509.CertPool, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+
+	for _, file := range strings.Split(files, ";") {
+		if file = strings.TrimSpace(file); file == "" {
+			continue
+		}
+
+		pemBytes, err := ioutil.ReadFile(filepath.Clean(file))
+		if err != nil {
+			return nil, errors.WithMessagef(err, "error reading CA certificates [%s]", file)
+		}
+
+		if !pool.AppendCertsFromPEM(pemBytes) {
+			return nil, errors.Errorf("no PEM-encoded certificates found in [%s]", file)
+		}
+	}
+
+	return pool, nil
+}
@@ -0,0 +1,170 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package httpclient
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_AddFlags(t *testing.T) {
+	cfg := &Config{}
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	cfg.AddFlags(flags)
+
+	require.NoError(t, flags.Parse([]string{"--cacerts", "ca.pem", "--clientcert", "client.pem", "--clientkey", "client.key",
+		"--insecureskipverify", "--httptimeout", "5s", "--proxy", "http://proxy:3128", "--credentials", "credentials.json"}))
+	require.Equal(t, &Config{CACerts: "ca.pem", ClientCert: "client.pem", ClientKey: "client.key", InsecureSkipVerify: true,
+		Timeout: 5 * time.Second, Proxy: "http://proxy:3128", CredentialsFile: "credentials.json"}, cfg)
+}
+
+func TestConfig_TLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "httpclient")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+	clientCertFile, clientKeyFile, clientCert := newCert(t, dir, "client")
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte("some response"))
+		require.NoError(t, err)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.VerifyClientCertIfGiven, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	caCertFile := filepath.Join(dir, "ca.pem")
+	require.NoError(t, ioutil.WriteFile(caCertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600))
+
+	t.Run("Untrusted server", func(t *testing.T) {
+		_, err := New(WithConfig(&Config{})).Get(server.URL)
+		require.Error(t, err)
+	})
+
+	t.Run("CA certs", func(t *testing.T) {
+		resp, err := New(WithConfig(&Config{CACerts: caCertFile + ";"})).Get(server.URL)
+		require.NoError(t, err)
+		require.Equal(t, "some response", string(resp.Payload))
+	})
+
+	t.Run("Insecure skip verify", func(t *testing.T) {
+		resp, err := New(WithConfig(&Config{InsecureSkipVerify: true})).Get(server.URL)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("Mutual TLS", func(t *testing.T) {
+		server.TLS.ClientAuth = tls.RequireAndVerifyClientCert
+
+		_, err := New(WithConfig(&Config{CACerts: caCertFile})).Get(server.URL)
+		require.Error(t, err)
+
+		resp, err := New(WithConfig(&Config{CACerts: caCertFile, ClientCert: clientCertFile, ClientKey: clientKeyFile})).Get(server.URL)
+		require.NoError(t, err)
+		require.Equal(t, "some response", string(resp.Payload))
+	})
+
+	t.Run("Invalid config", func(t *testing.T) {
+		for cfg, msg := range map[*Config]string{
+			{ClientCert: clientCertFile}:                            errClientKeyRequired.Error(),
+			{ClientKey: clientKeyFile}:                              errClientKeyRequired.Error(),
+			{ClientCert: clientCertFile, ClientKey: clientCertFile}: "error loading client certificate",
+			{CACerts: filepath.Join(dir, "xxx.pem")}:                "error reading CA certificates",
+			{CACerts: clientKeyFile}:                                "no PEM-encoded certificates found",
+			{Proxy: "http://proxy:xxx"}:                             "invalid proxy URL",
+		} {
+			c := New(WithConfig(cfg))
+
+			_, err := c.Get(server.URL)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "invalid HTTP client configuration")
+			require.Contains(t, err.Error(), msg)
+
+			// The error is returned for every request
+			_, err = c.Post(server.URL, nil)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), msg)
+		}
+	})
+}
+
+func TestConfig_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	_, err := New(WithConfig(&Config{Timeout: 10 * time.Millisecond})).Get(server.URL)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Client.Timeout exceeded")
+}
+
+func TestConfig_Proxy(t *testing.T) {
+	var proxied string
+
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+	}))
+	defer proxy.Close()
+
+	resp, err := New(WithConfig(&Config{Proxy: proxy.URL})).Get("http://dcas.example.com/content/xxx")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "http://dcas.example.com/content/xxx", proxied)
+}
+
+// newCert generates a self-signed certificate and writes the PEM-encoded certificate and key to files in the given directory
+func newCert(t *testing.T, dir, name string) (string, string, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(certBytes)
+	require.NoError(t, err)
+
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, name+".pem")
+	require.NoError(t, ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certBytes}), 0600))
+
+	keyFile := filepath.Join(dir, name+".key")
+	require.NoError(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes}), 0600))
+
+	return certFile, keyFile, cert
+}
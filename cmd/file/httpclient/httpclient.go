@@ -9,13 +9,20 @@ package httpclient
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"sync"
+
+	"github.com/pkg/errors"
 )
 
 const (
-	authHeader  = "Authorization"
-	tokenPrefix = "Bearer "
+	authHeader        = "Authorization"
+	contentTypeHeader = "Content-Type"
+	tokenPrefix       = "Bearer "
+
+	jsonContentType = "application/json"
 )
 
 // HTTPResponse contains an HTTP response
@@ -29,6 +36,14 @@ type HTTPResponse struct {
 // Client is an HTTP client
 type Client struct {
 	client *http.Client
+	config *Config
+
+	once        sync.Once
+	initErr     error
+	credentials map[string]*Credentials
+
+	mutex       sync.Mutex
+	authorizers map[string]authorizer
 }
 
 // Opt defines an option for the HTTP client
@@ -41,10 +56,19 @@ func WithTransport(rt http.RoundTripper) Opt {
 	}
 }
 
+// WithConfig sets the TLS, timeout, proxy and credentials settings of the client. The config is applied when the first
+// request is sent since it's usually populated from command-line flags (see Config.AddFlags) after the client is created.
+func WithConfig(cfg *Config) Opt {
+	return func(c *Client) {
+		c.config = cfg
+	}
+}
+
 // New returns a new HTTP client
 func New(opts ...Opt) *Client {
 	c := &Client{
-		client: &http.Client{},
+		client:      &http.Client{},
+		authorizers: make(map[string]authorizer),
 	}
 
 	for _, opt := range opts {
@@ -55,43 +79,84 @@ func New(opts ...Opt) *Client {
 }
 
 type requestOptions struct {
-	authToken string
+	authToken     string
+	contentLength int64
 }
 
 // RequestOpt sets a request option
 type RequestOpt func(opts *requestOptions)
 
-// WithAuthToken sets an authorization token in the header
+// WithAuthToken sets an authorization token in the header. The token may be given as a reference to an environment
+// variable or a file (see ResolveSecret). If no token is set then the credentials of the host in the credentials
+// store (see Config) are used, if any.
 func WithAuthToken(token string) RequestOpt {
 	return func(opts *requestOptions) {
 		opts.authToken = token
 	}
 }
 
+// WithContentLength sets the length of a streamed request body (see PostStream). If not set then the body
+// is sent using chunked transfer encoding.
+func WithContentLength(length int64) RequestOpt {
+	return func(opts *requestOptions) {
+		opts.contentLength = length
+	}
+}
+
 // Post posts an HTTP request
 func (c *Client) Post(url string, req []byte, opts ...RequestOpt) (*HTTPResponse, error) {
-	resp, err := c.put(url, req, opts)
-	if err != nil {
-		return nil, err
-	}
-	return c.handle(resp)
+	return c.send(http.MethodPost, url, jsonContentType, req, nil, opts)
+}
+
+// PostStream posts an HTTP request with the given content type whose body is read from the given reader as
+// the request is sent, so that the body is never held in memory as a whole
+func (c *Client) PostStream(url, contentType string, body io.Reader, opts ...RequestOpt) (*HTTPResponse, error) {
+	return c.send(http.MethodPost, url, contentType, nil, body, opts)
 }
 
 // Get put an HTTP GET request
 func (c *Client) Get(url string, opts ...RequestOpt) (*HTTPResponse, error) {
-	resp, err := c.get(url, opts)
-	if err != nil {
+	return c.send(http.MethodGet, url, "", nil, nil, opts)
+}
+
+// send sends an HTTP request whose body is either the given bytes or, if streamed, is read from the given reader.
+// If the request is unauthorized and the credentials may be refreshed (e.g. an OAuth2 access token that was revoked)
+// then the request is sent once more with the refreshed credentials, unless the body is streamed.
+func (c *Client) send(method, url, contentType string, body []byte, stream io.Reader, opts []RequestOpt) (*HTTPResponse, error) {
+	if err := c.init(); err != nil {
 		return nil, err
 	}
-	return c.handle(resp)
+
+	options := resolveRequestOptions(opts)
+
+	for attempt := 0; ; attempt++ {
+		httpReq, err := newRequest(method, url, contentType, body, stream, options)
+		if err != nil {
+			return nil, err
+		}
+
+		auth, err := c.authorize(httpReq, body, options)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := c.client.Do(httpReq)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode == http.StatusUnauthorized && attempt == 0 && stream == nil && auth != nil && auth.refresh() {
+			closeResponse(resp)
+
+			continue
+		}
+
+		return c.handle(resp)
+	}
 }
 
 func (c *Client) handle(resp *http.Response) (*HTTPResponse, error) {
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			fmt.Printf("Error closing HTTP response: %s", err)
-		}
-	}()
+	defer closeResponse(resp)
 
 	gotBody, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
@@ -118,36 +183,119 @@ func (c *Client) handle(resp *http.Response) (*HTTPResponse, error) {
 	}, nil
 }
 
-func (c *Client) get(url string, opts []RequestOpt) (*http.Response, error) {
-	httpReq, err := http.NewRequest(http.MethodGet, url, nil)
-	if err != nil {
+// init applies the config (if any) to the HTTP client and loads the credentials store
+func (c *Client) init() error {
+	c.once.Do(func() {
+		if c.config == nil {
+			return
+		}
+
+		if err := c.config.apply(c.client); err != nil {
+			c.initErr = errors.WithMessage(err, "invalid HTTP client configuration")
+			return
+		}
+
+		credentials, err := loadCredentials(c.config.CredentialsFile)
+		if err != nil {
+			c.initErr = err
+			return
+		}
+
+		c.credentials = credentials
+	})
+
+	return c.initErr
+}
+
+// authorize sets the authorization of the given request using the token given in the request options or, if none,
+// the credentials of the request's host in the credentials store. The authorizer that was used (if any) is returned.
+func (c *Client) authorize(req *http.Request, body []byte, options *requestOptions) (authorizer, error) {
+	var auth authorizer
+
+	if options.authToken != "" {
+		token, err := ResolveSecret(options.authToken)
+		if err != nil {
+			return nil, errors.WithMessage(err, "invalid authorization token")
+		}
+
+		auth = &bearerAuthorizer{token: token}
+	} else {
+		var err error
+
+		auth, err = c.hostAuthorizer(req.URL.Host, req.URL.Hostname())
+		if err != nil {
+			return nil, err
+		}
+
+		if auth == nil {
+			return nil, nil
+		}
+	}
+
+	if err := auth.authorize(req, body); err != nil {
 		return nil, err
 	}
 
-	options := resolveRequestOptions(opts)
+	return auth, nil
+}
 
-	if options.authToken != "" {
-		httpReq.Header.Set(authHeader, tokenPrefix+options.authToken)
+// hostAuthorizer returns the authorizer for the credentials of the given host (including the port, if any) or,
+// if there are none, of the given host name. The authorizer is cached so that acquired tokens are reused.
+func (c *Client) hostAuthorizer(host, hostName string) (authorizer, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, h := range []string{host, hostName} {
+		if auth, ok := c.authorizers[h]; ok {
+			return auth, nil
+		}
+
+		credentials, ok := c.credentials[h]
+		if !ok {
+			continue
+		}
+
+		auth, err := newAuthorizer(credentials, c.client)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "invalid credentials for host [%s] in [%s]", h, c.config.CredentialsFile)
+		}
+
+		c.authorizers[h] = auth
+
+		return auth, nil
 	}
 
-	return c.client.Do(httpReq)
+	return nil, nil
 }
 
-func (c *Client) put(url string, req []byte, opts []RequestOpt) (*http.Response, error) {
-	httpReq, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(req))
+func newRequest(method, url, contentType string, body []byte, stream io.Reader, options *requestOptions) (*http.Request, error) {
+	var reader io.Reader
+	if stream != nil {
+		reader = stream
+	} else if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	httpReq, err := http.NewRequest(method, url, reader)
 	if err != nil {
 		return nil, err
 	}
 
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	options := resolveRequestOptions(opts)
+	if contentType != "" {
+		httpReq.Header.Set(contentTypeHeader, contentType)
+	}
 
-	if options.authToken != "" {
-		httpReq.Header.Set(authHeader, tokenPrefix+options.authToken)
+	if stream != nil && options.contentLength > 0 {
+		httpReq.ContentLength = options.contentLength
 	}
 
-	return c.client.Do(httpReq)
+	return httpReq, nil
+}
+
+func closeResponse(resp *http.Response) {
+	if err := resp.Body.Close(); err != nil {
+		fmt.Printf("Error closing HTTP response: %s", err)
+	}
 }
 
 func resolveRequestOptions(opts []RequestOpt) *requestOptions {
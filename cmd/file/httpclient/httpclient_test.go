@@ -7,7 +7,9 @@ SPDX-License-Identifier: Apache-2.0
 package httpclient
 
 import (
+	"bytes"
 	"errors"
+	"io/ioutil"
 	"net/http"
 	"testing"
 
@@ -57,6 +59,38 @@ func TestClient(t *testing.T) {
 		require.Equal(t, respData, resp.Payload)
 	})
 
+	t.Run("PostStream -> success", func(t *testing.T) {
+		transport := &recordingTransport{
+			resp: &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     header,
+				Body:       mocks.NewResponseBody(respData),
+			},
+		}
+
+		c := New(WithTransport(transport))
+		require.NotNil(t, c)
+
+		resp, err := c.PostStream("http://localhost:80", "image/png", bytes.NewBufferString("some content"), WithContentLength(12), WithAuthToken("mytoken"))
+		require.NoError(t, err)
+		require.Equal(t, respData, resp.Payload)
+		require.Equal(t, "image/png", transport.req.Header.Get(contentTypeHeader))
+		require.Equal(t, "Bearer mytoken", transport.req.Header.Get(authHeader))
+		require.Equal(t, int64(12), transport.req.ContentLength)
+		require.Equal(t, "some content", string(transport.body))
+	})
+
+	t.Run("PostStream error", func(t *testing.T) {
+		errExpected := errors.New("injected error")
+
+		c := New(WithTransport(mocks.NewTransport().WithPostError(errExpected)))
+		require.NotNil(t, c)
+
+		_, err := c.PostStream("http://localhost:80", "image/png", bytes.NewBufferString("some content"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+
 	t.Run("Get error code", func(t *testing.T) {
 		const errMessage = "some error"
 		transport := mocks.NewTransport().
@@ -97,3 +131,22 @@ func TestClient(t *testing.T) {
 		require.Contains(t, err.Error(), errExpected.Error())
 	})
 }
+
+// recordingTransport records the request (and its body) and returns the given response
+type recordingTransport struct {
+	resp *http.Response
+	req  *http.Request
+	body []byte
+}
+
+func (m *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	m.req = req
+	m.body = body
+
+	return m.resp, nil
+}
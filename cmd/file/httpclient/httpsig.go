@@ -0,0 +1,177 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package httpclient
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/keyutil"
+)
+
+const (
+	signatureAlgorithm = "hs2019"
+	requestTarget      = "(request-target)"
+	dateHeader         = "Date"
+	digestHeader       = "Digest"
+)
+
+// HTTPSignatureCredentials contains the key used to sign requests using HTTP Signatures
+// (draft-cavage-http-signatures-12) with the hs2019 algorithm. ECDSA, Ed25519 and RSA keys are supported.
+type HTTPSignatureCredentials struct {
+	KeyID string `json:"keyId"`
+	// KeyFile is the file that contains the PEM-encoded private key
+	KeyFile string `json:"keyFile"`
+	// Headers are the (lower-case) names of the headers to sign. The default is (request-target), host, date
+	// and, if the request has a body that isn't streamed, digest.
+	Headers []string `json:"headers,omitempty"`
+}
+
+// httpSignatureAuthorizer signs requests and sets the signature in the Authorization header
+type httpSignatureAuthorizer struct {
+	keyID   string
+	signer  crypto.Signer
+	hash    crypto.Hash
+	headers []string
+}
+
+func newHTTPSignatureAuthorizer(credentials *HTTPSignatureCredentials) (*httpSignatureAuthorizer, error) {
+	if credentials.KeyID == "" || credentials.KeyFile == "" {
+		return nil, errors.New("the HTTP signature key ID and key file are required")
+	}
+
+	privateKey, err := keyutil.PrivateKeyFromFile(credentials.KeyFile)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "error loading HTTP signature key [%s]", credentials.KeyFile)
+	}
+
+	a := &httpSignatureAuthorizer{keyID: credentials.KeyID}
+
+	for _, h := range credentials.Headers {
+		a.headers = append(a.headers, strings.ToLower(h))
+	}
+
+	switch key := privateKey.(type) {
+	case *ecdsa.PrivateKey:
+		a.signer = key
+
+		switch bits := key.Curve.Params().BitSize; {
+		case bits <= 256:
+			a.hash = crypto.SHA256
+		case bits <= 384:
+			a.hash = crypto.SHA384
+		default:
+			a.hash = crypto.SHA512
+		}
+	case ed25519.PrivateKey:
+		a.signer = key
+	case *rsa.PrivateKey:
+		a.signer = key
+		a.hash = crypto.SHA512
+	default:
+		return nil, errors.Errorf("unsupported HTTP signature key type %T", privateKey)
+	}
+
+	return a, nil
+}
+
+func (a *httpSignatureAuthorizer) authorize(req *http.Request, body []byte) error {
+	if req.Header.Get(dateHeader) == "" {
+		req.Header.Set(dateHeader, time.Now().UTC().Format(http.TimeFormat))
+	}
+
+	if body != nil {
+		digest := sha256.Sum256(body)
+		req.Header.Set(digestHeader, "SHA-256="+base64.StdEncoding.EncodeToString(digest[:]))
+	}
+
+	headers := a.headers
+	if len(headers) == 0 {
+		headers = []string{requestTarget, "host", "date"}
+
+		if body != nil {
+			headers = append(headers, "digest")
+		}
+	}
+
+	signingString, err := signingString(req, headers)
+	if err != nil {
+		return err
+	}
+
+	signature, err := a.sign([]byte(signingString))
+	if err != nil {
+		return errors.WithMessage(err, "error signing HTTP request")
+	}
+
+	req.Header.Set(authHeader, fmt.Sprintf(`Signature keyId="%s",algorithm="%s",headers="%s",signature="%s"`,
+		a.keyID, signatureAlgorithm, strings.Join(headers, " "), base64.StdEncoding.EncodeToString(signature)))
+
+	return nil
+}
+
+func (a *httpSignatureAuthorizer) refresh() bool {
+	return false
+}
+
+func (a *httpSignatureAuthorizer) sign(data []byte) ([]byte, error) {
+	if a.hash == 0 {
+		// Ed25519 signs the message itself
+		return a.signer.Sign(rand.Reader, data, crypto.Hash(0))
+	}
+
+	h := a.hash.New()
+	if _, err := h.Write(data); err != nil {
+		return nil, err
+	}
+
+	var opts crypto.SignerOpts = a.hash
+	if _, ok := a.signer.(*rsa.PrivateKey); ok {
+		opts = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: a.hash}
+	}
+
+	return a.signer.Sign(rand.Reader, h.Sum(nil), opts)
+}
+
+// signingString returns the string to sign for the given request and headers
+func signingString(req *http.Request, headers []string) (string, error) {
+	lines := make([]string, 0, len(headers))
+
+	for _, name := range headers {
+		var value string
+
+		switch name {
+		case requestTarget:
+			value = strings.ToLower(req.Method) + " " + req.URL.RequestURI()
+		case "host":
+			value = req.Host
+			if value == "" {
+				value = req.URL.Host
+			}
+		default:
+			value = req.Header.Get(name)
+			if value == "" {
+				return "", errors.Errorf("the header [%s] can't be signed since it isn't set (note that the digest header isn't set for streamed requests)", name)
+			}
+		}
+
+		lines = append(lines, name+": "+value)
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
@@ -0,0 +1,155 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package httpclient
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var signatureRegex = regexp.MustCompile(`^Signature keyId="(.*)",algorithm="(.*)",headers="(.*)",signature="(.*)"$`)
+
+func TestClient_HTTPSignature(t *testing.T) {
+	dir, err := ioutil.TempDir("", "httpclient")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+	var req *http.Request
+	var body []byte
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		req, body = r, b
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	writeCredentials := func(key crypto.Signer, headers string) string {
+		keyBytes, err := x509.MarshalPKCS8PrivateKey(key)
+		require.NoError(t, err)
+
+		keyFile := filepath.Join(dir, "key.pem")
+		require.NoError(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyBytes}), 0600))
+
+		path := filepath.Join(dir, "credentials.json")
+		require.NoError(t, ioutil.WriteFile(path, []byte(`{"`+host+`": {"httpSignature": {"keyId": "key1", "keyFile": "`+
+			keyFile+`"`+headers+`}}}`), 0600))
+
+		return path
+	}
+
+	verify := func(t *testing.T, key crypto.Signer, expectedHeaders string) {
+		matches := signatureRegex.FindStringSubmatch(req.Header.Get(authHeader))
+		require.Len(t, matches, 5)
+		require.Equal(t, "key1", matches[1])
+		require.Equal(t, "hs2019", matches[2])
+		require.Equal(t, expectedHeaders, matches[3])
+
+		signature, err := base64.StdEncoding.DecodeString(matches[4])
+		require.NoError(t, err)
+
+		signed, err := signingString(req, strings.Split(matches[3], " "))
+		require.NoError(t, err)
+
+		switch pubKey := key.Public().(type) {
+		case *ecdsa.PublicKey:
+			digest := crypto.SHA384.New()
+			_, err := digest.Write([]byte(signed))
+			require.NoError(t, err)
+			require.True(t, ecdsa.VerifyASN1(pubKey, digest.Sum(nil), signature))
+		case ed25519.PublicKey:
+			require.True(t, ed25519.Verify(pubKey, []byte(signed), signature))
+		case *rsa.PublicKey:
+			digest := crypto.SHA512.New()
+			_, err := digest.Write([]byte(signed))
+			require.NoError(t, err)
+			require.NoError(t, rsa.VerifyPSS(pubKey, crypto.SHA512, digest.Sum(nil), signature, nil))
+		}
+	}
+
+	for name, key := range map[string]crypto.Signer{"ECDSA": ecKey, "Ed25519": edKey, "RSA": rsaKey} {
+		key := key
+
+		t.Run(name, func(t *testing.T) {
+			c := New(WithConfig(&Config{CredentialsFile: writeCredentials(key, "")}))
+
+			_, err := c.Post(server.URL+"/dcas?x=1", []byte(`{"field":"value"}`))
+			require.NoError(t, err)
+			require.Equal(t, `{"field":"value"}`, string(body))
+
+			digest := sha256.Sum256(body)
+			require.Equal(t, "SHA-256="+base64.StdEncoding.EncodeToString(digest[:]), req.Header.Get(digestHeader))
+			require.NotEmpty(t, req.Header.Get(dateHeader))
+
+			verify(t, key, "(request-target) host date digest")
+
+			_, err = c.Get(server.URL + "/dcas/xxx")
+			require.NoError(t, err)
+			require.Empty(t, req.Header.Get(digestHeader))
+
+			verify(t, key, "(request-target) host date")
+		})
+	}
+
+	t.Run("Streamed request", func(t *testing.T) {
+		c := New(WithConfig(&Config{CredentialsFile: writeCredentials(ecKey, "")}))
+
+		_, err := c.PostStream(server.URL, "text/plain", strings.NewReader("content"))
+		require.NoError(t, err)
+		require.Equal(t, "content", string(body))
+		require.Empty(t, req.Header.Get(digestHeader))
+
+		verify(t, ecKey, "(request-target) host date")
+	})
+
+	t.Run("Headers", func(t *testing.T) {
+		c := New(WithConfig(&Config{CredentialsFile: writeCredentials(edKey, `, "headers": ["(request-target)", "Content-Type"]`)}))
+
+		_, err := c.Post(server.URL, []byte("{}"))
+		require.NoError(t, err)
+
+		verify(t, edKey, "(request-target) content-type")
+	})
+
+	t.Run("Missing header", func(t *testing.T) {
+		c := New(WithConfig(&Config{CredentialsFile: writeCredentials(edKey, `, "headers": ["(request-target)", "digest"]`)}))
+
+		_, err := c.PostStream(server.URL, "text/plain", strings.NewReader("content"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "the header [digest] can't be signed since it isn't set")
+	})
+}
@@ -0,0 +1,164 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package httpclient
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// expiryDelta is the time before the expiry of an OAuth2 access token at which the token is refreshed
+const expiryDelta = 10 * time.Second
+
+// OAuth2Credentials contains the client credentials used to acquire access tokens from an OAuth2 token endpoint
+// using the client credentials grant (RFC 6749, section 4.4)
+type OAuth2Credentials struct {
+	TokenURL string `json:"tokenUrl"`
+	ClientID string `json:"clientId"`
+	// ClientSecret is the client secret (see ResolveSecret)
+	ClientSecret string   `json:"clientSecret"`
+	Scopes       []string `json:"scopes,omitempty"`
+}
+
+// tokenResponse is the response of the OAuth2 token endpoint
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// oauth2Authorizer sets an OAuth2 access token as the bearer token. The token is acquired when first needed
+// and is cached until shortly before it expires.
+type oauth2Authorizer struct {
+	credentials  *OAuth2Credentials
+	clientSecret string
+	client       *http.Client
+
+	mutex  sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+func newOAuth2Authorizer(credentials *OAuth2Credentials, client *http.Client) (*oauth2Authorizer, error) {
+	if credentials.TokenURL == "" || credentials.ClientID == "" {
+		return nil, errors.New("the OAuth2 token URL and client ID are required")
+	}
+
+	clientSecret, err := ResolveSecret(credentials.ClientSecret)
+	if err != nil {
+		return nil, errors.WithMessage(err, "invalid OAuth2 client secret")
+	}
+
+	return &oauth2Authorizer{
+		credentials:  credentials,
+		clientSecret: clientSecret,
+		client:       client,
+	}, nil
+}
+
+func (a *oauth2Authorizer) authorize(req *http.Request, _ []byte) error {
+	token, err := a.accessToken()
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set(authHeader, tokenPrefix+token)
+
+	return nil
+}
+
+func (a *oauth2Authorizer) refresh() bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.token = ""
+
+	return true
+}
+
+// accessToken returns the cached access token or, if there is none or it's about to expire, acquires a new one
+func (a *oauth2Authorizer) accessToken() (string, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.token != "" && (a.expiry.IsZero() || time.Now().Add(expiryDelta).Before(a.expiry)) {
+		return a.token, nil
+	}
+
+	resp, err := a.requestToken()
+	if err != nil {
+		return "", errors.WithMessagef(err, "error acquiring OAuth2 access token from [%s]", a.credentials.TokenURL)
+	}
+
+	a.token = resp.AccessToken
+	a.expiry = time.Time{}
+
+	if resp.ExpiresIn > 0 {
+		a.expiry = time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second)
+	}
+
+	return a.token, nil
+}
+
+func (a *oauth2Authorizer) requestToken() (*tokenResponse, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(a.credentials.Scopes) > 0 {
+		form.Set("scope", strings.Join(a.credentials.Scopes, " "))
+	}
+
+	req, err := http.NewRequest(http.MethodPost, a.credentials.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set(contentTypeHeader, "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(a.credentials.ClientID), url.QueryEscape(a.clientSecret))
+
+	httpResp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer closeResponse(httpResp)
+
+	respBytes, err := ioutil.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &tokenResponse{}
+	if err := json.Unmarshal(respBytes, resp); err != nil && httpResp.StatusCode == http.StatusOK {
+		return nil, errors.WithMessage(err, "invalid token response")
+	}
+
+	if httpResp.StatusCode != http.StatusOK {
+		if resp.Error != "" {
+			return nil, errors.Errorf("status code %d: %s %s", httpResp.StatusCode, resp.Error, resp.ErrorDescription)
+		}
+
+		return nil, errors.Errorf("status code %d: %s", httpResp.StatusCode, respBytes)
+	}
+
+	if resp.AccessToken == "" {
+		return nil, errors.New("no access token in token response")
+	}
+
+	if resp.TokenType != "" && !strings.EqualFold(resp.TokenType, "bearer") {
+		return nil, errors.Errorf("unsupported token type [%s]", resp.TokenType)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,175 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package httpclient
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_OAuth2(t *testing.T) {
+	dir, err := ioutil.TempDir("", "httpclient")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+	var (
+		tokenRequests int
+		expiresIn     = 3600
+		tokenResponse string
+		tokenStatus   = http.StatusOK
+	)
+
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientID, secret, ok := r.BasicAuth()
+		require.True(t, ok)
+		require.Equal(t, "cli", clientID)
+		require.Equal(t, "secret", secret)
+		require.NoError(t, r.ParseForm())
+		require.Equal(t, "client_credentials", r.Form.Get("grant_type"))
+		require.Equal(t, "dcas sidetree", r.Form.Get("scope"))
+
+		tokenRequests++
+
+		w.WriteHeader(tokenStatus)
+
+		if tokenResponse != "" {
+			_, err := w.Write([]byte(tokenResponse))
+			require.NoError(t, err)
+
+			return
+		}
+
+		_, err := fmt.Fprintf(w, `{"access_token": "token%d", "token_type": "Bearer", "expires_in": %d}`, tokenRequests, expiresIn)
+		require.NoError(t, err)
+	}))
+	defer tokenServer.Close()
+
+	var (
+		authorizations []string
+		revoked        string
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization := r.Header.Get(authHeader)
+		authorizations = append(authorizations, authorization)
+
+		if authorization == revoked {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	secretFile := filepath.Join(dir, "secret")
+	require.NoError(t, ioutil.WriteFile(secretFile, []byte("secret"), 0600))
+
+	credentialsFile := filepath.Join(dir, "credentials.json")
+	require.NoError(t, ioutil.WriteFile(credentialsFile, []byte(`{"`+host+`": {"oauth2": {"tokenUrl": "`+
+		tokenServer.URL+`", "clientId": "cli", "clientSecret": "file:`+secretFile+`", "scopes": ["dcas", "sidetree"]}}}`), 0600))
+
+	reset := func() {
+		tokenRequests = 0
+		expiresIn = 3600
+		tokenResponse = ""
+		tokenStatus = http.StatusOK
+		authorizations = nil
+		revoked = ""
+	}
+
+	t.Run("Cached token", func(t *testing.T) {
+		reset()
+
+		c := New(WithConfig(&Config{CredentialsFile: credentialsFile}))
+
+		_, err := c.Get(server.URL)
+		require.NoError(t, err)
+
+		_, err = c.Post(server.URL, []byte("{}"))
+		require.NoError(t, err)
+
+		require.Equal(t, 1, tokenRequests)
+		require.Equal(t, []string{"Bearer token1", "Bearer token1"}, authorizations)
+	})
+
+	t.Run("Expired token", func(t *testing.T) {
+		reset()
+		expiresIn = 5
+
+		c := New(WithConfig(&Config{CredentialsFile: credentialsFile}))
+
+		_, err := c.Get(server.URL)
+		require.NoError(t, err)
+
+		_, err = c.Get(server.URL)
+		require.NoError(t, err)
+
+		require.Equal(t, 2, tokenRequests)
+		require.Equal(t, []string{"Bearer token1", "Bearer token2"}, authorizations)
+	})
+
+	t.Run("Revoked token", func(t *testing.T) {
+		reset()
+		revoked = "Bearer token1"
+
+		c := New(WithConfig(&Config{CredentialsFile: credentialsFile}))
+
+		resp, err := c.Post(server.URL, []byte("{}"))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		require.Equal(t, 2, tokenRequests)
+		require.Equal(t, []string{"Bearer token1", "Bearer token2"}, authorizations)
+	})
+
+	t.Run("Revoked token - streamed request", func(t *testing.T) {
+		reset()
+		revoked = "Bearer token1"
+
+		c := New(WithConfig(&Config{CredentialsFile: credentialsFile}))
+
+		resp, err := c.PostStream(server.URL, "text/plain", strings.NewReader("content"))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		require.Equal(t, 1, tokenRequests)
+	})
+
+	t.Run("Token endpoint error", func(t *testing.T) {
+		reset()
+		tokenStatus = http.StatusBadRequest
+		tokenResponse = `{"error": "invalid_client", "error_description": "unknown client"}`
+
+		_, err := New(WithConfig(&Config{CredentialsFile: credentialsFile})).Get(server.URL)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "error acquiring OAuth2 access token from ["+tokenServer.URL+"]")
+		require.Contains(t, err.Error(), "status code 400: invalid_client unknown client")
+		require.Empty(t, authorizations)
+	})
+
+	t.Run("Invalid token response", func(t *testing.T) {
+		for response, msg := range map[string]string{
+			`{`:                        "invalid token response",
+			`{"token_type": "Bearer"}`: "no access token in token response",
+			`{"access_token": "token", "token_type": "mac"}`: "unsupported token type [mac]",
+		} {
+			reset()
+			tokenResponse = response
+
+			_, err := New(WithConfig(&Config{CredentialsFile: credentialsFile})).Get(server.URL)
+			require.Error(t, err, response)
+			require.Contains(t, err.Error(), msg, response)
+		}
+	})
+}
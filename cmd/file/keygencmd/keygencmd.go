@@ -0,0 +1,143 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package keygencmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/basecmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/keyutil"
+)
+
+const (
+	use      = "keygen"
+	desc     = "Generate a key pair for Sidetree file index operations"
+	longDesc = `
+The keygen command generates a key pair of the given type (P-256, secp256k1 or Ed25519) and writes the PEM-encoded private and public keys to the given directory. The files are
+named <name>_private.key and <name>_public.key (or private.key and public.key if --name is not specified). The public key may be used for
+--recoverykeyfile, --updatekeyfile, --nextupdatekeyfile and --nextrecoverykeyfile and the private key for --signingkeyfile. Existing files
+are not overwritten.
+`
+	examples = `
+- Generate the recovery and update key pairs for a new file index document:
+    $ ./fabric file keygen --dir ./keys --name recover
+    $ ./fabric file keygen --dir ./keys --name update
+
+- Generate an Ed25519 key pair:
+    $ ./fabric file keygen --dir ./keys --name update --keytype Ed25519
+`
+)
+
+const (
+	dirFlag  = "dir"
+	dirUsage = "The directory to which the key files are written. The directory is created if it doesn't exist. Example: --dir ./keys"
+
+	nameFlag  = "name"
+	nameUsage = "The prefix of the key file names. Example: --name update"
+
+	keyTypeFlag  = "keytype"
+	keyTypeUsage = "The type of key to generate. Supported types are P-256, secp256k1 and Ed25519. Example: --keytype Ed25519"
+
+	privateKeyFileName = "private.key"
+	publicKeyFileName  = "public.key"
+
+	dirMode  = 0700
+	fileMode = 0600
+)
+
+var errDirRequired = errors.New("directory (--dir) is required")
+
+// New returns the file keygen sub-command
+func New(settings *environment.Settings) *cobra.Command {
+	c := &command{
+		Command: basecmd.New(settings, nil),
+	}
+
+	cmd := &cobra.Command{
+		Use:     use,
+		Short:   desc,
+		Long:    longDesc,
+		Example: examples,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return c.validate()
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return c.run()
+		},
+	}
+
+	cmd.SetOutput(c.Settings.Streams.Out)
+	cmd.SilenceUsage = true
+
+	cmd.Flags().StringVar(&c.dir, dirFlag, "", dirUsage)
+	cmd.Flags().StringVar(&c.name, nameFlag, "", nameUsage)
+	cmd.Flags().StringVar(&c.keyType, keyTypeFlag, keyutil.KeyTypeP256, keyTypeUsage)
+
+	return cmd
+}
+
+// command implements the keygen command
+type command struct {
+	*basecmd.Command
+
+	dir     string
+	name    string
+	keyType string
+}
+
+func (c *command) validate() error {
+	if c.dir == "" {
+		return errDirRequired
+	}
+
+	return nil
+}
+
+func (c *command) run() error {
+	privateKeyFile := c.keyFile(privateKeyFileName)
+	publicKeyFile := c.keyFile(publicKeyFileName)
+
+	for _, file := range []string{privateKeyFile, publicKeyFile} {
+		if _, err := os.Stat(file); err == nil {
+			return errors.Errorf("key file [%s] already exists", file)
+		}
+	}
+
+	privateKeyPEM, publicKeyPEM, err := keyutil.GenerateKeyPair(c.keyType)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(c.dir, dirMode); err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(privateKeyFile, privateKeyPEM, fileMode); err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(publicKeyFile, publicKeyPEM, fileMode); err != nil {
+		return err
+	}
+
+	return c.Fprintln(fmt.Sprintf("Generated private key [%s] and public key [%s]", privateKeyFile, publicKeyFile))
+}
+
+func (c *command) keyFile(name string) string {
+	if c.name != "" {
+		name = c.name + "_" + name
+	}
+
+	return filepath.Join(c.dir, name)
+}
@@ -0,0 +1,92 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package keygencmd
+
+import (
+	"crypto/ed25519"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/keyutil"
+	"github.com/trustbloc/fabric-cli-ext/cmd/mocks"
+)
+
+func TestKeyGenCmd(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keygen")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+	keyDir := filepath.Join(dir, "keys")
+
+	t.Run("No --dir", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, &mocks.Writer{}).Execute(), errDirRequired.Error())
+	})
+
+	t.Run("With --name", func(t *testing.T) {
+		w := &mocks.Writer{}
+		require.NoError(t, newMockCmd(t, w, "--dir", keyDir, "--name", "update").Execute())
+		require.Contains(t, w.Written(), "Generated private key")
+
+		privateKey, err := keyutil.PrivateKeyFromFile(filepath.Join(keyDir, "update_private.key"))
+		require.NoError(t, err)
+
+		publicKey, err := keyutil.PublicKeyFromFile(filepath.Join(keyDir, "update_public.key"))
+		require.NoError(t, err)
+		require.NotNil(t, privateKey)
+		require.NotNil(t, publicKey)
+
+		fi, err := os.Stat(filepath.Join(keyDir, "update_private.key"))
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(fileMode), fi.Mode().Perm())
+	})
+
+	t.Run("Without --name", func(t *testing.T) {
+		require.NoError(t, newMockCmd(t, &mocks.Writer{}, "--dir", keyDir).Execute())
+
+		_, err := keyutil.PrivateKeyFromFile(filepath.Join(keyDir, "private.key"))
+		require.NoError(t, err)
+	})
+
+	t.Run("With --keytype", func(t *testing.T) {
+		require.NoError(t, newMockCmd(t, &mocks.Writer{}, "--dir", keyDir, "--name", "ed", "--keytype", keyutil.KeyTypeEd25519).Execute())
+
+		privateKey, err := keyutil.PrivateKeyFromFile(filepath.Join(keyDir, "ed_private.key"))
+		require.NoError(t, err)
+		require.IsType(t, ed25519.PrivateKey{}, privateKey)
+
+		err = newMockCmd(t, &mocks.Writer{}, "--dir", keyDir, "--name", "rsa", "--keytype", "RSA").Execute()
+		require.EqualError(t, err, "unsupported key type [RSA]")
+	})
+
+	t.Run("Already exists", func(t *testing.T) {
+		err := newMockCmd(t, &mocks.Writer{}, "--dir", keyDir, "--name", "update").Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "already exists")
+	})
+}
+
+func newMockCmd(t *testing.T, w io.Writer, args ...string) *cobra.Command {
+	settings := environment.NewDefaultSettings()
+	settings.Streams.Out = w
+
+	settings.Config.CurrentContext = "testctx"
+	settings.Config.Contexts[settings.Config.CurrentContext] = &environment.Context{}
+
+	c := New(settings)
+	require.NotNil(t, c)
+
+	c.SetArgs(args)
+
+	return c
+}
@@ -0,0 +1,208 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package keystore
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/keyutil"
+)
+
+const (
+	fileExt   = ".json"
+	backupExt = ".prev"
+
+	dirMode  = 0700
+	fileMode = 0600
+)
+
+// ErrNotFound indicates that the key store does not contain keys for the given file index document
+var ErrNotFound = errors.New("keys not found in key store")
+
+// KeyPair is a PEM-encoded key pair
+type KeyPair struct {
+	PrivateKey string `json:"privateKey"`
+	PublicKey  string `json:"publicKey"`
+}
+
+// Entry contains the update keys of a file index document. UpdateKey is the key pair whose commitment is
+// currently set on the document and which is used to sign the next update. NextUpdateKey is the key pair
+// whose commitment is set by the next update. KeyType is the type of the generated keys.
+type Entry struct {
+	KeyType       string   `json:"keyType,omitempty"`
+	UpdateKey     *KeyPair `json:"updateKey"`
+	NextUpdateKey *KeyPair `json:"nextUpdateKey"`
+}
+
+// NewEntry returns an entry with newly generated update and next update key pairs of the given
+// type (see keyutil.GenerateKeyPair). P-256 keys are generated if the key type is empty.
+func NewEntry(keyType string) (*Entry, error) {
+	if keyType == "" {
+		keyType = keyutil.KeyTypeP256
+	}
+
+	updateKey, err := newKeyPair(keyType)
+	if err != nil {
+		return nil, err
+	}
+
+	nextUpdateKey, err := newKeyPair(keyType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Entry{KeyType: keyType, UpdateKey: updateKey, NextUpdateKey: nextUpdateKey}, nil
+}
+
+// Store is a local key store that maintains the update keys of file index documents. Each entry is
+// saved in its own file (readable only by the owner) whose name is the unique suffix of the document.
+// Entries are replaced atomically and the previous entry is kept in a backup file with the extension
+// .json.prev so that it may be restored (by renaming the file) if an update that was accepted by the
+// Sidetree node is never anchored.
+type Store struct {
+	dir string
+}
+
+// New returns a key store in the given directory
+func New(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Get returns the entry for the given file index document ID (or URL)
+func (s *Store) Get(id string) (*Entry, error) {
+	entryBytes, err := ioutil.ReadFile(s.path(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.WithMessagef(ErrNotFound, "file index document [%s], key store [%s]", id, s.dir)
+		}
+
+		return nil, err
+	}
+
+	entry := &Entry{}
+	if err := json.Unmarshal(entryBytes, entry); err != nil {
+		return nil, errors.WithMessagef(err, "invalid key store entry for file index document [%s]", id)
+	}
+
+	if entry.UpdateKey == nil || entry.NextUpdateKey == nil {
+		return nil, errors.Errorf("incomplete key store entry for file index document [%s]", id)
+	}
+
+	return entry, nil
+}
+
+// Put saves the entry for the given file index document ID (or URL). If an entry already exists then
+// it is kept in the backup file.
+func (s *Store) Put(id string, entry *Entry) error {
+	entryBytes, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(s.dir, dirMode); err != nil {
+		return err
+	}
+
+	path := s.path(id)
+
+	prevBytes, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err == nil {
+		if err := writeFile(path+backupExt, prevBytes); err != nil {
+			return errors.WithMessagef(err, "error backing up key store entry for file index document [%s]", id)
+		}
+	}
+
+	return writeFile(path, entryBytes)
+}
+
+// Rotate is invoked after a successful update of the given file index document. The next update key
+// becomes the update key and a new next update key is generated.
+func (s *Store) Rotate(id string) error {
+	entry, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+
+	keyType := entry.KeyType
+	if keyType == "" {
+		keyType = keyutil.KeyTypeP256
+	}
+
+	nextUpdateKey, err := newKeyPair(keyType)
+	if err != nil {
+		return err
+	}
+
+	return s.Put(id, &Entry{KeyType: keyType, UpdateKey: entry.NextUpdateKey, NextUpdateKey: nextUpdateKey})
+}
+
+// writeFile writes the given data to a temporary file in the same directory which is then renamed to the
+// given path so that an existing file is never left partially written
+func writeFile(path string, data []byte) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+
+	err = writeAndSync(f, data)
+
+	if e := f.Close(); e != nil && err == nil {
+		err = e
+	}
+
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+
+	if err != nil {
+		_ = os.Remove(f.Name()) //nolint: errcheck
+
+		return err
+	}
+
+	return nil
+}
+
+func writeAndSync(f *os.File, data []byte) error {
+	if err := f.Chmod(fileMode); err != nil {
+		return err
+	}
+
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+
+	return f.Sync()
+}
+
+func (s *Store) path(id string) string {
+	return filepath.Join(s.dir, uniqueSuffix(id)+fileExt)
+}
+
+// uniqueSuffix returns the unique suffix of the given ID or URL, i.e. the part after the last ':'
+func uniqueSuffix(id string) string {
+	return id[strings.LastIndex(id, ":")+1:]
+}
+
+func newKeyPair(keyType string) (*KeyPair, error) {
+	privateKeyPEM, publicKeyPEM, err := keyutil.GenerateKeyPair(keyType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeyPair{PrivateKey: string(privateKeyPEM), PublicKey: string(publicKeyPEM)}, nil
+}
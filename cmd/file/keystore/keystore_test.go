@@ -0,0 +1,152 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package keystore
+
+import (
+	"crypto/ed25519"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/keyutil"
+)
+
+const (
+	id     = "file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA"
+	idxURL = "http://localhost:48326/file/identifiers/file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA"
+)
+
+func TestStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+	s := New(filepath.Join(dir, "keys"))
+
+	_, err = s.Get(id)
+	require.True(t, errors.Is(err, ErrNotFound))
+
+	entry, err := NewEntry("")
+	require.NoError(t, err)
+	require.Equal(t, keyutil.KeyTypeP256, entry.KeyType)
+	require.NotEqual(t, entry.UpdateKey.PublicKey, entry.NextUpdateKey.PublicKey)
+
+	_, err = keyutil.PrivateKeyFromPEM([]byte(entry.UpdateKey.PrivateKey))
+	require.NoError(t, err)
+
+	require.NoError(t, s.Put(id, entry))
+
+	fi, err := os.Stat(filepath.Join(dir, "keys", "EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA.json"))
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(fileMode), fi.Mode().Perm())
+
+	// The entry may be retrieved using either the ID or the URL of the document
+	e, err := s.Get(idxURL)
+	require.NoError(t, err)
+	require.Equal(t, entry, e)
+
+	require.NoError(t, s.Rotate(idxURL))
+
+	e, err = s.Get(id)
+	require.NoError(t, err)
+	require.Equal(t, entry.NextUpdateKey, e.UpdateKey)
+	require.NotEqual(t, entry.NextUpdateKey, e.NextUpdateKey)
+
+	// The previous entry is kept in the backup file and no temporary files are left behind
+	entryPath := filepath.Join(dir, "keys", "EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA.json")
+
+	fi, err = os.Stat(entryPath + backupExt)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(fileMode), fi.Mode().Perm())
+
+	files, err := ioutil.ReadDir(filepath.Join(dir, "keys"))
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	require.NoError(t, os.Rename(entryPath+backupExt, entryPath))
+
+	e, err = s.Get(id)
+	require.NoError(t, err)
+	require.Equal(t, entry, e)
+
+	require.True(t, errors.Is(s.Rotate("file:idx:xxx"), ErrNotFound))
+}
+
+func TestStore_WriteError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+	s := New(dir)
+
+	entry, err := NewEntry("")
+	require.NoError(t, err)
+	require.NoError(t, s.Put(id, entry))
+
+	// The existing entry is left intact if the backup can't be written (a directory is in the way)
+	backupPath := filepath.Join(dir, "EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA.json"+backupExt)
+	require.NoError(t, os.MkdirAll(filepath.Join(backupPath, "x"), dirMode))
+
+	err = s.Rotate(id)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "error backing up key store entry")
+
+	e, err := s.Get(id)
+	require.NoError(t, err)
+	require.Equal(t, entry, e)
+
+	// No temporary files are left behind
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+}
+
+func TestStore_KeyType(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+	s := New(dir)
+
+	entry, err := NewEntry(keyutil.KeyTypeEd25519)
+	require.NoError(t, err)
+	require.NoError(t, s.Put(id, entry))
+	require.NoError(t, s.Rotate(id))
+
+	e, err := s.Get(id)
+	require.NoError(t, err)
+	require.Equal(t, keyutil.KeyTypeEd25519, e.KeyType)
+
+	privateKey, err := keyutil.PrivateKeyFromPEM([]byte(e.NextUpdateKey.PrivateKey))
+	require.NoError(t, err)
+	require.IsType(t, ed25519.PrivateKey{}, privateKey)
+
+	_, err = NewEntry("RSA")
+	require.Error(t, err)
+}
+
+func TestStore_InvalidEntry(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+	s := New(dir)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "invalid.json"), []byte("{"), fileMode))
+	_, err = s.Get("file:idx:invalid")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid key store entry")
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "incomplete.json"), []byte("{}"), fileMode))
+	_, err = s.Get("file:idx:incomplete")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "incomplete key store entry")
+}
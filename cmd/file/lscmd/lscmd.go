@@ -0,0 +1,147 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package lscmd
+
+import (
+	"fmt"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/basecmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/fileidx"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/httpclient"
+)
+
+const (
+	use      = "ls"
+	desc     = "List the mappings in a file index document"
+	longDesc = `
+The ls command resolves a Sidetree file index document and lists the name and DCAS ID of each file mapping, sorted by name.
+`
+	examples = `
+- List the mappings in the given file index document:
+    $ ./fabric file ls --idxurl http://localhost:48326/file/identifiers/file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA==
+
+... results in output such as:
+
+	Base path: /content
+	NAME                 ID
+	person.schema.json   TbVyraOqG00TacPQH5WwWGnxkszpYSEhBKRyX_f25JI=
+	raised-hand.png      k1fqlkDdtmkTBVTHQgvpJbhTEch2XP0cn0C-DuP-9pE=
+`
+)
+
+const (
+	fileIndexURLFlag  = "idxurl"
+	fileIndexURLUsage = "The URL of the file index Sidetree document. Example: --idxurl http://localhost:48326/file/identifiers/file:idx:1234"
+
+	tableHeader = "NAME\tID"
+
+	msgNoMappings = "No mappings found"
+
+	// the mapping that is added by createidx to ensure uniqueness of the document
+	baseMapping = "."
+)
+
+var errFileIndexURLRequired = errors.New("file index URL (--idxurl) is required")
+
+// New returns the file ls sub-command
+func New(settings *environment.Settings) *cobra.Command {
+	cfg := httpclient.NewConfig(settings)
+
+	cmd := newCmd(settings, httpclient.New(httpclient.WithConfig(cfg)))
+	cfg.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+func newCmd(settings *environment.Settings, client fileidx.HTTPClient) *cobra.Command {
+	c := &command{
+		Command: basecmd.New(settings, nil),
+		client:  client,
+	}
+
+	cmd := &cobra.Command{
+		Use:     use,
+		Short:   desc,
+		Long:    longDesc,
+		Example: examples,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return c.validate()
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return c.run()
+		},
+	}
+
+	cmd.SetOutput(c.Settings.Streams.Out)
+	cmd.SilenceUsage = true
+
+	cmd.Flags().StringVar(&c.fileIndexURL, fileIndexURLFlag, "", fileIndexURLUsage)
+	httpclient.AddAuthTokenFlag(cmd.Flags(), &c.authToken, "the URL specified by --idxurl")
+
+	return cmd
+}
+
+// command implements the ls command
+type command struct {
+	*basecmd.Command
+	client fileidx.HTTPClient
+
+	fileIndexURL string
+	authToken    string
+}
+
+func (c *command) validate() error {
+	if c.fileIndexURL == "" {
+		return errFileIndexURLRequired
+	}
+
+	return nil
+}
+
+func (c *command) run() error {
+	fileIdx, err := fileidx.Get(c.client, c.fileIndexURL, c.authToken)
+	if err != nil {
+		return err
+	}
+
+	if err := c.Fprintln(fmt.Sprintf("Base path: %s", fileIdx.BasePath)); err != nil {
+		return err
+	}
+
+	var names []string
+	for name := range fileIdx.Mappings {
+		if name != baseMapping {
+			names = append(names, name)
+		}
+	}
+
+	if len(names) == 0 {
+		return c.Fprintln(msgNoMappings)
+	}
+
+	sort.Strings(names)
+
+	w := tabwriter.NewWriter(c.Settings.Streams.Out, 0, 0, 3, ' ', 0)
+
+	if _, err := fmt.Fprintln(w, tableHeader); err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if _, err := fmt.Fprintf(w, "%s\t%s\n", name, fileIdx.Mappings[name]); err != nil {
+			return err
+		}
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,102 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package lscmd
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/fileidx"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/httpclient"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/model"
+	"github.com/trustbloc/fabric-cli-ext/cmd/mocks"
+)
+
+const idxURL = "http://localhost:48326/file/identifiers/file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA"
+
+func TestNew(t *testing.T) {
+	require.NotNil(t, New(environment.NewDefaultSettings()))
+}
+
+func TestLsCmd(t *testing.T) {
+	t.Run("No --idxurl", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, &mocks.Writer{}).Execute(), errFileIndexURLRequired.Error())
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		client := newMockClient(t, map[string]string{".": "/content", "v1/b.json": "id2", "a.json": "id1"})
+
+		w := &mocks.Writer{}
+		require.NoError(t, newMockCmd(t, client, w, "--idxurl", idxURL).Execute())
+
+		lines := strings.Split(strings.TrimSpace(string(w.Bytes)), "\n")
+		require.Len(t, lines, 4)
+		require.Equal(t, "Base path: /content", lines[0])
+		require.Equal(t, []string{"NAME", "ID"}, strings.Fields(lines[1]))
+		require.Equal(t, []string{"a.json", "id1"}, strings.Fields(lines[2]))
+		require.Equal(t, []string{"v1/b.json", "id2"}, strings.Fields(lines[3]))
+	})
+
+	t.Run("No mappings", func(t *testing.T) {
+		client := newMockClient(t, map[string]string{".": "/content"})
+
+		w := &mocks.Writer{}
+		require.NoError(t, newMockCmd(t, client, w, "--idxurl", idxURL).Execute())
+		require.Contains(t, w.Written(), msgNoMappings)
+	})
+
+	t.Run("Get error", func(t *testing.T) {
+		errExpected := errors.New("injected error")
+		require.EqualError(t, newMockCmd(t, &mockHTTPClient{err: errExpected}, &mocks.Writer{}, "--idxurl", idxURL).Execute(), errExpected.Error())
+	})
+}
+
+func newMockClient(t *testing.T, mappings map[string]string) *mockHTTPClient {
+	fileIdxDocBytes, err := json.Marshal(&model.FileIndexDoc{FileIndex: model.FileIndex{BasePath: "/content", Mappings: mappings}})
+	require.NoError(t, err)
+
+	didResolutionBytes, err := json.Marshal(&model.DIDResolution{DIDDocument: fileIdxDocBytes})
+	require.NoError(t, err)
+
+	return &mockHTTPClient{response: &httpclient.HTTPResponse{StatusCode: http.StatusOK, Payload: didResolutionBytes}}
+}
+
+type mockHTTPClient struct {
+	response *httpclient.HTTPResponse
+	err      error
+}
+
+func (m *mockHTTPClient) Get(string, ...httpclient.RequestOpt) (*httpclient.HTTPResponse, error) {
+	return m.response, m.err
+}
+
+func (m *mockHTTPClient) Post(string, []byte, ...httpclient.RequestOpt) (*httpclient.HTTPResponse, error) {
+	panic("not implemented")
+}
+
+func newMockCmd(t *testing.T, client fileidx.HTTPClient, w io.Writer, args ...string) *cobra.Command {
+	settings := environment.NewDefaultSettings()
+	settings.Streams.Out = w
+
+	settings.Config.CurrentContext = "testctx"
+	settings.Config.Contexts[settings.Config.CurrentContext] = &environment.Context{}
+
+	c := newCmd(settings, client)
+	require.NotNil(t, c)
+
+	c.SetArgs(args)
+
+	return c
+}
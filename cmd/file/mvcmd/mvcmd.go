@@ -0,0 +1,187 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mvcmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/basecmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/fileidx"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/httpclient"
+)
+
+const (
+	use      = "mv"
+	desc     = "Rename a mapping in a file index document"
+	longDesc = `
+The mv command renames a file mapping in a Sidetree file index document by issuing a signed Sidetree update request. The mapping
+continues to reference the same file in DCAS. The command fails if a mapping with the new name already exists.
+`
+	examples = `
+- Rename the mapping 'person.schema.json' to 'v1/person.schema.json' in the given file index document:
+    $ ./fabric file mv --idxurl http://localhost:48326/file/identifiers/file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA== --from person.schema.json --to v1/person.schema.json --signingkeyfile ./keys/update.key --nextupdatekeyfile ./keys/next_update.pem
+`
+)
+
+const (
+	fileIndexURLFlag  = "idxurl"
+	fileIndexURLUsage = "The URL of the file index Sidetree document. Example: --idxurl http://localhost:48326/file/identifiers/file:idx:1234"
+
+	fromFlag  = "from"
+	fromUsage = "The name of the mapping to rename. Example: --from person.schema.json"
+
+	toFlag  = "to"
+	toUsage = "The new name of the mapping. Example: --to v1/person.schema.json"
+
+	noPromptFlag  = "noprompt"
+	noPromptUsage = "If specified then the rename operation will not prompt for confirmation. Example: --noprompt"
+
+	msgMappingRenamed  = "Mapping successfully renamed!"
+	msgAborted         = "Operation aborted"
+	msgContinueOrAbort = "Enter Y to continue or N to abort "
+)
+
+var (
+	errFileIndexURLRequired = errors.New("file index URL (--idxurl) is required")
+	errFromRequired         = errors.New("from (--from) is required")
+	errToRequired           = errors.New("to (--to) is required")
+	errSameName             = errors.New("from (--from) and to (--to) must be different")
+)
+
+// New returns the file mv sub-command
+func New(settings *environment.Settings) *cobra.Command {
+	cfg := httpclient.NewConfig(settings)
+
+	cmd := newCmd(settings, httpclient.New(httpclient.WithConfig(cfg)))
+	cfg.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+func newCmd(settings *environment.Settings, client fileidx.HTTPClient) *cobra.Command {
+	c := &command{
+		Command: basecmd.New(settings, nil),
+		client:  client,
+	}
+
+	cmd := &cobra.Command{
+		Use:     use,
+		Short:   desc,
+		Long:    longDesc,
+		Example: examples,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return c.validate()
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return c.run()
+		},
+	}
+
+	cmd.SetOutput(c.Settings.Streams.Out)
+	cmd.SilenceUsage = true
+
+	cmd.Flags().StringVar(&c.fileIndexURL, fileIndexURLFlag, "", fileIndexURLUsage)
+	cmd.Flags().StringVar(&c.from, fromFlag, "", fromUsage)
+	cmd.Flags().StringVar(&c.to, toFlag, "", toUsage)
+	httpclient.AddAuthTokenFlag(cmd.Flags(), &c.authToken, "the URL specified by --idxurl")
+	c.keys.AddFlags(cmd.Flags())
+	cmd.Flags().BoolVar(&c.noPrompt, noPromptFlag, false, noPromptUsage)
+
+	return cmd
+}
+
+// command implements the mv command
+type command struct {
+	*basecmd.Command
+	client fileidx.HTTPClient
+
+	fileIndexURL string
+	from         string
+	to           string
+	authToken    string
+	keys         fileidx.UpdateKeys
+	noPrompt     bool
+}
+
+func (c *command) validate() error {
+	if c.fileIndexURL == "" {
+		return errFileIndexURLRequired
+	}
+
+	if _, err := fileidx.UpdateURL(c.fileIndexURL); err != nil {
+		return err
+	}
+
+	if c.from == "" {
+		return errFromRequired
+	}
+
+	if c.to == "" {
+		return errToRequired
+	}
+
+	if c.from == c.to {
+		return errSameName
+	}
+
+	return c.keys.Validate()
+}
+
+func (c *command) run() error {
+	fileIdx, err := fileidx.Get(c.client, c.fileIndexURL, c.authToken)
+	if err != nil {
+		return err
+	}
+
+	id, ok := fileIdx.Mappings[c.from]
+	if !ok {
+		return errors.Errorf("mapping [%s] not found in file index document [%s]", c.from, c.fileIndexURL)
+	}
+
+	if _, ok := fileIdx.Mappings[c.to]; ok {
+		return errors.Errorf("mapping [%s] already exists in file index document [%s]", c.to, c.fileIndexURL)
+	}
+
+	if err := fileidx.CheckDocumentProtocol(c.client, c.fileIndexURL, c.authToken, c.keys.MultihashCode()); err != nil {
+		return err
+	}
+
+	if !c.noPrompt {
+		confirmed, e := c.confirmRename(id)
+		if e != nil {
+			return e
+		}
+
+		if !confirmed {
+			return c.Fprintln(msgAborted)
+		}
+	}
+
+	patch := []fileidx.Patch{{Op: fileidx.OpMove, From: fileidx.MappingPath(c.from), Path: fileidx.MappingPath(c.to)}}
+
+	if err := fileidx.Update(c.client, c.fileIndexURL, c.authToken, &c.keys, patch, fileidx.WithMultihash(c.keys.MultihashCode()), fileidx.WithProtocolChecked()); err != nil {
+		return err
+	}
+
+	return c.Fprintln(msgMappingRenamed)
+}
+
+// confirmRename prompts the user for confirmation of the rename
+func (c *command) confirmRename(id string) (bool, error) {
+	prompt := fmt.Sprintf("Renaming mapping [%s] (%s) to [%s] in file index document [%s]\n%s", c.from, id, c.to, c.fileIndexURL, msgContinueOrAbort)
+
+	if err := c.Fprintln(prompt); err != nil {
+		return false, err
+	}
+
+	return strings.ToLower(c.Prompt()) == "y", nil
+}
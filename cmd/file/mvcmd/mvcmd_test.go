@@ -0,0 +1,153 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mvcmd
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/fileidx"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/httpclient"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/model"
+	"github.com/trustbloc/fabric-cli-ext/cmd/mocks"
+)
+
+const idxURL = "http://localhost:48326/file/identifiers/file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA"
+
+var keyArgs = []string{"--signingkeyfile", "../testdata/update_private.key", "--nextupdatekeyfile", "../testdata/update2_public.key"}
+
+func TestNew(t *testing.T) {
+	require.NotNil(t, New(environment.NewDefaultSettings()))
+}
+
+func TestMvCmd_InvalidOptions(t *testing.T) {
+	t.Run("No --idxurl", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil).Execute(), errFileIndexURLRequired.Error())
+	})
+	t.Run("Invalid --idxurl", func(t *testing.T) {
+		err := newMockCmd(t, nil, "--idxurl", "http://localhost:48326/file").Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid file index URL")
+	})
+	t.Run("No --from", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, "--idxurl", idxURL).Execute(), errFromRequired.Error())
+	})
+	t.Run("No --to", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, "--idxurl", idxURL, "--from", "a.json").Execute(), errToRequired.Error())
+	})
+	t.Run("Same name", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, "--idxurl", idxURL, "--from", "a.json", "--to", "a.json").Execute(), errSameName.Error())
+	})
+	t.Run("No signing key", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, "--idxurl", idxURL, "--from", "a.json", "--to", "b.json").Execute(), fileidx.ErrSigningKeyOrFileRequired.Error())
+	})
+}
+
+func TestMvCmd(t *testing.T) {
+	fileIdxDocBytes, err := json.Marshal(&model.FileIndexDoc{FileIndex: model.FileIndex{BasePath: "/content", Mappings: map[string]string{"v1/a.json": "id1", "c.json": "id2"}}})
+	require.NoError(t, err)
+
+	newClient := func() *mockHTTPClient {
+		return &mockHTTPClient{
+			getResponse:  &httpclient.HTTPResponse{StatusCode: http.StatusOK, Payload: fileIdxDocBytes},
+			postResponse: &httpclient.HTTPResponse{StatusCode: http.StatusOK},
+		}
+	}
+
+	args := append([]string{"--idxurl", idxURL, "--from", "v1/a.json", "--to", "v2/a.json"}, keyArgs...)
+
+	t.Run("With --noprompt", func(t *testing.T) {
+		client := newClient()
+		w := &mocks.Writer{}
+		require.NoError(t, newMockCmdWithReaderWriter(t, &mocks.Reader{}, w, client, append(args, "--noprompt")...).Execute())
+		require.Contains(t, w.Written(), msgMappingRenamed)
+		require.Contains(t, string(client.postReq), `"op":"move"`)
+		require.Contains(t, string(client.postReq), `"from":"/fileIndex/mappings/v1~1a.json"`)
+		require.Contains(t, string(client.postReq), `"path":"/fileIndex/mappings/v2~1a.json"`)
+	})
+	t.Run("With prompt - Y", func(t *testing.T) {
+		w := &mocks.Writer{}
+		require.NoError(t, newMockCmdWithReaderWriter(t, &mocks.Reader{Bytes: []byte("Y\n")}, w, newClient(), args...).Execute())
+		require.Contains(t, w.Written(), "Renaming mapping [v1/a.json] (id1) to [v2/a.json]")
+		require.Contains(t, w.Written(), msgMappingRenamed)
+	})
+	t.Run("With prompt - N", func(t *testing.T) {
+		client := newClient()
+		w := &mocks.Writer{}
+		require.NoError(t, newMockCmdWithReaderWriter(t, &mocks.Reader{Bytes: []byte("N\n")}, w, client, args...).Execute())
+		require.Contains(t, w.Written(), msgAborted)
+		require.Empty(t, client.postReq)
+	})
+	t.Run("Mapping not found", func(t *testing.T) {
+		args := append([]string{"--idxurl", idxURL, "--from", "b.json", "--to", "d.json", "--noprompt"}, keyArgs...)
+		err := newMockCmd(t, newClient(), args...).Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "mapping [b.json] not found")
+	})
+	t.Run("Mapping already exists", func(t *testing.T) {
+		args := append([]string{"--idxurl", idxURL, "--from", "v1/a.json", "--to", "c.json", "--noprompt"}, keyArgs...)
+		err := newMockCmd(t, newClient(), args...).Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "mapping [c.json] already exists")
+	})
+	t.Run("Get error", func(t *testing.T) {
+		errExpected := errors.New("injected error")
+		require.EqualError(t, newMockCmd(t, &mockHTTPClient{getErr: errExpected}, append(args, "--noprompt")...).Execute(), errExpected.Error())
+	})
+	t.Run("Update error", func(t *testing.T) {
+		client := newClient()
+		client.postResponse = &httpclient.HTTPResponse{StatusCode: http.StatusInternalServerError, ErrorMsg: "server error"}
+
+		err := newMockCmd(t, client, append(args, "--noprompt")...).Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "server error")
+	})
+}
+
+type mockHTTPClient struct {
+	getResponse  *httpclient.HTTPResponse
+	getErr       error
+	postResponse *httpclient.HTTPResponse
+	postReq      []byte
+}
+
+func (m *mockHTTPClient) Get(string, ...httpclient.RequestOpt) (*httpclient.HTTPResponse, error) {
+	return m.getResponse, m.getErr
+}
+
+func (m *mockHTTPClient) Post(_ string, req []byte, _ ...httpclient.RequestOpt) (*httpclient.HTTPResponse, error) {
+	m.postReq = req
+
+	return m.postResponse, nil
+}
+
+func newMockCmd(t *testing.T, client fileidx.HTTPClient, args ...string) *cobra.Command {
+	return newMockCmdWithReaderWriter(t, &mocks.Reader{}, &mocks.Writer{}, client, args...)
+}
+
+func newMockCmdWithReaderWriter(t *testing.T, in io.Reader, w io.Writer, client fileidx.HTTPClient, args ...string) *cobra.Command {
+	settings := environment.NewDefaultSettings()
+	settings.Streams.Out = w
+	settings.Streams.In = in
+
+	settings.Config.CurrentContext = "testctx"
+	settings.Config.Contexts[settings.Config.CurrentContext] = &environment.Context{}
+
+	c := newCmd(settings, client)
+	require.NotNil(t, c)
+
+	c.SetArgs(args)
+
+	return c
+}
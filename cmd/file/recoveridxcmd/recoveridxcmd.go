@@ -0,0 +1,236 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package recoveridxcmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/basecmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/fileidx"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/httpclient"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/model"
+	"github.com/trustbloc/fabric-cli-ext/cmd/keyutil"
+)
+
+const (
+	use      = "recoveridx"
+	desc     = "Recover a file index document using the recovery key"
+	longDesc = `
+The recoveridx command replaces the contents of a Sidetree file index document by issuing a Sidetree recover request signed
+with the recovery private key. New recovery and update commitments are set on the document so that a lost or compromised
+update key may be replaced. If --path is specified then the document is replaced with a new file index document for the given
+base path (as is done by createidx), otherwise the current base path and mappings of the document are preserved.
+`
+	examples = `
+- Recover the given file index document, preserving its current mappings:
+    $ ./fabric file recoveridx --idxurl http://localhost:48326/file/identifiers/file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA== --signingkeyfile ./keys/recover.key --nextrecoverykeyfile ./keys/next_recover.pem --nextupdatekeyfile ./keys/next_update.pem
+`
+)
+
+const (
+	fileIndexURLFlag  = "idxurl"
+	fileIndexURLUsage = "The URL of the file index Sidetree document. Example: --idxurl http://localhost:48326/file/identifiers/file:idx:1234"
+
+	pathFlag  = "path"
+	pathUsage = "The base path of the new file index document. If not specified then the current base path and mappings are preserved. Example: --path /content"
+
+	fileIndexSigningKeyFlag  = "signingkey"
+	fileIndexSigningKeyUsage = "The recovery private key PEM used for signing the recovery of the index document. Example: --signingkey 'MHcCAQEEILmfa4yss8nsTJK2hKl+LAoiwW3p+eQzaHfITI9z8ptpoAoGCCqGSM49AwEHoUQDQgAEMd1/e/Nxh73bK12PEEcNSY9HxnP0N8er9ww9rjq1tNcsqfRjlL0bdTh9Basfn/4JrQHUHc6uS99yjQc+0u2bVg'"
+
+	fileIndexSigningKeyFileFlag  = "signingkeyfile"
+	fileIndexSigningKeyFileUsage = "The file that contains the recovery private key PEM used for signing the recovery of the index document. Example: --signingkeyfile ./keys/recover.key"
+
+	signerFlag  = "signer"
+	signerUsage = "The signer that holds the recovery private key used for signing the recovery of the index document, so that the private key is never exposed. This flag may be used instead of --signingkey(file). The signer is one of: a PKCS#11 token (pkcs11:lib=<library>;token=<token label>;pin=<user PIN>;label=<key label>), an external command that reads the JWS signing input from stdin and writes the base64url-encoded signature to stdout (cmd:<command> [args]) or an HTTP signing service (http(s)://<URL>). Example: --signer 'pkcs11:lib=/usr/lib/softhsm/libsofthsm2.so;token=fabric;pin=1234;label=update'"
+
+	signerPublicKeyFileFlag  = "signerpublickeyfile"
+	signerPublicKeyFileUsage = "The file that contains the public key PEM of the signer. Required for command and HTTP signers. Example: --signerpublickeyfile ./keys/signer_public.pem"
+
+	multihashFlag  = "multihash"
+	multihashUsage = "The multihash algorithm used to compute the commitments and reveal value of the recovery. The reveal value must be computed with the same algorithm as the commitment of the previous operation on the index document (see 'file createidx --multihash'). Supported values are sha2-256 and sha2-512. Example: --multihash sha2-512"
+
+	fileIndexNextRecoveryKeyFlag  = "nextrecoverykey"
+	fileIndexNextRecoveryKeyUsage = "The public key PEM used for creating commitment for next recovery of the index document. Example: --nextrecoverykey 'MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAEXlp4fWF5rgLthKr20tsJ0tBIE6UmrGuAC8iVG/DaedkSt7HihCx/t2BGjooduaKwEIOmPjx2zBsbkbFrYhhnVw'"
+
+	fileIndexNextRecoveryKeyFileFlag  = "nextrecoverykeyfile"
+	fileIndexNextRecoveryKeyFileUsage = "The file that contains the public key PEM used for creating commitment for next recovery of the index document. Example: --nextrecoverykeyfile ./next_recover_public.key"
+
+	fileIndexNextUpdateKeyFlag  = "nextupdatekey"
+	fileIndexNextUpdateKeyUsage = "The public key PEM used for creating commitment for next update of the index document. Example: --nextupdatekey 'MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAEFMy2n9jYZChYSjdhK9vUWvPjz9tzBcEa13Ye33haxFsT//3kGxOQhI7yb3MJsDvwLtdfLL6txM3RdOrmLABBvw'"
+
+	fileIndexNextUpdateKeyFileFlag  = "nextupdatekeyfile"
+	fileIndexNextUpdateKeyFileUsage = "The file that contains the public key PEM used for creating commitment for next update of the index document. Example: --nextupdatekeyfile ./next_update_public.key"
+
+	keyStoreFlag  = "keystore"
+	keyStoreUsage = "The key store directory in which to save newly generated update keys for the recovered index document. This flag may be used instead of --nextupdatekey(file). Example: --keystore ~/.fabric/keystore"
+
+	keyTypeFlag  = "keytype"
+	keyTypeUsage = "The type of update keys generated for the key store (--keystore). Supported types are P-256, secp256k1 and Ed25519. Example: --keytype Ed25519"
+
+	noPromptFlag  = "noprompt"
+	noPromptUsage = "If specified then the recover operation will not prompt for confirmation. Example: --noprompt"
+
+	msgDocumentRecovered = "File index document successfully recovered!"
+	msgAborted           = "Operation aborted"
+	msgContinueOrAbort   = "Enter Y to continue or N to abort "
+
+	// the mapping that is added by createidx to ensure uniqueness of the document
+	baseMapping = "."
+)
+
+var (
+	errFileIndexURLRequired = errors.New("file index URL (--idxurl) is required")
+	errInvalidPath          = errors.New("path (--path) must begin with '/'")
+)
+
+// New returns the file recoveridx sub-command
+func New(settings *environment.Settings) *cobra.Command {
+	cfg := httpclient.NewConfig(settings)
+
+	cmd := newCmd(settings, httpclient.New(httpclient.WithConfig(cfg)))
+	cfg.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+func newCmd(settings *environment.Settings, client fileidx.HTTPClient) *cobra.Command {
+	c := &command{
+		Command: basecmd.New(settings, nil),
+		client:  client,
+	}
+
+	cmd := &cobra.Command{
+		Use:     use,
+		Short:   desc,
+		Long:    longDesc,
+		Example: examples,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return c.validate()
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return c.run()
+		},
+	}
+
+	cmd.SetOutput(c.Settings.Streams.Out)
+	cmd.SilenceUsage = true
+
+	cmd.Flags().StringVar(&c.fileIndexURL, fileIndexURLFlag, "", fileIndexURLUsage)
+	cmd.Flags().StringVar(&c.path, pathFlag, "", pathUsage)
+	httpclient.AddAuthTokenFlag(cmd.Flags(), &c.authToken, "the URL specified by --idxurl")
+	cmd.Flags().StringVar(&c.keys.SigningKeyString, fileIndexSigningKeyFlag, "", fileIndexSigningKeyUsage)
+	cmd.Flags().StringVar(&c.keys.SigningKeyFile, fileIndexSigningKeyFileFlag, "", fileIndexSigningKeyFileUsage)
+	cmd.Flags().StringVar(&c.keys.Signer, signerFlag, "", signerUsage)
+	cmd.Flags().StringVar(&c.keys.SignerPublicKeyFile, signerPublicKeyFileFlag, "", signerPublicKeyFileUsage)
+	cmd.Flags().StringVar(&c.multihash, multihashFlag, fileidx.MultihashSHA2256, multihashUsage)
+	cmd.Flags().StringVar(&c.keys.NextRecoveryKeyString, fileIndexNextRecoveryKeyFlag, "", fileIndexNextRecoveryKeyUsage)
+	cmd.Flags().StringVar(&c.keys.NextRecoveryKeyFile, fileIndexNextRecoveryKeyFileFlag, "", fileIndexNextRecoveryKeyFileUsage)
+	cmd.Flags().StringVar(&c.keys.NextUpdateKeyString, fileIndexNextUpdateKeyFlag, "", fileIndexNextUpdateKeyUsage)
+	cmd.Flags().StringVar(&c.keys.NextUpdateKeyFile, fileIndexNextUpdateKeyFileFlag, "", fileIndexNextUpdateKeyFileUsage)
+	cmd.Flags().StringVar(&c.keys.KeyStoreDir, keyStoreFlag, "", keyStoreUsage)
+	cmd.Flags().StringVar(&c.keys.KeyType, keyTypeFlag, keyutil.KeyTypeP256, keyTypeUsage)
+	cmd.Flags().BoolVar(&c.noPrompt, noPromptFlag, false, noPromptUsage)
+
+	return cmd
+}
+
+// command implements the recoveridx command
+type command struct {
+	*basecmd.Command
+	client fileidx.HTTPClient
+
+	fileIndexURL  string
+	path          string
+	authToken     string
+	keys          fileidx.RecoveryKeys
+	multihash     string
+	multihashCode uint
+	noPrompt      bool
+}
+
+func (c *command) validate() error {
+	if c.fileIndexURL == "" {
+		return errFileIndexURLRequired
+	}
+
+	if _, err := fileidx.UpdateURL(c.fileIndexURL); err != nil {
+		return err
+	}
+
+	if c.path != "" && c.path[0:1] != "/" {
+		return errInvalidPath
+	}
+
+	multihashCode, err := fileidx.MultihashCode(c.multihash)
+	if err != nil {
+		return err
+	}
+
+	c.multihashCode = multihashCode
+
+	return c.keys.Validate()
+}
+
+func (c *command) run() error {
+	fileIdx, err := c.getFileIndex()
+	if err != nil {
+		return err
+	}
+
+	if err := fileidx.CheckDocumentProtocol(c.client, c.fileIndexURL, c.authToken, c.multihashCode); err != nil {
+		return err
+	}
+
+	if !c.noPrompt {
+		confirmed, e := c.confirmRecover(fileIdx)
+		if e != nil {
+			return e
+		}
+
+		if !confirmed {
+			return c.Fprintln(msgAborted)
+		}
+	}
+
+	if err := fileidx.Recover(c.client, c.fileIndexURL, c.authToken, &c.keys, fileIdx, fileidx.WithMultihash(c.multihashCode), fileidx.WithProtocolChecked()); err != nil {
+		return err
+	}
+
+	return c.Fprintln(msgDocumentRecovered)
+}
+
+// getFileIndex returns the contents of the recovered document. If a path was specified then a new
+// file index is returned, otherwise the current file index is resolved so that its mappings are preserved.
+func (c *command) getFileIndex() (*model.FileIndex, error) {
+	if c.path != "" {
+		return &model.FileIndex{
+			BasePath: c.path,
+			Mappings: map[string]string{
+				baseMapping: c.path,
+			},
+		}, nil
+	}
+
+	return fileidx.Get(c.client, c.fileIndexURL, c.authToken)
+}
+
+// confirmRecover prompts the user for confirmation of the recovery
+func (c *command) confirmRecover(fileIdx *model.FileIndex) (bool, error) {
+	prompt := fmt.Sprintf("Recovering file index document [%s] with base path [%s] and %d mapping(s)\n%s",
+		c.fileIndexURL, fileIdx.BasePath, len(fileIdx.Mappings), msgContinueOrAbort)
+
+	if err := c.Fprintln(prompt); err != nil {
+		return false, err
+	}
+
+	return strings.ToLower(c.Prompt()) == "y", nil
+}
@@ -0,0 +1,155 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package recoveridxcmd
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/fileidx"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/httpclient"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/model"
+	"github.com/trustbloc/fabric-cli-ext/cmd/mocks"
+)
+
+const idxURL = "http://localhost:48326/file/identifiers/file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA"
+
+var keyArgs = []string{
+	"--signingkeyfile", "../testdata/recover2_private.key",
+	"--nextrecoverykeyfile", "../testdata/recover_public.key",
+	"--nextupdatekeyfile", "../testdata/update2_public.key",
+}
+
+func TestNew(t *testing.T) {
+	require.NotNil(t, New(environment.NewDefaultSettings()))
+}
+
+func TestRecoverIdxCmd_InvalidOptions(t *testing.T) {
+	t.Run("No --idxurl", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil).Execute(), errFileIndexURLRequired.Error())
+	})
+	t.Run("Invalid --idxurl", func(t *testing.T) {
+		err := newMockCmd(t, nil, "--idxurl", "http://localhost:48326/file").Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid file index URL")
+	})
+	t.Run("Invalid --path", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, "--idxurl", idxURL, "--path", "content").Execute(), errInvalidPath.Error())
+	})
+	t.Run("No signing key", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, "--idxurl", idxURL).Execute(), fileidx.ErrSigningKeyOrFileRequired.Error())
+	})
+	t.Run("No next recovery key", func(t *testing.T) {
+		err := newMockCmd(t, nil, "--idxurl", idxURL, "--signingkeyfile", "../testdata/recover2_private.key").Execute()
+		require.EqualError(t, err, fileidx.ErrNextRecoveryKeyOrFileRequired.Error())
+	})
+}
+
+func TestRecoverIdxCmd(t *testing.T) {
+	fileIdxDocBytes, err := json.Marshal(&model.FileIndexDoc{FileIndex: model.FileIndex{BasePath: "/content", Mappings: map[string]string{".": "/content", "a.json": "id1"}}})
+	require.NoError(t, err)
+
+	newClient := func() *mockHTTPClient {
+		return &mockHTTPClient{
+			getResponse:  &httpclient.HTTPResponse{StatusCode: http.StatusOK, Payload: fileIdxDocBytes},
+			postResponse: &httpclient.HTTPResponse{StatusCode: http.StatusOK},
+		}
+	}
+
+	args := append([]string{"--idxurl", idxURL}, keyArgs...)
+
+	t.Run("Preserve mappings", func(t *testing.T) {
+		client := newClient()
+		w := &mocks.Writer{}
+		require.NoError(t, newMockCmdWithReaderWriter(t, &mocks.Reader{}, w, client, append(args, "--noprompt")...).Execute())
+		require.Contains(t, w.Written(), msgDocumentRecovered)
+		require.Contains(t, string(client.postReq), `"type":"recover"`)
+		require.Contains(t, string(client.postReq), `"a.json":"id1"`)
+	})
+	t.Run("New path", func(t *testing.T) {
+		client := &mockHTTPClient{postResponse: &httpclient.HTTPResponse{StatusCode: http.StatusOK}}
+		w := &mocks.Writer{}
+		require.NoError(t, newMockCmdWithReaderWriter(t, &mocks.Reader{}, w, client, append(args, "--path", "/schema", "--noprompt")...).Execute())
+		require.Contains(t, w.Written(), msgDocumentRecovered)
+		require.Contains(t, string(client.postReq), `"basePath":"/schema"`)
+		require.NotContains(t, string(client.postReq), `"a.json"`)
+	})
+	t.Run("With prompt - Y", func(t *testing.T) {
+		w := &mocks.Writer{}
+		require.NoError(t, newMockCmdWithReaderWriter(t, &mocks.Reader{Bytes: []byte("Y\n")}, w, newClient(), args...).Execute())
+		require.Contains(t, w.Written(), "with base path [/content] and 2 mapping(s)")
+		require.Contains(t, w.Written(), msgDocumentRecovered)
+	})
+	t.Run("With prompt - N", func(t *testing.T) {
+		client := newClient()
+		w := &mocks.Writer{}
+		require.NoError(t, newMockCmdWithReaderWriter(t, &mocks.Reader{Bytes: []byte("N\n")}, w, client, args...).Execute())
+		require.Contains(t, w.Written(), msgAborted)
+		require.Empty(t, client.postReq)
+	})
+	t.Run("Get error", func(t *testing.T) {
+		errExpected := errors.New("injected error")
+		require.EqualError(t, newMockCmd(t, &mockHTTPClient{getErr: errExpected}, append(args, "--noprompt")...).Execute(), errExpected.Error())
+	})
+	t.Run("Recover error", func(t *testing.T) {
+		client := newClient()
+		client.postResponse = &httpclient.HTTPResponse{StatusCode: http.StatusInternalServerError, ErrorMsg: "server error"}
+
+		err := newMockCmd(t, client, append(args, "--noprompt")...).Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "server error")
+	})
+}
+
+type mockHTTPClient struct {
+	getResponse  *httpclient.HTTPResponse
+	getErr       error
+	postResponse *httpclient.HTTPResponse
+	postReq      []byte
+}
+
+func (m *mockHTTPClient) Get(string, ...httpclient.RequestOpt) (*httpclient.HTTPResponse, error) {
+	if m.getResponse == nil && m.getErr == nil {
+		// The Sidetree node doesn't expose its protocol parameters
+		return &httpclient.HTTPResponse{StatusCode: http.StatusNotFound}, nil
+	}
+
+	return m.getResponse, m.getErr
+}
+
+func (m *mockHTTPClient) Post(_ string, req []byte, _ ...httpclient.RequestOpt) (*httpclient.HTTPResponse, error) {
+	m.postReq = req
+
+	return m.postResponse, nil
+}
+
+func newMockCmd(t *testing.T, client fileidx.HTTPClient, args ...string) *cobra.Command {
+	return newMockCmdWithReaderWriter(t, &mocks.Reader{}, &mocks.Writer{}, client, args...)
+}
+
+func newMockCmdWithReaderWriter(t *testing.T, in io.Reader, w io.Writer, client fileidx.HTTPClient, args ...string) *cobra.Command {
+	settings := environment.NewDefaultSettings()
+	settings.Streams.Out = w
+	settings.Streams.In = in
+
+	settings.Config.CurrentContext = "testctx"
+	settings.Config.Contexts[settings.Config.CurrentContext] = &environment.Context{}
+
+	c := newCmd(settings, client)
+	require.NotNil(t, c)
+
+	c.SetArgs(args)
+
+	return c
+}
@@ -0,0 +1,167 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package rmcmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/basecmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/fileidx"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/httpclient"
+)
+
+const (
+	use      = "rm"
+	desc     = "Remove a mapping from a file index document"
+	longDesc = `
+The rm command removes a file mapping from a Sidetree file index document by issuing a signed Sidetree update request. The file itself is not removed from DCAS.
+`
+	examples = `
+- Remove the mapping for 'person.schema.json' from the given file index document:
+    $ ./fabric file rm --idxurl http://localhost:48326/file/identifiers/file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA== --name person.schema.json --signingkeyfile ./keys/update.key --nextupdatekeyfile ./keys/next_update.pem
+`
+)
+
+const (
+	fileIndexURLFlag  = "idxurl"
+	fileIndexURLUsage = "The URL of the file index Sidetree document. Example: --idxurl http://localhost:48326/file/identifiers/file:idx:1234"
+
+	nameFlag  = "name"
+	nameUsage = "The name of the mapping to remove. Example: --name person.schema.json"
+
+	noPromptFlag  = "noprompt"
+	noPromptUsage = "If specified then the remove operation will not prompt for confirmation. Example: --noprompt"
+
+	msgMappingRemoved  = "Mapping successfully removed!"
+	msgAborted         = "Operation aborted"
+	msgContinueOrAbort = "Enter Y to continue or N to abort "
+)
+
+var (
+	errFileIndexURLRequired = errors.New("file index URL (--idxurl) is required")
+	errNameRequired         = errors.New("name (--name) is required")
+)
+
+// New returns the file rm sub-command
+func New(settings *environment.Settings) *cobra.Command {
+	cfg := httpclient.NewConfig(settings)
+
+	cmd := newCmd(settings, httpclient.New(httpclient.WithConfig(cfg)))
+	cfg.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+func newCmd(settings *environment.Settings, client fileidx.HTTPClient) *cobra.Command {
+	c := &command{
+		Command: basecmd.New(settings, nil),
+		client:  client,
+	}
+
+	cmd := &cobra.Command{
+		Use:     use,
+		Short:   desc,
+		Long:    longDesc,
+		Example: examples,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return c.validate()
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return c.run()
+		},
+	}
+
+	cmd.SetOutput(c.Settings.Streams.Out)
+	cmd.SilenceUsage = true
+
+	cmd.Flags().StringVar(&c.fileIndexURL, fileIndexURLFlag, "", fileIndexURLUsage)
+	cmd.Flags().StringVar(&c.name, nameFlag, "", nameUsage)
+	httpclient.AddAuthTokenFlag(cmd.Flags(), &c.authToken, "the URL specified by --idxurl")
+	c.keys.AddFlags(cmd.Flags())
+	cmd.Flags().BoolVar(&c.noPrompt, noPromptFlag, false, noPromptUsage)
+
+	return cmd
+}
+
+// command implements the rm command
+type command struct {
+	*basecmd.Command
+	client fileidx.HTTPClient
+
+	fileIndexURL string
+	name         string
+	authToken    string
+	keys         fileidx.UpdateKeys
+	noPrompt     bool
+}
+
+func (c *command) validate() error {
+	if c.fileIndexURL == "" {
+		return errFileIndexURLRequired
+	}
+
+	if _, err := fileidx.UpdateURL(c.fileIndexURL); err != nil {
+		return err
+	}
+
+	if c.name == "" {
+		return errNameRequired
+	}
+
+	return c.keys.Validate()
+}
+
+func (c *command) run() error {
+	fileIdx, err := fileidx.Get(c.client, c.fileIndexURL, c.authToken)
+	if err != nil {
+		return err
+	}
+
+	id, ok := fileIdx.Mappings[c.name]
+	if !ok {
+		return errors.Errorf("mapping [%s] not found in file index document [%s]", c.name, c.fileIndexURL)
+	}
+
+	if err := fileidx.CheckDocumentProtocol(c.client, c.fileIndexURL, c.authToken, c.keys.MultihashCode()); err != nil {
+		return err
+	}
+
+	if !c.noPrompt {
+		confirmed, e := c.confirmRemove(id)
+		if e != nil {
+			return e
+		}
+
+		if !confirmed {
+			return c.Fprintln(msgAborted)
+		}
+	}
+
+	patch := []fileidx.Patch{{Op: fileidx.OpRemove, Path: fileidx.MappingPath(c.name)}}
+
+	if err := fileidx.Update(c.client, c.fileIndexURL, c.authToken, &c.keys, patch, fileidx.WithMultihash(c.keys.MultihashCode()), fileidx.WithProtocolChecked()); err != nil {
+		return err
+	}
+
+	return c.Fprintln(msgMappingRemoved)
+}
+
+// confirmRemove prompts the user for confirmation of the remove
+func (c *command) confirmRemove(id string) (bool, error) {
+	prompt := fmt.Sprintf("Removing mapping [%s] (%s) from file index document [%s]\n%s", c.name, id, c.fileIndexURL, msgContinueOrAbort)
+
+	if err := c.Fprintln(prompt); err != nil {
+		return false, err
+	}
+
+	return strings.ToLower(c.Prompt()) == "y", nil
+}
@@ -0,0 +1,144 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package rmcmd
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/fileidx"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/httpclient"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/model"
+	"github.com/trustbloc/fabric-cli-ext/cmd/mocks"
+)
+
+const idxURL = "http://localhost:48326/file/identifiers/file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA"
+
+var keyArgs = []string{"--signingkeyfile", "../testdata/update_private.key", "--nextupdatekeyfile", "../testdata/update2_public.key"}
+
+func TestNew(t *testing.T) {
+	require.NotNil(t, New(environment.NewDefaultSettings()))
+}
+
+func TestRmCmd_InvalidOptions(t *testing.T) {
+	t.Run("No --idxurl", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil).Execute(), errFileIndexURLRequired.Error())
+	})
+	t.Run("Invalid --idxurl", func(t *testing.T) {
+		err := newMockCmd(t, nil, "--idxurl", "http://localhost:48326/file").Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid file index URL")
+	})
+	t.Run("No --name", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, "--idxurl", idxURL).Execute(), errNameRequired.Error())
+	})
+	t.Run("No signing key", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, "--idxurl", idxURL, "--name", "a.json").Execute(), fileidx.ErrSigningKeyOrFileRequired.Error())
+	})
+	t.Run("Key store and keys", func(t *testing.T) {
+		err := newMockCmd(t, nil, append([]string{"--idxurl", idxURL, "--name", "a.json", "--keystore", "./keystore"}, keyArgs...)...).Execute()
+		require.EqualError(t, err, fileidx.ErrKeyStoreWithUpdateKeys.Error())
+	})
+}
+
+func TestRmCmd(t *testing.T) {
+	fileIdxDocBytes, err := json.Marshal(&model.FileIndexDoc{FileIndex: model.FileIndex{BasePath: "/content", Mappings: map[string]string{"v1/a.json": "id1"}}})
+	require.NoError(t, err)
+
+	newClient := func() *mockHTTPClient {
+		return &mockHTTPClient{
+			getResponse:  &httpclient.HTTPResponse{StatusCode: http.StatusOK, Payload: fileIdxDocBytes},
+			postResponse: &httpclient.HTTPResponse{StatusCode: http.StatusOK},
+		}
+	}
+
+	args := append([]string{"--idxurl", idxURL, "--name", "v1/a.json"}, keyArgs...)
+
+	t.Run("With --noprompt", func(t *testing.T) {
+		client := newClient()
+		w := &mocks.Writer{}
+		require.NoError(t, newMockCmdWithReaderWriter(t, &mocks.Reader{}, w, client, append(args, "--noprompt")...).Execute())
+		require.Contains(t, w.Written(), msgMappingRemoved)
+		require.Contains(t, string(client.postReq), `"op":"remove"`)
+		require.Contains(t, string(client.postReq), `"path":"/fileIndex/mappings/v1~1a.json"`)
+	})
+	t.Run("With prompt - Y", func(t *testing.T) {
+		w := &mocks.Writer{}
+		require.NoError(t, newMockCmdWithReaderWriter(t, &mocks.Reader{Bytes: []byte("Y\n")}, w, newClient(), args...).Execute())
+		require.Contains(t, w.Written(), "Removing mapping [v1/a.json] (id1)")
+		require.Contains(t, w.Written(), msgMappingRemoved)
+	})
+	t.Run("With prompt - N", func(t *testing.T) {
+		client := newClient()
+		w := &mocks.Writer{}
+		require.NoError(t, newMockCmdWithReaderWriter(t, &mocks.Reader{Bytes: []byte("N\n")}, w, client, args...).Execute())
+		require.Contains(t, w.Written(), msgAborted)
+		require.Empty(t, client.postReq)
+	})
+	t.Run("Mapping not found", func(t *testing.T) {
+		args := append([]string{"--idxurl", idxURL, "--name", "b.json", "--noprompt"}, keyArgs...)
+		err := newMockCmd(t, newClient(), args...).Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "mapping [b.json] not found")
+	})
+	t.Run("Get error", func(t *testing.T) {
+		errExpected := errors.New("injected error")
+		require.EqualError(t, newMockCmd(t, &mockHTTPClient{getErr: errExpected}, append(args, "--noprompt")...).Execute(), errExpected.Error())
+	})
+	t.Run("Update error", func(t *testing.T) {
+		client := newClient()
+		client.postResponse = &httpclient.HTTPResponse{StatusCode: http.StatusInternalServerError, ErrorMsg: "server error"}
+
+		err := newMockCmd(t, client, append(args, "--noprompt")...).Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "server error")
+	})
+}
+
+type mockHTTPClient struct {
+	getResponse  *httpclient.HTTPResponse
+	getErr       error
+	postResponse *httpclient.HTTPResponse
+	postReq      []byte
+}
+
+func (m *mockHTTPClient) Get(string, ...httpclient.RequestOpt) (*httpclient.HTTPResponse, error) {
+	return m.getResponse, m.getErr
+}
+
+func (m *mockHTTPClient) Post(_ string, req []byte, _ ...httpclient.RequestOpt) (*httpclient.HTTPResponse, error) {
+	m.postReq = req
+
+	return m.postResponse, nil
+}
+
+func newMockCmd(t *testing.T, client fileidx.HTTPClient, args ...string) *cobra.Command {
+	return newMockCmdWithReaderWriter(t, &mocks.Reader{}, &mocks.Writer{}, client, args...)
+}
+
+func newMockCmdWithReaderWriter(t *testing.T, in io.Reader, w io.Writer, client fileidx.HTTPClient, args ...string) *cobra.Command {
+	settings := environment.NewDefaultSettings()
+	settings.Streams.Out = w
+	settings.Streams.In = in
+
+	settings.Config.CurrentContext = "testctx"
+	settings.Config.Contexts[settings.Config.CurrentContext] = &environment.Context{}
+
+	c := newCmd(settings, client)
+	require.NotNil(t, c)
+
+	c.SetArgs(args)
+
+	return c
+}
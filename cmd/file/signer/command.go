@@ -0,0 +1,78 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package signer
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/base64"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+)
+
+// commandSigner signs by invoking an external command. The JWS signing input is written to the standard
+// input of the command and the base64url-encoded signature is read from its standard output.
+type commandSigner struct {
+	args      []string
+	headers   jws.Headers
+	publicKey crypto.PublicKey
+}
+
+func newCommandSigner(args []string, publicKey crypto.PublicKey) (*commandSigner, error) {
+	h, err := headers(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &commandSigner{args: args, headers: h, publicKey: publicKey}, nil
+}
+
+func (s *commandSigner) Sign(data []byte) ([]byte, error) {
+	cmd := exec.Command(s.args[0], s.args[1:]...) //nolint: gosec
+
+	var stdout, stderr bytes.Buffer
+
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, errors.WithMessagef(err, "signing command [%s] failed: %s", s.args[0], strings.TrimSpace(stderr.String()))
+	}
+
+	return decodeSignature(stdout.String())
+}
+
+func (s *commandSigner) Headers() jws.Headers {
+	return s.headers
+}
+
+func (s *commandSigner) PublicKey() crypto.PublicKey {
+	return s.publicKey
+}
+
+func (s *commandSigner) Close() error {
+	return nil
+}
+
+// decodeSignature decodes the given base64url-encoded signature. Padding is optional.
+func decodeSignature(sig string) ([]byte, error) {
+	sig = strings.TrimRight(strings.TrimSpace(sig), "=")
+	if sig == "" {
+		return nil, errors.New("empty signature returned by signer")
+	}
+
+	sigBytes, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil {
+		return nil, errors.WithMessage(err, "invalid signature returned by signer - expecting base64url encoding")
+	}
+
+	return sigBytes, nil
+}
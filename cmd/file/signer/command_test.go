@@ -0,0 +1,83 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package signer
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/keyutil"
+)
+
+func TestCommandSigner(t *testing.T) {
+	dir, err := ioutil.TempDir("", "signer")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+	inputFile := filepath.Join(dir, "input")
+	script := filepath.Join(dir, "sign.sh")
+
+	// The script saves the signing input and returns the base64url encoding of [1, 2, 3]
+	require.NoError(t, ioutil.WriteFile(script, []byte("#!/bin/sh\ncat > "+inputFile+"\necho AQID\n"), 0700))
+
+	publicKey := &ecdsa.PublicKey{Curve: elliptic.P256()}
+
+	t.Run("Success", func(t *testing.T) {
+		s, err := New("cmd:"+script, publicKey, nil)
+		require.NoError(t, err)
+
+		sig, err := s.Sign([]byte("header.payload"))
+		require.NoError(t, err)
+		require.Equal(t, []byte{1, 2, 3}, sig)
+		require.Equal(t, keyutil.AlgES256, s.Headers()["alg"])
+
+		input, err := ioutil.ReadFile(inputFile)
+		require.NoError(t, err)
+		require.Equal(t, "header.payload", string(input))
+	})
+
+	t.Run("Command error", func(t *testing.T) {
+		s, err := New("cmd:/bin/sh -c 'exit 1'", publicKey, nil)
+		require.NoError(t, err)
+
+		_, err = s.Sign([]byte("header.payload"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "signing command [/bin/sh] failed")
+	})
+
+	t.Run("Command not found", func(t *testing.T) {
+		s, err := New("cmd:"+filepath.Join(dir, "xxx"), publicKey, nil)
+		require.NoError(t, err)
+
+		_, err = s.Sign([]byte("header.payload"))
+		require.Error(t, err)
+	})
+}
+
+func TestDecodeSignature(t *testing.T) {
+	sig, err := decodeSignature("AQID\n")
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2, 3}, sig)
+
+	sig, err = decodeSignature("AQI=")
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2}, sig)
+
+	_, err = decodeSignature(" ")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "empty signature")
+
+	_, err = decodeSignature("A+/*")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "expecting base64url encoding")
+}
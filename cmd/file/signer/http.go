@@ -0,0 +1,86 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package signer
+
+import (
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+)
+
+// SignRequest is the request that is posted to an HTTP signing service
+type SignRequest struct {
+	// Algorithm is the JWS algorithm
+	Algorithm string `json:"alg"`
+	// Input is the base64url-encoded JWS signing input
+	Input string `json:"input"`
+}
+
+// SignResponse is the response from an HTTP signing service
+type SignResponse struct {
+	// Signature is the base64url-encoded signature
+	Signature string `json:"signature"`
+}
+
+// httpSigner signs by posting the JWS signing input to a signing service
+type httpSigner struct {
+	url       string
+	client    HTTPClient
+	headers   jws.Headers
+	publicKey crypto.PublicKey
+}
+
+func newHTTPSigner(url string, publicKey crypto.PublicKey, client HTTPClient) (*httpSigner, error) {
+	h, err := headers(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &httpSigner{url: url, client: client, headers: h, publicKey: publicKey}, nil
+}
+
+func (s *httpSigner) Sign(data []byte) ([]byte, error) {
+	reqBytes, err := json.Marshal(&SignRequest{
+		Algorithm: s.headers[jws.HeaderAlgorithm].(string),
+		Input:     base64.RawURLEncoding.EncodeToString(data),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Post(s.url, reqBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("error from signing service [%s]. Status code %d: %s", s.url, resp.StatusCode, resp.ErrorMsg)
+	}
+
+	signResp := &SignResponse{}
+	if err := json.Unmarshal(resp.Payload, signResp); err != nil {
+		return nil, errors.WithMessagef(err, "invalid response from signing service [%s]", s.url)
+	}
+
+	return decodeSignature(signResp.Signature)
+}
+
+func (s *httpSigner) Headers() jws.Headers {
+	return s.headers
+}
+
+func (s *httpSigner) PublicKey() crypto.PublicKey {
+	return s.publicKey
+}
+
+func (s *httpSigner) Close() error {
+	return nil
+}
@@ -0,0 +1,75 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package signer
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/httpclient"
+	"github.com/trustbloc/fabric-cli-ext/cmd/keyutil"
+)
+
+const signingServiceURL = "https://localhost:9999/sign"
+
+func TestHTTPSigner(t *testing.T) {
+	publicKey := ed25519.PublicKey(make([]byte, ed25519.PublicKeySize))
+
+	t.Run("Success", func(t *testing.T) {
+		c := &mockHTTPClient{response: &httpclient.HTTPResponse{StatusCode: http.StatusOK, Payload: []byte(`{"signature":"AQID"}`)}}
+
+		s, err := New(signingServiceURL, publicKey, c)
+		require.NoError(t, err)
+
+		sig, err := s.Sign([]byte("header.payload"))
+		require.NoError(t, err)
+		require.Equal(t, []byte{1, 2, 3}, sig)
+		require.Equal(t, signingServiceURL, c.url)
+
+		signReq := &SignRequest{}
+		require.NoError(t, json.Unmarshal(c.req, signReq))
+		require.Equal(t, keyutil.AlgEdDSA, signReq.Algorithm)
+		require.Equal(t, "aGVhZGVyLnBheWxvYWQ", signReq.Input)
+	})
+
+	t.Run("Server error", func(t *testing.T) {
+		c := &mockHTTPClient{response: &httpclient.HTTPResponse{StatusCode: http.StatusForbidden, ErrorMsg: "forbidden"}}
+
+		s, err := New(signingServiceURL, publicKey, c)
+		require.NoError(t, err)
+
+		_, err = s.Sign([]byte("header.payload"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "Status code 403: forbidden")
+	})
+
+	t.Run("Invalid response", func(t *testing.T) {
+		c := &mockHTTPClient{response: &httpclient.HTTPResponse{StatusCode: http.StatusOK, Payload: []byte(`{`)}}
+
+		s, err := New(signingServiceURL, publicKey, c)
+		require.NoError(t, err)
+
+		_, err = s.Sign([]byte("header.payload"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid response from signing service")
+	})
+
+	t.Run("Client error", func(t *testing.T) {
+		errExpected := errors.New("injected client error")
+
+		s, err := New(signingServiceURL, publicKey, &mockHTTPClient{err: errExpected})
+		require.NoError(t, err)
+
+		_, err = s.Sign([]byte("header.payload"))
+		require.EqualError(t, err, errExpected.Error())
+	})
+}
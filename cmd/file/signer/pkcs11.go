@@ -0,0 +1,322 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package signer
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/asn1"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/miekg/pkcs11"
+	"github.com/pkg/errors"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+)
+
+const (
+	pkcs11LibOpt   = "lib"
+	pkcs11TokenOpt = "token"
+	pkcs11PinOpt   = "pin"
+	pkcs11LabelOpt = "label"
+)
+
+var (
+	oidCurveP256      = asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}
+	oidCurveP384      = asn1.ObjectIdentifier{1, 3, 132, 0, 34}
+	oidCurveP521      = asn1.ObjectIdentifier{1, 3, 132, 0, 35}
+	oidCurveSecp256k1 = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+)
+
+type pkcs11Spec struct {
+	lib   string
+	token string
+	pin   string
+	label string
+}
+
+// parsePKCS11Spec parses a spec of the form pkcs11:lib=<library>;token=<token label>;pin=<user PIN>;label=<key label>
+func parsePKCS11Spec(spec string) (*pkcs11Spec, error) {
+	opts := make(map[string]string)
+
+	for _, opt := range strings.Split(strings.TrimPrefix(spec, pkcs11Prefix), ";") {
+		if strings.TrimSpace(opt) == "" {
+			continue
+		}
+
+		kv := strings.SplitN(opt, "=", 2)
+		if len(kv) != 2 {
+			return nil, errors.Errorf("invalid PKCS#11 signer option [%s] - expecting name=value", opt)
+		}
+
+		opts[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+
+	s := &pkcs11Spec{
+		lib:   opts[pkcs11LibOpt],
+		token: opts[pkcs11TokenOpt],
+		pin:   opts[pkcs11PinOpt],
+		label: opts[pkcs11LabelOpt],
+	}
+
+	for name, value := range map[string]string{pkcs11LibOpt: s.lib, pkcs11TokenOpt: s.token, pkcs11LabelOpt: s.label} {
+		if value == "" {
+			return nil, errors.Errorf("PKCS#11 signer option [%s] is required", name)
+		}
+	}
+
+	return s, nil
+}
+
+// pkcs11Signer signs with an EC private key that is held in a PKCS#11 token
+type pkcs11Signer struct {
+	ctx        *pkcs11.Ctx
+	session    pkcs11.SessionHandle
+	privateKey pkcs11.ObjectHandle
+	publicKey  *ecdsa.PublicKey
+	headers    jws.Headers
+}
+
+func newPKCS11Signer(spec string) (*pkcs11Signer, error) {
+	opts, err := parsePKCS11Spec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := pkcs11.New(opts.lib)
+	if ctx == nil {
+		return nil, errors.Errorf("unable to load PKCS#11 library [%s]", opts.lib)
+	}
+
+	s := &pkcs11Signer{ctx: ctx}
+
+	if err := s.init(opts); err != nil {
+		ctx.Destroy()
+
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *pkcs11Signer) init(opts *pkcs11Spec) error {
+	if err := s.ctx.Initialize(); err != nil {
+		return errors.WithMessage(err, "error initializing PKCS#11 library")
+	}
+
+	slot, err := s.findSlot(opts.token)
+	if err != nil {
+		return s.finalize(err)
+	}
+
+	s.session, err = s.ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION)
+	if err != nil {
+		return s.finalize(errors.WithMessage(err, "error opening PKCS#11 session"))
+	}
+
+	if opts.pin != "" {
+		if err := s.ctx.Login(s.session, pkcs11.CKU_USER, opts.pin); err != nil {
+			return s.closeSession(errors.WithMessage(err, "error logging in to PKCS#11 token"))
+		}
+	}
+
+	s.privateKey, err = s.findObject(pkcs11.CKO_PRIVATE_KEY, opts.label)
+	if err != nil {
+		return s.closeSession(err)
+	}
+
+	publicKey, err := s.findObject(pkcs11.CKO_PUBLIC_KEY, opts.label)
+	if err != nil {
+		return s.closeSession(err)
+	}
+
+	s.publicKey, err = s.ecPublicKey(publicKey)
+	if err != nil {
+		return s.closeSession(err)
+	}
+
+	s.headers, err = headers(s.publicKey)
+	if err != nil {
+		return s.closeSession(err)
+	}
+
+	return nil
+}
+
+func (s *pkcs11Signer) Sign(data []byte) ([]byte, error) {
+	if err := s.ctx.SignInit(s.session, []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_ECDSA, nil)}, s.privateKey); err != nil {
+		return nil, errors.WithMessage(err, "error initializing PKCS#11 signature")
+	}
+
+	// CKM_ECDSA returns the signature as R || S, which is the JWS format
+	return s.ctx.Sign(s.session, digest(s.publicKey.Curve, data))
+}
+
+func (s *pkcs11Signer) Headers() jws.Headers {
+	return s.headers
+}
+
+func (s *pkcs11Signer) PublicKey() crypto.PublicKey {
+	return s.publicKey
+}
+
+func (s *pkcs11Signer) Close() error {
+	err := s.closeSession(nil)
+
+	s.ctx.Destroy()
+
+	return err
+}
+
+func (s *pkcs11Signer) findSlot(token string) (uint, error) {
+	slots, err := s.ctx.GetSlotList(true)
+	if err != nil {
+		return 0, errors.WithMessage(err, "error retrieving PKCS#11 slots")
+	}
+
+	for _, slot := range slots {
+		info, err := s.ctx.GetTokenInfo(slot)
+		if err != nil {
+			return 0, errors.WithMessage(err, "error retrieving PKCS#11 token info")
+		}
+
+		if info.Label == token {
+			return slot, nil
+		}
+	}
+
+	return 0, errors.Errorf("PKCS#11 token [%s] not found", token)
+}
+
+func (s *pkcs11Signer) findObject(class uint, label string) (pkcs11.ObjectHandle, error) {
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+	}
+
+	if err := s.ctx.FindObjectsInit(s.session, template); err != nil {
+		return 0, errors.WithMessage(err, "error finding PKCS#11 key")
+	}
+
+	objects, _, err := s.ctx.FindObjects(s.session, 1)
+
+	if e := s.ctx.FindObjectsFinal(s.session); e != nil && err == nil {
+		err = e
+	}
+
+	if err != nil {
+		return 0, errors.WithMessage(err, "error finding PKCS#11 key")
+	}
+
+	if len(objects) == 0 {
+		return 0, errors.Errorf("PKCS#11 key [%s] not found", label)
+	}
+
+	return objects[0], nil
+}
+
+func (s *pkcs11Signer) ecPublicKey(publicKey pkcs11.ObjectHandle) (*ecdsa.PublicKey, error) {
+	attrs, err := s.ctx.GetAttributeValue(s.session, publicKey, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, nil),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil),
+	})
+	if err != nil {
+		return nil, errors.WithMessage(err, "error retrieving PKCS#11 public key - only EC keys are supported")
+	}
+
+	var params, point []byte
+
+	for _, attr := range attrs {
+		switch attr.Type {
+		case pkcs11.CKA_EC_PARAMS:
+			params = attr.Value
+		case pkcs11.CKA_EC_POINT:
+			point = attr.Value
+		}
+	}
+
+	return ecPublicKeyFromPKCS11(params, point)
+}
+
+func (s *pkcs11Signer) closeSession(err error) error {
+	if e := s.ctx.CloseSession(s.session); e != nil && err == nil {
+		err = e
+	}
+
+	return s.finalize(err)
+}
+
+func (s *pkcs11Signer) finalize(err error) error {
+	if e := s.ctx.Finalize(); e != nil && err == nil {
+		err = e
+	}
+
+	return err
+}
+
+// ecPublicKeyFromPKCS11 returns the public key for the given DER-encoded curve OID (CKA_EC_PARAMS)
+// and DER-encoded point (CKA_EC_POINT)
+func ecPublicKeyFromPKCS11(params, point []byte) (*ecdsa.PublicKey, error) {
+	var oid asn1.ObjectIdentifier
+	if _, err := asn1.Unmarshal(params, &oid); err != nil {
+		return nil, errors.WithMessage(err, "invalid EC params")
+	}
+
+	// The point is usually wrapped in an octet string but some tokens return the raw point. Since
+	// an uncompressed point starts with the octet string tag, the whole point must be consumed.
+	var rawPoint []byte
+	if rest, err := asn1.Unmarshal(point, &rawPoint); err != nil || len(rest) != 0 {
+		rawPoint = point
+	}
+
+	if oid.Equal(oidCurveSecp256k1) {
+		publicKey, err := btcec.ParsePubKey(rawPoint, btcec.S256())
+		if err != nil {
+			return nil, errors.WithMessage(err, "invalid EC point")
+		}
+
+		return publicKey.ToECDSA(), nil
+	}
+
+	var curve elliptic.Curve
+
+	switch {
+	case oid.Equal(oidCurveP256):
+		curve = elliptic.P256()
+	case oid.Equal(oidCurveP384):
+		curve = elliptic.P384()
+	case oid.Equal(oidCurveP521):
+		curve = elliptic.P521()
+	default:
+		return nil, errors.Errorf("unsupported curve [%s]", oid)
+	}
+
+	x, y := elliptic.Unmarshal(curve, rawPoint) //nolint: staticcheck
+	if x == nil {
+		return nil, errors.New("invalid EC point")
+	}
+
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+// digest returns the digest of the given data using the hash algorithm that corresponds to the curve
+func digest(curve elliptic.Curve, data []byte) []byte {
+	switch curve {
+	case elliptic.P384():
+		d := sha512.Sum384(data)
+		return d[:]
+	case elliptic.P521():
+		d := sha512.Sum512(data)
+		return d[:]
+	default:
+		d := sha256.Sum256(data)
+		return d[:]
+	}
+}
@@ -0,0 +1,155 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package signer
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/stretchr/testify/require"
+)
+
+// pkcs11SignerEnv holds the spec of a PKCS#11 signer for an EC key in an existing token. For example, with SoftHSM:
+//
+//	softhsm2-util --init-token --free --label fabric --pin 1234 --so-pin 1234
+//	pkcs11-tool --module /usr/lib/softhsm/libsofthsm2.so --token-label fabric --login --pin 1234 \
+//	  --keypairgen --key-type EC:prime256v1 --label update
+//	export FABRIC_CLI_PKCS11_SIGNER="pkcs11:lib=/usr/lib/softhsm/libsofthsm2.so;token=fabric;pin=1234;label=update"
+const pkcs11SignerEnv = "FABRIC_CLI_PKCS11_SIGNER"
+
+func TestPKCS11Signer(t *testing.T) {
+	spec := os.Getenv(pkcs11SignerEnv)
+	if spec == "" {
+		t.Skipf("%s not set - skipping PKCS#11 signer test", pkcs11SignerEnv)
+	}
+
+	s, err := New(spec, nil, nil)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, s.Close()) }()
+
+	publicKey, ok := s.PublicKey().(*ecdsa.PublicKey)
+	require.True(t, ok)
+
+	sig, err := s.Sign([]byte("header.payload"))
+	require.NoError(t, err)
+
+	keySize := (publicKey.Curve.Params().BitSize + 7) / 8
+	require.Len(t, sig, 2*keySize)
+
+	r := new(big.Int).SetBytes(sig[:keySize])
+	sVal := new(big.Int).SetBytes(sig[keySize:])
+	require.True(t, ecdsa.Verify(publicKey, digest(publicKey.Curve, []byte("header.payload")), r, sVal))
+}
+
+func TestNewPKCS11Signer(t *testing.T) {
+	_, err := newPKCS11Signer("pkcs11:lib=./xxx.so;token=fabric")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "is required")
+
+	_, err = newPKCS11Signer("pkcs11:lib=./xxx.so;token=fabric;label=update")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unable to load PKCS#11 library")
+}
+
+func TestECPublicKeyFromPKCS11(t *testing.T) {
+	for oid, curve := range map[string]elliptic.Curve{
+		"P-256":     elliptic.P256(),
+		"P-384":     elliptic.P384(),
+		"P-521":     elliptic.P521(),
+		"secp256k1": btcec.S256(),
+	} {
+		params, point := pkcs11Attrs(t, curve)
+
+		publicKey, err := ecPublicKeyFromPKCS11(params, point)
+		require.NoError(t, err, oid)
+		require.Equal(t, curve, publicKey.Curve, oid)
+
+		// Raw point (not wrapped in an octet string)
+		var rawPoint []byte
+		_, err = asn1.Unmarshal(point, &rawPoint)
+		require.NoError(t, err)
+
+		publicKey2, err := ecPublicKeyFromPKCS11(params, rawPoint)
+		require.NoError(t, err, oid)
+		require.Equal(t, publicKey.X, publicKey2.X, oid)
+	}
+
+	params, point := pkcs11Attrs(t, elliptic.P256())
+
+	_, err := ecPublicKeyFromPKCS11([]byte("xxx"), point)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid EC params")
+
+	_, err = ecPublicKeyFromPKCS11(params, []byte{4, 1, 2})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid EC point")
+
+	p224Params, err := asn1.Marshal(asn1.ObjectIdentifier{1, 3, 132, 0, 33})
+	require.NoError(t, err)
+
+	_, err = ecPublicKeyFromPKCS11(p224Params, point)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unsupported curve")
+
+	k1Params, _ := pkcs11Attrs(t, btcec.S256())
+
+	_, err = ecPublicKeyFromPKCS11(k1Params, []byte{4, 1, 2})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid EC point")
+}
+
+func TestDigest(t *testing.T) {
+	d := sha256.Sum256([]byte("data"))
+	require.Equal(t, d[:], digest(elliptic.P256(), []byte("data")))
+	require.Len(t, digest(elliptic.P384(), []byte("data")), 48)
+	require.Len(t, digest(elliptic.P521(), []byte("data")), 64)
+}
+
+// pkcs11Attrs returns the CKA_EC_PARAMS and CKA_EC_POINT attribute values of a new key on the given curve
+func pkcs11Attrs(t *testing.T, curve elliptic.Curve) ([]byte, []byte) {
+	var oid asn1.ObjectIdentifier
+
+	switch curve {
+	case elliptic.P256():
+		oid = oidCurveP256
+	case elliptic.P384():
+		oid = oidCurveP384
+	case elliptic.P521():
+		oid = oidCurveP521
+	default:
+		oid = oidCurveSecp256k1
+	}
+
+	params, err := asn1.Marshal(oid)
+	require.NoError(t, err)
+
+	var x, y *big.Int
+
+	if curve == btcec.S256() {
+		privateKey, err := btcec.NewPrivateKey(btcec.S256())
+		require.NoError(t, err)
+
+		x, y = privateKey.X, privateKey.Y
+	} else {
+		privateKey, err := ecdsa.GenerateKey(curve, rand.Reader)
+		require.NoError(t, err)
+
+		x, y = privateKey.X, privateKey.Y
+	}
+
+	point, err := asn1.Marshal(elliptic.Marshal(curve, x, y)) //nolint: staticcheck
+	require.NoError(t, err)
+
+	return params, point
+}
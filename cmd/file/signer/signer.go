@@ -0,0 +1,159 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package signer
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/ecsigner"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/edsigner"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/client"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/httpclient"
+	"github.com/trustbloc/fabric-cli-ext/cmd/keyutil"
+)
+
+const (
+	pkcs11Prefix  = "pkcs11:"
+	commandPrefix = "cmd:"
+	httpPrefix    = "http://"
+	httpsPrefix   = "https://"
+)
+
+// ErrPublicKeyRequired indicates that the public key of a command or HTTP signer was not provided
+var ErrPublicKeyRequired = errors.New("the public key of the signer (--signerpublickeyfile) is required for command and HTTP signers")
+
+// Signer signs Sidetree operation requests. Sign is passed the JWS signing input and returns the signature
+// in JWS format (i.e. R || S for ECDSA keys). Close must be called once the signer is no longer needed.
+type Signer interface {
+	Sign(data []byte) ([]byte, error)
+	Headers() jws.Headers
+	PublicKey() crypto.PublicKey
+	Close() error
+}
+
+// Validate validates the given signer spec without connecting to the signer. The spec is one of:
+//
+//	pkcs11:lib=<library>;token=<token label>;pin=<user PIN>;label=<key label>
+//	cmd:<command> [args...]
+//	http(s)://<signing service URL>
+func Validate(spec string, hasPublicKey bool) error {
+	switch {
+	case strings.HasPrefix(spec, pkcs11Prefix):
+		_, err := parsePKCS11Spec(spec)
+		return err
+	case strings.HasPrefix(spec, commandPrefix):
+		if len(strings.Fields(strings.TrimPrefix(spec, commandPrefix))) == 0 {
+			return errors.Errorf("command not specified in signer [%s]", spec)
+		}
+	case strings.HasPrefix(spec, httpPrefix), strings.HasPrefix(spec, httpsPrefix):
+	default:
+		return errors.Errorf("unsupported signer [%s] - the signer must start with pkcs11:, cmd:, http:// or https://", spec)
+	}
+
+	if !hasPublicKey {
+		return ErrPublicKeyRequired
+	}
+
+	return nil
+}
+
+// New returns the signer for the given spec (see Validate). The public key must be provided for command
+// and HTTP signers. For PKCS#11 signers the public key is read from the token and the given key is ignored.
+func New(spec string, publicKey crypto.PublicKey, client HTTPClient) (Signer, error) {
+	if err := Validate(spec, publicKey != nil); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case strings.HasPrefix(spec, pkcs11Prefix):
+		return newPKCS11Signer(spec)
+	case strings.HasPrefix(spec, commandPrefix):
+		return newCommandSigner(strings.Fields(strings.TrimPrefix(spec, commandPrefix)), publicKey)
+	default:
+		return newHTTPSigner(spec, publicKey, client)
+	}
+}
+
+// HTTPClient is the HTTP client used by the HTTP signer
+type HTTPClient interface {
+	Post(url string, req []byte, opts ...httpclient.RequestOpt) (*httpclient.HTTPResponse, error)
+}
+
+// localSigner signs with a private key that is held in memory
+type localSigner struct {
+	signer    client.Signer
+	publicKey crypto.PublicKey
+}
+
+// NewLocal returns a signer for the given private key
+func NewLocal(privateKey crypto.PrivateKey) (Signer, error) {
+	cryptoSigner, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return nil, keyutil.ErrUnsupportedKey
+	}
+
+	alg, err := Algorithm(cryptoSigner.Public())
+	if err != nil {
+		return nil, errors.WithMessage(err, "unsupported signing key")
+	}
+
+	var s client.Signer
+
+	switch key := privateKey.(type) {
+	case *ecdsa.PrivateKey:
+		s = ecsigner.New(key, alg, "")
+	case ed25519.PrivateKey:
+		s = edsigner.New(key, alg, "")
+	default:
+		return nil, keyutil.ErrUnsupportedKey
+	}
+
+	return &localSigner{signer: s, publicKey: cryptoSigner.Public()}, nil
+}
+
+func (s *localSigner) Sign(data []byte) ([]byte, error) {
+	return s.signer.Sign(data)
+}
+
+func (s *localSigner) Headers() jws.Headers {
+	return s.signer.Headers()
+}
+
+func (s *localSigner) PublicKey() crypto.PublicKey {
+	return s.publicKey
+}
+
+func (s *localSigner) Close() error {
+	return nil
+}
+
+// Algorithm returns the JWS algorithm for the given public key. EC (P-256, P-384, P-521 and secp256k1)
+// and Ed25519 keys are supported since these are the key types supported by Sidetree.
+func Algorithm(publicKey crypto.PublicKey) (string, error) {
+	if _, ok := publicKey.(*rsa.PublicKey); ok {
+		return "", keyutil.ErrUnsupportedKey
+	}
+
+	return keyutil.Algorithm(publicKey)
+}
+
+// headers returns the JWS headers for the given public key
+func headers(publicKey crypto.PublicKey) (jws.Headers, error) {
+	alg, err := Algorithm(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return jws.Headers{jws.HeaderAlgorithm: alg}, nil
+}
@@ -0,0 +1,144 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package signer
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/httpclient"
+	"github.com/trustbloc/fabric-cli-ext/cmd/keyutil"
+)
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, Validate("pkcs11:lib=/usr/lib/softhsm/libsofthsm2.so;token=fabric;pin=1234;label=update", false))
+	require.NoError(t, Validate("pkcs11:lib=/usr/lib/softhsm/libsofthsm2.so;token=fabric;label=update", false))
+	require.NoError(t, Validate("cmd:sign --key update", true))
+	require.NoError(t, Validate("http://localhost:9999/sign", true))
+	require.NoError(t, Validate("https://localhost:9999/sign", true))
+
+	require.EqualError(t, Validate("cmd:sign", false), ErrPublicKeyRequired.Error())
+	require.EqualError(t, Validate("https://localhost:9999/sign", false), ErrPublicKeyRequired.Error())
+
+	err := Validate("cmd: ", true)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "command not specified")
+
+	err = Validate("pkcs11:lib=/usr/lib/softhsm/libsofthsm2.so;token=fabric", false)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "PKCS#11 signer option [label] is required")
+
+	err = Validate("pkcs11:lib=/usr/lib/softhsm/libsofthsm2.so;token", false)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "expecting name=value")
+
+	err = Validate("file:./update.key", true)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unsupported signer")
+}
+
+func TestNew(t *testing.T) {
+	publicKey := &ecdsa.PublicKey{Curve: elliptic.P256()}
+
+	s, err := New("cmd:sign", publicKey, nil)
+	require.NoError(t, err)
+	require.IsType(t, &commandSigner{}, s)
+	require.Equal(t, publicKey, s.PublicKey())
+	require.NoError(t, s.Close())
+
+	s, err = New("https://localhost:9999/sign", publicKey, &mockHTTPClient{})
+	require.NoError(t, err)
+	require.IsType(t, &httpSigner{}, s)
+	require.NoError(t, s.Close())
+
+	_, err = New("cmd:sign", nil, nil)
+	require.EqualError(t, err, ErrPublicKeyRequired.Error())
+
+	_, err = New("cmd:sign", &rsa.PublicKey{}, nil)
+	require.Error(t, err)
+
+	_, err = New("pkcs11:lib=./xxx.so;token=fabric;label=update", nil, nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unable to load PKCS#11 library")
+}
+
+func TestNewLocal(t *testing.T) {
+	for keyType, alg := range map[string]string{
+		keyutil.KeyTypeP256:      keyutil.AlgES256,
+		keyutil.KeyTypeSecp256k1: keyutil.AlgES256K,
+		keyutil.KeyTypeEd25519:   keyutil.AlgEdDSA,
+	} {
+		privateKeyPEM, _, err := keyutil.GenerateKeyPair(keyType)
+		require.NoError(t, err)
+
+		privateKey, err := keyutil.PrivateKeyFromPEM(privateKeyPEM)
+		require.NoError(t, err)
+
+		s, err := NewLocal(privateKey)
+		require.NoError(t, err, keyType)
+		require.Equal(t, alg, s.Headers()[jws.HeaderAlgorithm], keyType)
+		require.NotNil(t, s.PublicKey())
+
+		sig, err := s.Sign([]byte("data"))
+		require.NoError(t, err)
+		require.NotEmpty(t, sig)
+		require.NoError(t, s.Close())
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	_, err = NewLocal(rsaKey)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unsupported signing key")
+
+	_, err = NewLocal("xxx")
+	require.Error(t, err)
+}
+
+func TestAlgorithm(t *testing.T) {
+	for alg, publicKey := range map[string]interface{}{
+		keyutil.AlgES256:  &ecdsa.PublicKey{Curve: elliptic.P256()},
+		keyutil.AlgES384:  &ecdsa.PublicKey{Curve: elliptic.P384()},
+		keyutil.AlgES512:  &ecdsa.PublicKey{Curve: elliptic.P521()},
+		keyutil.AlgES256K: &ecdsa.PublicKey{Curve: btcec.S256()},
+		keyutil.AlgEdDSA:  ed25519.PublicKey{},
+	} {
+		a, err := Algorithm(publicKey)
+		require.NoError(t, err)
+		require.Equal(t, alg, a)
+	}
+
+	_, err := Algorithm(&ecdsa.PublicKey{Curve: elliptic.P224()})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unsupported curve")
+
+	_, err = Algorithm(&rsa.PublicKey{})
+	require.Error(t, err)
+}
+
+type mockHTTPClient struct {
+	response *httpclient.HTTPResponse
+	err      error
+	url      string
+	req      []byte
+}
+
+func (m *mockHTTPClient) Post(url string, req []byte, _ ...httpclient.RequestOpt) (*httpclient.HTTPResponse, error) {
+	m.url = url
+	m.req = req
+
+	return m.response, m.err
+}
@@ -0,0 +1,252 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package submitcmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/basecmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/fileidx"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/httpclient"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/model"
+)
+
+const (
+	use      = "submit"
+	desc     = "Submit a previously generated Sidetree request for a file index document"
+	longDesc = `
+The submit command posts a signed Sidetree request that was previously generated offline (e.g. on an air-gapped machine) using 'file createidx --out' or 'file upload --out'.
+For an update request, the files contained in the request are first uploaded to DCAS (at the content URL given when the request was generated) and their DCAS IDs are verified against
+the IDs that were computed when the request was generated. The response is the same as the response of the command that generated the request.
+`
+	examples = `
+- Submit a create request:
+    $ ./fabric file submit --url http://localhost:48326/file --request ./create-request.json --noprompt
+
+	Response:
+		{
+		  ".": "/content",
+		  "id": "file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA=="
+		}
+
+- Submit an update request generated by 'file upload --out':
+    $ ./fabric file submit --url http://localhost:48326/file --request ./update-request.json --authtoken mytoken --contentauthtoken mycontenttoken --noprompt
+`
+)
+
+const (
+	urlFlag  = "url"
+	urlUsage = "The URL of the file index Sidetree endpoint (or of its operations endpoint) to which to submit the request. Example: --url http://localhost:48326/file"
+
+	requestFlag  = "request"
+	requestUsage = "The file that contains the request generated by 'file createidx --out' or 'file upload --out'. Example: --request ./request.json"
+
+	noPromptFlag  = "noprompt"
+	noPromptUsage = "If specified then the submit operation will not prompt for confirmation. Example: --noprompt"
+
+	msgAborted         = "Operation aborted"
+	msgContinueOrAbort = "Enter Y to continue or N to abort "
+)
+
+var (
+	errURLRequired     = errors.New("URL (--url) is required")
+	errRequestRequired = errors.New("request (--request) is required")
+)
+
+// New returns the file submit sub-command
+func New(settings *environment.Settings) *cobra.Command {
+	cfg := httpclient.NewConfig(settings)
+
+	cmd := newCmd(settings, httpclient.New(httpclient.WithConfig(cfg)))
+	cfg.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+func newCmd(settings *environment.Settings, client fileidx.HTTPClient) *cobra.Command {
+	c := &command{
+		Command: basecmd.New(settings, nil),
+		client:  client,
+	}
+
+	cmd := &cobra.Command{
+		Use:     use,
+		Short:   desc,
+		Long:    longDesc,
+		Example: examples,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return c.validate()
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return c.run()
+		},
+	}
+
+	cmd.SetOutput(c.Settings.Streams.Out)
+	cmd.SilenceUsage = true
+
+	cmd.Flags().StringVar(&c.url, urlFlag, "", urlUsage)
+	cmd.Flags().StringVar(&c.requestFile, requestFlag, "", requestUsage)
+	httpclient.AddAuthTokenFlag(cmd.Flags(), &c.authToken, "the URL specified by --url")
+	httpclient.AddContentAuthTokenFlag(cmd.Flags(), &c.contentAuthToken, "upload the files of an update request to DCAS")
+	cmd.Flags().BoolVar(&c.noPrompt, noPromptFlag, false, noPromptUsage)
+
+	return cmd
+}
+
+// command implements the submit command
+type command struct {
+	*basecmd.Command
+	client fileidx.HTTPClient
+
+	url              string
+	requestFile      string
+	authToken        string
+	contentAuthToken string
+	noPrompt         bool
+}
+
+// uploadedFile is the response entry for each file of an update request (the same as for 'file upload')
+type uploadedFile struct {
+	Name        string
+	ID          string
+	ContentType string
+}
+
+func (c *command) validate() error {
+	if c.url == "" {
+		return errURLRequired
+	}
+
+	if c.requestFile == "" {
+		return errRequestRequired
+	}
+
+	if c.contentAuthToken == "" {
+		c.contentAuthToken = c.authToken
+	}
+
+	return nil
+}
+
+func (c *command) run() error {
+	r, err := fileidx.ReadRequest(c.requestFile)
+	if err != nil {
+		return err
+	}
+
+	if !c.noPrompt {
+		confirmed, e := c.confirm(r)
+		if e != nil {
+			return e
+		}
+
+		if !confirmed {
+			return c.Fprintln(msgAborted)
+		}
+	}
+
+	files, err := c.upload(r)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.post(r.Request)
+	if err != nil {
+		return err
+	}
+
+	if r.Operation == fileidx.OperationCreate {
+		return c.printDoc(resp.Payload)
+	}
+
+	filesBytes, err := json.Marshal(files)
+	if err != nil {
+		return err
+	}
+
+	return c.Fprint(string(filesBytes))
+}
+
+// upload uploads the files of the request to DCAS and ensures that their IDs match the IDs in the request
+func (c *command) upload(r *fileidx.OfflineRequest) ([]*uploadedFile, error) {
+	files := make([]*uploadedFile, 0, len(r.Files))
+
+	for _, f := range r.Files {
+		id, err := fileidx.Upload(c.client, r.ContentURL, c.contentAuthToken, f.ContentType, f.Content)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "error uploading file [%s]", f.Name)
+		}
+
+		if id != f.ID {
+			return nil, errors.Errorf("the DCAS ID [%s] of file [%s] doesn't match the ID [%s] in the request", id, f.Name, f.ID)
+		}
+
+		files = append(files, &uploadedFile{Name: f.Name, ID: id, ContentType: f.ContentType})
+	}
+
+	return files, nil
+}
+
+func (c *command) post(req []byte) (*httpclient.HTTPResponse, error) {
+	var reqOpts []httpclient.RequestOpt
+	if c.authToken != "" {
+		reqOpts = append(reqOpts, httpclient.WithAuthToken(c.authToken))
+	}
+
+	resp, err := c.client.Post(fileidx.EndpointURL(c.url)+"/operations", req, reqOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		if resp.StatusCode == http.StatusUnauthorized {
+			return nil, errors.Errorf("status code %d: %s - Did you provide an authorization token (--authtoken)?", resp.StatusCode, resp.ErrorMsg)
+		}
+
+		return nil, errors.Errorf("status code %d: %s", resp.StatusCode, resp.ErrorMsg)
+	}
+
+	return resp, nil
+}
+
+// printDoc prints the document in the response of a create request
+func (c *command) printDoc(payload []byte) error {
+	var r model.DIDResolution
+	if err := json.Unmarshal(payload, &r); err != nil {
+		return fmt.Errorf("unmarshal data return from sidtree %w", err)
+	}
+
+	didDocBytes := payload
+	// check if data is did resolution
+	if len(r.DIDDocument) != 0 {
+		didDocBytes = r.DIDDocument
+	}
+
+	return c.Fprint(string(didDocBytes))
+}
+
+// confirm prompts the user for confirmation of the submit
+func (c *command) confirm(r *fileidx.OfflineRequest) (bool, error) {
+	prompt := fmt.Sprintf("Submitting %s request to [%s]", r.Operation, c.url)
+	for _, f := range r.Files {
+		prompt += fmt.Sprintf("\n  %s (%s) to [%s]", f.Name, f.ID, r.ContentURL)
+	}
+
+	if err := c.Fprintln(prompt + "\n" + msgContinueOrAbort); err != nil {
+		return false, err
+	}
+
+	return strings.ToLower(c.Prompt()) == "y", nil
+}
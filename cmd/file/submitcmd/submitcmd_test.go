@@ -0,0 +1,215 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package submitcmd
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/fileidx"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/httpclient"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/model"
+	"github.com/trustbloc/fabric-cli-ext/cmd/mocks"
+)
+
+const (
+	url        = "http://localhost:48326/file"
+	contentURL = "http://localhost:48326/content"
+	docID      = "file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA"
+)
+
+func TestNew(t *testing.T) {
+	require.NotNil(t, New(environment.NewDefaultSettings()))
+}
+
+func TestSubmitCmd_InvalidOptions(t *testing.T) {
+	t.Run("No --url", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, "--request", "./request.json").Execute(), errURLRequired.Error())
+	})
+
+	t.Run("No --request", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, "--url", url).Execute(), errRequestRequired.Error())
+	})
+}
+
+func TestSubmitCmd(t *testing.T) {
+	dir, err := ioutil.TempDir("", "submitcmd")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+	content := []byte(`{"title":"Person"}`)
+
+	fileID, err := fileidx.DCASID("application/json", content)
+	require.NoError(t, err)
+
+	createReqFile := filepath.Join(dir, "create.json")
+	require.NoError(t, fileidx.WriteRequest(createReqFile, &fileidx.OfflineRequest{
+		Operation: fileidx.OperationCreate,
+		Request:   json.RawMessage(`{"type":"create"}`),
+	}))
+
+	updateReqFile := filepath.Join(dir, "update.json")
+	require.NoError(t, fileidx.WriteRequest(updateReqFile, &fileidx.OfflineRequest{
+		Operation:  fileidx.OperationUpdate,
+		Request:    json.RawMessage(`{"type":"update"}`),
+		ContentURL: contentURL,
+		Files:      []*fileidx.OfflineFile{{Name: "person.schema.json", ID: fileID, ContentType: "application/json", Content: content}},
+	}))
+
+	docBytes, err := json.Marshal(&model.FileIndexDoc{ID: docID, FileIndex: model.FileIndex{BasePath: "/content"}})
+	require.NoError(t, err)
+
+	resolutionBytes, err := json.Marshal(&model.DIDResolution{DIDDocument: docBytes})
+	require.NoError(t, err)
+
+	t.Run("Create", func(t *testing.T) {
+		c := &mockHTTPClient{responses: map[string]*httpclient.HTTPResponse{
+			url + "/operations": {StatusCode: http.StatusOK, Payload: resolutionBytes},
+		}}
+
+		w := &mocks.Writer{}
+		require.NoError(t, newMockCmdWithReaderWriter(t, &mocks.Reader{Bytes: []byte("Y\n")}, w, c, "--url", url+"/operations", "--request", createReqFile).Execute())
+		require.Contains(t, w.Written(), "Submitting create request to")
+		require.Contains(t, w.Written(), string(docBytes))
+		require.Equal(t, `{"type":"create"}`, string(c.posted[url+"/operations"]))
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		c := &mockHTTPClient{responses: map[string]*httpclient.HTTPResponse{
+			contentURL:          {StatusCode: http.StatusOK, Payload: []byte(`"` + fileID + `"`)},
+			url + "/operations": {StatusCode: http.StatusOK},
+		}}
+
+		w := &mocks.Writer{}
+		require.NoError(t, newMockCmdWithReaderWriter(t, &mocks.Reader{}, w, c, "--url", url, "--request", updateReqFile, "--noprompt").Execute())
+		require.Contains(t, w.Written(), `[{"Name":"person.schema.json","ID":"`+fileID+`","ContentType":"application/json"}]`)
+		require.Equal(t, `{"type":"update"}`, string(c.posted[url+"/operations"]))
+
+		file := &fileidx.File{}
+		require.NoError(t, json.Unmarshal(c.posted[contentURL], file))
+		require.Equal(t, content, file.Content)
+	})
+
+	t.Run("Update - DCAS ID mismatch", func(t *testing.T) {
+		c := &mockHTTPClient{responses: map[string]*httpclient.HTTPResponse{
+			contentURL: {StatusCode: http.StatusOK, Payload: []byte(`"xxx"`)},
+		}}
+
+		err := newMockCmd(t, c, "--url", url, "--request", updateReqFile, "--noprompt").Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "the DCAS ID [xxx] of file [person.schema.json] doesn't match")
+		require.Empty(t, c.posted[url+"/operations"])
+	})
+
+	t.Run("Update - upload error", func(t *testing.T) {
+		c := &mockHTTPClient{responses: map[string]*httpclient.HTTPResponse{
+			contentURL: {StatusCode: http.StatusUnauthorized, ErrorMsg: "Unauthorized"},
+		}}
+
+		err := newMockCmd(t, c, "--url", url, "--request", updateReqFile, "--noprompt").Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "error uploading file [person.schema.json]")
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		c := &mockHTTPClient{responses: map[string]*httpclient.HTTPResponse{
+			url + "/operations": {StatusCode: http.StatusUnauthorized, ErrorMsg: "Unauthorized"},
+		}}
+
+		err := newMockCmd(t, c, "--url", url, "--request", createReqFile, "--noprompt").Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "Did you provide an authorization token (--authtoken)?")
+	})
+
+	t.Run("Server error", func(t *testing.T) {
+		c := &mockHTTPClient{responses: map[string]*httpclient.HTTPResponse{
+			url + "/operations": {StatusCode: http.StatusInternalServerError, ErrorMsg: "server error"},
+		}}
+
+		err := newMockCmd(t, c, "--url", url, "--request", createReqFile, "--noprompt").Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "server error")
+	})
+
+	t.Run("Client error", func(t *testing.T) {
+		errExpected := errors.New("injected error")
+
+		err := newMockCmd(t, &mockHTTPClient{err: errExpected}, "--url", url, "--request", createReqFile, "--noprompt").Execute()
+		require.EqualError(t, err, errExpected.Error())
+	})
+
+	t.Run("Request not found", func(t *testing.T) {
+		err := newMockCmd(t, &mockHTTPClient{}, "--url", url, "--request", filepath.Join(dir, "xxx.json"), "--noprompt").Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "error reading request")
+	})
+
+	t.Run("With prompt - N", func(t *testing.T) {
+		c := &mockHTTPClient{}
+
+		w := &mocks.Writer{}
+		require.NoError(t, newMockCmdWithReaderWriter(t, &mocks.Reader{Bytes: []byte("N\n")}, w, c, "--url", url, "--request", updateReqFile).Execute())
+		require.Contains(t, w.Written(), "person.schema.json ("+fileID+") to ["+contentURL+"]")
+		require.Contains(t, w.Written(), msgAborted)
+		require.Empty(t, c.posted)
+	})
+}
+
+// mockHTTPClient returns the response for the URL of each POST request and records the posted requests
+type mockHTTPClient struct {
+	responses map[string]*httpclient.HTTPResponse
+	err       error
+	posted    map[string][]byte
+}
+
+func (m *mockHTTPClient) Get(string, ...httpclient.RequestOpt) (*httpclient.HTTPResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockHTTPClient) Post(url string, req []byte, _ ...httpclient.RequestOpt) (*httpclient.HTTPResponse, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	if m.posted == nil {
+		m.posted = make(map[string][]byte)
+	}
+
+	m.posted[url] = req
+
+	return m.responses[url], nil
+}
+
+func newMockCmd(t *testing.T, client fileidx.HTTPClient, args ...string) *cobra.Command {
+	return newMockCmdWithReaderWriter(t, &mocks.Reader{}, &mocks.Writer{}, client, args...)
+}
+
+func newMockCmdWithReaderWriter(t *testing.T, in io.Reader, w io.Writer, client fileidx.HTTPClient, args ...string) *cobra.Command {
+	settings := environment.NewDefaultSettings()
+	settings.Streams.Out = w
+	settings.Streams.In = in
+
+	settings.Config.CurrentContext = "testctx"
+	settings.Config.Contexts[settings.Config.CurrentContext] = &environment.Context{}
+
+	c := newCmd(settings, client)
+	require.NotNil(t, c)
+
+	c.SetArgs(args)
+
+	return c
+}
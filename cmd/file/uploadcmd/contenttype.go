@@ -0,0 +1,176 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package uploadcmd
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Sources of the content type of a file
+const (
+	contentTypeFromOverride  = "override"
+	contentTypeFromExtension = "extension"
+	contentTypeFromContent   = "sniffed"
+)
+
+// sniffLen is the number of bytes that are considered when sniffing the content type of a file
+const sniffLen = 512
+
+// extensionContentTypes maps file extensions to content types. A built-in table is used rather than the host's MIME
+// tables so that a file is given the same content type (and therefore the same DCAS ID) on every machine. The entries
+// for the extensions known to Go's own table have the same content types so that the DCAS IDs of previously uploaded
+// files are unchanged.
+var extensionContentTypes = map[string]string{
+	".avif":   "image/avif",
+	".bmp":    "image/bmp",
+	".cbor":   "application/cbor",
+	".css":    "text/css; charset=utf-8",
+	".csv":    "text/csv; charset=utf-8",
+	".gif":    "image/gif",
+	".gz":     "application/gzip",
+	".htm":    "text/html; charset=utf-8",
+	".html":   "text/html; charset=utf-8",
+	".ico":    "image/x-icon",
+	".jpeg":   "image/jpeg",
+	".jpg":    "image/jpeg",
+	".js":     "text/javascript; charset=utf-8",
+	".json":   "application/json",
+	".jsonld": "application/ld+json",
+	".md":     "text/markdown; charset=utf-8",
+	".mjs":    "text/javascript; charset=utf-8",
+	".mp3":    "audio/mpeg",
+	".mp4":    "video/mp4",
+	".otf":    "font/otf",
+	".pdf":    "application/pdf",
+	".pem":    "application/x-pem-file",
+	".png":    "image/png",
+	".svg":    "image/svg+xml",
+	".tar":    "application/x-tar",
+	".tif":    "image/tiff",
+	".tiff":   "image/tiff",
+	".ttf":    "font/ttf",
+	".txt":    "text/plain; charset=utf-8",
+	".wasm":   "application/wasm",
+	".webm":   "video/webm",
+	".webp":   "image/webp",
+	".woff":   "font/woff",
+	".woff2":  "font/woff2",
+	".xml":    "text/xml; charset=utf-8",
+	".yaml":   "application/yaml",
+	".yml":    "application/yaml",
+	".zip":    "application/zip",
+}
+
+// contentTypeOverride sets the content type of the files whose name matches the pattern
+type contentTypeOverride struct {
+	pattern     string
+	contentType string
+}
+
+// parseContentTypeOverrides parses the name=type overrides given by --contenttype
+func parseContentTypeOverrides(items []string) ([]*contentTypeOverride, error) {
+	var overrides []*contentTypeOverride
+
+	for _, item := range items {
+		p := strings.Index(item, "=")
+		if p < 1 || p == len(item)-1 {
+			return nil, errors.Errorf("invalid content type override [%s] - expecting name=type", item)
+		}
+
+		pattern := strings.TrimSpace(item[:p])
+		contentType := strings.TrimSpace(item[p+1:])
+
+		if err := validatePatterns([]string{pattern}); err != nil {
+			return nil, err
+		}
+
+		if _, _, err := mime.ParseMediaType(contentType); err != nil {
+			return nil, errors.WithMessagef(err, "invalid content type [%s]", contentType)
+		}
+
+		overrides = append(overrides, &contentTypeOverride{pattern: pattern, contentType: contentType})
+	}
+
+	return overrides, nil
+}
+
+// setContentType sets the content type of the given file. The content type is taken from the first override whose
+// pattern matches the file's name or, if none match, from the file's extension. If the file has no extension or the
+// extension is unknown then the content type is sniffed from the first bytes of the file.
+func setContentType(file *fileInfo, overrides []*contentTypeOverride) error {
+	for _, o := range overrides {
+		if matchesAny(file.Name, []string{o.pattern}) {
+			file.ContentType = o.contentType
+			file.ContentTypeSource = contentTypeFromOverride
+
+			return nil
+		}
+	}
+
+	contentType, err := contentTypeFromFileName(filepath.Base(file.Path))
+	if err == nil {
+		file.ContentType = contentType
+		file.ContentTypeSource = contentTypeFromExtension
+
+		return nil
+	}
+
+	contentType, err = sniffContentType(file.Path)
+	if err != nil {
+		return err
+	}
+
+	file.ContentType = contentType
+	file.ContentTypeSource = contentTypeFromContent
+
+	return nil
+}
+
+func contentTypeFromFileName(fileName string) (string, error) {
+	p := strings.LastIndex(fileName, ".")
+	if p == -1 {
+		return "", errNoFileExtension
+	}
+
+	contentType, ok := extensionContentTypes[strings.ToLower(fileName[p:])]
+	if !ok {
+		return "", errors.WithMessagef(errUnknownExtension, fileName)
+	}
+
+	return contentType, nil
+}
+
+// sniffContentType returns the content type of the file at the given path as determined by the first bytes of the file
+func sniffContentType(path string) (string, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return "", err
+	}
+
+	defer func() {
+		if err := f.Close(); err != nil {
+			fmt.Printf("Error closing file [%s]: %s", path, err)
+		}
+	}()
+
+	buf := make([]byte, sniffLen)
+
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", errors.WithMessagef(err, "error reading file [%s]", path)
+	}
+
+	return http.DetectContentType(buf[:n]), nil
+}
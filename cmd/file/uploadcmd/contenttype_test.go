@@ -0,0 +1,90 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package uploadcmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseContentTypeOverrides(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		overrides, err := parseContentTypeOverrides([]string{"README=text/markdown; charset=utf-8", " *.dat = application/cbor "})
+		require.NoError(t, err)
+		require.Equal(t, []*contentTypeOverride{
+			{pattern: "README", contentType: "text/markdown; charset=utf-8"},
+			{pattern: "*.dat", contentType: "application/cbor"},
+		}, overrides)
+	})
+
+	t.Run("Invalid entry", func(t *testing.T) {
+		for _, item := range []string{"README", "=text/plain", "README="} {
+			_, err := parseContentTypeOverrides([]string{item})
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "expecting name=type")
+		}
+	})
+
+	t.Run("Invalid pattern", func(t *testing.T) {
+		_, err := parseContentTypeOverrides([]string{"[=text/plain"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid pattern")
+	})
+
+	t.Run("Invalid content type", func(t *testing.T) {
+		_, err := parseContentTypeOverrides([]string{"README=text/"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid content type [text/]")
+	})
+}
+
+func TestSetContentType(t *testing.T) {
+	dir, err := ioutil.TempDir("", "uploadcmd")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+	writeFile := func(name string, content []byte) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, ioutil.WriteFile(path, content, 0600))
+
+		return path
+	}
+
+	pngHeader := []byte("\x89PNG\x0D\x0A\x1A\x0A")
+
+	overrides, err := parseContentTypeOverrides([]string{"docs/README=text/markdown; charset=utf-8", "*.dat=application/cbor"})
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name        string
+		path        string
+		contentType string
+		source      string
+	}{
+		{name: "person.schema.json", path: writeFile("person.schema.json", []byte("{}")), contentType: "application/json", source: contentTypeFromExtension},
+		{name: "IMAGE.PNG", path: writeFile("IMAGE.PNG", pngHeader), contentType: "image/png", source: contentTypeFromExtension},
+		{name: "docs/README", path: writeFile("README", []byte("# Title")), contentType: "text/markdown; charset=utf-8", source: contentTypeFromOverride},
+		{name: "values.dat", path: writeFile("values.dat", []byte{0xa0}), contentType: "application/cbor", source: contentTypeFromOverride},
+		{name: "image", path: writeFile("image", pngHeader), contentType: "image/png", source: contentTypeFromContent},
+		{name: "notes.xxx", path: writeFile("notes.xxx", []byte("some notes")), contentType: "text/plain; charset=utf-8", source: contentTypeFromContent},
+		{name: "empty", path: writeFile("empty", nil), contentType: "text/plain; charset=utf-8", source: contentTypeFromContent},
+	} {
+		file := &fileInfo{Name: tc.name, Path: tc.path}
+		require.NoError(t, setContentType(file, overrides), tc.name)
+		require.Equal(t, tc.contentType, file.ContentType, tc.name)
+		require.Equal(t, tc.source, file.ContentTypeSource, tc.name)
+	}
+
+	t.Run("Sniff error", func(t *testing.T) {
+		err := setContentType(&fileInfo{Name: "xxx", Path: filepath.Join(dir, "xxx")}, nil)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,111 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package uploadcmd
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// getDirFiles recursively traverses the given directory and returns the files that match the include
+// patterns (all files if none are given) and do not match the exclude patterns. Each file is named by
+// its slash-separated path relative to the directory.
+func getDirFiles(dir string, include, exclude []string) (files, error) {
+	if err := validatePatterns(include); err != nil {
+		return nil, err
+	}
+
+	if err := validatePatterns(exclude); err != nil {
+		return nil, err
+	}
+
+	var f files
+
+	err := filepath.Walk(dir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+
+		if relPath == "." {
+			return nil
+		}
+
+		name := filepath.ToSlash(relPath)
+
+		if matchesAny(name, exclude) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if info.IsDir() || (len(include) > 0 && !matchesAny(name, include)) {
+			return nil
+		}
+
+		fileInfo, err := getFileInfo(name, filePath)
+		if err != nil {
+			return err
+		}
+
+		f = append(f, fileInfo)
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithMessagef(err, "error reading directory [%s]", dir)
+	}
+
+	return f, nil
+}
+
+// matchesAny returns true if either the given slash-separated path or its base name matches any of the patterns
+func matchesAny(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+
+		if ok, _ := path.Match(pattern, path.Base(name)); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func validatePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return errors.WithMessagef(err, "invalid pattern [%s]", pattern)
+		}
+	}
+
+	return nil
+}
+
+// splitList splits the given semi-colon separated list, ignoring empty entries
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ";") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
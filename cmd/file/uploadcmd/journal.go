@@ -0,0 +1,109 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package uploadcmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// journal records the files that were uploaded to DCAS for an update of a file index document. If an upload
+// fails then the index document isn't updated, so a re-run with --resume uses the journal to skip the files that
+// were already uploaded. The journal is removed once the index document is updated.
+type journal struct {
+	path  string
+	mutex sync.Mutex
+
+	IdxURL  string            `json:"idxUrl"`
+	URL     string            `json:"url"`
+	Uploads map[string]string `json:"uploads"`
+}
+
+// openJournal returns the journal at the given path. If resume is true then the uploads recorded by a previous run
+// (for the same index document and URL) are loaded, otherwise a new journal is started.
+func openJournal(path, idxURL, url string, resume bool) (*journal, error) {
+	j := &journal{
+		path:    path,
+		IdxURL:  idxURL,
+		URL:     url,
+		Uploads: make(map[string]string),
+	}
+
+	if !resume {
+		return j, nil
+	}
+
+	journalBytes, err := ioutil.ReadFile(filepath.Clean(path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return j, nil
+		}
+
+		return nil, errors.WithMessagef(err, "error reading upload journal [%s]", path)
+	}
+
+	previous := &journal{}
+	if err := json.Unmarshal(journalBytes, previous); err != nil {
+		return nil, errors.WithMessagef(err, "invalid upload journal [%s]", path)
+	}
+
+	if previous.IdxURL != idxURL || previous.URL != url {
+		return nil, errors.Errorf("the upload journal [%s] was recorded for index document [%s] and URL [%s] and cannot be resumed for index document [%s] and URL [%s]",
+			path, previous.IdxURL, previous.URL, idxURL, url)
+	}
+
+	for name, id := range previous.Uploads {
+		j.Uploads[name] = id
+	}
+
+	return j, nil
+}
+
+// uploaded returns true if the file with the given name and DCAS ID was already uploaded
+func (j *journal) uploaded(name, id string) bool {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	return j.Uploads[name] == id
+}
+
+// record records the upload of the file with the given name and DCAS ID and saves the journal
+func (j *journal) record(name, id string) error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	j.Uploads[name] = id
+
+	journalBytes, err := json.Marshal(j)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(j.path), 0700); err != nil {
+		return errors.WithMessagef(err, "error creating directory for upload journal [%s]", j.path)
+	}
+
+	if err := ioutil.WriteFile(filepath.Clean(j.path), journalBytes, 0600); err != nil {
+		return errors.WithMessagef(err, "error writing upload journal [%s]", j.path)
+	}
+
+	return nil
+}
+
+// remove removes the journal file (if it exists)
+func (j *journal) remove() error {
+	if err := os.Remove(j.path); err != nil && !os.IsNotExist(err) {
+		return errors.WithMessagef(err, "error removing upload journal [%s]", j.path)
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package uploadcmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournal(t *testing.T) {
+	const (
+		idxURL = "http://localhost:48326/file/identifiers/file:idx:1234"
+		url    = "http://localhost:48326/content"
+	)
+
+	dir, err := ioutil.TempDir("", "journal")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+	path := filepath.Join(dir, "journal", "upload.json")
+
+	j, err := openJournal(path, idxURL, url, true)
+	require.NoError(t, err)
+	require.Empty(t, j.Uploads)
+	require.NoError(t, j.remove())
+
+	require.NoError(t, j.record("a.json", "id1"))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	j, err = openJournal(path, idxURL, url, true)
+	require.NoError(t, err)
+	require.True(t, j.uploaded("a.json", "id1"))
+	require.False(t, j.uploaded("a.json", "id2"))
+	require.False(t, j.uploaded("b.json", "id1"))
+
+	// A new journal is started if the upload isn't resumed
+	j, err = openJournal(path, idxURL, url, false)
+	require.NoError(t, err)
+	require.False(t, j.uploaded("a.json", "id1"))
+
+	require.NoError(t, j.remove())
+
+	_, err = os.Stat(path)
+	require.True(t, os.IsNotExist(err))
+
+	t.Run("Invalid journal", func(t *testing.T) {
+		require.NoError(t, ioutil.WriteFile(path, []byte("{"), 0600))
+
+		_, err := openJournal(path, idxURL, url, true)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid upload journal")
+	})
+
+	t.Run("Read error", func(t *testing.T) {
+		_, err := openJournal(dir, idxURL, url, true)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "error reading upload journal")
+	})
+}
@@ -10,22 +10,14 @@ import (
 	"encoding/json"
 )
 
-type uploadFile struct {
-	ContentType string `json:"contentType"`
-	Content     []byte `json:"content"`
-}
-
 type fileInfo struct {
 	Name        string `json:",omitempty"`
+	Path        string `json:"-"`
 	ID          string `json:",omitempty"`
 	ContentType string `json:",omitempty"`
-	Content     []byte `json:",omitempty"`
-}
 
-type jsonPatch struct {
-	Op    string `json:"op"`
-	Path  string `json:"path"`
-	Value string `json:"value"`
+	// ContentTypeSource indicates how the content type was determined (override, extension or sniffed)
+	ContentTypeSource string `json:"-"`
 }
 
 type files []*fileInfo
@@ -7,32 +7,23 @@ SPDX-License-Identifier: Apache-2.0
 package uploadcmd
 
 import (
-	"crypto"
-	"crypto/ecdsa"
-	"crypto/x509"
-	"encoding/json"
-	"encoding/pem"
 	"fmt"
+	"io"
 	"io/ioutil"
-	"mime"
-	"net/http"
 	"net/url"
+	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"github.com/hyperledger/fabric-cli/pkg/environment"
 
-	"github.com/trustbloc/sidetree-core-go/pkg/commitment"
-	"github.com/trustbloc/sidetree-core-go/pkg/jws"
-	"github.com/trustbloc/sidetree-core-go/pkg/patch"
-	"github.com/trustbloc/sidetree-core-go/pkg/util/ecsigner"
-	"github.com/trustbloc/sidetree-core-go/pkg/util/pubkey"
-	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/client"
-
 	"github.com/trustbloc/fabric-cli-ext/cmd/basecmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/fileidx"
 	"github.com/trustbloc/fabric-cli-ext/cmd/file/httpclient"
 	"github.com/trustbloc/fabric-cli-ext/cmd/file/model"
 )
@@ -42,6 +33,26 @@ const (
 	desc     = "Upload a file to DCAS"
 	longDesc = `
 The upload command allows a client to upload one or more files to DCAS and add them to a Sidetree file index document. The response is a JSON document that contains the names of the files that were updated along with their DCAS ID and content-type.
+
+The files may be given explicitly (--files), in which case each file is indexed by its base name, or a directory may be given (--dir), in which case
+the directory is traversed recursively and each file is indexed by its path relative to the directory. The files in the directory may be filtered
+using --include and --exclude. Before any file is uploaded, a plan is displayed that shows which index mappings will be added and which will be replaced
+along with the content type of each file and how it was determined.
+
+The content type of a file is determined from its extension using a built-in table, so that a file is given the same content type on every machine.
+If the file has no extension or the extension is unknown then the content type is sniffed from the content of the file. The content type of specific
+files may be set with --contenttype.
+
+The DCAS ID of each file is computed locally and compared with the existing mapping in the file index. Files whose content is unchanged are not uploaded
+and the file index document is only updated if at least one mapping changes. Specify --force to upload all files regardless.
+
+The files are uploaded in parallel (--parallel) and uploads that fail with a transient error are retried (--retries). The index document is only updated once
+all files are uploaded. Each completed upload is recorded in a journal so that, if an upload fails, the command may be re-run with --resume in order to skip the
+files that were already uploaded.
+
+Each file is streamed from disk as it's uploaded, so files of any size may be uploaded without loading them into memory. By default a file is uploaded as a JSON
+request that contains its base64-encoded content. If the server supports it, a file may instead be uploaded as a raw request body or as a multipart/form-data
+request (--uploadmode), which avoids the overhead of base64 encoding.
 `
 	examples = `
 - Upload two files to the '/content' path and add index entries to the given file index document:
@@ -60,6 +71,30 @@ The upload command allows a client to upload one or more files to DCAS and add t
 			"ContentType": "image/png"
 		  }
 		]
+
+- Upload all JSON files (excluding those under the 'drafts' directory) in the './schemas' directory to the '/schema' path:
+    $ ./fabric file upload --url http://localhost:48326/schema --dir ./schemas --include *.json --exclude drafts --idxurl http://localhost:48326/file/file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA== --signingkeyfile ./keys/update.key --nextupdatekeyfile ./keys/next_update.pem
+
+- Upload a file using the update keys (which are then rotated) in the given key store:
+    $ ./fabric file upload --url http://localhost:48326/content --files ./person.schema.json --idxurl http://localhost:48326/file/identifiers/file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA== --keystore ~/.fabric/keystore
+
+- Upload a file and wait (for at most 1 minute) until the new mapping is anchored and may be resolved from the file index document:
+    $ ./fabric file upload --url http://localhost:48326/content --files ./person.schema.json --idxurl http://localhost:48326/file/identifiers/file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA== --keystore ~/.fabric/keystore --wait
+
+- Upload all files in the './schemas' directory, 8 at a time, resuming a previous upload that failed:
+    $ ./fabric file upload --url http://localhost:48326/schema --dir ./schemas --idxurl http://localhost:48326/file/identifiers/file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA== --keystore ~/.fabric/keystore --parallel 8 --resume
+
+- Upload a large binary file as a raw request body if the server supports it (falling back to a JSON request otherwise):
+    $ ./fabric file upload --url http://localhost:48326/content --files ./video.mp4 --idxurl http://localhost:48326/file/identifiers/file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA== --keystore ~/.fabric/keystore --uploadmode auto
+
+- Generate the signed update request for a file without contacting the Sidetree node or DCAS (e.g. on an air-gapped machine) and save it, along with the file, to be submitted later with 'file submit':
+    $ ./fabric file upload --url http://localhost:48326/content --files ./person.schema.json --idxurl http://localhost:48326/file/identifiers/file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA== --signingkeyfile ./keys/update.key --nextupdatekeyfile ./keys/next_update.pem --out ./update-request.json
+
+- Upload a file and sign the update of the file index document with a key that is held in a PKCS#11 token:
+    $ ./fabric file upload --url http://localhost:48326/content --files ./person.schema.json --idxurl http://localhost:48326/file/identifiers/file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA== --signer 'pkcs11:lib=/usr/lib/softhsm/libsofthsm2.so;token=fabric;pin=1234;label=update' --nextupdatekeyfile ./keys/next_update.pem
+
+- Upload a file and sign the update of the file index document using a signing service:
+    $ ./fabric file upload --url http://localhost:48326/content --files ./person.schema.json --idxurl http://localhost:48326/file/identifiers/file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA== --signer https://signer.example.com/sign --signerpublickeyfile ./keys/update_public.pem --nextupdatekeyfile ./keys/next_update.pem
 `
 )
 
@@ -67,68 +102,99 @@ const (
 	fileFlag  = "files"
 	fileUsage = "The semi-colin separated paths of the files to upload. Example: --files ./samples/content1.json;./samples/image.png"
 
+	dirFlag  = "dir"
+	dirUsage = "The directory containing the files to upload. The directory is traversed recursively. Example: --dir ./samples"
+
+	includeFlag  = "include"
+	includeUsage = "A semi-colon separated list of glob patterns of the files in --dir to upload. A pattern is matched against both the relative path and the name of the file. Example: --include *.json;*.png"
+
+	excludeFlag  = "exclude"
+	excludeUsage = "A semi-colon separated list of glob patterns of the files and directories in --dir to skip. A pattern is matched against both the relative path and the name of the file or directory. Example: --exclude drafts;*.tmp"
+
 	urlFlag  = "url"
 	urlUsage = "The URL to which to add the file(s). Example: --url http://localhost:48326/content"
 
 	fileIndexURLFlag  = "idxurl"
 	fileIndexURLUsage = "The URL of the file index Sidetree document to be updated with the new/updated files. Example: --idxurl http://localhost:48326/file/file:idx:1234"
 
-	authTokenFlag  = "authtoken"
-	authTokenUsage = "The bearer authorization token that may be required to access the URL specified by --idxurl. Example: --authtoken mytoken" //nolint: gosec
+	forceFlag  = "force"
+	forceUsage = "If specified then all files are uploaded and their mappings updated, even if the content of a file is unchanged. Example: --force"
 
-	contentAuthTokenFlag  = "contentauthtoken"
-	contentAuthTokenUsage = "The bearer authorization token to upload files to the URL specified by --url. This is only required if it is different from --authtoken. Example: --contentauthtoken mytoken" //nolint: gosec
+	waitFlag  = "wait"
+	waitUsage = "If specified then the command waits until the update of the index document is anchored, i.e. until the new mappings may be resolved with the expected update commitment, and then reports how long anchoring took. An optional timeout may be given (the default is 1m). Example: --wait or --wait=2m"
 
-	fileIndexNextUpdateKeyFlag  = "nextupdatekey"
-	fileIndexNextUpdateKeyUsage = "The public key PEM used for creating commitment for next update of the index document. Example: --nextupdatekey 'MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAEFMy2n9jYZChYSjdhK9vUWvPjz9tzBcEa13Ye33haxFsT//3kGxOQhI7yb3MJsDvwLtdfLL6txM3RdOrmLABBvw'"
+	outFlag  = "out"
+	outUsage = "If specified then the files are not uploaded. Instead, their DCAS IDs are computed locally and the signed update request of the index document is written, along with the files, to the given file so that the request may be generated offline and submitted later using 'file submit'. Since the index document isn't resolved, all files are added (or replaced) and the base path of --url is not validated. May not be specified together with --keystore. Example: --out ./update-request.json"
 
-	fileIndexNextUpdateKeyFileFlag  = "nextupdatekeyfile"
-	fileIndexNextUpdateKeyFileUsage = "The file that contains the public key PEM used for creating commitment for next update of the index document. Example: --nextupdatekeyfile ./next_update_public.key"
+	parallelFlag  = "parallel"
+	parallelUsage = "The maximum number of files that are uploaded in parallel. Example: --parallel 8"
 
-	fileIndexSigningKeyFlag  = "signingkey"
-	fileIndexSigningKeyUsage = "The private key PEM used for signing the update of the index document. Example: --signingkey 'MHcCAQEEILmfa4yss8nsTJK2hKl+LAoiwW3p+eQzaHfITI9z8ptpoAoGCCqGSM49AwEHoUQDQgAEMd1/e/Nxh73bK12PEEcNSY9HxnP0N8er9ww9rjq1tNcsqfRjlL0bdTh9Basfn/4JrQHUHc6uS99yjQc+0u2bVg'"
+	retriesFlag  = "retries"
+	retriesUsage = "The maximum number of times that the upload of a file is retried if it fails with a transient error (a network error or timeout, or an HTTP status such as 503). Example: --retries 5"
 
-	fileIndexSigningKeyFileFlag  = "signingkeyfile"
-	fileIndexSigningKeyFileUsage = "The file that contains the private key PEM used for signing the update of the index document. Example: --signingkeyfile ./keys/signing.key"
+	retryBackoffFlag  = "retrybackoff"
+	retryBackoffUsage = "The time to wait before the first retry of an upload. The time doubles on each subsequent retry. Example: --retrybackoff 1s"
+
+	resumeFlag  = "resume"
+	resumeUsage = "If specified then the files that were uploaded by a previous run that failed, as recorded in the upload journal, are not uploaded again. Example: --resume"
+
+	journalFlag  = "journal"
+	journalUsage = "The file in which completed uploads are recorded until the index document is updated. The default is upload-<unique suffix of the index document>.json in the journal directory of the fabric-cli home. Example: --journal ./upload-journal.json"
+
+	contentTypeFlag  = "contenttype"
+	contentTypeUsage = "A name=type entry that sets the content type of the files whose name matches the given glob pattern (matched against both the name of the file's mapping and its base name). This flag may be specified multiple times, in which case the first matching entry applies. By default, the content type is determined from the file extension using a built-in table or, if the file has no extension or the extension is unknown, by sniffing the content of the file. Example: --contenttype 'README=text/markdown; charset=utf-8' --contenttype '*.dat=application/cbor'"
+
+	uploadModeFlag  = "uploadmode"
+	uploadModeUsage = "The mode in which files are uploaded: json (a JSON request that contains the base64-encoded content of the file), raw (the content of the file as the request body), multipart (the file as the 'file' part of a multipart/form-data request) or auto (raw if the server accepts it, otherwise json). Whichever mode is used, the server is expected to store the file under the same DCAS ID as a JSON upload, which is verified for raw and multipart uploads. Example: --uploadmode auto"
 
 	noPromptFlag  = "noprompt"
 	noPromptUsage = "If specified then the upload operation will not prompt for confirmation. Example: --noprompt"
 
 	msgAborted         = "Operation aborted"
+	msgUpToDate        = "All files are up to date"
+	msgRequestSaved    = "Update request saved to [%s]"
+	msgUploaded        = "Uploaded %s (%d/%d)"
+	msgSkipped         = "Skipping %s - already uploaded according to the upload journal"
 	msgContinueOrAbort = "Enter Y to continue or N to abort "
+)
 
-	// default multihash for Sidetree
-	sha2_256 = 18
-
-	signingAlgorithm = "ES256"
+const (
+	defaultParallel     = 4
+	defaultRetries      = 3
+	defaultRetryBackoff = 500 * time.Millisecond
 
-	jsonPatchBasePath  = "/fileIndex/mappings/"
-	jsonPatchAddOp     = "add"
-	jsonPatchReplaceOp = "replace"
+	journalDir = "journal"
 )
 
 var (
-	errURLRequired                          = errors.New("URL (--url) is required")
-	errFilesRequired                        = errors.New("files (--files) is required")
-	errFileIndexURLRequired                 = errors.New("file index URL (--idxurl) is required")
-	errNextUpdateKeyOrFileRequired          = errors.New("either next update key (--nextupdatekey) or key file (--nextupdatekeyfile) is required")
-	errOnlyOneOfNextUpdateKeyOrFileRequired = errors.New("only one of next update key (--nextupdatekey) or key file (--nextupdatekeyfile) may be specified")
-	errNoFileExtension                      = errors.New("content type cannot be deduced since no file extension provided")
-	errUnknownExtension                     = errors.New("content type cannot be deduced from extension")
-	errSigningKeyOrFileRequired             = errors.New("either signing key (--signingkey) or key file (--signingkeyfile) is required")
-	errOnlyOneOfSigningKeyOrFileRequired    = errors.New("only one of signing key (--signingkey) or key file (--signingkeyfile) may be specified")
-	errPrivateKeyNotFoundInPEM              = errors.New("private key not found in PEM")
-	errPublicKeyNotFoundInPEM               = errors.New("public key not found in PEM")
+	errURLRequired          = errors.New("URL (--url) is required")
+	errFilesRequired        = errors.New("either files (--files) or directory (--dir) is required")
+	errOnlyOneOfFilesOrDir  = errors.New("only one of files (--files) or directory (--dir) may be specified")
+	errFiltersRequireDir    = errors.New("--include and --exclude may only be specified with --dir")
+	errNoFilesFound         = errors.New("no files found to upload")
+	errFileIndexURLRequired = errors.New("file index URL (--idxurl) is required")
+	errNoFileExtension      = errors.New("content type cannot be deduced since no file extension provided")
+	errUnknownExtension     = errors.New("content type cannot be deduced from extension")
+	errWaitWithOut          = errors.New("--wait may not be specified together with --out")
+	errKeyStoreWithOut      = errors.New("key store (--keystore) may not be specified together with --out since the keys would be rotated before the request is submitted")
+	errInvalidParallel      = errors.New("--parallel must be at least 1")
+	errInvalidRetries       = errors.New("--retries may not be negative")
 )
 
 type httpClient interface {
 	Post(url string, req []byte, opts ...httpclient.RequestOpt) (*httpclient.HTTPResponse, error)
+	PostStream(url, contentType string, body io.Reader, opts ...httpclient.RequestOpt) (*httpclient.HTTPResponse, error)
 	Get(url string, opts ...httpclient.RequestOpt) (*httpclient.HTTPResponse, error)
 }
 
 // New returns the file upload sub-command
 func New(settings *environment.Settings) *cobra.Command {
-	return newCmd(settings, httpclient.New())
+	cfg := httpclient.NewConfig(settings)
+
+	cmd := newCmd(settings, httpclient.New(httpclient.WithConfig(cfg)))
+	cfg.AddFlags(cmd.Flags())
+
+	return cmd
 }
 
 func newCmd(settings *environment.Settings, client httpClient) *cobra.Command {
@@ -158,14 +224,25 @@ func newCmd(settings *environment.Settings, client httpClient) *cobra.Command {
 	cmd.SilenceUsage = true
 
 	cmd.Flags().StringVar(&c.file, fileFlag, "", fileUsage)
+	cmd.Flags().StringVar(&c.dir, dirFlag, "", dirUsage)
+	cmd.Flags().StringVar(&c.include, includeFlag, "", includeUsage)
+	cmd.Flags().StringVar(&c.exclude, excludeFlag, "", excludeUsage)
 	cmd.Flags().StringVar(&c.url, urlFlag, "", urlUsage)
-	cmd.Flags().StringVar(&c.authToken, authTokenFlag, "", authTokenUsage)
-	cmd.Flags().StringVar(&c.contentAuthToken, contentAuthTokenFlag, "", contentAuthTokenUsage)
+	httpclient.AddAuthTokenFlag(cmd.Flags(), &c.authToken, "the URL specified by --idxurl")
+	httpclient.AddContentAuthTokenFlag(cmd.Flags(), &c.contentAuthToken, "upload files to the URL specified by --url")
 	cmd.Flags().StringVar(&c.fileIndexURL, fileIndexURLFlag, "", fileIndexURLUsage)
-	cmd.Flags().StringVar(&c.fileIndexNextUpdateKeyString, fileIndexNextUpdateKeyFlag, "", fileIndexNextUpdateKeyUsage)
-	cmd.Flags().StringVar(&c.fileIndexNextUpdateKeyFile, fileIndexNextUpdateKeyFileFlag, "", fileIndexNextUpdateKeyFileUsage)
-	cmd.Flags().StringVar(&c.fileIndexSigningKeyString, fileIndexSigningKeyFlag, "", fileIndexSigningKeyUsage)
-	cmd.Flags().StringVar(&c.fileIndexSigningKeyFile, fileIndexSigningKeyFileFlag, "", fileIndexSigningKeyFileUsage)
+	c.keys.AddFlags(cmd.Flags())
+	cmd.Flags().BoolVar(&c.force, forceFlag, false, forceUsage)
+	cmd.Flags().DurationVar(&c.wait, waitFlag, 0, waitUsage)
+	cmd.Flags().Lookup(waitFlag).NoOptDefVal = fileidx.DefaultWaitTimeout.String()
+	cmd.Flags().StringVar(&c.out, outFlag, "", outUsage)
+	cmd.Flags().IntVar(&c.parallel, parallelFlag, defaultParallel, parallelUsage)
+	cmd.Flags().IntVar(&c.retries, retriesFlag, defaultRetries, retriesUsage)
+	cmd.Flags().DurationVar(&c.retryBackoff, retryBackoffFlag, defaultRetryBackoff, retryBackoffUsage)
+	cmd.Flags().BoolVar(&c.resume, resumeFlag, false, resumeUsage)
+	cmd.Flags().StringVar(&c.journal, journalFlag, "", journalUsage)
+	cmd.Flags().StringArrayVar(&c.contentTypes, contentTypeFlag, nil, contentTypeUsage)
+	cmd.Flags().StringVar(&c.uploadMode, uploadModeFlag, fileidx.UploadModeJSON, uploadModeUsage)
 	cmd.Flags().BoolVar(&c.noPrompt, noPromptFlag, false, noPromptUsage)
 
 	return cmd
@@ -176,18 +253,29 @@ type command struct {
 	*basecmd.Command
 	client httpClient
 
-	file                         string
-	url                          string
-	authToken                    string
-	contentAuthToken             string
-	basePath                     string
-	fileIndexURL                 string
-	fileIndexUpdateURL           string
-	fileIndexSigningKeyFile      string
-	fileIndexSigningKeyString    string
-	fileIndexNextUpdateKeyFile   string
-	fileIndexNextUpdateKeyString string
-	noPrompt                     bool
+	file             string
+	dir              string
+	include          string
+	exclude          string
+	url              string
+	authToken        string
+	contentAuthToken string
+	basePath         string
+	fileIndexURL     string
+	keys             fileidx.UpdateKeys
+	force            bool
+	wait             time.Duration
+	out              string
+	parallel         int
+	retries          int
+	retryBackoff     time.Duration
+	resume           bool
+	journal          string
+	contentTypes     []string
+	uploadMode       string
+	noPrompt         bool
+
+	contentTypeOverrides []*contentTypeOverride
 }
 
 func (c *command) validateAndProcessArgs() error {
@@ -199,18 +287,41 @@ func (c *command) validateAndProcessArgs() error {
 		return err
 	}
 
-	if c.file == "" {
-		return errFilesRequired
+	if err := c.validateFiles(); err != nil {
+		return err
+	}
+
+	if err := c.keys.Validate(); err != nil {
+		return err
+	}
+
+	if c.out != "" && c.keys.KeyStoreDir != "" {
+		return errKeyStoreWithOut
+	}
+
+	if c.out != "" && c.wait > 0 {
+		return errWaitWithOut
+	}
+
+	if c.parallel < 1 {
+		return errInvalidParallel
 	}
 
-	if err := c.validateSigningKey(); err != nil {
+	if c.retries < 0 {
+		return errInvalidRetries
+	}
+
+	if err := fileidx.ValidateUploadMode(c.uploadMode); err != nil {
 		return err
 	}
 
-	if err := c.validateNextUpdateKey(); err != nil {
+	contentTypeOverrides, err := parseContentTypeOverrides(c.contentTypes)
+	if err != nil {
 		return err
 	}
 
+	c.contentTypeOverrides = contentTypeOverrides
+
 	if c.contentAuthToken == "" {
 		c.contentAuthToken = c.authToken
 	}
@@ -218,6 +329,22 @@ func (c *command) validateAndProcessArgs() error {
 	return nil
 }
 
+func (c *command) validateFiles() error {
+	if c.file == "" && c.dir == "" {
+		return errFilesRequired
+	}
+
+	if c.file != "" && c.dir != "" {
+		return errOnlyOneOfFilesOrDir
+	}
+
+	if c.dir == "" && (c.include != "" || c.exclude != "") {
+		return errFiltersRequireDir
+	}
+
+	return nil
+}
+
 func (c *command) validateAndProcessURL() error {
 	if c.url == "" {
 		return errURLRequired
@@ -242,17 +369,16 @@ func (c *command) validateAndProcessFileIdxURL() error {
 		return errFileIndexURLRequired
 	}
 
-	pos := strings.LastIndex(c.fileIndexURL, "/identifiers")
-	if pos == -1 {
-		return errors.Errorf("invalid file index URL: [%s] - the file index ID must be prefixed by identifiers/", c.fileIndexURL)
-	}
-
-	c.fileIndexUpdateURL = fmt.Sprintf("%s/operations", c.fileIndexURL[0:pos])
+	_, err := fileidx.UpdateURL(c.fileIndexURL)
 
-	return nil
+	return err
 }
 
 func (c *command) run() error {
+	if c.out != "" {
+		return c.runOffline()
+	}
+
 	fileIdx, err := c.getFileIndex()
 	if err != nil {
 		return err
@@ -263,8 +389,25 @@ func (c *command) run() error {
 		return err
 	}
 
+	changed, unchanged, err := c.getChanges(fileIdx, f)
+	if err != nil {
+		return err
+	}
+
+	if err := c.Fprintln(getPlan(c.url, fileIdx, changed, unchanged)); err != nil {
+		return err
+	}
+
+	if len(changed) == 0 {
+		return c.Fprintln(msgUpToDate)
+	}
+
+	if err := fileidx.CheckDocumentProtocol(c.client, c.fileIndexURL, c.authToken, c.keys.MultihashCode()); err != nil {
+		return err
+	}
+
 	if !c.noPrompt {
-		confirmed, e := c.confirmUpload(c.url, f)
+		confirmed, e := c.confirmUpload()
 		if e != nil {
 			return e
 		}
@@ -274,402 +417,439 @@ func (c *command) run() error {
 		}
 	}
 
-	for _, file := range f {
-		id, e := c.upload(file.ContentType, file.Content)
-		if e != nil {
-			return e
-		}
+	j, err := openJournal(c.journalPath(), c.fileIndexURL, c.url, c.resume)
+	if err != nil {
+		return err
+	}
 
-		file.ID = id
+	if err := c.uploadFiles(changed, j); err != nil {
+		return err
+	}
+
+	// The expected update commitment must be computed before the update since the update rotates the keys in the key store
+	var updateCommitment string
+	if c.wait > 0 {
+		updateCommitment, err = c.keys.NextUpdateCommitment(c.fileIndexURL, c.keys.MultihashCode())
+		if err != nil {
+			return err
+		}
 	}
 
-	err = c.updateIndexFile(fileIdx, f)
+	err = c.updateIndexFile(fileIdx, changed)
 	if err != nil {
 		return err
 	}
 
-	return c.Fprint(f.String())
-}
+	if err := j.remove(); err != nil {
+		return err
+	}
 
-// confirmUpload prompts the user for confirmation of the upload
-func (c *command) confirmUpload(url string, files files) (bool, error) {
-	prompt := fmt.Sprintf("Uploading the following files to [%s]\n%s\n%s", url, files, msgContinueOrAbort)
+	if err := c.Fprint(changed.String()); err != nil {
+		return err
+	}
 
-	err := c.Fprintln(prompt)
-	if err != nil {
-		return false, err
+	if c.wait > 0 {
+		return c.waitForAnchor(updateCommitment, changed)
 	}
 
-	return strings.ToLower(c.Prompt()) == "y", nil
+	return nil
 }
 
-func (c *command) upload(contentType string, fileBytes []byte) (string, error) {
-	req := &uploadFile{
-		ContentType: contentType,
-		Content:     fileBytes,
+// waitForAnchor waits until the mappings of the given files may be resolved from the file index document with the expected update commitment
+func (c *command) waitForAnchor(updateCommitment string, files files) error {
+	expected := &fileidx.Expected{
+		UpdateCommitment: updateCommitment,
+		Mappings:         make(map[string]string),
 	}
 
-	reqBytes, err := json.Marshal(req)
-	if err != nil {
-		return "", err
+	for _, f := range files {
+		expected.Mappings[f.Name] = f.ID
 	}
 
-	var reqOpts []httpclient.RequestOpt
-	if c.contentAuthToken != "" {
-		reqOpts = append(reqOpts, httpclient.WithAuthToken(c.contentAuthToken))
+	elapsed, err := fileidx.Wait(c.client, c.fileIndexURL, c.authToken, expected, c.wait)
+	if err != nil {
+		return err
 	}
 
-	resp, err := c.client.Post(c.url, reqBytes, reqOpts...)
+	return c.Fprintln(fmt.Sprintf("\nFile index update anchored in %s", elapsed.Round(time.Millisecond)))
+}
+
+// runOffline computes the DCAS IDs of the files locally and writes the signed update request of the index document,
+// along with the files, to the file specified by --out. Neither the Sidetree node nor DCAS is contacted.
+func (c *command) runOffline() error {
+	f, err := c.getFiles()
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		if resp.StatusCode == http.StatusUnauthorized {
-			return "", errors.Errorf("status code %d: %s - Did you provide an authorization token (--contentauthtoken)?", resp.StatusCode, resp.ErrorMsg)
+	var offlineFiles []*fileidx.OfflineFile
+
+	for _, file := range f {
+		// The files are saved in the request so their content is loaded
+		content, e := ioutil.ReadFile(filepath.Clean(file.Path))
+		if e != nil {
+			return e
 		}
 
-		return "", errors.Errorf("status code %d: %s", resp.StatusCode, resp.ErrorMsg)
-	}
+		file.ID, err = fileidx.DCASID(file.ContentType, content)
+		if err != nil {
+			return err
+		}
 
-	var fileID string
-	err = json.Unmarshal(resp.Payload, &fileID)
-	if err != nil {
-		return "", err
+		offlineFiles = append(offlineFiles, &fileidx.OfflineFile{
+			Name:        file.Name,
+			ID:          file.ID,
+			ContentType: file.ContentType,
+			Content:     content,
+		})
 	}
 
-	return fileID, nil
-}
+	// The index document isn't resolved so all mappings are added ('add' replaces an existing mapping)
+	fileIdx := &model.FileIndex{}
 
-func (c *command) updateIndexFile(fileIdx *model.FileIndex, files files) error {
-	patch, err := getUpdatePatch(fileIdx, files)
-	if err != nil {
+	if err := c.Fprintln(getPlan(c.url, fileIdx, f, nil)); err != nil {
 		return err
 	}
 
-	req, err := c.getUpdateRequest(patch)
+	if !c.noPrompt {
+		confirmed, e := c.confirmUpload()
+		if e != nil {
+			return e
+		}
+
+		if !confirmed {
+			return c.Fprintln(msgAborted)
+		}
+	}
+
+	req, err := fileidx.NewUpdateRequest(c.client, c.fileIndexURL, &c.keys, getUpdatePatch(fileIdx, f), fileidx.WithMultihash(c.keys.MultihashCode()))
 	if err != nil {
 		return err
 	}
 
-	var reqOpts []httpclient.RequestOpt
-	if c.authToken != "" {
-		reqOpts = append(reqOpts, httpclient.WithAuthToken(c.authToken))
+	err = fileidx.WriteRequest(c.out, &fileidx.OfflineRequest{
+		Operation:  fileidx.OperationUpdate,
+		Request:    req,
+		ContentURL: c.url,
+		Files:      offlineFiles,
+	})
+	if err != nil {
+		return err
 	}
 
-	resp, err := c.client.Post(c.fileIndexUpdateURL, req, reqOpts...)
-	if err != nil {
+	if err := c.Fprintln(f.String()); err != nil {
 		return err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		if resp.StatusCode == http.StatusUnauthorized {
-			return errors.Errorf("error updating file index document. Status code %d: %s - Did you provide an authorization token (--authtoken)?", resp.StatusCode, resp.ErrorMsg)
-		}
+	return c.Fprintln(fmt.Sprintf(msgRequestSaved, c.out))
+}
 
-		return errors.Errorf("error updating file index document. Status code %d: %s", resp.StatusCode, resp.ErrorMsg)
+// getChanges returns the files whose content differs from the content referenced by the file index
+// (or all files if --force is specified) along with the files that are unchanged. The DCAS ID of each
+// file is computed (unless --force is specified) so that the files don't have to be hashed again.
+func (c *command) getChanges(fileIdx *model.FileIndex, f files) (files, files, error) {
+	if c.force {
+		return f, nil, nil
 	}
 
-	return err
-}
+	var changed, unchanged files
 
-func (c *command) getFiles() (files, error) {
-	var f files
-	for _, filePath := range strings.Split(c.file, ";") {
-		fileInfo, err := getFileInfo(filePath)
+	for _, file := range f {
+		id, err := fileidx.DCASIDFromFile(file.ContentType, file.Path)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
-		f = append(f, fileInfo)
+		file.ID = id
+
+		if fileIdx.Mappings[file.Name] == id {
+			unchanged = append(unchanged, file)
+		} else {
+			changed = append(changed, file)
+		}
 	}
 
-	return f, nil
+	return changed, unchanged, nil
 }
 
-func (c *command) getUpdateRequest(patchStr string) ([]byte, error) {
-	uniqueSuffix, err := getUniqueSuffix(c.fileIndexURL)
+// confirmUpload prompts the user for confirmation of the upload
+func (c *command) confirmUpload() (bool, error) {
+	err := c.Fprintln(msgContinueOrAbort)
 	if err != nil {
-		return nil, err
+		return false, err
 	}
 
-	updatePatch, err := patch.NewJSONPatch(patchStr)
-	if err != nil {
-		return nil, err
-	}
+	return strings.ToLower(c.Prompt()) == "y", nil
+}
 
-	updateKeySigner, err := c.updateKeySigner()
-	if err != nil {
-		return nil, err
-	}
+// uploadFiles uploads the given files to DCAS, at most --parallel at a time, and sets the DCAS ID of each file.
+// Each completed upload is recorded in the journal and the files that the journal records as uploaded are skipped.
+func (c *command) uploadFiles(f files, j *journal) error {
+	var pending files
 
-	updateKeyPublic, err := c.updateKeyPublic()
-	if err != nil {
-		return nil, err
-	}
+	for _, file := range f {
+		// The ID was already computed by getChanges unless --force was specified. It is replaced by the
+		// ID returned by DCAS when the file is uploaded.
+		if file.ID == "" {
+			id, err := fileidx.DCASIDFromFile(file.ContentType, file.Path)
+			if err != nil {
+				return err
+			}
 
-	nextUpdateKeyPublic, err := c.nextUpdateKeyPublic()
-	if err != nil {
-		return nil, err
-	}
+			file.ID = id
+		}
 
-	updateCommitment, err := commitment.GetCommitment(nextUpdateKeyPublic, sha2_256)
-	if err != nil {
-		return nil, err
-	}
+		if !j.uploaded(file.Name, file.ID) {
+			pending = append(pending, file)
+			continue
+		}
 
-	revealValue, err := commitment.GetRevealValue(updateKeyPublic, sha2_256)
-	if err != nil {
-		return nil, err
+		if err := c.Fprintln(fmt.Sprintf(msgSkipped, file.Name)); err != nil {
+			return err
+		}
 	}
 
-	return client.NewUpdateRequest(&client.UpdateRequestInfo{
-		DidSuffix:        uniqueSuffix,
-		RevealValue:      revealValue,
-		UpdateCommitment: updateCommitment,
-		UpdateKey:        updateKeyPublic,
-		Patches:          []patch.Patch{updatePatch},
-		MultihashCode:    sha2_256,
-		Signer:           updateKeySigner,
-	})
+	u := &uploader{command: c, journal: j, total: len(pending), mode: c.uploadMode}
+
+	return u.upload(pending)
 }
 
-func (c *command) getFileIndex() (*model.FileIndex, error) {
-	var reqOpts []httpclient.RequestOpt
-	if c.authToken != "" {
-		reqOpts = append(reqOpts, httpclient.WithAuthToken(c.authToken))
-	}
+// uploader uploads files in parallel and reports the progress
+type uploader struct {
+	*command
+	journal *journal
+	total   int
 
-	resp, err := c.client.Get(c.fileIndexURL, reqOpts...)
-	if err != nil {
-		return nil, err
-	}
+	mutex sync.Mutex
+	mode  string
+	done  int
+	err   error
+}
 
-	if resp.StatusCode != http.StatusOK {
-		if resp.StatusCode == http.StatusNotFound {
-			return nil, errors.Errorf("file index document [%s] not found", c.fileIndexURL)
-		}
+func (u *uploader) upload(f files) error {
+	var wg sync.WaitGroup
 
-		if resp.StatusCode == http.StatusUnauthorized {
-			return nil, errors.Errorf("error retrieving file index document [%s]. Status code %d: %s - Did you provide an authorization token (--authtoken)?", c.fileIndexURL, resp.StatusCode, resp.ErrorMsg)
-		}
+	sem := make(chan struct{}, u.parallel)
 
-		return nil, errors.Errorf("error retrieving file index document [%s] status code %d: %s", c.fileIndexURL, resp.StatusCode, resp.ErrorMsg)
-	}
+	for _, file := range f {
+		sem <- struct{}{}
+		wg.Add(1)
 
-	var r model.DIDResolution
-	if errUnmarshal := json.Unmarshal(resp.Payload, &r); errUnmarshal != nil {
-		return nil, fmt.Errorf("unmarshal data return from sidtree %w", errUnmarshal)
-	}
+		go func(file *fileInfo) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
 
-	didDocBytes := resp.Payload
-	// check if data is did resolution
-	if len(r.DIDDocument) != 0 {
-		didDocBytes = r.DIDDocument
+			u.uploadFile(file)
+		}(file)
 	}
 
-	fileIdxDoc := &model.FileIndexDoc{}
-	err = json.Unmarshal(didDocBytes, fileIdxDoc)
-	if err != nil {
-		return nil, err
-	}
+	wg.Wait()
 
-	// Validate that the base path is correct
-	if fileIdxDoc.FileIndex.BasePath != c.basePath {
-		return nil, errors.Errorf("base path of file index doc does not match the base path of the file: [%s] != [%s]", fileIdxDoc.FileIndex.BasePath, c.basePath)
+	if u.err == nil {
+		return nil
 	}
 
-	return &fileIdxDoc.FileIndex, nil
-}
-
-func (c *command) updateKeySigner() (client.Signer, error) {
-	privateKey, err := c.signingPrivateKey()
-	if err != nil {
-		return nil, err
+	if u.done == 0 {
+		return u.err
 	}
 
-	return ecsigner.New(privateKey, signingAlgorithm, ""), nil
+	return errors.WithMessagef(u.err, "%d of %d files were uploaded and recorded in the upload journal [%s] - re-run with --resume to skip them", u.done, u.total, u.journal.path)
 }
 
-func (c *command) updateKeyPublic() (*jws.JWK, error) {
-	privateKey, err := c.signingPrivateKey()
-	if err != nil {
-		return nil, err
+func (u *uploader) uploadFile(file *fileInfo) {
+	if u.failed() {
+		// Don't start any more uploads once an upload has failed
+		return
 	}
 
-	return pubkey.GetPublicKeyJWK(&privateKey.PublicKey)
-}
+	u.mutex.Lock()
+	mode := u.mode
+	u.mutex.Unlock()
 
-func (c *command) nextUpdateKeyPublic() (*jws.JWK, error) {
-	pubKey, err := c.nextUpdateKey()
+	id, mode, err := fileidx.UploadFile(u.client, u.url, u.contentAuthToken, file.ContentType, file.Path,
+		fileidx.WithRetries(u.retries, u.retryBackoff), fileidx.WithUploadMode(mode))
 	if err != nil {
-		return nil, err
+		u.setError(errors.WithMessagef(err, "error uploading file [%s]", file.Path))
+		return
 	}
 
-	return pubkey.GetPublicKeyJWK(pubKey)
-}
-
-func (c *command) nextUpdateKey() (crypto.PublicKey, error) {
-	if c.fileIndexNextUpdateKeyFile != "" {
-		return publicKeyFromFile(c.fileIndexNextUpdateKeyFile)
+	if mode != fileidx.UploadModeJSON && id != file.ID {
+		u.setError(errors.Errorf("the DCAS ID [%s] returned for file [%s] doesn't match the ID [%s] of a JSON upload - the server may not support --uploadmode %s",
+			id, file.Path, file.ID, mode))
+		return
 	}
 
-	return publicKeyFromPEM([]byte(c.fileIndexNextUpdateKeyString))
-}
-
-func publicKeyFromFile(file string) (crypto.PublicKey, error) {
-	keyBytes, err := ioutil.ReadFile(filepath.Clean(file))
-	if err != nil {
-		return nil, err
+	if err := u.journal.record(file.Name, id); err != nil {
+		u.setError(err)
+		return
 	}
 
-	return publicKeyFromPEM(keyBytes)
-}
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
 
-func publicKeyFromPEM(pubKeyPEM []byte) (crypto.PublicKey, error) {
-	block, _ := pem.Decode(pubKeyPEM)
-	if block == nil {
-		return nil, errPublicKeyNotFoundInPEM
-	}
+	file.ID = id
+	u.done++
 
-	key, err := x509.ParsePKIXPublicKey(block.Bytes)
-	if err != nil {
-		return nil, err
-	}
+	// With --uploadmode auto, the mode that the server accepted is used for the remaining uploads
+	u.mode = mode
 
-	publicKey, ok := key.(crypto.PublicKey)
-	if !ok {
-		return nil, errors.New("invalid public key")
+	if err := u.Fprintln(fmt.Sprintf(msgUploaded, file.Name, u.done, u.total)); err != nil && u.err == nil {
+		u.err = err
 	}
-
-	return publicKey, nil
 }
 
-func (c *command) signingPrivateKey() (*ecdsa.PrivateKey, error) {
-	if c.fileIndexSigningKeyFile != "" {
-		return privateKeyFromFile(c.fileIndexSigningKeyFile)
-	}
+func (u *uploader) failed() bool {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
 
-	return privateKeyFromPEM([]byte(c.fileIndexSigningKeyString))
+	return u.err != nil
 }
 
-func (c *command) validateSigningKey() error {
-	if c.fileIndexSigningKeyFile == "" && c.fileIndexSigningKeyString == "" {
-		return errSigningKeyOrFileRequired
-	}
+func (u *uploader) setError(err error) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
 
-	if c.fileIndexSigningKeyFile != "" && c.fileIndexSigningKeyString != "" {
-		return errOnlyOneOfSigningKeyOrFileRequired
+	if u.err == nil {
+		u.err = err
 	}
-
-	return nil
 }
 
-func (c *command) validateNextUpdateKey() error {
-	if c.fileIndexNextUpdateKeyFile == "" && c.fileIndexNextUpdateKeyString == "" {
-		return errNextUpdateKeyOrFileRequired
+// journalPath returns the path of the upload journal (--journal) or, if not specified, the default path
+// in the journal directory of the fabric-cli home
+func (c *command) journalPath() string {
+	if c.journal != "" {
+		return c.journal
 	}
 
-	if c.fileIndexNextUpdateKeyFile != "" && c.fileIndexNextUpdateKeyString != "" {
-		return errOnlyOneOfNextUpdateKeyOrFileRequired
+	name := c.fileIndexURL
+	if p := strings.LastIndex(name, ":"); p != -1 {
+		name = name[p+1:]
 	}
 
-	return nil
+	return c.Settings.Home.Path(journalDir, fmt.Sprintf("upload-%s.json", name))
 }
 
-func getFileInfo(path string) (*fileInfo, error) {
-	var fileName string
-	p := strings.LastIndex(path, "/")
-	if p == -1 {
-		fileName = path
+func (c *command) updateIndexFile(fileIdx *model.FileIndex, files files) error {
+	return fileidx.Update(c.client, c.fileIndexURL, c.authToken, &c.keys, getUpdatePatch(fileIdx, files),
+		fileidx.WithMultihash(c.keys.MultihashCode()), fileidx.WithProtocolChecked())
+}
+
+func (c *command) getFiles() (files, error) {
+	var f files
+	var err error
+
+	if c.dir != "" {
+		f, err = getDirFiles(c.dir, splitList(c.include), splitList(c.exclude))
 	} else {
-		fileName = path[p+1:]
+		f, err = getListedFiles(splitList(c.file))
 	}
 
-	contentType, err := contentTypeFromFileName(fileName)
 	if err != nil {
 		return nil, err
 	}
 
-	content, err := ioutil.ReadFile(filepath.Clean(path))
-	if err != nil {
-		return nil, err
+	if len(f) == 0 {
+		return nil, errNoFilesFound
 	}
 
-	return &fileInfo{
-		Name:        fileName,
-		Content:     content,
-		ContentType: contentType,
-	}, nil
-}
+	names := make(map[string]string)
+	for _, info := range f {
+		if path, ok := names[info.Name]; ok {
+			return nil, errors.Errorf("files [%s] and [%s] both map to the file index name [%s]", path, info.Path, info.Name)
+		}
 
-func contentTypeFromFileName(fileName string) (string, error) {
-	p := strings.LastIndex(fileName, ".")
-	if p == -1 {
-		return "", errNoFileExtension
-	}
+		names[info.Name] = info.Path
 
-	contentType := mime.TypeByExtension(fileName[p:])
-	if contentType == "" {
-		return "", errors.WithMessagef(errUnknownExtension, fileName)
+		if err := setContentType(info, c.contentTypeOverrides); err != nil {
+			return nil, err
+		}
 	}
 
-	return contentType, nil
+	return f, nil
 }
 
-func getUpdatePatch(fileIdx *model.FileIndex, files files) (string, error) {
-	var patch []jsonPatch
-	for _, f := range files {
-		p := jsonPatch{
-			Path:  jsonPatchBasePath + f.Name,
-			Value: f.ID,
-		}
-
-		if _, ok := fileIdx.Mappings[f.Name]; ok {
-			p.Op = jsonPatchReplaceOp
-		} else {
-			p.Op = jsonPatchAddOp
+func getListedFiles(paths []string) (files, error) {
+	var f files
+	for _, filePath := range paths {
+		fileInfo, err := getFileInfo(filepath.Base(filePath), filePath)
+		if err != nil {
+			return nil, err
 		}
 
-		patch = append(patch, p)
+		f = append(f, fileInfo)
 	}
 
-	patchBytes, err := json.Marshal(patch)
+	return f, nil
+}
+
+func (c *command) getFileIndex() (*model.FileIndex, error) {
+	fileIdx, err := fileidx.Get(c.client, c.fileIndexURL, c.authToken)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return string(patchBytes), nil
-}
-
-func getUniqueSuffix(id string) (string, error) {
-	p := strings.LastIndex(id, ":")
-	if p == -1 {
-		return "", errors.Errorf("unique suffix not provided in URL [%s]", id)
+	// Validate that the base path is correct
+	if fileIdx.BasePath != c.basePath {
+		return nil, errors.Errorf("base path of file index doc does not match the base path of the file: [%s] != [%s]", fileIdx.BasePath, c.basePath)
 	}
 
-	return id[p+1:], nil
+	return fileIdx, nil
 }
 
-func privateKeyFromFile(file string) (*ecdsa.PrivateKey, error) {
-	keyBytes, err := ioutil.ReadFile(filepath.Clean(file))
+// getFileInfo returns the info of the file at the given path. The name is the name of the file's mapping in the file index.
+// The content of the file isn't loaded since the file is streamed when its DCAS ID is computed and when it's uploaded.
+// The content type is set later (see setContentType).
+func getFileInfo(name, path string) (*fileInfo, error) {
+	info, err := os.Stat(path)
 	if err != nil {
 		return nil, err
 	}
 
-	return privateKeyFromPEM(keyBytes)
+	if info.IsDir() {
+		return nil, errors.Errorf("[%s] is a directory", path)
+	}
+
+	return &fileInfo{
+		Name: name,
+		Path: path,
+	}, nil
 }
 
-func privateKeyFromPEM(privateKeyPEM []byte) (*ecdsa.PrivateKey, error) {
-	privBlock, _ := pem.Decode(privateKeyPEM)
-	if privBlock == nil {
-		return nil, errPrivateKeyNotFoundInPEM
+func getUpdatePatch(fileIdx *model.FileIndex, files files) []fileidx.Patch {
+	var patch []fileidx.Patch
+	for _, f := range files {
+		patch = append(patch, fileidx.Patch{
+			Op:    mappingOp(fileIdx, f.Name),
+			Path:  fileidx.MappingPath(f.Name),
+			Value: f.ID,
+		})
 	}
 
-	privKey, err := x509.ParseECPrivateKey(privBlock.Bytes)
-	if err != nil {
-		return nil, err
+	return patch
+}
+
+// getPlan returns a description of the index mappings that will be added and replaced by the upload
+// along with the files that will be skipped since they're unchanged
+func getPlan(url string, fileIdx *model.FileIndex, changed, unchanged files) string {
+	plan := fmt.Sprintf("Uploading the following files to [%s]:", url)
+	for _, f := range changed {
+		plan += fmt.Sprintf("\n  %-9s %s (%s) %s [%s]", mappingOp(fileIdx, f.Name), f.Name, f.Path, f.ContentType, f.ContentTypeSource)
+	}
+
+	for _, f := range unchanged {
+		plan += fmt.Sprintf("\n  %-9s %s (%s) %s [%s]", "unchanged", f.Name, f.Path, f.ContentType, f.ContentTypeSource)
+	}
+
+	return plan
+}
+
+// mappingOp returns the JSON patch operation required to set the given mapping in the file index
+func mappingOp(fileIdx *model.FileIndex, name string) string {
+	if _, ok := fileIdx.Mappings[name]; ok {
+		return fileidx.OpReplace
 	}
 
-	return privKey, nil
+	return fileidx.OpAdd
 }
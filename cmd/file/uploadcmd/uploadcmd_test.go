@@ -7,10 +7,17 @@ SPDX-License-Identifier: Apache-2.0
 package uploadcmd
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/spf13/cobra"
@@ -18,8 +25,11 @@ import (
 
 	"github.com/hyperledger/fabric-cli/pkg/environment"
 
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/fileidx"
 	"github.com/trustbloc/fabric-cli-ext/cmd/file/httpclient"
 	"github.com/trustbloc/fabric-cli-ext/cmd/file/model"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/signer"
+	"github.com/trustbloc/fabric-cli-ext/cmd/keyutil"
 	"github.com/trustbloc/fabric-cli-ext/cmd/mocks"
 )
 
@@ -43,16 +53,18 @@ func TestUloadCmd_New(t *testing.T) {
 
 func TestUloadCmd_InvalidOptions(t *testing.T) {
 	const (
-		urlFlag               = "--url"
-		url                   = "http://localhost:80/content"
-		filesFlag             = "--files"
-		files                 = "./samplefile.json"
-		idxUrlFlag            = "--idxurl"
-		idxUrl                = "http://localhost:80/file/identifiers/file:idx:1234"
-		nextUpdateKeyFlag     = "--nextupdatekey"
-		nextUpdateKeyFileFlag = "--nextupdatekeyfile"
-		signingkeyFlag        = "--signingkey"
-		signingkeyfileFlag    = "--signingkeyfile"
+		urlFlag                 = "--url"
+		url                     = "http://localhost:80/content"
+		filesFlag               = "--files"
+		files                   = "./samplefile.json"
+		idxUrlFlag              = "--idxurl"
+		idxUrl                  = "http://localhost:80/file/identifiers/file:idx:1234"
+		nextUpdateKeyFlag       = "--nextupdatekey"
+		nextUpdateKeyFileFlag   = "--nextupdatekeyfile"
+		signingkeyFlag          = "--signingkey"
+		signingkeyfileFlag      = "--signingkeyfile"
+		signerFlag              = "--signer"
+		signerPublicKeyFileFlag = "--signerpublickeyfile"
 	)
 
 	t.Run("No options", func(t *testing.T) {
@@ -73,6 +85,14 @@ func TestUloadCmd_InvalidOptions(t *testing.T) {
 		require.EqualError(t, newMockCmd(t, nil, urlFlag, url, idxUrlFlag, idxUrl).Execute(), errFilesRequired.Error())
 	})
 
+	t.Run("Both --files and --dir", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, urlFlag, url, idxUrlFlag, idxUrl, filesFlag, files, "--dir", "./testdata").Execute(), errOnlyOneOfFilesOrDir.Error())
+	})
+
+	t.Run("--include without --dir", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, urlFlag, url, idxUrlFlag, idxUrl, filesFlag, files, "--include", "*.json").Execute(), errFiltersRequireDir.Error())
+	})
+
 	t.Run("Invalid --idxurl", func(t *testing.T) {
 		err := newMockCmd(t, nil, urlFlag, url, filesFlag, files, idxUrlFlag, "localhost:80").Execute()
 		require.Error(t, err)
@@ -80,19 +100,61 @@ func TestUloadCmd_InvalidOptions(t *testing.T) {
 	})
 
 	t.Run("Next update key required", func(t *testing.T) {
-		require.EqualError(t, newMockCmd(t, nil, urlFlag, url, filesFlag, files, idxUrlFlag, idxUrl, signingkeyFlag, signingKey).Execute(), errNextUpdateKeyOrFileRequired.Error())
+		require.EqualError(t, newMockCmd(t, nil, urlFlag, url, filesFlag, files, idxUrlFlag, idxUrl, signingkeyFlag, signingKey).Execute(), fileidx.ErrNextUpdateKeyOrFileRequired.Error())
 	})
 
 	t.Run("Next update key and file specified", func(t *testing.T) {
-		require.EqualError(t, newMockCmd(t, nil, urlFlag, url, filesFlag, files, idxUrlFlag, idxUrl, nextUpdateKeyFlag, nextUpdateKey, nextUpdateKeyFileFlag, "./pub_key", signingkeyFlag, signingKey).Execute(), errOnlyOneOfNextUpdateKeyOrFileRequired.Error())
+		require.EqualError(t, newMockCmd(t, nil, urlFlag, url, filesFlag, files, idxUrlFlag, idxUrl, nextUpdateKeyFlag, nextUpdateKey, nextUpdateKeyFileFlag, "./pub_key", signingkeyFlag, signingKey).Execute(), fileidx.ErrOnlyOneOfNextUpdateKeyOrFileRequired.Error())
 	})
 
 	t.Run("Update key required", func(t *testing.T) {
-		require.EqualError(t, newMockCmd(t, nil, urlFlag, url, filesFlag, files, idxUrlFlag, idxUrl, nextUpdateKeyFlag, nextUpdateKey, nextUpdateKeyFileFlag, "./pub_key").Execute(), errSigningKeyOrFileRequired.Error())
+		require.EqualError(t, newMockCmd(t, nil, urlFlag, url, filesFlag, files, idxUrlFlag, idxUrl, nextUpdateKeyFlag, nextUpdateKey, nextUpdateKeyFileFlag, "./pub_key").Execute(), fileidx.ErrSigningKeyOrFileRequired.Error())
 	})
 
 	t.Run("Update key and file specified", func(t *testing.T) {
-		require.EqualError(t, newMockCmd(t, nil, urlFlag, url, filesFlag, files, idxUrlFlag, idxUrl, nextUpdateKeyFileFlag, "./pub_key", signingkeyFlag, signingKey, signingkeyfileFlag, "./key").Execute(), errOnlyOneOfSigningKeyOrFileRequired.Error())
+		require.EqualError(t, newMockCmd(t, nil, urlFlag, url, filesFlag, files, idxUrlFlag, idxUrl, nextUpdateKeyFileFlag, "./pub_key", signingkeyFlag, signingKey, signingkeyfileFlag, "./key").Execute(), fileidx.ErrOnlyOneOfSigningKeyOrFileRequired.Error())
+	})
+
+	t.Run("Update key and signer specified", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, urlFlag, url, filesFlag, files, idxUrlFlag, idxUrl, nextUpdateKeyFileFlag, "./pub_key", signingkeyFlag, signingKey, signerFlag, "cmd:sign", signerPublicKeyFileFlag, "./pub_key").Execute(), fileidx.ErrOnlyOneOfSigningKeyOrFileRequired.Error())
+	})
+
+	t.Run("Signer public key required", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, urlFlag, url, filesFlag, files, idxUrlFlag, idxUrl, nextUpdateKeyFileFlag, "./pub_key", signerFlag, "https://localhost:9999/sign").Execute(), signer.ErrPublicKeyRequired.Error())
+	})
+
+	t.Run("Signer public key without signer", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, urlFlag, url, filesFlag, files, idxUrlFlag, idxUrl, nextUpdateKeyFileFlag, "./pub_key", signingkeyFlag, signingKey, signerPublicKeyFileFlag, "./pub_key").Execute(), fileidx.ErrSignerPublicKeyWithoutSigner.Error())
+	})
+
+	t.Run("Unsupported multihash", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, urlFlag, url, filesFlag, files, idxUrlFlag, idxUrl, signingkeyFlag, signingKey, nextUpdateKeyFlag, nextUpdateKey, "--multihash", "md5").Execute(), fileidx.ErrUnsupportedMultihash.Error())
+	})
+
+	t.Run("Wait and out specified", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, urlFlag, url, filesFlag, files, idxUrlFlag, idxUrl, signingkeyFlag, signingKey, nextUpdateKeyFlag, nextUpdateKey, "--wait", "--out", "./request.json").Execute(), errWaitWithOut.Error())
+	})
+
+	t.Run("Key store and out specified", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, urlFlag, url, filesFlag, files, idxUrlFlag, idxUrl, "--keystore", "./keystore", "--out", "./request.json").Execute(), errKeyStoreWithOut.Error())
+	})
+
+	t.Run("Invalid --parallel", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, urlFlag, url, filesFlag, files, idxUrlFlag, idxUrl, signingkeyFlag, signingKey, nextUpdateKeyFlag, nextUpdateKey, "--parallel", "0").Execute(), errInvalidParallel.Error())
+	})
+
+	t.Run("Invalid --retries", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, urlFlag, url, filesFlag, files, idxUrlFlag, idxUrl, signingkeyFlag, signingKey, nextUpdateKeyFlag, nextUpdateKey, "--retries", "-1").Execute(), errInvalidRetries.Error())
+	})
+
+	t.Run("Invalid --uploadmode", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, urlFlag, url, filesFlag, files, idxUrlFlag, idxUrl, signingkeyFlag, signingKey, nextUpdateKeyFlag, nextUpdateKey, "--uploadmode", "xxx").Execute(), fileidx.ErrUnsupportedUploadMode.Error())
+	})
+
+	t.Run("Invalid signer", func(t *testing.T) {
+		err := newMockCmd(t, nil, urlFlag, url, filesFlag, files, idxUrlFlag, idxUrl, nextUpdateKeyFileFlag, "./pub_key", signerFlag, "pkcs11:lib=./lib.so").Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "PKCS#11 signer option")
 	})
 }
 
@@ -158,6 +220,79 @@ func TestUploadCmd(t *testing.T) {
 		require.Contains(t, w.Written(), resp)
 	})
 
+	t.Run("With --multihash", func(t *testing.T) {
+		w := &mocks.Writer{}
+
+		c := newMockCmdWithReaderWriter(t, &mocks.Reader{}, w, transport, append(args, "--noprompt", "--multihash", "sha2-512")...)
+		require.NoError(t, c.Execute())
+		require.Contains(t, w.Written(), resp)
+	})
+
+	t.Run("With --wait", func(t *testing.T) {
+		rt := &sidetreeTransport{t: t, doc: fileIdxDoc, dcasID: dcasIDJSON}
+
+		w := &mocks.Writer{}
+		require.NoError(t, newMockCmdWithReaderWriter(t, &mocks.Reader{}, w, rt, append(args, "--noprompt", "--wait")...).Execute())
+		require.Contains(t, w.Written(), resp)
+		require.Contains(t, w.Written(), "File index update anchored in")
+	})
+
+	t.Run("With --wait - timeout", func(t *testing.T) {
+		w := &mocks.Writer{}
+		err := newMockCmdWithReaderWriter(t, &mocks.Reader{}, w, transport, append(args, "--noprompt", "--wait=10ms")...).Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "timed out after 10ms waiting for file index document")
+		require.Contains(t, w.Written(), resp)
+	})
+
+	t.Run("With --out", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "uploadcmd")
+		require.NoError(t, err)
+		defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+		out := filepath.Join(dir, "request.json")
+
+		w := &mocks.Writer{}
+
+		// Neither the Sidetree node nor DCAS is contacted so no transport is required
+		c := newMockCmdWithReaderWriter(t, &mocks.Reader{Bytes: []byte("Y\n")}, w, nil, append(args, "--out", out)...)
+		require.NoError(t, c.Execute())
+		require.Contains(t, w.Written(), "add       person.schema.json")
+		require.Contains(t, w.Written(), resp)
+		require.Contains(t, w.Written(), fmt.Sprintf(msgRequestSaved, out))
+
+		r, err := fileidx.ReadRequest(out)
+		require.NoError(t, err)
+		require.Equal(t, fileidx.OperationUpdate, r.Operation)
+		require.Equal(t, url, r.ContentURL)
+		require.Len(t, r.Files, 1)
+		require.Equal(t, "person.schema.json", r.Files[0].Name)
+		require.Equal(t, "TbVyraOqG00TacPQH5WwWGnxkszpYSEhBKRyX_f25JI=", r.Files[0].ID)
+		require.Equal(t, "application/json", r.Files[0].ContentType)
+
+		updateReq := &struct {
+			Type   string `json:"type"`
+			Suffix string `json:"didSuffix"`
+		}{}
+		require.NoError(t, json.Unmarshal(r.Request, updateReq))
+		require.Equal(t, "update", updateReq.Type)
+		require.Equal(t, "EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA==", updateReq.Suffix)
+	})
+
+	t.Run("With --out - prompt N", func(t *testing.T) {
+		w := &mocks.Writer{}
+
+		c := newMockCmdWithReaderWriter(t, &mocks.Reader{Bytes: []byte("N\n")}, w, nil, append(args, "--out", "./xxx/request.json")...)
+		require.NoError(t, c.Execute())
+		require.Contains(t, w.Written(), msgAborted)
+	})
+
+	t.Run("With --out - write error", func(t *testing.T) {
+		err := newMockCmd(t, nil, append(args, "--out", "./xxx/request.json", "--noprompt")...).Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "error writing request")
+	})
+
 	t.Run("With invalid key", func(t *testing.T) {
 		w := &mocks.Writer{}
 
@@ -165,7 +300,7 @@ func TestUploadCmd(t *testing.T) {
 		c := newMockCmdWithReaderWriter(t, &mocks.Reader{Bytes: []byte("Y\n")}, w, transport, args...)
 		err := c.Execute()
 		require.Error(t, err)
-		require.Contains(t, err.Error(), errPrivateKeyNotFoundInPEM.Error())
+		require.Contains(t, err.Error(), keyutil.ErrPrivateKeyNotFoundInPEM.Error())
 		require.Contains(t, w.Written(), err.Error())
 	})
 
@@ -213,6 +348,140 @@ func TestUploadCmd(t *testing.T) {
 		})
 	})
 
+	t.Run("Duplicate file index names", func(t *testing.T) {
+		args := []string{"--url", url, "--files", files + ";" + files, "--idxurl", idxUrl, "--nextupdatekey", nextUpdateKey, "--signingkey", signingKey, "--noprompt"}
+
+		err := newMockCmd(t, transport, args...).Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "both map to the file index name [person.schema.json]")
+	})
+
+	t.Run("With --dir", func(t *testing.T) {
+		dir := newTestDir(t)
+		defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+		fileIdxDoc := &model.FileIndexDoc{
+			ID:           "file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA==",
+			UniqueSuffix: "EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA==",
+			FileIndex: model.FileIndex{
+				BasePath: "/content/v1",
+				Mappings: map[string]string{"a/schema.json": "xxx"},
+			},
+		}
+
+		fileIdxDocBytes, err := json.Marshal(fileIdxDoc)
+		require.NoError(t, err)
+
+		transport := mocks.NewTransport().
+			WithGetResponse(&http.Response{StatusCode: http.StatusOK, Header: header, Body: mocks.NewResponseBody(fileIdxDocBytes)}).
+			WithPostResponse(&http.Response{StatusCode: http.StatusOK, Header: header, Body: mocks.NewResponseBody([]byte(dcasIDJSON))})
+
+		args := []string{"--url", url, "--dir", dir, "--include", "*.json", "--exclude", "drafts", "--idxurl", idxUrl, "--nextupdatekey", nextUpdateKey, "--signingkey", signingKey, "--noprompt"}
+
+		w := &mocks.Writer{}
+		c := newMockCmdWithReaderWriter(t, &mocks.Reader{}, w, transport, args...)
+		require.NoError(t, c.Execute())
+		require.Contains(t, w.Written(), "replace   a/schema.json")
+		require.Contains(t, w.Written(), "add       b/schema.json")
+		require.NotContains(t, w.Written(), "drafts")
+		require.NotContains(t, w.Written(), "image.png")
+	})
+
+	t.Run("Content types", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "uploadcmd")
+		require.NoError(t, err)
+		defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "README"), []byte("# Schemas"), 0600))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "LICENSE"), []byte("Apache-2.0"), 0600))
+
+		fileIdxDocBytes, err := json.Marshal(&model.FileIndexDoc{FileIndex: model.FileIndex{BasePath: "/content/v1"}})
+		require.NoError(t, err)
+
+		transport := mocks.NewTransport().
+			WithGetResponse(&http.Response{StatusCode: http.StatusOK, Header: header, Body: mocks.NewResponseBody(fileIdxDocBytes)}).
+			WithPostResponse(&http.Response{StatusCode: http.StatusOK, Header: header, Body: mocks.NewResponseBody([]byte(dcasIDJSON))})
+
+		args := []string{"--url", url, "--dir", dir, "--idxurl", idxUrl, "--nextupdatekey", nextUpdateKey, "--signingkey", signingKey,
+			"--contenttype", "README=text/markdown; charset=utf-8", "--noprompt"}
+
+		w := &mocks.Writer{}
+		require.NoError(t, newMockCmdWithReaderWriter(t, &mocks.Reader{}, w, transport, args...).Execute())
+		require.Contains(t, w.Written(), "add       README ("+filepath.Join(dir, "README")+") text/markdown; charset=utf-8 [override]")
+		require.Contains(t, w.Written(), "add       LICENSE ("+filepath.Join(dir, "LICENSE")+") text/plain; charset=utf-8 [sniffed]")
+		require.Contains(t, w.Written(), `"Name":"README","ID":"TbVyraOqG00TacPQH5WwWGnxkszpYSEhBKRyX_f25JI=","ContentType":"text/markdown; charset=utf-8"`)
+	})
+
+	t.Run("Invalid content type override", func(t *testing.T) {
+		args := []string{"--url", url, "--files", "./testdata/person.schema.json", "--idxurl", idxUrl, "--nextupdatekey", nextUpdateKey, "--signingkey", signingKey,
+			"--contenttype", "README", "--noprompt"}
+
+		err := newMockCmd(t, nil, args...).Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid content type override [README]")
+	})
+
+	t.Run("Unchanged files", func(t *testing.T) {
+		const fileID = "TbVyraOqG00TacPQH5WwWGnxkszpYSEhBKRyX_f25JI="
+
+		dir, err := ioutil.TempDir("", "uploadcmd")
+		require.NoError(t, err)
+		defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+		otherFile := filepath.Join(dir, "other.schema.json")
+		require.NoError(t, ioutil.WriteFile(otherFile, []byte(`{"title":"Other"}`), 0600))
+
+		fileIdxDoc := &model.FileIndexDoc{
+			ID:           "file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA==",
+			UniqueSuffix: "EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA==",
+			FileIndex: model.FileIndex{
+				BasePath: "/content/v1",
+				Mappings: map[string]string{"person.schema.json": fileID},
+			},
+		}
+
+		fileIdxDocBytes, err := json.Marshal(fileIdxDoc)
+		require.NoError(t, err)
+
+		transport := mocks.NewTransport().
+			WithGetResponse(&http.Response{StatusCode: http.StatusOK, Header: header, Body: mocks.NewResponseBody(fileIdxDocBytes)}).
+			WithPostResponse(&http.Response{StatusCode: http.StatusOK, Header: header, Body: mocks.NewResponseBody([]byte(`"xxx"`))})
+
+		t.Run("Some files changed", func(t *testing.T) {
+			args := []string{"--url", url, "--files", files + ";" + otherFile, "--idxurl", idxUrl, "--nextupdatekey", nextUpdateKey, "--signingkey", signingKey, "--noprompt"}
+
+			w := &mocks.Writer{}
+			require.NoError(t, newMockCmdWithReaderWriter(t, &mocks.Reader{}, w, transport, args...).Execute())
+			require.Contains(t, w.Written(), "unchanged person.schema.json")
+			require.Contains(t, w.Written(), "add       other.schema.json")
+			require.Contains(t, w.Written(), `[{"Name":"other.schema.json","ID":"xxx","ContentType":"application/json"}]`)
+		})
+
+		t.Run("All files unchanged", func(t *testing.T) {
+			w := &mocks.Writer{}
+			require.NoError(t, newMockCmdWithReaderWriter(t, &mocks.Reader{}, w, transport, append(args, "--noprompt")...).Execute())
+			require.Contains(t, w.Written(), "unchanged person.schema.json")
+			require.Contains(t, w.Written(), msgUpToDate)
+			require.NotContains(t, w.Written(), `"ID":"xxx"`)
+		})
+
+		t.Run("With --force", func(t *testing.T) {
+			w := &mocks.Writer{}
+			require.NoError(t, newMockCmdWithReaderWriter(t, &mocks.Reader{}, w, transport, append(args, "--noprompt", "--force")...).Execute())
+			require.Contains(t, w.Written(), "replace   person.schema.json")
+			require.Contains(t, w.Written(), `[{"Name":"person.schema.json","ID":"xxx","ContentType":"application/json"}]`)
+		})
+	})
+
+	t.Run("With --dir - no files found", func(t *testing.T) {
+		dir := newTestDir(t)
+		defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+		args := []string{"--url", url, "--dir", dir, "--include", "*.xml", "--idxurl", idxUrl, "--nextupdatekey", nextUpdateKey, "--signingkey", signingKey, "--noprompt"}
+
+		require.EqualError(t, newMockCmd(t, transport, args...).Execute(), errNoFilesFound.Error())
+	})
+
 	t.Run("With prompt - N", func(t *testing.T) {
 		w := &mocks.Writer{}
 
@@ -254,7 +523,7 @@ func TestUploadCmd(t *testing.T) {
 			).
 			WithPostError(errExpected)
 
-		c := newMockCmd(t, transport, append(args, "--noprompt")...)
+		c := newMockCmd(t, transport, append(args, "--noprompt", "--retries", "0")...)
 		err := c.Execute()
 		require.Error(t, err)
 		require.Contains(t, err.Error(), errExpected.Error())
@@ -335,6 +604,191 @@ func TestUploadCmd(t *testing.T) {
 	})
 }
 
+func TestUploadCmd_Resume(t *testing.T) {
+	const (
+		url    = "http://localhost:48326/content/v1"
+		idxURL = "http://localhost:48326/file/identifiers/file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA=="
+	)
+
+	dir, err := ioutil.TempDir("", "uploadcmd")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+	otherFile := filepath.Join(dir, "other.schema.json")
+	require.NoError(t, ioutil.WriteFile(otherFile, []byte(`{"title":"Other"}`), 0600))
+
+	journalFile := filepath.Join(dir, "journal", "upload.json")
+
+	args := []string{"--url", url, "--files", "./testdata/person.schema.json;" + otherFile, "--idxurl", idxURL, "--nextupdatekey", nextUpdateKey,
+		"--signingkey", signingKey, "--journal", journalFile, "--parallel", "1", "--retries", "1", "--retrybackoff", "1ms", "--noprompt"}
+
+	docBytes, err := json.Marshal(&model.FileIndexDoc{FileIndex: model.FileIndex{BasePath: "/content/v1"}})
+	require.NoError(t, err)
+
+	t.Run("Upload fails", func(t *testing.T) {
+		rt := &contentTransport{doc: docBytes, fail: map[string]int{"other.schema.json": 2}}
+
+		err := newMockCmd(t, rt, args...).Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "error uploading file ["+otherFile+"]")
+		require.Contains(t, err.Error(), "1 of 2 files were uploaded and recorded in the upload journal ["+journalFile+"] - re-run with --resume")
+		require.Equal(t, 3, rt.uploads)
+		require.False(t, rt.updated)
+
+		_, err = os.Stat(journalFile)
+		require.NoError(t, err)
+	})
+
+	t.Run("Resume with different URL", func(t *testing.T) {
+		rt := &contentTransport{doc: docBytes}
+
+		args := append([]string{}, args...)
+		args[1] = "http://localhost:48426/content/v1"
+
+		err := newMockCmd(t, rt, append(args, "--resume")...).Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "cannot be resumed")
+	})
+
+	t.Run("Resume", func(t *testing.T) {
+		rt := &contentTransport{doc: docBytes}
+
+		w := &mocks.Writer{}
+		require.NoError(t, newMockCmdWithReaderWriter(t, &mocks.Reader{}, w, rt, append(args, "--resume")...).Execute())
+		require.Contains(t, w.Written(), fmt.Sprintf(msgSkipped, "person.schema.json"))
+		require.Contains(t, w.Written(), fmt.Sprintf(msgUploaded, "other.schema.json", 1, 1))
+		require.Contains(t, w.Written(), `{"Name":"person.schema.json","ID":"TbVyraOqG00TacPQH5WwWGnxkszpYSEhBKRyX_f25JI=","ContentType":"application/json"}`)
+		require.Equal(t, 1, rt.uploads)
+		require.True(t, rt.updated)
+
+		// The journal is removed once the index document is updated
+		_, err = os.Stat(journalFile)
+		require.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("Parallel", func(t *testing.T) {
+		rt := &contentTransport{doc: docBytes, fail: map[string]int{"other.schema.json": 1}}
+
+		w := &mocks.Writer{}
+		require.NoError(t, newMockCmdWithReaderWriter(t, &mocks.Reader{}, w, rt, append(args, "--parallel", "2")...).Execute())
+		require.Contains(t, w.Written(), "(2/2)")
+		require.Equal(t, 3, rt.uploads)
+		require.True(t, rt.updated)
+	})
+}
+
+func TestUploadCmd_UploadMode(t *testing.T) {
+	const (
+		url    = "http://localhost:48326/content/v1"
+		idxURL = "http://localhost:48326/file/identifiers/file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA=="
+	)
+
+	dir, err := ioutil.TempDir("", "uploadcmd")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+	otherFile := filepath.Join(dir, "other.schema.json")
+	require.NoError(t, ioutil.WriteFile(otherFile, []byte(`{"title":"Other"}`), 0600))
+
+	args := []string{"--url", url, "--files", "./testdata/person.schema.json;" + otherFile, "--idxurl", idxURL, "--nextupdatekey", nextUpdateKey,
+		"--signingkey", signingKey, "--journal", filepath.Join(dir, "upload.json"), "--parallel", "1", "--noprompt"}
+
+	docBytes, err := json.Marshal(&model.FileIndexDoc{FileIndex: model.FileIndex{BasePath: "/content/v1"}})
+	require.NoError(t, err)
+
+	t.Run("Raw", func(t *testing.T) {
+		rt := &contentTransport{doc: docBytes}
+
+		w := &mocks.Writer{}
+		require.NoError(t, newMockCmdWithReaderWriter(t, &mocks.Reader{}, w, rt, append(args, "--uploadmode", "raw")...).Execute())
+		require.Contains(t, w.Written(), `{"Name":"person.schema.json","ID":"TbVyraOqG00TacPQH5WwWGnxkszpYSEhBKRyX_f25JI=","ContentType":"application/json"}`)
+		require.Equal(t, 2, rt.rawUploads)
+		require.True(t, rt.updated)
+	})
+
+	t.Run("Auto - raw rejected", func(t *testing.T) {
+		rt := &contentTransport{doc: docBytes, rejectRaw: true}
+
+		require.NoError(t, newMockCmd(t, rt, append(args, "--uploadmode", "auto")...).Execute())
+
+		// Once the raw upload is rejected, the remaining files are uploaded as JSON
+		require.Equal(t, 3, rt.uploads)
+		require.Equal(t, 0, rt.rawUploads)
+		require.True(t, rt.updated)
+	})
+
+	t.Run("Multipart - ID mismatch", func(t *testing.T) {
+		rt := &contentTransport{doc: docBytes}
+
+		err := newMockCmd(t, rt, append(args, "--uploadmode", "multipart")...).Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "the server may not support --uploadmode multipart")
+		require.False(t, rt.updated)
+	})
+}
+
+// contentTransport resolves the given file index document and returns the DCAS ID of each uploaded file. The upload
+// of a file fails with a 503 the given number of times. Raw uploads are rejected with a 415 if rejectRaw is true.
+type contentTransport struct {
+	doc       []byte
+	fail      map[string]int
+	rejectRaw bool
+
+	mutex      sync.Mutex
+	uploads    int
+	rawUploads int
+	updated    bool
+}
+
+func (m *contentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method == http.MethodGet {
+		return &http.Response{StatusCode: http.StatusOK, Body: mocks.NewResponseBody(m.doc)}, nil
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if strings.HasSuffix(req.URL.Path, "/operations") {
+		m.updated = true
+
+		return &http.Response{StatusCode: http.StatusOK, Body: mocks.NewResponseBody(nil)}, nil
+	}
+
+	m.uploads++
+
+	reqBytes, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &fileidx.File{ContentType: req.Header.Get("Content-Type"), Content: reqBytes}
+
+	if bytes.HasPrefix(reqBytes, []byte(`{"contentType":`)) {
+		if err := json.Unmarshal(reqBytes, file); err != nil {
+			return nil, err
+		}
+	} else {
+		if m.rejectRaw {
+			return &http.Response{StatusCode: http.StatusUnsupportedMediaType, Body: mocks.NewResponseBody([]byte("unsupported"))}, nil
+		}
+
+		m.rawUploads++
+	}
+
+	if strings.Contains(string(file.Content), "Other") && m.fail["other.schema.json"] > 0 {
+		m.fail["other.schema.json"]--
+
+		return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: mocks.NewResponseBody([]byte("unavailable"))}, nil
+	}
+
+	id, err := fileidx.DCASID(file.ContentType, file.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{StatusCode: http.StatusOK, Body: mocks.NewResponseBody([]byte(`"` + id + `"`))}, nil
+}
+
 func TestContentTypeFromFileName(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		contentType, err := contentTypeFromFileName("file.json")
@@ -355,6 +809,130 @@ func TestContentTypeFromFileName(t *testing.T) {
 	})
 }
 
+func TestGetDirFiles(t *testing.T) {
+	dir := newTestDir(t)
+	defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+	getNames := func(f files) []string {
+		var names []string
+		for _, info := range f {
+			names = append(names, info.Name)
+		}
+
+		return names
+	}
+
+	t.Run("Include", func(t *testing.T) {
+		f, err := getDirFiles(dir, []string{"*.json"}, nil)
+		require.NoError(t, err)
+		require.Equal(t, []string{"a/schema.json", "b/schema.json", "drafts/draft.json"}, getNames(f))
+		require.Equal(t, filepath.Join(dir, "a", "schema.json"), f[0].Path)
+	})
+
+	t.Run("Exclude", func(t *testing.T) {
+		f, err := getDirFiles(dir, nil, []string{"drafts", "b/*"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"a/schema.json", "image.png"}, getNames(f))
+	})
+
+	t.Run("Invalid pattern", func(t *testing.T) {
+		_, err := getDirFiles(dir, []string{"["}, nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid pattern")
+	})
+
+	t.Run("Directory not found", func(t *testing.T) {
+		_, err := getDirFiles(filepath.Join(dir, "xxx"), nil, nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "error reading directory")
+	})
+}
+
+func TestGetUpdatePatch(t *testing.T) {
+	fileIdx := &model.FileIndex{Mappings: map[string]string{"a/schema.json": "id1"}}
+
+	patch := getUpdatePatch(fileIdx, files{{Name: "a/schema.json", ID: "id2"}, {Name: "b.json", ID: "id3"}})
+	require.Equal(t, []fileidx.Patch{
+		{Op: fileidx.OpReplace, Path: "/fileIndex/mappings/a~1schema.json", Value: "id2"},
+		{Op: fileidx.OpAdd, Path: "/fileIndex/mappings/b.json", Value: "id3"},
+	}, patch)
+}
+
+// newTestDir creates a temporary directory with the following structure:
+// a/schema.json, b/schema.json, drafts/draft.json, image.png
+func newTestDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "uploadcmd")
+	require.NoError(t, err)
+
+	for _, name := range []string{"a/schema.json", "b/schema.json", "drafts/draft.json", "image.png"} {
+		filePath := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0700))
+		require.NoError(t, ioutil.WriteFile(filePath, []byte("{}"), 0600))
+	}
+
+	return dir
+}
+
+// sidetreeTransport simulates the content endpoint, which returns the given DCAS ID, and a Sidetree node that
+// resolves the given document. Once the document is updated, the document is resolved with the patched mappings
+// and the update commitment of the update request.
+type sidetreeTransport struct {
+	t        *testing.T
+	doc      *model.FileIndexDoc
+	dcasID   string
+	metadata string
+}
+
+func (m *sidetreeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method == http.MethodPost && !strings.HasSuffix(req.URL.Path, "/operations") {
+		return &http.Response{StatusCode: http.StatusOK, Body: mocks.NewResponseBody([]byte(m.dcasID))}, nil
+	}
+
+	if req.Method == http.MethodPost {
+		reqBytes, err := ioutil.ReadAll(req.Body)
+		require.NoError(m.t, err)
+
+		updateReq := &struct {
+			Delta struct {
+				UpdateCommitment string `json:"updateCommitment"`
+				Patches          []struct {
+					Patches []fileidx.Patch `json:"patches"`
+				} `json:"patches"`
+			} `json:"delta"`
+		}{}
+		require.NoError(m.t, json.Unmarshal(reqBytes, updateReq))
+
+		doc := *m.doc
+		doc.FileIndex.Mappings = make(map[string]string)
+
+		for _, p := range updateReq.Delta.Patches[0].Patches {
+			doc.FileIndex.Mappings[strings.TrimPrefix(p.Path, "/fileIndex/mappings/")] = p.Value
+		}
+
+		m.doc = &doc
+		m.metadata = fmt.Sprintf(`{"updateCommitment":"%s","published":true}`, updateReq.Delta.UpdateCommitment)
+
+		return &http.Response{StatusCode: http.StatusOK, Body: mocks.NewResponseBody(nil)}, nil
+	}
+
+	if strings.HasSuffix(req.URL.Path, "/protocol") {
+		return &http.Response{StatusCode: http.StatusNotFound, Body: mocks.NewResponseBody(nil)}, nil
+	}
+
+	docBytes, err := json.Marshal(m.doc)
+	require.NoError(m.t, err)
+
+	resolution := &model.DIDResolution{DIDDocument: docBytes}
+	if m.metadata != "" {
+		resolution.MethodMetadata = json.RawMessage(m.metadata)
+	}
+
+	respBytes, err := json.Marshal(resolution)
+	require.NoError(m.t, err)
+
+	return &http.Response{StatusCode: http.StatusOK, Body: mocks.NewResponseBody(respBytes)}, nil
+}
+
 func newMockCmd(t *testing.T, rt http.RoundTripper, args ...string) *cobra.Command {
 	return newMockCmdWithReaderWriter(t, &mocks.Reader{}, &mocks.Writer{}, rt, args...)
 }
@@ -363,6 +941,7 @@ func newMockCmdWithReaderWriter(t *testing.T, in io.Reader, w io.Writer, transpo
 	settings := environment.NewDefaultSettings()
 	settings.Streams.Out = w
 	settings.Streams.In = in
+	settings.Home = environment.Home(filepath.Join(os.TempDir(), "uploadcmd"))
 
 	settings.Config.CurrentContext = "testctx"
 	settings.Config.Contexts[settings.Config.CurrentContext] = &environment.Context{}
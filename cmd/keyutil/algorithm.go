@@ -0,0 +1,62 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package keyutil
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/pkg/errors"
+)
+
+// JWS algorithms
+const (
+	AlgES256  = "ES256"
+	AlgES384  = "ES384"
+	AlgES512  = "ES512"
+	AlgES256K = "ES256K"
+	AlgEdDSA  = "EdDSA"
+	AlgRS256  = "RS256"
+)
+
+// ErrUnsupportedKey indicates that the key type is not supported
+var ErrUnsupportedKey = errors.New("unsupported key type")
+
+// Algorithm returns the JWS algorithm for the given public key. EC (P-256, P-384, P-521 and secp256k1),
+// Ed25519 and RSA (RS256) keys are supported.
+func Algorithm(publicKey crypto.PublicKey) (string, error) {
+	switch key := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		return ECAlgorithm(key.Curve)
+	case ed25519.PublicKey:
+		return AlgEdDSA, nil
+	case *rsa.PublicKey:
+		return AlgRS256, nil
+	default:
+		return "", ErrUnsupportedKey
+	}
+}
+
+// ECAlgorithm returns the JWS algorithm for the given curve
+func ECAlgorithm(curve elliptic.Curve) (string, error) {
+	switch curve {
+	case elliptic.P256():
+		return AlgES256, nil
+	case elliptic.P384():
+		return AlgES384, nil
+	case elliptic.P521():
+		return AlgES512, nil
+	case btcec.S256():
+		return AlgES256K, nil
+	default:
+		return "", errors.Errorf("unsupported curve [%s]", curve.Params().Name)
+	}
+}
@@ -0,0 +1,40 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package keyutil
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlgorithm(t *testing.T) {
+	for alg, publicKey := range map[string]crypto.PublicKey{
+		AlgES256:  &ecdsa.PublicKey{Curve: elliptic.P256()},
+		AlgES384:  &ecdsa.PublicKey{Curve: elliptic.P384()},
+		AlgES512:  &ecdsa.PublicKey{Curve: elliptic.P521()},
+		AlgES256K: &ecdsa.PublicKey{Curve: btcec.S256()},
+		AlgEdDSA:  ed25519.PublicKey{},
+		AlgRS256:  &rsa.PublicKey{},
+	} {
+		a, err := Algorithm(publicKey)
+		require.NoError(t, err)
+		require.Equal(t, alg, a)
+	}
+
+	_, err := Algorithm(&ecdsa.PublicKey{Curve: elliptic.P224()})
+	require.EqualError(t, err, "unsupported curve [P-224]")
+
+	_, err = Algorithm("invalid")
+	require.Equal(t, ErrUnsupportedKey, err)
+}
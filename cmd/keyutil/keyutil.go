@@ -0,0 +1,414 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package keyutil
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/pkg/errors"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+)
+
+const (
+	certificateBlockType = "CERTIFICATE"
+	privateKeyBlockType  = "PRIVATE KEY"
+	ecPrivateKeyType     = "EC PRIVATE KEY"
+	publicKeyBlockType   = "PUBLIC KEY"
+
+	ecPrivateKeyVersion = 1
+
+	ktyEC  = "EC"
+	ktyOKP = "OKP"
+
+	crvP256      = "P-256"
+	crvP384      = "P-384"
+	crvP521      = "P-521"
+	crvSecp256k1 = "secp256k1"
+	crvEd25519   = "Ed25519"
+)
+
+// Key types that may be generated
+const (
+	KeyTypeP256      = crvP256
+	KeyTypeSecp256k1 = crvSecp256k1
+	KeyTypeEd25519   = crvEd25519
+)
+
+var (
+	oidPublicKeyEC    = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidCurveSecp256k1 = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+)
+
+var (
+	// ErrPublicKeyNotFoundInPEM indicates that the PEM does not contain a public key
+	ErrPublicKeyNotFoundInPEM = errors.New("public key not found in PEM")
+
+	// ErrPrivateKeyNotFoundInPEM indicates that the PEM does not contain a private key
+	ErrPrivateKeyNotFoundInPEM = errors.New("private key not found in PEM")
+)
+
+// PublicKeyFromFile loads the public key from the given file. The file may contain
+// a PEM-encoded public key or X.509 certificate, or a public key in JWK format.
+func PublicKeyFromFile(file string) (crypto.PublicKey, error) {
+	keyBytes, err := ioutil.ReadFile(filepath.Clean(file))
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(keyBytes), []byte("{")) {
+		jwk := &jws.JWK{}
+		if err := json.Unmarshal(keyBytes, jwk); err != nil {
+			return nil, errors.WithMessage(err, "invalid JWK")
+		}
+
+		return PublicKeyFromJWK(jwk)
+	}
+
+	return PublicKeyFromPEM(keyBytes)
+}
+
+// PublicKeyFromPEM parses the public key from the given PEM. The PEM may contain
+// either a PKIX public key or an X.509 certificate.
+func PublicKeyFromPEM(pubKeyPEM []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(pubKeyPEM)
+	if block == nil {
+		return nil, ErrPublicKeyNotFoundInPEM
+	}
+
+	if block.Type == certificateBlockType {
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		return cert.PublicKey, nil
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		// The x509 package doesn't support the secp256k1 curve
+		if secp256k1Key, e := parseSecp256k1PublicKey(block.Bytes); e == nil {
+			return secp256k1Key, nil
+		}
+
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// PrivateKeyFromFile loads the private key from the given PEM file
+func PrivateKeyFromFile(file string) (crypto.PrivateKey, error) {
+	keyBytes, err := ioutil.ReadFile(filepath.Clean(file))
+	if err != nil {
+		return nil, err
+	}
+
+	return PrivateKeyFromPEM(keyBytes)
+}
+
+// PrivateKeyFromPEM parses the private key from the given PEM. The key may
+// be encoded in PKCS#8, SEC 1 (EC, including secp256k1) or PKCS#1 (RSA) format.
+func PrivateKeyFromPEM(privateKeyPEM []byte) (crypto.PrivateKey, error) {
+	block, _ := pem.Decode(privateKeyPEM)
+	if block == nil {
+		return nil, ErrPrivateKeyNotFoundInPEM
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	if key, err := parseSecp256k1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	return nil, errors.Errorf("unsupported private key type in PEM block [%s]", block.Type)
+}
+
+// PublicKeyFromJWK returns the public key for the given JWK. EC (P-256, P-384, P-521 and
+// secp256k1) and OKP (Ed25519) keys are supported.
+func PublicKeyFromJWK(jwk *jws.JWK) (crypto.PublicKey, error) {
+	if err := jwk.Validate(); err != nil {
+		return nil, err
+	}
+
+	x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+	if err != nil {
+		return nil, errors.WithMessage(err, "invalid JWK x")
+	}
+
+	switch jwk.Kty {
+	case ktyEC:
+		return ecPublicKeyFromJWK(jwk, x)
+	case ktyOKP:
+		if jwk.Crv != crvEd25519 || len(x) != ed25519.PublicKeySize {
+			return nil, errors.Errorf("unsupported OKP key [%s]", jwk.Crv)
+		}
+
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, errors.Errorf("unsupported JWK key type [%s]", jwk.Kty)
+	}
+}
+
+func ecPublicKeyFromJWK(jwk *jws.JWK, x []byte) (crypto.PublicKey, error) {
+	curve, err := curveFromName(jwk.Crv)
+	if err != nil {
+		return nil, err
+	}
+
+	y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+	if err != nil {
+		return nil, errors.WithMessage(err, "invalid JWK y")
+	}
+
+	publicKey := &ecdsa.PublicKey{
+		Curve: curve,
+		X:     new(big.Int).SetBytes(x),
+		Y:     new(big.Int).SetBytes(y),
+	}
+
+	if !curve.IsOnCurve(publicKey.X, publicKey.Y) {
+		return nil, errors.New("JWK point is not on the curve")
+	}
+
+	return publicKey, nil
+}
+
+func curveFromName(crv string) (elliptic.Curve, error) {
+	switch crv {
+	case crvP256:
+		return elliptic.P256(), nil
+	case crvP384:
+		return elliptic.P384(), nil
+	case crvP521:
+		return elliptic.P521(), nil
+	case crvSecp256k1:
+		return btcec.S256(), nil
+	default:
+		return nil, errors.Errorf("unsupported curve [%s]", crv)
+	}
+}
+
+// GenerateKeyPair generates a new key pair of the given type (P-256, secp256k1 or Ed25519) and returns
+// the PEM-encoded private and public keys
+func GenerateKeyPair(keyType string) (privateKeyPEM, publicKeyPEM []byte, err error) {
+	privateKey, err := generatePrivateKey(keyType)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	privateKeyPEM, err = PrivateKeyToPEM(privateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	publicKeyPEM, err = PublicKeyToPEM(privateKey.Public())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return privateKeyPEM, publicKeyPEM, nil
+}
+
+// PrivateKeyToPEM encodes the given private key as a PKCS#8 PEM or, for secp256k1 (which
+// isn't supported by PKCS#8 in the x509 package), as a SEC 1 PEM
+func PrivateKeyToPEM(privateKey crypto.PrivateKey) ([]byte, error) {
+	if key, ok := privateKey.(*ecdsa.PrivateKey); ok && key.Curve == btcec.S256() {
+		keyBytes, err := marshalSecp256k1PrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+
+		return pem.EncodeToMemory(&pem.Block{Type: ecPrivateKeyType, Bytes: keyBytes}), nil
+	}
+
+	keyBytes, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: privateKeyBlockType, Bytes: keyBytes}), nil
+}
+
+// PublicKeyToPEM encodes the given public key as a PKIX PEM
+func PublicKeyToPEM(publicKey crypto.PublicKey) ([]byte, error) {
+	var keyBytes []byte
+	var err error
+
+	if key, ok := publicKey.(*ecdsa.PublicKey); ok && key.Curve == btcec.S256() {
+		keyBytes, err = marshalSecp256k1PublicKey(key)
+	} else {
+		keyBytes, err = x509.MarshalPKIXPublicKey(publicKey)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: publicKeyBlockType, Bytes: keyBytes}), nil
+}
+
+func generatePrivateKey(keyType string) (crypto.Signer, error) {
+	switch keyType {
+	case KeyTypeP256:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case KeyTypeSecp256k1:
+		return ecdsa.GenerateKey(btcec.S256(), rand.Reader)
+	case KeyTypeEd25519:
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+
+		return privateKey, nil
+	default:
+		return nil, errors.Errorf("unsupported key type [%s]", keyType)
+	}
+}
+
+// ecPrivateKey is the SEC 1 (RFC 5915) structure of an EC private key
+type ecPrivateKey struct {
+	Version       int
+	PrivateKey    []byte
+	NamedCurveOID asn1.ObjectIdentifier `asn1:"optional,explicit,tag:0"`
+	PublicKey     asn1.BitString        `asn1:"optional,explicit,tag:1"`
+}
+
+// pkcs8 is the PKCS#8 (RFC 5208) structure of a private key
+type pkcs8 struct {
+	Version    int
+	Algo       pkix.AlgorithmIdentifier
+	PrivateKey []byte
+}
+
+// publicKeyInfo is the PKIX (RFC 5280) structure of a public key
+type publicKeyInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+// parseSecp256k1PrivateKey parses a secp256k1 private key in either SEC 1 or PKCS#8 format
+func parseSecp256k1PrivateKey(der []byte) (*ecdsa.PrivateKey, error) {
+	// The curve is given either by the PKCS#8 algorithm parameters or by the SEC 1 named curve
+	curveSpecified := false
+
+	var p8 pkcs8
+	if _, err := asn1.Unmarshal(der, &p8); err == nil && p8.Algo.Algorithm.Equal(oidPublicKeyEC) {
+		if !isSecp256k1(p8.Algo.Parameters.FullBytes) {
+			return nil, errors.New("not a secp256k1 key")
+		}
+
+		der = p8.PrivateKey
+		curveSpecified = true
+	}
+
+	var key ecPrivateKey
+	if _, err := asn1.Unmarshal(der, &key); err != nil {
+		return nil, err
+	}
+
+	if len(key.NamedCurveOID) > 0 {
+		if !key.NamedCurveOID.Equal(oidCurveSecp256k1) {
+			return nil, errors.New("not a secp256k1 key")
+		}
+
+		curveSpecified = true
+	}
+
+	if !curveSpecified {
+		return nil, errors.New("curve not specified")
+	}
+
+	privateKey, _ := btcec.PrivKeyFromBytes(btcec.S256(), key.PrivateKey)
+
+	return privateKey.ToECDSA(), nil
+}
+
+// parseSecp256k1PublicKey parses a secp256k1 public key in PKIX format
+func parseSecp256k1PublicKey(der []byte) (*ecdsa.PublicKey, error) {
+	var info publicKeyInfo
+	if _, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, err
+	}
+
+	if !info.Algorithm.Algorithm.Equal(oidPublicKeyEC) || !isSecp256k1(info.Algorithm.Parameters.FullBytes) {
+		return nil, errors.New("not a secp256k1 key")
+	}
+
+	publicKey, err := btcec.ParsePubKey(info.PublicKey.RightAlign(), btcec.S256())
+	if err != nil {
+		return nil, err
+	}
+
+	return publicKey.ToECDSA(), nil
+}
+
+func marshalSecp256k1PrivateKey(key *ecdsa.PrivateKey) ([]byte, error) {
+	privateKeyBytes := make([]byte, (key.Curve.Params().BitSize+7)/8)
+	d := key.D.Bytes()
+	copy(privateKeyBytes[len(privateKeyBytes)-len(d):], d)
+
+	publicKeyBytes := (*btcec.PublicKey)(&key.PublicKey).SerializeUncompressed()
+
+	return asn1.Marshal(ecPrivateKey{
+		Version:       ecPrivateKeyVersion,
+		PrivateKey:    privateKeyBytes,
+		NamedCurveOID: oidCurveSecp256k1,
+		PublicKey:     asn1.BitString{Bytes: publicKeyBytes, BitLength: 8 * len(publicKeyBytes)},
+	})
+}
+
+func marshalSecp256k1PublicKey(key *ecdsa.PublicKey) ([]byte, error) {
+	params, err := asn1.Marshal(oidCurveSecp256k1)
+	if err != nil {
+		return nil, err
+	}
+
+	keyBytes := (*btcec.PublicKey)(key).SerializeUncompressed()
+
+	return asn1.Marshal(publicKeyInfo{
+		Algorithm: pkix.AlgorithmIdentifier{
+			Algorithm:  oidPublicKeyEC,
+			Parameters: asn1.RawValue{FullBytes: params},
+		},
+		PublicKey: asn1.BitString{Bytes: keyBytes, BitLength: 8 * len(keyBytes)},
+	})
+}
+
+func isSecp256k1(params []byte) bool {
+	var oid asn1.ObjectIdentifier
+	if _, err := asn1.Unmarshal(params, &oid); err != nil {
+		return false
+	}
+
+	return oid.Equal(oidCurveSecp256k1)
+}
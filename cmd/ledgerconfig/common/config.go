@@ -6,6 +6,12 @@ SPDX-License-Identifier: Apache-2.0
 
 package common
 
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
 // Format specifies the format of the configuration
 type Format string
 
@@ -56,3 +62,73 @@ type Component struct {
 	// Tags contains optional tags that describe the data
 	Tags []string `json:",omitempty"`
 }
+
+const (
+	// FormatEncrypted indicates that the config is an encrypted envelope. The original
+	// format of the config is stored inside the envelope.
+	FormatEncrypted Format = "ENCRYPTED"
+)
+
+// NewConfigFromKeyValues returns a Config that contains the given key-values. The key-values
+// are grouped by peer and application (in the order in which they are provided). All of the
+// key-values must belong to the same MSP.
+func NewConfigFromKeyValues(kvs []*KeyValue) (*Config, error) {
+	if len(kvs) == 0 {
+		return nil, errors.New("no key-values provided")
+	}
+
+	cfg := &Config{MspID: kvs[0].MspID}
+
+	peers := make(map[string]*Peer)
+	apps := make(map[string]*App)
+
+	for _, kv := range kvs {
+		if kv.MspID != cfg.MspID {
+			return nil, errors.Errorf("all key-values must belong to MSP [%s] but found [%s]", cfg.MspID, kv.MspID)
+		}
+
+		appKey := strings.Join([]string{kv.PeerID, kv.AppName, kv.AppVersion}, keyDivider)
+
+		app, ok := apps[appKey]
+		if !ok {
+			app = &App{AppName: kv.AppName, Version: kv.AppVersion}
+			apps[appKey] = app
+
+			cfg.addApp(peers, kv.PeerID, app)
+		}
+
+		if kv.ComponentName == "" {
+			app.Format = kv.Format
+			app.Config = kv.Config
+			app.Tags = kv.Tags
+
+			continue
+		}
+
+		app.Components = append(app.Components, &Component{
+			Name:    kv.ComponentName,
+			Version: kv.ComponentVersion,
+			Format:  kv.Format,
+			Config:  kv.Config,
+			Tags:    kv.Tags,
+		})
+	}
+
+	return cfg, nil
+}
+
+func (cfg *Config) addApp(peers map[string]*Peer, peerID string, app *App) {
+	if peerID == "" {
+		cfg.Apps = append(cfg.Apps, app)
+		return
+	}
+
+	peer, ok := peers[peerID]
+	if !ok {
+		peer = &Peer{PeerID: peerID}
+		peers[peerID] = peer
+		cfg.Peers = append(cfg.Peers, peer)
+	}
+
+	peer.Apps = append(peer.Apps, app)
+}
@@ -0,0 +1,55 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConfigFromKeyValues(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		kvs := []*KeyValue{
+			{Key: &Key{MspID: "msp1", PeerID: "peer1", AppName: "app1", AppVersion: "1"}, Value: &Value{Format: "JSON", Config: "{}"}},
+			{Key: &Key{MspID: "msp1", PeerID: "peer1", AppName: "app1", AppVersion: "1", ComponentName: "comp1", ComponentVersion: "1"}, Value: &Value{Format: "Other", Config: "c1"}},
+			{Key: &Key{MspID: "msp1", PeerID: "peer2", AppName: "app1", AppVersion: "1", ComponentName: "comp1", ComponentVersion: "1"}, Value: &Value{Format: "Other", Config: "c2"}},
+			{Key: &Key{MspID: "msp1", AppName: "app2", AppVersion: "1"}, Value: &Value{Format: "Other", Config: "a2", Tags: []string{"tag1"}}},
+		}
+
+		cfg, err := NewConfigFromKeyValues(kvs)
+		require.NoError(t, err)
+		require.Equal(t, "msp1", cfg.MspID)
+
+		require.Len(t, cfg.Peers, 2)
+		require.Equal(t, "peer1", cfg.Peers[0].PeerID)
+		require.Len(t, cfg.Peers[0].Apps, 1)
+		require.Equal(t, "{}", cfg.Peers[0].Apps[0].Config)
+		require.Len(t, cfg.Peers[0].Apps[0].Components, 1)
+		require.Equal(t, "c1", cfg.Peers[0].Apps[0].Components[0].Config)
+		require.Equal(t, "peer2", cfg.Peers[1].PeerID)
+		require.Empty(t, cfg.Peers[1].Apps[0].Config)
+		require.Equal(t, "c2", cfg.Peers[1].Apps[0].Components[0].Config)
+
+		require.Len(t, cfg.Apps, 1)
+		require.Equal(t, "app2", cfg.Apps[0].AppName)
+		require.Equal(t, []string{"tag1"}, cfg.Apps[0].Tags)
+	})
+	t.Run("No key-values", func(t *testing.T) {
+		_, err := NewConfigFromKeyValues(nil)
+		require.EqualError(t, err, "no key-values provided")
+	})
+	t.Run("Multiple MSPs", func(t *testing.T) {
+		kvs := []*KeyValue{
+			{Key: &Key{MspID: "msp1", AppName: "app1", AppVersion: "1"}, Value: &Value{}},
+			{Key: &Key{MspID: "msp2", AppName: "app1", AppVersion: "1"}, Value: &Value{}},
+		}
+
+		_, err := NewConfigFromKeyValues(kvs)
+		require.EqualError(t, err, "all key-values must belong to MSP [msp1] but found [msp2]")
+	})
+}
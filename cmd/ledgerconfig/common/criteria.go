@@ -31,3 +31,18 @@ type Criteria struct {
 	// ComponentVersion is the version of the application component config
 	ComponentVersion string `json:",omitempty"`
 }
+
+// Matches returns true if the given key matches the criteria. Fields that are not
+// specified in the criteria match any value.
+func (c *Criteria) Matches(k *Key) bool {
+	return matches(c.MspID, k.MspID) &&
+		matches(c.PeerID, k.PeerID) &&
+		matches(c.AppName, k.AppName) &&
+		matches(c.AppVersion, k.AppVersion) &&
+		matches(c.ComponentName, k.ComponentName) &&
+		matches(c.ComponentVersion, k.ComponentVersion)
+}
+
+func matches(criteria, value string) bool {
+	return criteria == "" || criteria == value
+}
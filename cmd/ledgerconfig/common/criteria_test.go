@@ -0,0 +1,39 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCriteria_Matches(t *testing.T) {
+	key := &Key{MspID: "msp1", PeerID: "peer1", AppName: "app1", AppVersion: "v1", ComponentName: "comp1", ComponentVersion: "v1"}
+
+	require.True(t, (&Criteria{}).Matches(key))
+	require.True(t, (&Criteria{MspID: "msp1"}).Matches(key))
+	require.True(t, (&Criteria{MspID: "msp1", AppName: "app1", ComponentName: "comp1"}).Matches(key))
+	require.False(t, (&Criteria{MspID: "msp2"}).Matches(key))
+	require.False(t, (&Criteria{MspID: "msp1", PeerID: "peer2"}).Matches(key))
+	require.False(t, (&Criteria{AppName: "app1", ComponentVersion: "v2"}).Matches(key))
+}
+
+func TestUnmarshalKey(t *testing.T) {
+	key, err := UnmarshalKey("msp1!peer1!app1!v1!comp1!v2")
+	require.NoError(t, err)
+	require.Equal(t, &Key{MspID: "msp1", PeerID: "peer1", AppName: "app1", AppVersion: "v1", ComponentName: "comp1", ComponentVersion: "v2"}, key)
+	require.Equal(t, "msp1!peer1!app1!v1!comp1!v2", key.Marshal())
+
+	key, err = UnmarshalKey("msp1!!app1!v1!!")
+	require.NoError(t, err)
+	require.Equal(t, &Key{MspID: "msp1", AppName: "app1", AppVersion: "v1"}, key)
+	require.Equal(t, "msp1!!app1!v1!!", key.Marshal())
+
+	_, err = UnmarshalKey("msp1!app1")
+	require.EqualError(t, err, "invalid config key [msp1!app1]")
+}
@@ -80,26 +80,56 @@ func NewCriteriaBaseCommand(settings *environment.Settings, p basecmd.FactoryPro
 	return c
 }
 
-// Validate validates the flags
+// Validate validates the flags. Either --criteria or (at least) --mspid must be specified.
 func (c *CriteriaBaseCommand) Validate() error {
-	if c.criteriaStr != "" {
-		if c.mspID != "" || c.peerID != "" || c.appName != "" || c.appVersion != "" || c.componentName != "" || c.componentVersion != "" {
-			return errors.New(errCriteriaMustBeAlone)
-		}
-
-		// Validate the criteria
-		criteria := &Criteria{}
-		if err := json.Unmarshal([]byte(c.criteriaStr), criteria); err != nil {
-			return errors.WithMessagef(err, errInvalidCriteria)
-		}
-	} else {
-		if c.mspID == "" {
-			return errors.New(errMspOrCriteriaRequired)
-		}
+	if c.criteriaStr == "" && c.mspID == "" {
+		return errors.New(errMspOrCriteriaRequired)
+	}
+
+	return c.ValidateCriteria()
+}
+
+// ValidateCriteria ensures that --criteria, if specified, is valid and isn't used together with the other
+// criteria flags. Unlike Validate, none of the criteria fields are required.
+func (c *CriteriaBaseCommand) ValidateCriteria() error {
+	if c.criteriaStr == "" {
+		return nil
 	}
+
+	if c.mspID != "" || c.peerID != "" || c.appName != "" || c.appVersion != "" || c.componentName != "" || c.componentVersion != "" {
+		return errors.New(errCriteriaMustBeAlone)
+	}
+
+	// Validate the criteria
+	criteria := &Criteria{}
+	if err := json.Unmarshal([]byte(c.criteriaStr), criteria); err != nil {
+		return errors.WithMessagef(err, errInvalidCriteria)
+	}
+
 	return nil
 }
 
+// IsCriteriaSpecified returns true if criteria was specified using any of the criteria flags
+func (c *CriteriaBaseCommand) IsCriteriaSpecified() bool {
+	return c.criteriaStr != "" || c.mspID != "" || c.peerID != "" || c.appName != "" ||
+		c.appVersion != "" || c.componentName != "" || c.componentVersion != ""
+}
+
+// GetCriteria returns the Criteria
+func (c *CriteriaBaseCommand) GetCriteria() (*Criteria, error) {
+	criteriaBytes, err := c.GetCriteriaBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	criteria := &Criteria{}
+	if err := json.Unmarshal(criteriaBytes, criteria); err != nil {
+		return nil, errors.WithMessagef(err, errInvalidCriteria)
+	}
+
+	return criteria, nil
+}
+
 // GetCriteriaBytes returns the Criteria marshalled as JSON
 func (c *CriteriaBaseCommand) GetCriteriaBytes() ([]byte, error) {
 	if c.criteriaStr != "" {
@@ -87,6 +87,31 @@ func TestCriteriaBaseCommand(t *testing.T) {
 	})
 }
 
+func TestCriteriaBaseCommand_ValidateCriteria(t *testing.T) {
+	validate := func(args ...string) error {
+		mc := &testCmd{}
+		cmd := &cobra.Command{
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return mc.ValidateCriteria()
+			},
+		}
+		mc.CriteriaBaseCommand = newMockCriteriaBaseCmd(t, cmd, nil, args...)
+
+		return cmd.Execute()
+	}
+
+	// None of the fields are required
+	require.NoError(t, validate())
+	require.NoError(t, validate("--appname", "app1"))
+	require.NoError(t, validate("--criteria", `{"AppName":"app1"}`))
+
+	require.EqualError(t, validate("--appname", "app1", "--criteria", "{}"), errCriteriaMustBeAlone)
+
+	err := validate("--criteria", "xxx")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), errInvalidCriteria)
+}
+
 func TestCriteriaBaseCommand_GetConfig(t *testing.T) {
 	factory := &mocks.Factory{}
 	p := func(config *environment.Config) (fabric.Factory, error) { return factory, nil }
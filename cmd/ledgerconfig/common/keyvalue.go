@@ -8,6 +8,16 @@ package common
 
 import (
 	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// keyDivider separates the fields of a key in the configscc state store
+	keyDivider = "!"
+
+	numKeyFields = 6
 )
 
 // Key is used to uniquely identify a specific application configuration and is used as the
@@ -32,6 +42,30 @@ func (k *Key) String() string {
 	return fmt.Sprintf("(MSP:%s),(Peer:%s),(AppName:%s),(AppVersion:%s),(Comp:%s),(CompVersion:%s)", k.MspID, k.PeerID, k.AppName, k.AppVersion, k.ComponentName, k.ComponentVersion)
 }
 
+// Marshal returns the string that is used as the key in the configscc state store,
+// i.e. MspID!PeerID!AppName!AppVersion!ComponentName!ComponentVersion
+func (k *Key) Marshal() string {
+	return strings.Join([]string{k.MspID, k.PeerID, k.AppName, k.AppVersion, k.ComponentName, k.ComponentVersion}, keyDivider)
+}
+
+// UnmarshalKey creates a key from the string that is used as the key in the configscc state store,
+// i.e. MspID!PeerID!AppName!AppVersion!ComponentName!ComponentVersion
+func UnmarshalKey(str string) (*Key, error) {
+	keyParts := strings.Split(str, keyDivider)
+	if len(keyParts) != numKeyFields {
+		return nil, errors.Errorf("invalid config key [%s]", str)
+	}
+
+	return &Key{
+		MspID:            keyParts[0],
+		PeerID:           keyParts[1],
+		AppName:          keyParts[2],
+		AppVersion:       keyParts[3],
+		ComponentName:    keyParts[4],
+		ComponentVersion: keyParts[5],
+	}, nil
+}
+
 // Value contains the configuration data and is persisted as a JSON document in the store.
 type Value struct {
 	// TxID is the ID of the transaction in which the config was stored/updated
@@ -0,0 +1,101 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package envelope
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+
+	"github.com/pkg/errors"
+	"github.com/square/go-jose/v3"
+)
+
+const (
+	// AlgECDHES indicates that the content key is agreed using ephemeral-static ECDH with the Concat KDF (RFC 7518, section 4.6)
+	AlgECDHES = string(jose.ECDH_ES)
+	// AlgRSAOAEP indicates that the content key is wrapped using RSA-OAEP with SHA-256 (RFC 7518, section 4.3)
+	AlgRSAOAEP = string(jose.RSA_OAEP_256)
+	// Enc is the content encryption algorithm
+	Enc = string(jose.A256GCM)
+
+	// formatHeader is the protected header that contains the format of the original content
+	formatHeader jose.HeaderKey = "fmt"
+)
+
+// ErrUnsupportedKey indicates that the key type is not supported
+var ErrUnsupportedKey = errors.New("unsupported key type")
+
+// Seal encrypts the given content for the given recipient public key and returns the envelope, which is a JWE
+// (RFC 7516) in compact serialization: EC keys use ECDH-ES and RSA keys use RSA-OAEP-256, and the content is
+// encrypted using A256GCM. The format of the content is stored in the protected "fmt" header (authenticated but
+// not encrypted) so that it may be restored when the envelope is opened.
+func Seal(content []byte, format string, recipient crypto.PublicKey) ([]byte, error) {
+	var alg jose.KeyAlgorithm
+
+	switch recipient.(type) {
+	case *ecdsa.PublicKey:
+		alg = jose.ECDH_ES
+	case *rsa.PublicKey:
+		alg = jose.RSA_OAEP_256
+	default:
+		return nil, ErrUnsupportedKey
+	}
+
+	encrypter, err := jose.NewEncrypter(jose.A256GCM, jose.Recipient{Algorithm: alg, Key: recipient},
+		(&jose.EncrypterOptions{}).WithHeader(formatHeader, format))
+	if err != nil {
+		return nil, err
+	}
+
+	jwe, err := encrypter.Encrypt(content)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := jwe.CompactSerialize()
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(sealed), nil
+}
+
+// Open decrypts the given envelope (see Seal) using the recipient's private key and
+// returns the content along with its original format.
+func Open(sealed []byte, recipient crypto.PrivateKey) ([]byte, string, error) {
+	jwe, err := jose.ParseEncrypted(string(sealed))
+	if err != nil {
+		return nil, "", errors.WithMessage(err, "invalid envelope")
+	}
+
+	if enc, _ := jwe.Header.ExtraHeaders[jose.HeaderKey("enc")].(string); enc != Enc {
+		return nil, "", errors.Errorf("unsupported envelope encryption [%s]", enc)
+	}
+
+	switch jwe.Header.Algorithm {
+	case AlgECDHES:
+		if _, ok := recipient.(*ecdsa.PrivateKey); !ok {
+			return nil, "", errors.New("an EC private key is required to open the envelope")
+		}
+	case AlgRSAOAEP:
+		if _, ok := recipient.(*rsa.PrivateKey); !ok {
+			return nil, "", errors.New("an RSA private key is required to open the envelope")
+		}
+	default:
+		return nil, "", errors.Errorf("unsupported envelope algorithm [%s]", jwe.Header.Algorithm)
+	}
+
+	content, err := jwe.Decrypt(recipient)
+	if err != nil {
+		return nil, "", errors.WithMessage(err, "unable to decrypt envelope")
+	}
+
+	format, _ := jwe.Header.ExtraHeaders[formatHeader].(string)
+
+	return content, format, nil
+}
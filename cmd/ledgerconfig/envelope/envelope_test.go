@@ -0,0 +1,196 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package envelope
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/square/go-jose/v3"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	content = `{"Username":"admin","Password":"secret"}`
+	format  = "JSON"
+)
+
+func TestSealOpen(t *testing.T) {
+	t.Run("EC", func(t *testing.T) {
+		privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		sealed, err := Seal([]byte(content), format, &privateKey.PublicKey)
+		require.NoError(t, err)
+		require.NotContains(t, string(sealed), "secret")
+
+		header := protectedHeader(t, sealed)
+		require.Equal(t, AlgECDHES, header["alg"])
+		require.Equal(t, Enc, header["enc"])
+		require.Equal(t, format, header["fmt"])
+		require.NotEmpty(t, header["epk"])
+
+		decrypted, f, err := Open(sealed, privateKey)
+		require.NoError(t, err)
+		require.Equal(t, content, string(decrypted))
+		require.Equal(t, format, f)
+
+		otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		_, _, err = Open(sealed, otherKey)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unable to decrypt envelope")
+
+		rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+
+		_, _, err = Open(sealed, rsaKey)
+		require.EqualError(t, err, "an EC private key is required to open the envelope")
+	})
+
+	t.Run("RSA", func(t *testing.T) {
+		privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+
+		sealed, err := Seal([]byte(content), format, &privateKey.PublicKey)
+		require.NoError(t, err)
+
+		header := protectedHeader(t, sealed)
+		require.Equal(t, AlgRSAOAEP, header["alg"])
+		require.Equal(t, Enc, header["enc"])
+
+		decrypted, f, err := Open(sealed, privateKey)
+		require.NoError(t, err)
+		require.Equal(t, content, string(decrypted))
+		require.Equal(t, format, f)
+
+		ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		_, _, err = Open(sealed, ecKey)
+		require.EqualError(t, err, "an RSA private key is required to open the envelope")
+	})
+
+	t.Run("Standard JWE", func(t *testing.T) {
+		privateKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		require.NoError(t, err)
+
+		// An envelope produced by any JWE implementation may be opened
+		encrypter, err := jose.NewEncrypter(jose.A256GCM,
+			jose.Recipient{Algorithm: jose.ECDH_ES, Key: &privateKey.PublicKey}, nil)
+		require.NoError(t, err)
+
+		jwe, err := encrypter.Encrypt([]byte(content))
+		require.NoError(t, err)
+
+		compact, err := jwe.CompactSerialize()
+		require.NoError(t, err)
+
+		decrypted, f, err := Open([]byte(compact), privateKey)
+		require.NoError(t, err)
+		require.Equal(t, content, string(decrypted))
+		require.Empty(t, f)
+
+		// ...and an envelope may be decrypted by any JWE implementation
+		sealed, err := Seal([]byte(content), format, &privateKey.PublicKey)
+		require.NoError(t, err)
+
+		parsed, err := jose.ParseEncrypted(string(sealed))
+		require.NoError(t, err)
+
+		decrypted, err = parsed.Decrypt(privateKey)
+		require.NoError(t, err)
+		require.Equal(t, content, string(decrypted))
+	})
+
+	t.Run("Tampered format", func(t *testing.T) {
+		privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		sealed, err := Seal([]byte(content), format, &privateKey.PublicKey)
+		require.NoError(t, err)
+
+		header := protectedHeader(t, sealed)
+		header["fmt"] = "YAML"
+
+		headerBytes, err := json.Marshal(header)
+		require.NoError(t, err)
+
+		parts := strings.Split(string(sealed), ".")
+		parts[0] = base64.RawURLEncoding.EncodeToString(headerBytes)
+
+		_, _, err = Open([]byte(strings.Join(parts, ".")), privateKey)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unable to decrypt envelope")
+	})
+
+	t.Run("Unsupported key", func(t *testing.T) {
+		publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		_, err = Seal([]byte(content), format, publicKey)
+		require.Equal(t, ErrUnsupportedKey, err)
+	})
+
+	t.Run("Unsupported algorithm", func(t *testing.T) {
+		privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+
+		encrypter, err := jose.NewEncrypter(jose.A128GCM,
+			jose.Recipient{Algorithm: jose.RSA_OAEP_256, Key: &privateKey.PublicKey}, nil)
+		require.NoError(t, err)
+
+		jwe, err := encrypter.Encrypt([]byte(content))
+		require.NoError(t, err)
+
+		compact, err := jwe.CompactSerialize()
+		require.NoError(t, err)
+
+		_, _, err = Open([]byte(compact), privateKey)
+		require.EqualError(t, err, "unsupported envelope encryption [A128GCM]")
+
+		encrypter, err = jose.NewEncrypter(jose.A256GCM,
+			jose.Recipient{Algorithm: jose.RSA1_5, Key: &privateKey.PublicKey}, nil)
+		require.NoError(t, err)
+
+		jwe, err = encrypter.Encrypt([]byte(content))
+		require.NoError(t, err)
+
+		compact, err = jwe.CompactSerialize()
+		require.NoError(t, err)
+
+		_, _, err = Open([]byte(compact), privateKey)
+		require.EqualError(t, err, "unsupported envelope algorithm [RSA1_5]")
+	})
+
+	t.Run("Invalid envelope", func(t *testing.T) {
+		_, _, err := Open([]byte("{"), nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid envelope")
+	})
+}
+
+func protectedHeader(t *testing.T, sealed []byte) map[string]interface{} {
+	parts := strings.Split(string(sealed), ".")
+	require.Len(t, parts, 5)
+
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	require.NoError(t, err)
+
+	header := make(map[string]interface{})
+	require.NoError(t, json.Unmarshal(headerBytes, &header))
+
+	return header
+}
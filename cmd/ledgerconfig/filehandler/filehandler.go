@@ -0,0 +1,252 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package filehandler
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/hyperledger/fabric-cli/pkg/fabric"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/retry"
+	"github.com/pkg/errors"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/common"
+)
+
+const (
+	// AppName is the name of the file handler application in ledger config
+	AppName = "file-handler"
+	// AppVersion is the version of the file handler application config
+	AppVersion = "1"
+	// ComponentVersion is the version of the file handler component (base path) config
+	ComponentVersion = "1"
+
+	// Format is the format of the file handler config
+	Format common.Format = "JSON"
+)
+
+// AuthConfig contains the authorization tokens of a file handler
+type AuthConfig struct {
+	// ReadTokens contains a set of names of tokens for authorizing read requests
+	ReadTokens []string
+	// WriteTokens contains a set of names of tokens for authorizing write requests
+	WriteTokens []string
+}
+
+// Config contains the configuration of a file handler for a given base path
+type Config struct {
+	Authorization AuthConfig
+
+	BasePath       string
+	ChaincodeName  string
+	Collection     string
+	IndexNamespace string
+	IndexDocID     string
+}
+
+// Validate returns an error if any of the required fields are missing or if the
+// base path or index document ID are invalid
+func (c *Config) Validate() error {
+	if c.BasePath == "" {
+		return errors.New("base path is required")
+	}
+
+	if !strings.HasPrefix(c.BasePath, "/") {
+		return errors.Errorf("base path [%s] must begin with '/'", c.BasePath)
+	}
+
+	if c.ChaincodeName == "" {
+		return errors.New("chaincode name is required")
+	}
+
+	if c.Collection == "" {
+		return errors.New("collection is required")
+	}
+
+	if c.IndexNamespace == "" {
+		return errors.New("index namespace is required")
+	}
+
+	if c.IndexDocID != "" {
+		return ValidateIndexDocID(c.IndexDocID, c.IndexNamespace)
+	}
+
+	return nil
+}
+
+// ValidateIndexDocID returns an error if the given file index document ID does not begin with the given namespace
+func ValidateIndexDocID(indexDocID, namespace string) error {
+	if !strings.HasPrefix(indexDocID, namespace) {
+		return errors.Errorf("file index ID must begin with [%s]", namespace)
+	}
+
+	return nil
+}
+
+// Handler contains the file handler config of a given peer
+type Handler struct {
+	*Config
+
+	MspID  string
+	PeerID string
+	TxID   string
+	Tags   []string
+}
+
+// Criteria returns the criteria used to query file handler config. The peer ID and base path are optional.
+func Criteria(mspID, peerID, basePath string) *common.Criteria {
+	criteria := &common.Criteria{
+		MspID:      mspID,
+		PeerID:     peerID,
+		AppName:    AppName,
+		AppVersion: AppVersion,
+	}
+
+	if basePath != "" {
+		criteria.ComponentName = basePath
+		criteria.ComponentVersion = ComponentVersion
+	}
+
+	return criteria
+}
+
+// FromKeyValue returns the file handler that is stored in the given key-value
+func FromKeyValue(kv *common.KeyValue) (*Handler, error) {
+	if kv.Value == nil {
+		return nil, errors.Errorf("no value for file handler config %s", kv.Key)
+	}
+
+	if kv.Format == common.FormatEncrypted {
+		return nil, errors.Errorf("file handler config %s is encrypted", kv.Key)
+	}
+
+	cfg := &Config{}
+	if err := json.Unmarshal([]byte(kv.Config), cfg); err != nil {
+		return nil, errors.WithMessagef(err, "invalid file handler config %s", kv.Key)
+	}
+
+	return &Handler{
+		Config: cfg,
+		MspID:  kv.MspID,
+		PeerID: kv.PeerID,
+		TxID:   kv.TxID,
+		Tags:   kv.Tags,
+	}, nil
+}
+
+// FromKeyValues returns the file handlers that are stored in the given key-values
+func FromKeyValues(kvs []*common.KeyValue) ([]*Handler, error) {
+	var handlers []*Handler
+	for _, kv := range kvs {
+		h, err := FromKeyValue(kv)
+		if err != nil {
+			return nil, err
+		}
+
+		handlers = append(handlers, h)
+	}
+
+	return handlers, nil
+}
+
+// ToKeyValue returns the key-value that is used to store the given file handler config for the given peer.
+// The tags of an existing handler (see FromKeyValue) should be given when it is rewritten so that they're retained.
+func ToKeyValue(mspID, peerID string, cfg *Config, tags []string) (*common.KeyValue, error) {
+	cfgBytes, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &common.KeyValue{
+		Key: &common.Key{
+			MspID:            mspID,
+			PeerID:           peerID,
+			AppName:          AppName,
+			AppVersion:       AppVersion,
+			ComponentName:    cfg.BasePath,
+			ComponentVersion: ComponentVersion,
+		},
+		Value: &common.Value{
+			Format: Format,
+			Config: string(cfgBytes),
+			Tags:   tags,
+		},
+	}, nil
+}
+
+// ParseList splits the given semicolon-separated list into its (non-empty) elements
+func ParseList(list string) []string {
+	var values []string
+	for _, v := range strings.Split(list, ";") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+
+	return values
+}
+
+// PeerDiscoverer discovers the peers of an MSP
+type PeerDiscoverer interface {
+	DiscoverPeers(mspID string) ([]string, error)
+}
+
+// ResolvePeers returns the peers in the given semicolon-separated list or, if allPeers is true,
+// the peers of the given MSP that are found using Fabric discovery
+func ResolvePeers(d PeerDiscoverer, mspID, peers string, allPeers bool) ([]string, error) {
+	if allPeers {
+		return d.DiscoverPeers(mspID)
+	}
+
+	return ParseList(peers), nil
+}
+
+// Query returns the key-values of the file handler config that match the given criteria
+func Query(ch fabric.Channel, criteria *common.Criteria) ([]*common.KeyValue, error) {
+	criteriaBytes, err := json.Marshal(criteria)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := ch.Query(channel.Request{
+		ChaincodeID: common.ConfigSCC,
+		Fcn:         "get",
+		Args:        [][]byte{criteriaBytes},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var kvs []*common.KeyValue
+	if err := json.Unmarshal(resp.Payload, &kvs); err != nil {
+		return nil, errors.WithMessage(err, "invalid config returned from query")
+	}
+
+	return kvs, nil
+}
+
+// Save saves the given key-values in a single transaction
+func Save(ch fabric.Channel, kvs []*common.KeyValue) error {
+	cfg, err := common.NewConfigFromKeyValues(kvs)
+	if err != nil {
+		return err
+	}
+
+	cfgBytes, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+
+	_, err = ch.Execute(channel.Request{
+		ChaincodeID: common.ConfigSCC,
+		Fcn:         "save",
+		Args:        [][]byte{cfgBytes},
+	}, channel.WithRetry(retry.DefaultChannelOpts))
+
+	return err
+}
@@ -0,0 +1,176 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package filehandler
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/common"
+	"github.com/trustbloc/fabric-cli-ext/cmd/mocks"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	newConfig := func() *Config {
+		return &Config{
+			BasePath:       "/content",
+			ChaincodeName:  "files",
+			Collection:     "consortium",
+			IndexNamespace: "file:idx",
+			IndexDocID:     "file:idx:1234",
+		}
+	}
+
+	require.NoError(t, newConfig().Validate())
+
+	cfg := newConfig()
+	cfg.BasePath = ""
+	require.EqualError(t, cfg.Validate(), "base path is required")
+
+	cfg = newConfig()
+	cfg.BasePath = "content"
+	require.EqualError(t, cfg.Validate(), "base path [content] must begin with '/'")
+
+	cfg = newConfig()
+	cfg.ChaincodeName = ""
+	require.EqualError(t, cfg.Validate(), "chaincode name is required")
+
+	cfg = newConfig()
+	cfg.Collection = ""
+	require.EqualError(t, cfg.Validate(), "collection is required")
+
+	cfg = newConfig()
+	cfg.IndexNamespace = ""
+	require.EqualError(t, cfg.Validate(), "index namespace is required")
+
+	cfg = newConfig()
+	cfg.IndexDocID = "file:xxx:1234"
+	require.EqualError(t, cfg.Validate(), "file index ID must begin with [file:idx]")
+}
+
+func TestCriteria(t *testing.T) {
+	require.Equal(t, &common.Criteria{MspID: "msp1", AppName: AppName, AppVersion: AppVersion}, Criteria("msp1", "", ""))
+	require.Equal(t, &common.Criteria{
+		MspID:            "msp1",
+		PeerID:           "peer1",
+		AppName:          AppName,
+		AppVersion:       AppVersion,
+		ComponentName:    "/content",
+		ComponentVersion: ComponentVersion,
+	}, Criteria("msp1", "peer1", "/content"))
+}
+
+func TestKeyValue(t *testing.T) {
+	cfg := &Config{
+		Authorization:  AuthConfig{ReadTokens: []string{"r1"}},
+		BasePath:       "/content",
+		ChaincodeName:  "files",
+		Collection:     "consortium",
+		IndexNamespace: "file:idx",
+	}
+
+	kv, err := ToKeyValue("msp1", "peer1", cfg, []string{"tag1"})
+	require.NoError(t, err)
+	require.Equal(t, "/content", kv.ComponentName)
+	require.Equal(t, Format, kv.Format)
+	require.Equal(t, []string{"tag1"}, kv.Tags)
+
+	handlers, err := FromKeyValues([]*common.KeyValue{kv})
+	require.NoError(t, err)
+	require.Len(t, handlers, 1)
+	require.Equal(t, "msp1", handlers[0].MspID)
+	require.Equal(t, "peer1", handlers[0].PeerID)
+	require.Equal(t, cfg, handlers[0].Config)
+	require.Equal(t, []string{"tag1"}, handlers[0].Tags)
+
+	_, err = FromKeyValue(&common.KeyValue{Key: kv.Key})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no value for file handler config")
+
+	_, err = FromKeyValues([]*common.KeyValue{{Key: kv.Key, Value: &common.Value{Format: common.FormatEncrypted}}})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "is encrypted")
+
+	_, err = FromKeyValue(&common.KeyValue{Key: kv.Key, Value: &common.Value{Config: "{"}})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid file handler config")
+}
+
+func TestParseList(t *testing.T) {
+	require.Empty(t, ParseList(""))
+	require.Equal(t, []string{"peer1", "peer2"}, ParseList("peer1; ;peer2;"))
+}
+
+func TestResolvePeers(t *testing.T) {
+	d := &mockDiscoverer{peers: []string{"peer0", "peer1"}}
+
+	peers, err := ResolvePeers(d, "msp1", "peer2;peer3", false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"peer2", "peer3"}, peers)
+
+	peers, err = ResolvePeers(d, "msp1", "", true)
+	require.NoError(t, err)
+	require.Equal(t, []string{"peer0", "peer1"}, peers)
+	require.Equal(t, "msp1", d.mspID)
+}
+
+func TestQueryAndSave(t *testing.T) {
+	ch := &mocks.Channel{}
+
+	kv, err := ToKeyValue("msp1", "peer1", &Config{BasePath: "/content"}, nil)
+	require.NoError(t, err)
+
+	kvsBytes, err := json.Marshal([]*common.KeyValue{kv})
+	require.NoError(t, err)
+
+	t.Run("Query", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: kvsBytes}, nil)
+
+		kvs, err := Query(ch, Criteria("msp1", "", ""))
+		require.NoError(t, err)
+		require.Len(t, kvs, 1)
+
+		ch.QueryReturns(channel.Response{Payload: []byte("{")}, nil)
+		_, err = Query(ch, Criteria("msp1", "", ""))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid config returned from query")
+
+		errExpected := errors.New("query error")
+		ch.QueryReturns(channel.Response{}, errExpected)
+		_, err = Query(ch, Criteria("msp1", "", ""))
+		require.EqualError(t, err, errExpected.Error())
+	})
+
+	t.Run("Save", func(t *testing.T) {
+		require.NoError(t, Save(ch, []*common.KeyValue{kv}))
+
+		req, _ := ch.ExecuteArgsForCall(ch.ExecuteCallCount() - 1)
+		require.Equal(t, "save", req.Fcn)
+
+		cfg := &common.Config{}
+		require.NoError(t, json.Unmarshal(req.Args[0], cfg))
+		require.Equal(t, "msp1", cfg.MspID)
+		require.Len(t, cfg.Peers, 1)
+
+		require.Error(t, Save(ch, nil))
+	})
+}
+
+type mockDiscoverer struct {
+	peers []string
+	mspID string
+}
+
+func (m *mockDiscoverer) DiscoverPeers(mspID string) ([]string, error) {
+	m.mspID = mspID
+
+	return m.peers, nil
+}
@@ -0,0 +1,249 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package addcmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/basecmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/common"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/filehandler"
+)
+
+const (
+	use      = "add"
+	desc     = "Add a file handler configuration"
+	longDesc = `
+The add command creates the file handler configuration for a base path on one or more peers. The configuration
+is saved in a single transaction. The command fails if the file handler for the base path already exists on any
+of the given peers. The peers may be specified explicitly (--peers) or, if --all-peers is specified, they are
+resolved using Fabric discovery for the channel of the current context and the given MSP.
+`
+	examples = `
+- Add a file handler for /content on two peers in Org1MSP:
+    $ ./fabric ledgerconfig filehandler add --mspid Org1MSP --peers peer0.org1.example.com;peer1.org1.example.com --path /content --chaincode files --collection consortium --idxns file:idx --readtokens content_r --writetokens content_w
+
+- Add a file handler for /content on all peers in Org1MSP:
+    $ ./fabric ledgerconfig filehandler add --mspid Org1MSP --all-peers --path /content --chaincode files --collection consortium --idxns file:idx
+`
+)
+
+const (
+	mspIDFlag  = "mspid"
+	mspIDUsage = "The ID of the MSP. Example: --mspid Org1MSP"
+
+	peersFlag  = "peers"
+	peersUsage = "A semi-colon-separated list of peers. Example: --peers peer0.org1.com;peer1.org1.com"
+
+	allPeersFlag  = "all-peers"
+	allPeersUsage = "If specified then the peers of the MSP are resolved using Fabric discovery. Example: --all-peers"
+
+	basePathFlag  = "path"
+	basePathUsage = "The file handler base path. Example: --path /content"
+
+	chaincodeFlag  = "chaincode"
+	chaincodeUsage = "The name of the chaincode in which files are stored. Example: --chaincode files"
+
+	collectionFlag  = "collection"
+	collectionUsage = "The name of the collection in which files are stored. Example: --collection consortium"
+
+	indexNamespaceFlag  = "idxns"
+	indexNamespaceUsage = "The namespace of the file index Sidetree documents. Example: --idxns file:idx"
+
+	fileIndexIDFlag  = "idxid"
+	fileIndexIDUsage = "The (optional) ID of the file index Sidetree document. Example: --idxid file:idx:1234"
+
+	readTokensFlag  = "readtokens"
+	readTokensUsage = "A semi-colon-separated list of the names of tokens that authorize read requests. Example: --readtokens content_r"
+
+	writeTokensFlag  = "writetokens"
+	writeTokensUsage = "A semi-colon-separated list of the names of tokens that authorize write requests. Example: --writetokens content_w"
+
+	noPromptFlag  = "noprompt"
+	noPromptUsage = "If specified then the add operation will not prompt for confirmation. Example: --noprompt"
+
+	msgConfigAdded     = "File handler successfully added!"
+	msgAborted         = "Operation aborted"
+	msgContinueOrAbort = "Enter Y to continue or N to abort "
+)
+
+var (
+	errMSPRequired   = errors.New("msp (--mspid) is required")
+	errPeersRequired = errors.New("one of --peers or --all-peers must be specified")
+)
+
+// New returns the filehandler add sub-command
+func New(settings *environment.Settings) *cobra.Command {
+	return newCmd(settings, nil)
+}
+
+func newCmd(settings *environment.Settings, p basecmd.FactoryProvider) *cobra.Command {
+	c := &command{
+		Command: basecmd.New(settings, p),
+	}
+
+	cmd := &cobra.Command{
+		Use:     use,
+		Short:   desc,
+		Long:    longDesc,
+		Example: examples,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return c.validate()
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return c.run()
+		},
+	}
+
+	cmd.SetOutput(c.Settings.Streams.Out)
+	cmd.SilenceUsage = true
+
+	cmd.Flags().StringVar(&c.mspID, mspIDFlag, "", mspIDUsage)
+	cmd.Flags().StringVar(&c.peers, peersFlag, "", peersUsage)
+	cmd.Flags().BoolVar(&c.allPeers, allPeersFlag, false, allPeersUsage)
+	cmd.Flags().StringVar(&c.basePath, basePathFlag, "", basePathUsage)
+	cmd.Flags().StringVar(&c.chaincodeName, chaincodeFlag, "", chaincodeUsage)
+	cmd.Flags().StringVar(&c.collection, collectionFlag, "", collectionUsage)
+	cmd.Flags().StringVar(&c.indexNamespace, indexNamespaceFlag, "", indexNamespaceUsage)
+	cmd.Flags().StringVar(&c.fileIndexID, fileIndexIDFlag, "", fileIndexIDUsage)
+	cmd.Flags().StringVar(&c.readTokens, readTokensFlag, "", readTokensUsage)
+	cmd.Flags().StringVar(&c.writeTokens, writeTokensFlag, "", writeTokensUsage)
+	cmd.Flags().BoolVar(&c.noPrompt, noPromptFlag, false, noPromptUsage)
+
+	return cmd
+}
+
+// command implements the add command
+type command struct {
+	*basecmd.Command
+
+	// Flags
+	mspID          string
+	peers          string
+	allPeers       bool
+	basePath       string
+	chaincodeName  string
+	collection     string
+	indexNamespace string
+	fileIndexID    string
+	readTokens     string
+	writeTokens    string
+	noPrompt       bool
+}
+
+func (c *command) validate() error {
+	if c.mspID == "" {
+		return errMSPRequired
+	}
+
+	if c.allPeers == (len(filehandler.ParseList(c.peers)) > 0) {
+		return errPeersRequired
+	}
+
+	return c.handlerConfig().Validate()
+}
+
+func (c *command) handlerConfig() *filehandler.Config {
+	return &filehandler.Config{
+		Authorization: filehandler.AuthConfig{
+			ReadTokens:  filehandler.ParseList(c.readTokens),
+			WriteTokens: filehandler.ParseList(c.writeTokens),
+		},
+		BasePath:       c.basePath,
+		ChaincodeName:  c.chaincodeName,
+		Collection:     c.collection,
+		IndexNamespace: c.indexNamespace,
+		IndexDocID:     c.fileIndexID,
+	}
+}
+
+func (c *command) run() error {
+	ch, err := c.Channel()
+	if err != nil {
+		return err
+	}
+
+	peers, err := filehandler.ResolvePeers(c, c.mspID, c.peers, c.allPeers)
+	if err != nil {
+		return err
+	}
+
+	if c.allPeers {
+		if err := c.Fprintln(fmt.Sprintf("Resolved peers: %s", peers)); err != nil {
+			return err
+		}
+	}
+
+	existing, err := filehandler.Query(ch, filehandler.Criteria(c.mspID, "", c.basePath))
+	if err != nil {
+		return err
+	}
+
+	for _, kv := range existing {
+		for _, peerID := range peers {
+			if kv.PeerID == peerID {
+				return errors.Errorf("file handler [%s] already exists on peer [%s]", c.basePath, peerID)
+			}
+		}
+	}
+
+	cfg := c.handlerConfig()
+
+	var kvs []*common.KeyValue
+	for _, peerID := range peers {
+		kv, e := filehandler.ToKeyValue(c.mspID, peerID, cfg, nil)
+		if e != nil {
+			return e
+		}
+
+		kvs = append(kvs, kv)
+	}
+
+	// Get confirmation from the user
+	if !c.noPrompt {
+		confirmed, e := c.confirmAdd(cfg, peers)
+		if e != nil {
+			return e
+		}
+		if !confirmed {
+			return c.Fprintln(msgAborted)
+		}
+	}
+
+	if err := filehandler.Save(ch, kvs); err != nil {
+		return err
+	}
+
+	return c.Fprintln(msgConfigAdded)
+}
+
+// confirmAdd prompts the user for confirmation of the add
+func (c *command) confirmAdd(cfg *filehandler.Config, peers []string) (bool, error) {
+	cfgBytes, err := json.Marshal(cfg)
+	if err != nil {
+		return false, err
+	}
+
+	displayedJSON, err := common.FormatJSON(cfgBytes)
+	if err != nil {
+		return false, err
+	}
+
+	prompt := fmt.Sprintf("Adding the following file handler to peers %s:\n\n%s\n\n%s", peers, displayedJSON, msgContinueOrAbort)
+
+	if err := c.Fprintln(prompt); err != nil {
+		return false, err
+	}
+
+	return strings.ToLower(c.Prompt()) == "y", nil
+}
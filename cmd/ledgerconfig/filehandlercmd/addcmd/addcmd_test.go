@@ -0,0 +1,184 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package addcmd
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/hyperledger/fabric-cli/pkg/fabric"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	fabmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/basecmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/common"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/filehandler"
+	"github.com/trustbloc/fabric-cli-ext/cmd/mocks"
+)
+
+var args = []string{
+	"--mspid", "Org1MSP", "--peers", "peer0.org1.example.com;peer1.org1.example.com", "--path", "/content",
+	"--chaincode", "files", "--collection", "consortium", "--idxns", "file:idx",
+	"--readtokens", "content_r", "--writetokens", "content_w;admin_w",
+}
+
+func TestNew(t *testing.T) {
+	require.NotNil(t, New(environment.NewDefaultSettings()))
+}
+
+func TestAddCmd_InvalidOptions(t *testing.T) {
+	t.Run("No MSP", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil).Execute(), errMSPRequired.Error())
+	})
+	t.Run("No peers", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, "--mspid", "Org1MSP").Execute(), errPeersRequired.Error())
+	})
+	t.Run("Peers and all peers", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, "--mspid", "Org1MSP", "--peers", "peer0", "--all-peers").Execute(), errPeersRequired.Error())
+	})
+	t.Run("No path", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, "--mspid", "Org1MSP", "--peers", "peer0").Execute(), "base path is required")
+	})
+	t.Run("Invalid index doc ID", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, append(args, "--idxid", "file:xxx:1234")...).Execute(),
+			"file index ID must begin with [file:idx]")
+	})
+}
+
+func TestAddCmd(t *testing.T) {
+	factory := &mocks.Factory{}
+	ch := &mocks.Channel{}
+	factory.ChannelReturns(ch, nil)
+	p := func(config *environment.Config) (fabric.Factory, error) { return factory, nil }
+
+	t.Run("With --noprompt", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: []byte("null")}, nil)
+		w := &mocks.Writer{}
+		c := newMockCmdWithReaderWriter(t, &mocks.Reader{}, w, p, append(args, "--noprompt")...)
+		require.NoError(t, c.Execute())
+		require.Contains(t, w.Written(), msgConfigAdded)
+
+		req, _ := ch.ExecuteArgsForCall(ch.ExecuteCallCount() - 1)
+		require.Equal(t, "save", req.Fcn)
+
+		cfg := &common.Config{}
+		require.NoError(t, json.Unmarshal(req.Args[0], cfg))
+		require.Equal(t, "Org1MSP", cfg.MspID)
+		require.Len(t, cfg.Peers, 2)
+
+		comp := cfg.Peers[1].Apps[0].Components[0]
+		require.Equal(t, "/content", comp.Name)
+
+		handlerCfg := &filehandler.Config{}
+		require.NoError(t, json.Unmarshal([]byte(comp.Config), handlerCfg))
+		require.Equal(t, "files", handlerCfg.ChaincodeName)
+		require.Equal(t, []string{"content_r"}, handlerCfg.Authorization.ReadTokens)
+		require.Equal(t, []string{"content_w", "admin_w"}, handlerCfg.Authorization.WriteTokens)
+	})
+	t.Run("With prompt - Y", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: []byte("null")}, nil)
+		w := &mocks.Writer{}
+		c := newMockCmdWithReaderWriter(t, &mocks.Reader{Bytes: []byte("Y\n")}, w, p, args...)
+		require.NoError(t, c.Execute())
+		require.Contains(t, w.Written(), msgContinueOrAbort)
+		require.Contains(t, w.Written(), msgConfigAdded)
+	})
+	t.Run("With prompt - N", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: []byte("null")}, nil)
+		w := &mocks.Writer{}
+		c := newMockCmdWithReaderWriter(t, &mocks.Reader{Bytes: []byte("N\n")}, w, p, args...)
+		require.NoError(t, c.Execute())
+		require.Contains(t, w.Written(), msgAborted)
+		require.NotContains(t, w.Written(), msgConfigAdded)
+	})
+	t.Run("With --all-peers", func(t *testing.T) {
+		factory.SDKReturns(mocks.NewSDK(
+			&fabmocks.MockPeer{MockMSP: "Org1MSP", MockURL: "grpcs://peer1.org1.example.com:7051"},
+			&fabmocks.MockPeer{MockMSP: "Org1MSP", MockURL: "grpcs://peer0.org1.example.com:7051"},
+			&fabmocks.MockPeer{MockMSP: "Org2MSP", MockURL: "grpcs://peer0.org2.example.com:7051"},
+		), nil)
+
+		ch.QueryReturns(channel.Response{Payload: []byte("null")}, nil)
+		w := &mocks.Writer{}
+		c := newMockCmdWithReaderWriter(t, &mocks.Reader{Bytes: []byte("Y\n")}, w, p,
+			"--mspid", "Org1MSP", "--all-peers", "--path", "/content", "--chaincode", "files", "--collection", "consortium", "--idxns", "file:idx")
+		require.NoError(t, c.Execute())
+		require.Contains(t, w.Written(), "Resolved peers: [peer0.org1.example.com peer1.org1.example.com]")
+		require.Contains(t, w.Written(), msgConfigAdded)
+
+		req, _ := ch.ExecuteArgsForCall(ch.ExecuteCallCount() - 1)
+		cfg := &common.Config{}
+		require.NoError(t, json.Unmarshal(req.Args[0], cfg))
+		require.Len(t, cfg.Peers, 2)
+	})
+	t.Run("With --all-peers - discovery error", func(t *testing.T) {
+		factory.SDKReturns(mocks.NewSDK().WithDiscoveryError(errors.New("discovery error")), nil)
+
+		c := newMockCmd(t, p, "--mspid", "Org1MSP", "--all-peers", "--path", "/content", "--chaincode", "files", "--collection", "consortium", "--idxns", "file:idx")
+		err := c.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "discovery error")
+	})
+	t.Run("Already exists", func(t *testing.T) {
+		kv, err := filehandler.ToKeyValue("Org1MSP", "peer1.org1.example.com", &filehandler.Config{BasePath: "/content"}, nil)
+		require.NoError(t, err)
+
+		kvsBytes, err := json.Marshal([]*common.KeyValue{kv})
+		require.NoError(t, err)
+
+		ch.QueryReturns(channel.Response{Payload: kvsBytes}, nil)
+		c := newMockCmd(t, p, append(args, "--noprompt")...)
+		require.EqualError(t, c.Execute(), "file handler [/content] already exists on peer [peer1.org1.example.com]")
+	})
+	t.Run("Query error", func(t *testing.T) {
+		errExpected := errors.New("query error")
+		ch.QueryReturns(channel.Response{}, errExpected)
+		c := newMockCmd(t, p, append(args, "--noprompt")...)
+		require.EqualError(t, c.Execute(), errExpected.Error())
+	})
+	t.Run("Execute error", func(t *testing.T) {
+		errExpected := errors.New("execute error")
+		ch.QueryReturns(channel.Response{Payload: []byte("null")}, nil)
+		ch.ExecuteReturns(channel.Response{}, errExpected)
+		defer ch.ExecuteReturns(channel.Response{}, nil)
+
+		c := newMockCmd(t, p, append(args, "--noprompt")...)
+		require.EqualError(t, c.Execute(), errExpected.Error())
+	})
+	t.Run("Channel error", func(t *testing.T) {
+		errExpected := errors.New("channel error")
+		p := func(config *environment.Config) (fabric.Factory, error) { return nil, errExpected }
+
+		c := newMockCmd(t, p, append(args, "--noprompt")...)
+		require.EqualError(t, c.Execute(), errExpected.Error())
+	})
+}
+
+func newMockCmd(t *testing.T, p basecmd.FactoryProvider, args ...string) *cobra.Command {
+	return newMockCmdWithReaderWriter(t, &mocks.Reader{}, &mocks.Writer{}, p, args...)
+}
+
+func newMockCmdWithReaderWriter(t *testing.T, in io.Reader, w io.Writer, p basecmd.FactoryProvider, args ...string) *cobra.Command {
+	settings := environment.NewDefaultSettings()
+	settings.Streams.Out = w
+	settings.Streams.In = in
+
+	settings.Config.CurrentContext = "testctx"
+	settings.Config.Contexts[settings.Config.CurrentContext] = &environment.Context{}
+
+	c := newCmd(settings, p)
+	require.NotNil(t, c)
+
+	c.SetArgs(args)
+
+	return c
+}
@@ -0,0 +1,48 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package filehandlercmd
+
+import (
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/spf13/cobra"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/filehandlercmd/addcmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/filehandlercmd/listcmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/filehandlercmd/removecmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/filehandlercmd/showcmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/filehandlercmd/tokenscmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/filehandlercmd/verifycmd"
+)
+
+const (
+	use      = "filehandler"
+	desc     = "Manages file handler configuration"
+	longDesc = "The filehandler command allows you to add, list, show, remove, and verify file handler configuration, and to manage the authorization tokens of file handlers."
+)
+
+// New returns the ledgerconfig filehandler sub-command
+func New(settings *environment.Settings) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: desc,
+		Long:  longDesc,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.HelpFunc()(cmd, args)
+		},
+	}
+
+	cmd.AddCommand(
+		addcmd.New(settings),
+		listcmd.New(settings),
+		showcmd.New(settings),
+		removecmd.New(settings),
+		tokenscmd.New(settings),
+		verifycmd.New(settings),
+	)
+
+	return cmd
+}
@@ -0,0 +1,33 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package filehandlercmd
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/mocks"
+)
+
+func TestNew(t *testing.T) {
+	cmd := New(environment.NewDefaultSettings())
+	require.NotNil(t, cmd)
+
+	w := &mocks.Writer{}
+	cmd.SetOutput(w)
+
+	require.NoError(t, cmd.Execute())
+
+	require.Contains(t, w.Written(), "Add a file handler configuration")
+	require.Contains(t, w.Written(), "List file handler configurations")
+	require.Contains(t, w.Written(), "Show a file handler configuration")
+	require.Contains(t, w.Written(), "Remove a file handler configuration")
+	require.Contains(t, w.Written(), "Manages file handler authorization tokens")
+	require.Contains(t, w.Written(), "Verify the consistency of a file handler configuration across peers")
+}
@@ -0,0 +1,144 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package listcmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/basecmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/filehandler"
+)
+
+const (
+	use      = "list"
+	desc     = "List file handler configurations"
+	longDesc = `
+The list command displays a table of the file handler configurations of every peer in the given MSP. The list may
+be restricted to a single peer using the --peerid option.
+`
+	examples = `
+- List the file handlers of all peers in Org1MSP:
+    $ ./fabric ledgerconfig filehandler list --mspid Org1MSP
+
+... results in output such as:
+
+	PEER                    PATH      CHAINCODE  COLLECTION  INDEX NAMESPACE  INDEX DOC ID         READ TOKENS  WRITE TOKENS
+	peer0.org1.example.com  /content  files      consortium  file:idx         file:idx:EiAuN66...  content_r    content_w
+	peer1.org1.example.com  /content  files      consortium  file:idx         file:idx:EiAuN66...  content_r    content_w
+`
+)
+
+const (
+	mspIDFlag  = "mspid"
+	mspIDUsage = "The ID of the MSP. Example: --mspid Org1MSP"
+
+	peerIDFlag  = "peerid"
+	peerIDUsage = "The (optional) ID of the peer. Example: --peerid peer0.org1.com"
+
+	msgNoFileHandlers = "No file handlers found"
+
+	tableHeader = "PEER\tPATH\tCHAINCODE\tCOLLECTION\tINDEX NAMESPACE\tINDEX DOC ID\tREAD TOKENS\tWRITE TOKENS"
+)
+
+var errMSPRequired = errors.New("msp (--mspid) is required")
+
+// New returns the filehandler list sub-command
+func New(settings *environment.Settings) *cobra.Command {
+	return newCmd(settings, nil)
+}
+
+func newCmd(settings *environment.Settings, p basecmd.FactoryProvider) *cobra.Command {
+	c := &command{
+		Command: basecmd.New(settings, p),
+	}
+
+	cmd := &cobra.Command{
+		Use:     use,
+		Short:   desc,
+		Long:    longDesc,
+		Example: examples,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			if c.mspID == "" {
+				return errMSPRequired
+			}
+			return nil
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return c.run()
+		},
+	}
+
+	cmd.SetOutput(c.Settings.Streams.Out)
+	cmd.SilenceUsage = true
+
+	cmd.Flags().StringVar(&c.mspID, mspIDFlag, "", mspIDUsage)
+	cmd.Flags().StringVar(&c.peerID, peerIDFlag, "", peerIDUsage)
+
+	return cmd
+}
+
+// command implements the list command
+type command struct {
+	*basecmd.Command
+
+	// Flags
+	mspID  string
+	peerID string
+}
+
+func (c *command) run() error {
+	ch, err := c.Channel()
+	if err != nil {
+		return err
+	}
+
+	kvs, err := filehandler.Query(ch, filehandler.Criteria(c.mspID, c.peerID, ""))
+	if err != nil {
+		return err
+	}
+
+	handlers, err := filehandler.FromKeyValues(kvs)
+	if err != nil {
+		return err
+	}
+
+	if len(handlers) == 0 {
+		return c.Fprintln(msgNoFileHandlers)
+	}
+
+	sort.SliceStable(handlers, func(i, j int) bool {
+		if handlers[i].PeerID == handlers[j].PeerID {
+			return handlers[i].BasePath < handlers[j].BasePath
+		}
+		return handlers[i].PeerID < handlers[j].PeerID
+	})
+
+	w := tabwriter.NewWriter(c.Settings.Streams.Out, 0, 0, 2, ' ', 0)
+
+	if _, err = fmt.Fprintln(w, tableHeader); err != nil {
+		return err
+	}
+
+	for _, h := range handlers {
+		_, err = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			h.PeerID, h.BasePath, h.ChaincodeName, h.Collection, h.IndexNamespace, h.IndexDocID,
+			strings.Join(h.Authorization.ReadTokens, ";"), strings.Join(h.Authorization.WriteTokens, ";"),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,86 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package listcmd
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/hyperledger/fabric-cli/pkg/fabric"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/basecmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/mocks"
+)
+
+const payload = `[
+{"MspID":"Org1MSP","PeerID":"peer1","AppName":"file-handler","AppVersion":"1","ComponentName":"/schema","ComponentVersion":"1","TxID":"tx1","Format":"JSON","Config":"{\"BasePath\":\"/schema\",\"ChaincodeName\":\"files\",\"Collection\":\"consortium\",\"IndexNamespace\":\"file:idx\"}"},
+{"MspID":"Org1MSP","PeerID":"peer0","AppName":"file-handler","AppVersion":"1","ComponentName":"/content","ComponentVersion":"1","TxID":"tx1","Format":"JSON","Config":"{\"Authorization\":{\"ReadTokens\":[\"content_r\"],\"WriteTokens\":[\"content_w\",\"admin_w\"]},\"BasePath\":\"/content\",\"ChaincodeName\":\"files\",\"Collection\":\"consortium\",\"IndexNamespace\":\"file:idx\",\"IndexDocID\":\"file:idx:1234\"}"}
+]`
+
+func TestNew(t *testing.T) {
+	require.NotNil(t, New(environment.NewDefaultSettings()))
+}
+
+func TestListCmd(t *testing.T) {
+	factory := &mocks.Factory{}
+	ch := &mocks.Channel{}
+	factory.ChannelReturns(ch, nil)
+	p := func(config *environment.Config) (fabric.Factory, error) { return factory, nil }
+
+	t.Run("No MSP", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, p, &mocks.Writer{}).Execute(), errMSPRequired.Error())
+	})
+	t.Run("Success", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: []byte(payload)}, nil)
+		w := &mocks.Writer{}
+		require.NoError(t, newMockCmd(t, p, w, "--mspid", "Org1MSP").Execute())
+
+		lines := strings.Split(strings.TrimSpace(string(w.Bytes)), "\n")
+		require.Len(t, lines, 3)
+		require.True(t, strings.HasPrefix(lines[0], "PEER"))
+		require.Equal(t, []string{"peer0", "/content", "files", "consortium", "file:idx", "file:idx:1234", "content_r", "content_w;admin_w"}, strings.Fields(lines[1]))
+		require.Equal(t, []string{"peer1", "/schema", "files", "consortium", "file:idx"}, strings.Fields(lines[2]))
+	})
+	t.Run("No file handlers", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: []byte("null")}, nil)
+		w := &mocks.Writer{}
+		require.NoError(t, newMockCmd(t, p, w, "--mspid", "Org1MSP", "--peerid", "peer0").Execute())
+		require.Equal(t, msgNoFileHandlers, w.Written())
+	})
+	t.Run("Invalid config", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: []byte(`[{"MspID":"Org1MSP","PeerID":"peer0","Config":"{"}]`)}, nil)
+		err := newMockCmd(t, p, &mocks.Writer{}, "--mspid", "Org1MSP").Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid file handler config")
+	})
+	t.Run("Query error", func(t *testing.T) {
+		errExpected := errors.New("query error")
+		ch.QueryReturns(channel.Response{}, errExpected)
+		require.EqualError(t, newMockCmd(t, p, &mocks.Writer{}, "--mspid", "Org1MSP").Execute(), errExpected.Error())
+	})
+}
+
+func newMockCmd(t *testing.T, p basecmd.FactoryProvider, w io.Writer, args ...string) *cobra.Command {
+	settings := environment.NewDefaultSettings()
+	settings.Streams.Out = w
+
+	settings.Config.CurrentContext = "testctx"
+	settings.Config.Contexts[settings.Config.CurrentContext] = &environment.Context{}
+
+	c := newCmd(settings, p)
+	require.NotNil(t, c)
+
+	c.SetArgs(args)
+
+	return c
+}
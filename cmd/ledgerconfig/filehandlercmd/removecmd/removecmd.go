@@ -0,0 +1,183 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package removecmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/retry"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/basecmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/common"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/filehandler"
+)
+
+const (
+	use      = "remove"
+	desc     = "Remove a file handler configuration"
+	longDesc = `
+The remove command deletes the file handler configuration for a base path. If --peerid is specified then the
+file handler is removed from the given peer only, otherwise it is removed from all peers in the MSP.
+`
+	examples = `
+- Remove the file handler for /content from peer0.org1.example.com:
+    $ ./fabric ledgerconfig filehandler remove --mspid Org1MSP --peerid peer0.org1.example.com --path /content
+
+- Remove the file handler for /content from all peers in Org1MSP:
+    $ ./fabric ledgerconfig filehandler remove --mspid Org1MSP --path /content --noprompt
+`
+)
+
+const (
+	mspIDFlag  = "mspid"
+	mspIDUsage = "The ID of the MSP. Example: --mspid Org1MSP"
+
+	peerIDFlag  = "peerid"
+	peerIDUsage = "The (optional) ID of the peer. Example: --peerid peer0.org1.com"
+
+	basePathFlag  = "path"
+	basePathUsage = "The file handler base path. Example: --path /content"
+
+	noPromptFlag  = "noprompt"
+	noPromptUsage = "If specified then the remove operation will not prompt for confirmation. Example: --noprompt"
+
+	msgConfigRemoved   = "File handler successfully removed!"
+	msgNoFileHandler   = "No file handler found for the given path"
+	msgAborted         = "Operation aborted"
+	msgContinueOrAbort = "Enter Y to continue or N to abort "
+)
+
+var (
+	errMSPRequired      = errors.New("msp (--mspid) is required")
+	errBasePathRequired = errors.New("base path (--path) is required")
+)
+
+// New returns the filehandler remove sub-command
+func New(settings *environment.Settings) *cobra.Command {
+	return newCmd(settings, nil)
+}
+
+func newCmd(settings *environment.Settings, p basecmd.FactoryProvider) *cobra.Command {
+	c := &command{
+		Command: basecmd.New(settings, p),
+	}
+
+	cmd := &cobra.Command{
+		Use:     use,
+		Short:   desc,
+		Long:    longDesc,
+		Example: examples,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return c.validate()
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return c.run()
+		},
+	}
+
+	cmd.SetOutput(c.Settings.Streams.Out)
+	cmd.SilenceUsage = true
+
+	cmd.Flags().StringVar(&c.mspID, mspIDFlag, "", mspIDUsage)
+	cmd.Flags().StringVar(&c.peerID, peerIDFlag, "", peerIDUsage)
+	cmd.Flags().StringVar(&c.basePath, basePathFlag, "", basePathUsage)
+	cmd.Flags().BoolVar(&c.noPrompt, noPromptFlag, false, noPromptUsage)
+
+	return cmd
+}
+
+// command implements the remove command
+type command struct {
+	*basecmd.Command
+
+	// Flags
+	mspID    string
+	peerID   string
+	basePath string
+	noPrompt bool
+}
+
+func (c *command) validate() error {
+	if c.mspID == "" {
+		return errMSPRequired
+	}
+
+	if c.basePath == "" {
+		return errBasePathRequired
+	}
+
+	return nil
+}
+
+func (c *command) run() error {
+	ch, err := c.Channel()
+	if err != nil {
+		return err
+	}
+
+	criteria := filehandler.Criteria(c.mspID, c.peerID, c.basePath)
+
+	kvs, err := filehandler.Query(ch, criteria)
+	if err != nil {
+		return err
+	}
+
+	if len(kvs) == 0 {
+		return c.Fprintln(msgNoFileHandler)
+	}
+
+	// Get confirmation from the user
+	if !c.noPrompt {
+		confirmed, e := c.confirmRemove(kvs)
+		if e != nil {
+			return e
+		}
+		if !confirmed {
+			return c.Fprintln(msgAborted)
+		}
+	}
+
+	criteriaBytes, err := json.Marshal(criteria)
+	if err != nil {
+		return err
+	}
+
+	req := channel.Request{
+		ChaincodeID: common.ConfigSCC,
+		Fcn:         "delete",
+		Args:        [][]byte{criteriaBytes},
+	}
+
+	_, err = ch.Execute(req, channel.WithRetry(retry.DefaultChannelOpts))
+	if err != nil {
+		return err
+	}
+
+	return c.Fprintln(msgConfigRemoved)
+}
+
+// confirmRemove prompts the user for confirmation of the remove
+func (c *command) confirmRemove(kvs []*common.KeyValue) (bool, error) {
+	var peers []string
+	for _, kv := range kvs {
+		peers = append(peers, kv.PeerID)
+	}
+
+	prompt := fmt.Sprintf("The file handler [%s] will be removed from peers %s\n\n%s", c.basePath, peers, msgContinueOrAbort)
+
+	if err := c.Fprintln(prompt); err != nil {
+		return false, err
+	}
+
+	return strings.ToLower(c.Prompt()) == "y", nil
+}
@@ -0,0 +1,129 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package removecmd
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/hyperledger/fabric-cli/pkg/fabric"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/basecmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/common"
+	"github.com/trustbloc/fabric-cli-ext/cmd/mocks"
+)
+
+const payload = `[
+{"MspID":"Org1MSP","PeerID":"peer0","AppName":"file-handler","AppVersion":"1","ComponentName":"/content","ComponentVersion":"1","TxID":"tx1","Format":"JSON","Config":"{}"},
+{"MspID":"Org1MSP","PeerID":"peer1","AppName":"file-handler","AppVersion":"1","ComponentName":"/content","ComponentVersion":"1","TxID":"tx1","Format":"JSON","Config":"{}"}
+]`
+
+func TestNew(t *testing.T) {
+	require.NotNil(t, New(environment.NewDefaultSettings()))
+}
+
+func TestRemoveCmd_InvalidOptions(t *testing.T) {
+	t.Run("No MSP", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil).Execute(), errMSPRequired.Error())
+	})
+	t.Run("No path", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, "--mspid", "Org1MSP").Execute(), errBasePathRequired.Error())
+	})
+}
+
+func TestRemoveCmd(t *testing.T) {
+	factory := &mocks.Factory{}
+	ch := &mocks.Channel{}
+	factory.ChannelReturns(ch, nil)
+	p := func(config *environment.Config) (fabric.Factory, error) { return factory, nil }
+
+	t.Run("With --noprompt", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: []byte(payload)}, nil)
+		w := &mocks.Writer{}
+		c := newMockCmdWithReaderWriter(t, &mocks.Reader{}, w, p, "--mspid", "Org1MSP", "--peerid", "peer0", "--path", "/content", "--noprompt")
+		require.NoError(t, c.Execute())
+		require.Contains(t, w.Written(), msgConfigRemoved)
+
+		req, _ := ch.ExecuteArgsForCall(ch.ExecuteCallCount() - 1)
+		require.Equal(t, "delete", req.Fcn)
+
+		criteria := &common.Criteria{}
+		require.NoError(t, json.Unmarshal(req.Args[0], criteria))
+		require.Equal(t, &common.Criteria{
+			MspID:            "Org1MSP",
+			PeerID:           "peer0",
+			AppName:          "file-handler",
+			AppVersion:       "1",
+			ComponentName:    "/content",
+			ComponentVersion: "1",
+		}, criteria)
+	})
+	t.Run("With prompt - Y", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: []byte(payload)}, nil)
+		w := &mocks.Writer{}
+		c := newMockCmdWithReaderWriter(t, &mocks.Reader{Bytes: []byte("Y\n")}, w, p, "--mspid", "Org1MSP", "--path", "/content")
+		require.NoError(t, c.Execute())
+		require.Contains(t, w.Written(), "[peer0 peer1]")
+		require.Contains(t, w.Written(), msgConfigRemoved)
+	})
+	t.Run("With prompt - N", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: []byte(payload)}, nil)
+		w := &mocks.Writer{}
+		c := newMockCmdWithReaderWriter(t, &mocks.Reader{Bytes: []byte("N\n")}, w, p, "--mspid", "Org1MSP", "--path", "/content")
+		require.NoError(t, c.Execute())
+		require.Contains(t, w.Written(), msgAborted)
+		require.NotContains(t, w.Written(), msgConfigRemoved)
+	})
+	t.Run("Not found", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: []byte("null")}, nil)
+		w := &mocks.Writer{}
+		c := newMockCmdWithReaderWriter(t, &mocks.Reader{}, w, p, "--mspid", "Org1MSP", "--path", "/content", "--noprompt")
+		require.NoError(t, c.Execute())
+		require.Contains(t, w.Written(), msgNoFileHandler)
+	})
+	t.Run("Query error", func(t *testing.T) {
+		errExpected := errors.New("query error")
+		ch.QueryReturns(channel.Response{}, errExpected)
+		c := newMockCmd(t, p, "--mspid", "Org1MSP", "--path", "/content", "--noprompt")
+		require.EqualError(t, c.Execute(), errExpected.Error())
+	})
+	t.Run("Execute error", func(t *testing.T) {
+		errExpected := errors.New("execute error")
+		ch.QueryReturns(channel.Response{Payload: []byte(payload)}, nil)
+		ch.ExecuteReturns(channel.Response{}, errExpected)
+		defer ch.ExecuteReturns(channel.Response{}, nil)
+
+		c := newMockCmd(t, p, "--mspid", "Org1MSP", "--path", "/content", "--noprompt")
+		require.EqualError(t, c.Execute(), errExpected.Error())
+	})
+}
+
+func newMockCmd(t *testing.T, p basecmd.FactoryProvider, args ...string) *cobra.Command {
+	return newMockCmdWithReaderWriter(t, &mocks.Reader{}, &mocks.Writer{}, p, args...)
+}
+
+func newMockCmdWithReaderWriter(t *testing.T, in io.Reader, w io.Writer, p basecmd.FactoryProvider, args ...string) *cobra.Command {
+	settings := environment.NewDefaultSettings()
+	settings.Streams.Out = w
+	settings.Streams.In = in
+
+	settings.Config.CurrentContext = "testctx"
+	settings.Config.Contexts[settings.Config.CurrentContext] = &environment.Context{}
+
+	c := newCmd(settings, p)
+	require.NotNil(t, c)
+
+	c.SetArgs(args)
+
+	return c
+}
@@ -0,0 +1,154 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package showcmd
+
+import (
+	"encoding/json"
+
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/basecmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/common"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/filehandler"
+)
+
+const (
+	use      = "show"
+	desc     = "Show a file handler configuration"
+	longDesc = `
+The show command displays the file handler configuration for a base path on a given peer.
+`
+	examples = `
+- Show the file handler for /content on peer0.org1.example.com:
+    $ ./fabric ledgerconfig filehandler show --mspid Org1MSP --peerid peer0.org1.example.com --path /content
+
+... results in output such as:
+
+	{
+	  "Authorization": {
+	    "ReadTokens": ["content_r"],
+	    "WriteTokens": ["content_w"]
+	  },
+	  "BasePath": "/content",
+	  "ChaincodeName": "files",
+	  "Collection": "consortium",
+	  "IndexNamespace": "file:idx",
+	  "IndexDocID": "file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA"
+	}
+`
+)
+
+const (
+	mspIDFlag  = "mspid"
+	mspIDUsage = "The ID of the MSP. Example: --mspid Org1MSP"
+
+	peerIDFlag  = "peerid"
+	peerIDUsage = "The ID of the peer. Example: --peerid peer0.org1.com"
+
+	basePathFlag  = "path"
+	basePathUsage = "The file handler base path. Example: --path /content"
+)
+
+var (
+	errMSPRequired      = errors.New("msp (--mspid) is required")
+	errPeerIDRequired   = errors.New("peer ID (--peerid) is required")
+	errBasePathRequired = errors.New("base path (--path) is required")
+)
+
+// New returns the filehandler show sub-command
+func New(settings *environment.Settings) *cobra.Command {
+	return newCmd(settings, nil)
+}
+
+func newCmd(settings *environment.Settings, p basecmd.FactoryProvider) *cobra.Command {
+	c := &command{
+		Command: basecmd.New(settings, p),
+	}
+
+	cmd := &cobra.Command{
+		Use:     use,
+		Short:   desc,
+		Long:    longDesc,
+		Example: examples,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return c.validate()
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return c.run()
+		},
+	}
+
+	cmd.SetOutput(c.Settings.Streams.Out)
+	cmd.SilenceUsage = true
+
+	cmd.Flags().StringVar(&c.mspID, mspIDFlag, "", mspIDUsage)
+	cmd.Flags().StringVar(&c.peerID, peerIDFlag, "", peerIDUsage)
+	cmd.Flags().StringVar(&c.basePath, basePathFlag, "", basePathUsage)
+
+	return cmd
+}
+
+// command implements the show command
+type command struct {
+	*basecmd.Command
+
+	// Flags
+	mspID    string
+	peerID   string
+	basePath string
+}
+
+func (c *command) validate() error {
+	if c.mspID == "" {
+		return errMSPRequired
+	}
+
+	if c.peerID == "" {
+		return errPeerIDRequired
+	}
+
+	if c.basePath == "" {
+		return errBasePathRequired
+	}
+
+	return nil
+}
+
+func (c *command) run() error {
+	ch, err := c.Channel()
+	if err != nil {
+		return err
+	}
+
+	kvs, err := filehandler.Query(ch, filehandler.Criteria(c.mspID, c.peerID, c.basePath))
+	if err != nil {
+		return err
+	}
+
+	if len(kvs) == 0 {
+		return errors.Errorf("file handler [%s] not found on peer [%s]", c.basePath, c.peerID)
+	}
+
+	h, err := filehandler.FromKeyValue(kvs[0])
+	if err != nil {
+		return err
+	}
+
+	cfgBytes, err := json.Marshal(h.Config)
+	if err != nil {
+		return err
+	}
+
+	displayedJSON, err := common.FormatJSON(cfgBytes)
+	if err != nil {
+		return err
+	}
+
+	return c.Fprintln(string(displayedJSON))
+}
@@ -0,0 +1,78 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package showcmd
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/hyperledger/fabric-cli/pkg/fabric"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/basecmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/mocks"
+)
+
+const payload = `[{"MspID":"Org1MSP","PeerID":"peer0","AppName":"file-handler","AppVersion":"1","ComponentName":"/content","ComponentVersion":"1","TxID":"tx1","Format":"JSON","Config":"{\"BasePath\":\"/content\",\"ChaincodeName\":\"files\",\"Collection\":\"consortium\",\"IndexNamespace\":\"file:idx\"}"}]`
+
+func TestNew(t *testing.T) {
+	require.NotNil(t, New(environment.NewDefaultSettings()))
+}
+
+func TestShowCmd(t *testing.T) {
+	factory := &mocks.Factory{}
+	ch := &mocks.Channel{}
+	factory.ChannelReturns(ch, nil)
+	p := func(config *environment.Config) (fabric.Factory, error) { return factory, nil }
+
+	t.Run("No MSP", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, p, &mocks.Writer{}).Execute(), errMSPRequired.Error())
+	})
+	t.Run("No peer", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, p, &mocks.Writer{}, "--mspid", "Org1MSP").Execute(), errPeerIDRequired.Error())
+	})
+	t.Run("No path", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, p, &mocks.Writer{}, "--mspid", "Org1MSP", "--peerid", "peer0").Execute(), errBasePathRequired.Error())
+	})
+	t.Run("Success", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: []byte(payload)}, nil)
+		w := &mocks.Writer{}
+		require.NoError(t, newMockCmd(t, p, w, "--mspid", "Org1MSP", "--peerid", "peer0", "--path", "/content").Execute())
+		require.Contains(t, w.Written(), `  "BasePath": "/content",`)
+		require.Contains(t, w.Written(), `  "ChaincodeName": "files",`)
+	})
+	t.Run("Not found", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: []byte("null")}, nil)
+		err := newMockCmd(t, p, &mocks.Writer{}, "--mspid", "Org1MSP", "--peerid", "peer0", "--path", "/content").Execute()
+		require.EqualError(t, err, "file handler [/content] not found on peer [peer0]")
+	})
+	t.Run("Query error", func(t *testing.T) {
+		errExpected := errors.New("query error")
+		ch.QueryReturns(channel.Response{}, errExpected)
+		err := newMockCmd(t, p, &mocks.Writer{}, "--mspid", "Org1MSP", "--peerid", "peer0", "--path", "/content").Execute()
+		require.EqualError(t, err, errExpected.Error())
+	})
+}
+
+func newMockCmd(t *testing.T, p basecmd.FactoryProvider, w io.Writer, args ...string) *cobra.Command {
+	settings := environment.NewDefaultSettings()
+	settings.Streams.Out = w
+
+	settings.Config.CurrentContext = "testctx"
+	settings.Config.Contexts[settings.Config.CurrentContext] = &environment.Context{}
+
+	c := newCmd(settings, p)
+	require.NotNil(t, c)
+
+	c.SetArgs(args)
+
+	return c
+}
@@ -0,0 +1,59 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tokenscmd
+
+import (
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/spf13/cobra"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/basecmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/filehandler"
+)
+
+const (
+	addUse      = "add"
+	addDesc     = "Add an authorization token to a file handler"
+	addLongDesc = `
+The add command adds the name of a read token (--read) and/or a write token (--write) to the authorization of the
+file handler for the given base path. The token is added on the given peers or, if --peers is not specified, on
+all peers in the MSP that have the file handler. Peers that already have the token are skipped.
+`
+	addExamples = `
+- Add a write token to the file handler for /content on all peers in Org1MSP:
+    $ ./fabric ledgerconfig filehandler tokens add --mspid Org1MSP --path /content --write content_w2
+
+- Add a read token to the file handler for /content on two peers:
+    $ ./fabric ledgerconfig filehandler tokens add --mspid Org1MSP --peers peer0.org1.example.com;peer1.org1.example.com --path /content --read content_r --noprompt
+`
+)
+
+func newAddCmd(settings *environment.Settings, p basecmd.FactoryProvider) *cobra.Command {
+	var c *updateCommand
+
+	cmd := &cobra.Command{
+		Use:     addUse,
+		Short:   addDesc,
+		Long:    addLongDesc,
+		Example: addExamples,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return c.validate()
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return c.update(func(cfg *filehandler.AuthConfig) bool {
+				var readAdded, writeAdded bool
+				cfg.ReadTokens, readAdded = addToken(cfg.ReadTokens, c.read)
+				cfg.WriteTokens, writeAdded = addToken(cfg.WriteTokens, c.write)
+
+				return readAdded || writeAdded
+			})
+		},
+	}
+
+	c = newUpdateCommand(settings, p, cmd)
+
+	return cmd
+}
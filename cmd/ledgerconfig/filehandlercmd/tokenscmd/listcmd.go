@@ -0,0 +1,84 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tokenscmd
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/spf13/cobra"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/basecmd"
+)
+
+const (
+	listUse      = "list"
+	listDesc     = "List the authorization tokens of a file handler"
+	listLongDesc = `
+The list command displays a table of the read and write tokens of the file handler for the given base path on the
+given peers or, if --peers is not specified, on all peers in the MSP that have the file handler.
+`
+	listExamples = `
+- List the tokens of the file handler for /content on all peers in Org1MSP:
+    $ ./fabric ledgerconfig filehandler tokens list --mspid Org1MSP --path /content
+
+... results in output such as:
+
+	PEER                    READ TOKENS  WRITE TOKENS
+	peer0.org1.example.com  content_r    content_w
+	peer1.org1.example.com  content_r    content_w;content_w2
+`
+
+	tableHeader = "PEER\tREAD TOKENS\tWRITE TOKENS"
+)
+
+func newListCmd(settings *environment.Settings, p basecmd.FactoryProvider) *cobra.Command {
+	var c *command
+
+	cmd := &cobra.Command{
+		Use:     listUse,
+		Short:   listDesc,
+		Long:    listLongDesc,
+		Example: listExamples,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return c.validate()
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return c.list()
+		},
+	}
+
+	c = newCommand(settings, p, cmd)
+
+	return cmd
+}
+
+func (c *command) list() error {
+	handlers, err := c.loadHandlers()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(c.Settings.Streams.Out, 0, 0, 2, ' ', 0)
+
+	if _, err = fmt.Fprintln(w, tableHeader); err != nil {
+		return err
+	}
+
+	for _, h := range handlers {
+		_, err = fmt.Fprintf(w, "%s\t%s\t%s\n", h.PeerID,
+			strings.Join(h.Authorization.ReadTokens, ";"), strings.Join(h.Authorization.WriteTokens, ";"),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,56 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tokenscmd
+
+import (
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/spf13/cobra"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/basecmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/filehandler"
+)
+
+const (
+	removeUse      = "remove"
+	removeDesc     = "Remove an authorization token from a file handler"
+	removeLongDesc = `
+The remove command removes the name of a read token (--read) and/or a write token (--write) from the authorization
+of the file handler for the given base path. The token is removed on the given peers or, if --peers is not specified,
+on all peers in the MSP that have the file handler. Peers that don't have the token are skipped.
+`
+	removeExamples = `
+- Remove a write token from the file handler for /content on all peers in Org1MSP:
+    $ ./fabric ledgerconfig filehandler tokens remove --mspid Org1MSP --path /content --write content_w
+`
+)
+
+func newRemoveCmd(settings *environment.Settings, p basecmd.FactoryProvider) *cobra.Command {
+	var c *updateCommand
+
+	cmd := &cobra.Command{
+		Use:     removeUse,
+		Short:   removeDesc,
+		Long:    removeLongDesc,
+		Example: removeExamples,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return c.validate()
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return c.update(func(cfg *filehandler.AuthConfig) bool {
+				var readRemoved, writeRemoved bool
+				cfg.ReadTokens, readRemoved = removeToken(cfg.ReadTokens, c.read)
+				cfg.WriteTokens, writeRemoved = removeToken(cfg.WriteTokens, c.write)
+
+				return readRemoved || writeRemoved
+			})
+		},
+	}
+
+	c = newUpdateCommand(settings, p, cmd)
+
+	return cmd
+}
@@ -0,0 +1,320 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tokenscmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/basecmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/common"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/filehandler"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/signature"
+)
+
+const (
+	use      = "tokens"
+	desc     = "Manages file handler authorization tokens"
+	longDesc = "The tokens command allows you to add, remove, and list the authorization tokens of a file handler."
+)
+
+const (
+	mspIDFlag  = "mspid"
+	mspIDUsage = "The ID of the MSP. Example: --mspid Org1MSP"
+
+	peersFlag  = "peers"
+	peersUsage = "An (optional) semi-colon-separated list of peers. If not specified then all peers in the MSP are used. Example: --peers peer0.org1.com;peer1.org1.com"
+
+	basePathFlag  = "path"
+	basePathUsage = "The file handler base path. Example: --path /content"
+
+	readFlag  = "read"
+	readUsage = "The name of a token that authorizes read requests. Example: --read content_r"
+
+	writeFlag  = "write"
+	writeUsage = "The name of a token that authorizes write requests. Example: --write content_w"
+
+	noPromptFlag  = "noprompt"
+	noPromptUsage = "If specified then the operation will not prompt for confirmation. Example: --noprompt"
+
+	msgTokensUpdated   = "Tokens successfully updated!"
+	msgUpToDate        = "Tokens are already up to date"
+	msgAborted         = "Operation aborted"
+	msgContinueOrAbort = "Enter Y to continue or N to abort "
+)
+
+var (
+	errMSPRequired          = errors.New("msp (--mspid) is required")
+	errBasePathRequired     = errors.New("base path (--path) is required")
+	errReadOrWriteRequired  = errors.New("one of --read or --write must be specified")
+	errNoFileHandlerForPath = "file handler [%s] not found"
+)
+
+// New returns the filehandler tokens sub-command
+func New(settings *environment.Settings) *cobra.Command {
+	return newCmd(settings, nil)
+}
+
+func newCmd(settings *environment.Settings, p basecmd.FactoryProvider) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: desc,
+		Long:  longDesc,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.HelpFunc()(cmd, args)
+		},
+	}
+
+	cmd.AddCommand(
+		newAddCmd(settings, p),
+		newRemoveCmd(settings, p),
+		newListCmd(settings, p),
+	)
+
+	return cmd
+}
+
+// command is the base for the tokens sub-commands
+type command struct {
+	*basecmd.Command
+
+	// Flags
+	mspID    string
+	peers    string
+	basePath string
+}
+
+func newCommand(settings *environment.Settings, p basecmd.FactoryProvider, cmd *cobra.Command) *command {
+	c := &command{
+		Command: basecmd.New(settings, p),
+	}
+
+	cmd.SetOutput(c.Settings.Streams.Out)
+	cmd.SilenceUsage = true
+
+	cmd.Flags().StringVar(&c.mspID, mspIDFlag, "", mspIDUsage)
+	cmd.Flags().StringVar(&c.peers, peersFlag, "", peersUsage)
+	cmd.Flags().StringVar(&c.basePath, basePathFlag, "", basePathUsage)
+
+	return c
+}
+
+func (c *command) validate() error {
+	if c.mspID == "" {
+		return errMSPRequired
+	}
+
+	if c.basePath == "" {
+		return errBasePathRequired
+	}
+
+	return nil
+}
+
+// loadHandlers returns the file handlers for the base path on the requested peers (or on all peers
+// in the MSP if no peers were requested)
+func (c *command) loadHandlers() ([]*filehandler.Handler, error) {
+	ch, err := c.Channel()
+	if err != nil {
+		return nil, err
+	}
+
+	kvs, err := filehandler.Query(ch, filehandler.Criteria(c.mspID, "", c.basePath))
+	if err != nil {
+		return nil, err
+	}
+
+	handlers, err := filehandler.FromKeyValues(kvs)
+	if err != nil {
+		return nil, err
+	}
+
+	peers := filehandler.ParseList(c.peers)
+	if len(peers) == 0 {
+		if len(handlers) == 0 {
+			return nil, errors.Errorf(errNoFileHandlerForPath, c.basePath)
+		}
+
+		return handlers, nil
+	}
+
+	handlersForPeer := make(map[string]*filehandler.Handler)
+	for _, h := range handlers {
+		handlersForPeer[h.PeerID] = h
+	}
+
+	var result []*filehandler.Handler
+	for _, peerID := range peers {
+		h, ok := handlersForPeer[peerID]
+		if !ok {
+			return nil, errors.Errorf(errNoFileHandlerForPath+" on peer [%s]", c.basePath, peerID)
+		}
+
+		result = append(result, h)
+	}
+
+	return result, nil
+}
+
+// tokenUpdater updates the read or write tokens of the given config and returns true if the tokens changed
+type tokenUpdater func(cfg *filehandler.AuthConfig) bool
+
+// updateCommand is the base for the tokens add and remove sub-commands
+type updateCommand struct {
+	*command
+
+	// Flags
+	read     string
+	write    string
+	noPrompt bool
+}
+
+func newUpdateCommand(settings *environment.Settings, p basecmd.FactoryProvider, cmd *cobra.Command) *updateCommand {
+	c := &updateCommand{
+		command: newCommand(settings, p, cmd),
+	}
+
+	cmd.Flags().StringVar(&c.read, readFlag, "", readUsage)
+	cmd.Flags().StringVar(&c.write, writeFlag, "", writeUsage)
+	cmd.Flags().BoolVar(&c.noPrompt, noPromptFlag, false, noPromptUsage)
+
+	return c
+}
+
+func (c *updateCommand) validate() error {
+	if err := c.command.validate(); err != nil {
+		return err
+	}
+
+	if c.read == "" && c.write == "" {
+		return errReadOrWriteRequired
+	}
+
+	return nil
+}
+
+// update applies the given updater to the tokens of the file handler on each peer, displays a before/after
+// summary and saves the changes in a single transaction
+func (c *updateCommand) update(updater tokenUpdater) error {
+	handlers, err := c.loadHandlers()
+	if err != nil {
+		return err
+	}
+
+	var kvs []*common.KeyValue
+	for _, h := range handlers {
+		before := h.Authorization
+
+		after := filehandler.AuthConfig{
+			ReadTokens:  append([]string(nil), before.ReadTokens...),
+			WriteTokens: append([]string(nil), before.WriteTokens...),
+		}
+
+		if !updater(&after) {
+			if e := c.Fprintln(fmt.Sprintf("%s: up to date", h.PeerID)); e != nil {
+				return e
+			}
+
+			continue
+		}
+
+		if e := c.Fprintln(summary(h.PeerID, before, after)); e != nil {
+			return e
+		}
+
+		h.Authorization = after
+
+		// The signature of the existing config (if any) no longer applies so the config is saved unsigned
+		kv, e := filehandler.ToKeyValue(h.MspID, h.PeerID, h.Config, signature.RemoveTag(h.Tags))
+		if e != nil {
+			return e
+		}
+
+		kvs = append(kvs, kv)
+	}
+
+	if len(kvs) == 0 {
+		return c.Fprintln(msgUpToDate)
+	}
+
+	// Get confirmation from the user
+	if !c.noPrompt {
+		confirmed, e := c.confirm()
+		if e != nil {
+			return e
+		}
+		if !confirmed {
+			return c.Fprintln(msgAborted)
+		}
+	}
+
+	ch, err := c.Channel()
+	if err != nil {
+		return err
+	}
+
+	if err := filehandler.Save(ch, kvs); err != nil {
+		return err
+	}
+
+	return c.Fprintln(msgTokensUpdated)
+}
+
+// confirm prompts the user for confirmation of the update
+func (c *updateCommand) confirm() (bool, error) {
+	if err := c.Fprintln(msgContinueOrAbort); err != nil {
+		return false, err
+	}
+
+	return strings.ToLower(c.Prompt()) == "y", nil
+}
+
+func summary(peerID string, before, after filehandler.AuthConfig) string {
+	return fmt.Sprintf("%s:\n  ReadTokens:  %s -> %s\n  WriteTokens: %s -> %s",
+		peerID, before.ReadTokens, after.ReadTokens, before.WriteTokens, after.WriteTokens)
+}
+
+// addToken adds the given token to the given set of tokens. The updated tokens are returned along
+// with true if the token was added or false if the token already exists.
+func addToken(tokens []string, token string) ([]string, bool) {
+	if token == "" || contains(tokens, token) {
+		return tokens, false
+	}
+
+	return append(tokens, token), true
+}
+
+// removeToken removes the given token from the given set of tokens. The updated tokens are returned along
+// with true if the token was removed or false if the token doesn't exist.
+func removeToken(tokens []string, token string) ([]string, bool) {
+	if token == "" || !contains(tokens, token) {
+		return tokens, false
+	}
+
+	var result []string
+	for _, t := range tokens {
+		if t != token {
+			result = append(result, t)
+		}
+	}
+
+	return result, true
+}
+
+func contains(tokens []string, token string) bool {
+	for _, t := range tokens {
+		if t == token {
+			return true
+		}
+	}
+
+	return false
+}
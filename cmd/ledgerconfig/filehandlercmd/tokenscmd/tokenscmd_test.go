@@ -0,0 +1,225 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tokenscmd
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/hyperledger/fabric-cli/pkg/fabric"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/basecmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/common"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/filehandler"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/signature"
+	"github.com/trustbloc/fabric-cli-ext/cmd/mocks"
+)
+
+const payload = `[
+{"MspID":"Org1MSP","PeerID":"peer0","AppName":"file-handler","AppVersion":"1","ComponentName":"/content","ComponentVersion":"1","TxID":"tx1","Format":"JSON","Config":"{\"Authorization\":{\"ReadTokens\":[\"content_r\"],\"WriteTokens\":[\"content_w\"]},\"BasePath\":\"/content\"}","Tags":["tag1","tag2"]},
+{"MspID":"Org1MSP","PeerID":"peer1","AppName":"file-handler","AppVersion":"1","ComponentName":"/content","ComponentVersion":"1","TxID":"tx1","Format":"JSON","Config":"{\"Authorization\":{\"ReadTokens\":[\"content_r\"],\"WriteTokens\":[\"content_w\",\"content_w2\"]},\"BasePath\":\"/content\"}"}
+]`
+
+func TestNew(t *testing.T) {
+	cmd := New(environment.NewDefaultSettings())
+	require.NotNil(t, cmd)
+
+	w := &mocks.Writer{}
+	cmd.SetOutput(w)
+
+	require.NoError(t, cmd.Execute())
+	require.Contains(t, w.Written(), addDesc)
+	require.Contains(t, w.Written(), removeDesc)
+	require.Contains(t, w.Written(), listDesc)
+}
+
+func TestTokensCmd_InvalidOptions(t *testing.T) {
+	t.Run("No MSP", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, "add").Execute(), errMSPRequired.Error())
+	})
+	t.Run("No path", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, "remove", "--mspid", "Org1MSP").Execute(), errBasePathRequired.Error())
+	})
+	t.Run("No read or write", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, "add", "--mspid", "Org1MSP", "--path", "/content").Execute(), errReadOrWriteRequired.Error())
+	})
+	t.Run("List - no path", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, "list", "--mspid", "Org1MSP").Execute(), errBasePathRequired.Error())
+	})
+}
+
+func TestTokensCmd(t *testing.T) {
+	factory := &mocks.Factory{}
+	ch := &mocks.Channel{}
+	factory.ChannelReturns(ch, nil)
+	p := func(config *environment.Config) (fabric.Factory, error) { return factory, nil }
+
+	t.Run("Add", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: []byte(payload)}, nil)
+		w := &mocks.Writer{}
+		c := newMockCmdWithReaderWriter(t, &mocks.Reader{}, w, p, "add", "--mspid", "Org1MSP", "--path", "/content", "--write", "content_w2", "--noprompt")
+		require.NoError(t, c.Execute())
+		require.Contains(t, w.Written(), "WriteTokens: [content_w] -> [content_w content_w2]")
+		require.Contains(t, w.Written(), "peer1: up to date")
+		require.Contains(t, w.Written(), msgTokensUpdated)
+
+		handlers := getSavedHandlers(t, ch)
+		require.Len(t, handlers, 1)
+		require.Equal(t, "peer0", handlers[0].PeerID)
+		require.Equal(t, []string{"content_r"}, handlers[0].Authorization.ReadTokens)
+		require.Equal(t, []string{"content_w", "content_w2"}, handlers[0].Authorization.WriteTokens)
+
+		// The tags of the existing config are retained
+		require.Equal(t, []string{"tag1", "tag2"}, handlers[0].Tags)
+	})
+	t.Run("Signed config", func(t *testing.T) {
+		kvs := []*common.KeyValue{{
+			Key: &common.Key{MspID: "Org1MSP", PeerID: "peer0", AppName: filehandler.AppName, AppVersion: filehandler.AppVersion,
+				ComponentName: "/content", ComponentVersion: filehandler.ComponentVersion},
+			Value: &common.Value{TxID: "tx1", Format: filehandler.Format,
+				Config: `{"Authorization":{"ReadTokens":["content_r"]},"BasePath":"/content"}`,
+				Tags:   signature.SetTag([]string{"tag1"}, "eyJhbGciOiJFUzI1NiJ9..c2lnbmF0dXJl")},
+		}}
+
+		signedPayload, err := json.Marshal(kvs)
+		require.NoError(t, err)
+
+		ch.QueryReturns(channel.Response{Payload: signedPayload}, nil)
+		c := newMockCmdWithReaderWriter(t, &mocks.Reader{}, &mocks.Writer{}, p, "add", "--mspid", "Org1MSP", "--path", "/content", "--read", "content_r2", "--noprompt")
+		require.NoError(t, c.Execute())
+
+		// The signature no longer applies to the updated config so it's removed and the other tags are retained
+		handlers := getSavedHandlers(t, ch)
+		require.Len(t, handlers, 1)
+		require.Equal(t, []string{"content_r", "content_r2"}, handlers[0].Authorization.ReadTokens)
+		require.Equal(t, []string{"tag1"}, handlers[0].Tags)
+	})
+	t.Run("Remove", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: []byte(payload)}, nil)
+		w := &mocks.Writer{}
+		c := newMockCmdWithReaderWriter(t, &mocks.Reader{}, w, p, "remove", "--mspid", "Org1MSP", "--peers", "peer0;peer1", "--path", "/content", "--read", "content_r", "--noprompt")
+		require.NoError(t, c.Execute())
+		require.Contains(t, w.Written(), "ReadTokens:  [content_r] -> []")
+		require.Contains(t, w.Written(), msgTokensUpdated)
+
+		handlers := getSavedHandlers(t, ch)
+		require.Len(t, handlers, 2)
+		require.Empty(t, handlers[0].Authorization.ReadTokens)
+		require.Empty(t, handlers[1].Authorization.ReadTokens)
+		require.Equal(t, []string{"content_w", "content_w2"}, handlers[1].Authorization.WriteTokens)
+	})
+	t.Run("Up to date", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: []byte(payload)}, nil)
+		w := &mocks.Writer{}
+		c := newMockCmdWithReaderWriter(t, &mocks.Reader{}, w, p, "remove", "--mspid", "Org1MSP", "--path", "/content", "--write", "xxx", "--noprompt")
+		require.NoError(t, c.Execute())
+		require.Contains(t, w.Written(), msgUpToDate)
+		require.NotContains(t, w.Written(), msgTokensUpdated)
+	})
+	t.Run("With prompt - Y", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: []byte(payload)}, nil)
+		w := &mocks.Writer{}
+		c := newMockCmdWithReaderWriter(t, &mocks.Reader{Bytes: []byte("Y\n")}, w, p, "add", "--mspid", "Org1MSP", "--path", "/content", "--read", "content_r2")
+		require.NoError(t, c.Execute())
+		require.Contains(t, w.Written(), msgContinueOrAbort)
+		require.Contains(t, w.Written(), msgTokensUpdated)
+	})
+	t.Run("With prompt - N", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: []byte(payload)}, nil)
+		w := &mocks.Writer{}
+		c := newMockCmdWithReaderWriter(t, &mocks.Reader{Bytes: []byte("N\n")}, w, p, "add", "--mspid", "Org1MSP", "--path", "/content", "--read", "content_r2")
+		require.NoError(t, c.Execute())
+		require.Contains(t, w.Written(), msgAborted)
+		require.NotContains(t, w.Written(), msgTokensUpdated)
+	})
+	t.Run("List", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: []byte(payload)}, nil)
+		w := &mocks.Writer{}
+		c := newMockCmdWithReaderWriter(t, &mocks.Reader{}, w, p, "list", "--mspid", "Org1MSP", "--path", "/content")
+		require.NoError(t, c.Execute())
+
+		lines := strings.Split(strings.TrimSpace(string(w.Bytes)), "\n")
+		require.Len(t, lines, 3)
+		require.Equal(t, []string{"peer0", "content_r", "content_w"}, strings.Fields(lines[1]))
+		require.Equal(t, []string{"peer1", "content_r", "content_w;content_w2"}, strings.Fields(lines[2]))
+	})
+	t.Run("File handler not found", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: []byte("null")}, nil)
+		c := newMockCmd(t, p, "list", "--mspid", "Org1MSP", "--path", "/content")
+		require.EqualError(t, c.Execute(), "file handler [/content] not found")
+	})
+	t.Run("File handler not found on peer", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: []byte(payload)}, nil)
+		c := newMockCmd(t, p, "add", "--mspid", "Org1MSP", "--peers", "peer2", "--path", "/content", "--read", "r", "--noprompt")
+		require.EqualError(t, c.Execute(), "file handler [/content] not found on peer [peer2]")
+	})
+	t.Run("Query error", func(t *testing.T) {
+		errExpected := errors.New("query error")
+		ch.QueryReturns(channel.Response{}, errExpected)
+		c := newMockCmd(t, p, "add", "--mspid", "Org1MSP", "--path", "/content", "--read", "r", "--noprompt")
+		require.EqualError(t, c.Execute(), errExpected.Error())
+	})
+	t.Run("Execute error", func(t *testing.T) {
+		errExpected := errors.New("execute error")
+		ch.QueryReturns(channel.Response{Payload: []byte(payload)}, nil)
+		ch.ExecuteReturns(channel.Response{}, errExpected)
+		defer ch.ExecuteReturns(channel.Response{}, nil)
+
+		c := newMockCmd(t, p, "add", "--mspid", "Org1MSP", "--path", "/content", "--read", "r", "--noprompt")
+		require.EqualError(t, c.Execute(), errExpected.Error())
+	})
+}
+
+func getSavedHandlers(t *testing.T, ch *mocks.Channel) []*filehandler.Handler {
+	req, _ := ch.ExecuteArgsForCall(ch.ExecuteCallCount() - 1)
+	require.Equal(t, "save", req.Fcn)
+
+	cfg := &common.Config{}
+	require.NoError(t, json.Unmarshal(req.Args[0], cfg))
+
+	var handlers []*filehandler.Handler
+	for _, peer := range cfg.Peers {
+		handlerCfg := &filehandler.Config{}
+		require.NoError(t, json.Unmarshal([]byte(peer.Apps[0].Components[0].Config), handlerCfg))
+
+		handlers = append(handlers, &filehandler.Handler{
+			Config: handlerCfg,
+			MspID:  cfg.MspID,
+			PeerID: peer.PeerID,
+			Tags:   peer.Apps[0].Components[0].Tags,
+		})
+	}
+
+	return handlers
+}
+
+func newMockCmd(t *testing.T, p basecmd.FactoryProvider, args ...string) *cobra.Command {
+	return newMockCmdWithReaderWriter(t, &mocks.Reader{}, &mocks.Writer{}, p, args...)
+}
+
+func newMockCmdWithReaderWriter(t *testing.T, in io.Reader, w io.Writer, p basecmd.FactoryProvider, args ...string) *cobra.Command {
+	settings := environment.NewDefaultSettings()
+	settings.Streams.Out = w
+	settings.Streams.In = in
+
+	settings.Config.CurrentContext = "testctx"
+	settings.Config.Contexts[settings.Config.CurrentContext] = &environment.Context{}
+
+	c := newCmd(settings, p)
+	require.NotNil(t, c)
+
+	c.SetArgs(args)
+
+	return c
+}
@@ -0,0 +1,322 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifycmd
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/basecmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/httpclient"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/filehandler"
+)
+
+const (
+	use      = "verify"
+	desc     = "Verify the consistency of a file handler configuration across peers"
+	longDesc = `
+The verify command loads the file handler configuration for a base path from each of the given peers (--peers) or
+from all peers in the MSP (--all-peers), in which case the peers are resolved using Fabric discovery for the channel
+of the current context and the given MSP. The command reports:
+
+* Peers that have no file handler configuration for the base path
+* Peers whose file handler configuration can't be decoded
+* Fields of the configuration whose values differ between peers
+* File index document IDs that fail to resolve at the Sidetree endpoint given by --url (if specified)
+
+An error is returned if any inconsistency is found.
+`
+	examples = `
+- Verify the file handler for /content on two peers in Org1MSP:
+    $ ./fabric ledgerconfig filehandler verify --mspid Org1MSP --peers peer0.org1.example.com;peer1.org1.example.com --path /content
+
+- Verify the file handler for /content on all peers in Org1MSP and resolve the file index document:
+    $ ./fabric ledgerconfig filehandler verify --mspid Org1MSP --all-peers --path /content --url http://localhost:48326/file/identifiers --authtoken mytoken
+
+... results in output such as:
+
+	peer1.org1.example.com: no file handler configuration for [/content]
+	IndexDocID differs: peer0.org1.example.com=[file:idx:1234], peer2.org1.example.com=[file:idx:5678]
+	IndexDocID [file:idx:5678] (peer2.org1.example.com) failed to resolve: status code 404: not found
+	Error: found 3 inconsistencies in the file handler configuration for [/content]
+`
+)
+
+const (
+	mspIDFlag  = "mspid"
+	mspIDUsage = "The ID of the MSP. Example: --mspid Org1MSP"
+
+	peersFlag  = "peers"
+	peersUsage = "A semi-colon-separated list of peers. Example: --peers peer0.org1.com;peer1.org1.com"
+
+	allPeersFlag  = "all-peers"
+	allPeersUsage = "If specified then the peers of the MSP are resolved using Fabric discovery. Example: --all-peers"
+
+	basePathFlag  = "path"
+	basePathUsage = "The file handler base path. Example: --path /content"
+
+	urlFlag  = "url"
+	urlUsage = "The (optional) Sidetree resolution URL used to resolve the file index document IDs. Example: --url http://localhost:48326/file/identifiers"
+)
+
+var (
+	errMSPRequired           = errors.New("msp (--mspid) is required")
+	errBasePathRequired      = errors.New("base path (--path) is required")
+	errPeersOrAllPeersNeeded = errors.New("one of --peers or --all-peers must be specified")
+)
+
+type httpClient interface {
+	Get(url string, opts ...httpclient.RequestOpt) (*httpclient.HTTPResponse, error)
+}
+
+// New returns the filehandler verify sub-command
+func New(settings *environment.Settings) *cobra.Command {
+	cfg := httpclient.NewConfig(settings)
+
+	cmd := newCmd(settings, nil, httpclient.New(httpclient.WithConfig(cfg)))
+	cfg.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+func newCmd(settings *environment.Settings, p basecmd.FactoryProvider, client httpClient) *cobra.Command {
+	c := &command{
+		Command: basecmd.New(settings, p),
+		client:  client,
+	}
+
+	cmd := &cobra.Command{
+		Use:     use,
+		Short:   desc,
+		Long:    longDesc,
+		Example: examples,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return c.validate()
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return c.run()
+		},
+	}
+
+	cmd.SetOutput(c.Settings.Streams.Out)
+	cmd.SilenceUsage = true
+
+	cmd.Flags().StringVar(&c.mspID, mspIDFlag, "", mspIDUsage)
+	cmd.Flags().StringVar(&c.peers, peersFlag, "", peersUsage)
+	cmd.Flags().BoolVar(&c.allPeers, allPeersFlag, false, allPeersUsage)
+	cmd.Flags().StringVar(&c.basePath, basePathFlag, "", basePathUsage)
+	cmd.Flags().StringVar(&c.url, urlFlag, "", urlUsage)
+	httpclient.AddAuthTokenFlag(cmd.Flags(), &c.authToken, "the URL specified by --url")
+
+	return cmd
+}
+
+// command implements the verify command
+type command struct {
+	*basecmd.Command
+
+	client httpClient
+
+	// Flags
+	mspID     string
+	peers     string
+	allPeers  bool
+	basePath  string
+	url       string
+	authToken string
+}
+
+func (c *command) validate() error {
+	if c.mspID == "" {
+		return errMSPRequired
+	}
+
+	if c.basePath == "" {
+		return errBasePathRequired
+	}
+
+	if c.allPeers == (len(filehandler.ParseList(c.peers)) > 0) {
+		return errPeersOrAllPeersNeeded
+	}
+
+	return nil
+}
+
+func (c *command) run() error {
+	peers, handlers, invalid, err := c.loadHandlers()
+	if err != nil {
+		return err
+	}
+
+	var issues []string
+	for _, peerID := range peers {
+		if e, ok := invalid[peerID]; ok {
+			issues = append(issues, fmt.Sprintf("%s: invalid file handler configuration: %s", peerID, e))
+			continue
+		}
+
+		if _, ok := handlers[peerID]; !ok {
+			issues = append(issues, fmt.Sprintf("%s: no file handler configuration for [%s]", peerID, c.basePath))
+		}
+	}
+
+	issues = append(issues, c.diff(peers, handlers)...)
+
+	if c.url != "" {
+		issues = append(issues, c.resolveIndexDocIDs(peers, handlers)...)
+	}
+
+	for _, issue := range issues {
+		if err := c.Fprintln(issue); err != nil {
+			return err
+		}
+	}
+
+	if len(issues) > 0 {
+		return errors.Errorf("found %d inconsistencies in the file handler configuration for [%s]", len(issues), c.basePath)
+	}
+
+	return c.Fprintln(fmt.Sprintf("The file handler configuration for [%s] is consistent across %d peer(s)", c.basePath, len(peers)))
+}
+
+// loadHandlers returns the (sorted) peers to verify along with the file handler for the base path of each peer.
+// The handlers that can't be decoded are returned separately, along with the error, so that they may be reported
+// as inconsistencies rather than preventing the other peers from being verified.
+func (c *command) loadHandlers() ([]string, map[string]*filehandler.Handler, map[string]error, error) {
+	ch, err := c.Channel()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	kvs, err := filehandler.Query(ch, filehandler.Criteria(c.mspID, "", ""))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	handlers := make(map[string]*filehandler.Handler)
+	invalid := make(map[string]error)
+
+	for _, kv := range kvs {
+		if kv.ComponentName != c.basePath {
+			continue
+		}
+
+		h, e := filehandler.FromKeyValue(kv)
+		if e != nil {
+			invalid[kv.PeerID] = e
+			continue
+		}
+
+		handlers[kv.PeerID] = h
+	}
+
+	peers, err := filehandler.ResolvePeers(c, c.mspID, c.peers, c.allPeers)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	sort.Strings(peers)
+
+	if c.allPeers {
+		if err := c.Fprintln(fmt.Sprintf("Resolved peers: %s", peers)); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	return peers, handlers, invalid, nil
+}
+
+// diff returns a description of each field whose value differs between peers
+func (c *command) diff(peers []string, handlers map[string]*filehandler.Handler) []string {
+	fields := []struct {
+		name  string
+		value func(h *filehandler.Handler) string
+	}{
+		{"ChaincodeName", func(h *filehandler.Handler) string { return h.ChaincodeName }},
+		{"Collection", func(h *filehandler.Handler) string { return h.Collection }},
+		{"IndexNamespace", func(h *filehandler.Handler) string { return h.IndexNamespace }},
+		{"IndexDocID", func(h *filehandler.Handler) string { return h.IndexDocID }},
+		{"ReadTokens", func(h *filehandler.Handler) string { return strings.Join(h.Authorization.ReadTokens, ";") }},
+		{"WriteTokens", func(h *filehandler.Handler) string { return strings.Join(h.Authorization.WriteTokens, ";") }},
+	}
+
+	var issues []string
+	for _, field := range fields {
+		values := make(map[string]struct{})
+
+		var peerValues []string
+		for _, peerID := range peers {
+			h, ok := handlers[peerID]
+			if !ok {
+				continue
+			}
+
+			value := field.value(h)
+			values[value] = struct{}{}
+			peerValues = append(peerValues, fmt.Sprintf("%s=[%s]", peerID, value))
+		}
+
+		if len(values) > 1 {
+			issues = append(issues, fmt.Sprintf("%s differs: %s", field.name, strings.Join(peerValues, ", ")))
+		}
+	}
+
+	return issues
+}
+
+// resolveIndexDocIDs resolves each of the distinct file index document IDs at the Sidetree endpoint and
+// returns a description of each ID that failed to resolve
+func (c *command) resolveIndexDocIDs(peers []string, handlers map[string]*filehandler.Handler) []string {
+	var ids []string
+	peersForID := make(map[string][]string)
+
+	for _, peerID := range peers {
+		h, ok := handlers[peerID]
+		if !ok || h.IndexDocID == "" {
+			continue
+		}
+
+		if _, ok := peersForID[h.IndexDocID]; !ok {
+			ids = append(ids, h.IndexDocID)
+		}
+
+		peersForID[h.IndexDocID] = append(peersForID[h.IndexDocID], peerID)
+	}
+
+	var reqOpts []httpclient.RequestOpt
+	if c.authToken != "" {
+		reqOpts = append(reqOpts, httpclient.WithAuthToken(c.authToken))
+	}
+
+	var issues []string
+	for _, id := range ids {
+		if err := c.resolve(id, reqOpts); err != nil {
+			issues = append(issues, fmt.Sprintf("IndexDocID [%s] (%s) failed to resolve: %s", id, strings.Join(peersForID[id], ", "), err))
+		}
+	}
+
+	return issues
+}
+
+func (c *command) resolve(id string, reqOpts []httpclient.RequestOpt) error {
+	resp, err := c.client.Get(strings.TrimSuffix(c.url, "/")+"/"+id, reqOpts...)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("status code %d: %s", resp.StatusCode, resp.ErrorMsg)
+	}
+
+	return nil
+}
@@ -0,0 +1,176 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifycmd
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/hyperledger/fabric-cli/pkg/fabric"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	fabmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/basecmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/file/httpclient"
+	"github.com/trustbloc/fabric-cli-ext/cmd/mocks"
+)
+
+const (
+	consistentPayload = `[
+{"MspID":"Org1MSP","PeerID":"peer0","AppName":"file-handler","AppVersion":"1","ComponentName":"/content","ComponentVersion":"1","TxID":"tx1","Format":"JSON","Config":"{\"BasePath\":\"/content\",\"ChaincodeName\":\"files\",\"IndexNamespace\":\"file:idx\",\"IndexDocID\":\"file:idx:1234\"}"},
+{"MspID":"Org1MSP","PeerID":"peer1","AppName":"file-handler","AppVersion":"1","ComponentName":"/content","ComponentVersion":"1","TxID":"tx1","Format":"JSON","Config":"{\"BasePath\":\"/content\",\"ChaincodeName\":\"files\",\"IndexNamespace\":\"file:idx\",\"IndexDocID\":\"file:idx:1234\"}"}
+]`
+
+	inconsistentPayload = `[
+{"MspID":"Org1MSP","PeerID":"peer0","AppName":"file-handler","AppVersion":"1","ComponentName":"/content","ComponentVersion":"1","TxID":"tx1","Format":"JSON","Config":"{\"BasePath\":\"/content\",\"ChaincodeName\":\"files\",\"IndexNamespace\":\"file:idx\",\"IndexDocID\":\"file:idx:1234\"}"},
+{"MspID":"Org1MSP","PeerID":"peer1","AppName":"file-handler","AppVersion":"1","ComponentName":"/content","ComponentVersion":"1","TxID":"tx1","Format":"JSON","Config":"{\"BasePath\":\"/content\",\"ChaincodeName\":\"files\",\"IndexNamespace\":\"file:idx\",\"IndexDocID\":\"file:idx:5678\"}"},
+{"MspID":"Org1MSP","PeerID":"peer2","AppName":"file-handler","AppVersion":"1","ComponentName":"/schema","ComponentVersion":"1","TxID":"tx1","Format":"JSON","Config":"{\"BasePath\":\"/schema\"}"}
+]`
+)
+
+func TestNew(t *testing.T) {
+	require.NotNil(t, New(environment.NewDefaultSettings()))
+}
+
+func TestVerifyCmd_InvalidOptions(t *testing.T) {
+	t.Run("No MSP", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, nil, &mocks.Writer{}).Execute(), errMSPRequired.Error())
+	})
+	t.Run("No path", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, nil, &mocks.Writer{}, "--mspid", "Org1MSP").Execute(), errBasePathRequired.Error())
+	})
+	t.Run("No peers", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, nil, &mocks.Writer{}, "--mspid", "Org1MSP", "--path", "/content").Execute(), errPeersOrAllPeersNeeded.Error())
+	})
+	t.Run("Peers and all peers", func(t *testing.T) {
+		c := newMockCmd(t, nil, nil, &mocks.Writer{}, "--mspid", "Org1MSP", "--path", "/content", "--peers", "peer0", "--all-peers")
+		require.EqualError(t, c.Execute(), errPeersOrAllPeersNeeded.Error())
+	})
+}
+
+func TestVerifyCmd(t *testing.T) {
+	factory := &mocks.Factory{}
+	ch := &mocks.Channel{}
+	factory.ChannelReturns(ch, nil)
+	p := func(config *environment.Config) (fabric.Factory, error) { return factory, nil }
+
+	peer0 := &fabmocks.MockPeer{MockMSP: "Org1MSP", MockURL: "grpcs://peer0:7051"}
+	peer1 := &fabmocks.MockPeer{MockMSP: "Org1MSP", MockURL: "grpcs://peer1:7051"}
+	peer2 := &fabmocks.MockPeer{MockMSP: "Org1MSP", MockURL: "grpcs://peer2:7051"}
+
+	factory.SDKReturns(mocks.NewSDK(peer0, peer1), nil)
+
+	t.Run("Consistent", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: []byte(consistentPayload)}, nil)
+		client := &mockHTTPClient{}
+		w := &mocks.Writer{}
+		c := newMockCmd(t, p, client, w, "--mspid", "Org1MSP", "--path", "/content", "--all-peers", "--url", "http://localhost/file/identifiers/", "--authtoken", "tk")
+		require.NoError(t, c.Execute())
+		require.Contains(t, w.Written(), "Resolved peers: [peer0 peer1]")
+		require.Contains(t, w.Written(), "is consistent across 2 peer(s)")
+		require.Equal(t, []string{"http://localhost/file/identifiers/file:idx:1234"}, client.urls)
+	})
+	t.Run("Inconsistent", func(t *testing.T) {
+		factory.SDKReturns(mocks.NewSDK(peer2, peer1, peer0), nil)
+		defer factory.SDKReturns(mocks.NewSDK(peer0, peer1), nil)
+
+		ch.QueryReturns(channel.Response{Payload: []byte(inconsistentPayload)}, nil)
+		client := &mockHTTPClient{notFound: map[string]bool{"file:idx:5678": true}}
+		w := &mocks.Writer{}
+		c := newMockCmd(t, p, client, w, "--mspid", "Org1MSP", "--path", "/content", "--all-peers", "--url", "http://localhost/file/identifiers")
+		require.EqualError(t, c.Execute(), "found 3 inconsistencies in the file handler configuration for [/content]")
+		require.Contains(t, w.Written(), "peer2: no file handler configuration for [/content]")
+		require.Contains(t, w.Written(), "IndexDocID differs: peer0=[file:idx:1234], peer1=[file:idx:5678]")
+		require.Contains(t, w.Written(), "IndexDocID [file:idx:5678] (peer1) failed to resolve: status code 404: not found")
+		require.Len(t, client.urls, 2)
+	})
+	t.Run("Given peers", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: []byte(consistentPayload)}, nil)
+		w := &mocks.Writer{}
+		c := newMockCmd(t, p, &mockHTTPClient{}, w, "--mspid", "Org1MSP", "--path", "/content", "--peers", "peer0;peer3")
+		require.Error(t, c.Execute())
+		require.Contains(t, w.Written(), "peer3: no file handler configuration for [/content]")
+	})
+	t.Run("Resolve error", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: []byte(consistentPayload)}, nil)
+		w := &mocks.Writer{}
+		c := newMockCmd(t, p, &mockHTTPClient{err: errors.New("connection refused")}, w, "--mspid", "Org1MSP", "--path", "/content", "--all-peers", "--url", "http://localhost")
+		require.Error(t, c.Execute())
+		require.Contains(t, w.Written(), "failed to resolve: connection refused")
+	})
+	t.Run("Discovery error", func(t *testing.T) {
+		factory.SDKReturns(mocks.NewSDK().WithDiscoveryError(errors.New("discovery error")), nil)
+		defer factory.SDKReturns(mocks.NewSDK(peer0, peer1), nil)
+
+		ch.QueryReturns(channel.Response{Payload: []byte(consistentPayload)}, nil)
+		err := newMockCmd(t, p, &mockHTTPClient{}, &mocks.Writer{}, "--mspid", "Org1MSP", "--path", "/content", "--all-peers").Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "discovery error")
+	})
+	t.Run("Query error", func(t *testing.T) {
+		errExpected := errors.New("query error")
+		ch.QueryReturns(channel.Response{}, errExpected)
+		c := newMockCmd(t, p, &mockHTTPClient{}, &mocks.Writer{}, "--mspid", "Org1MSP", "--path", "/content", "--all-peers")
+		require.EqualError(t, c.Execute(), errExpected.Error())
+	})
+	t.Run("Invalid config", func(t *testing.T) {
+		// The invalid config on peer0 is reported and peer1 is still verified
+		payload := `[{"MspID":"Org1MSP","PeerID":"peer0","ComponentName":"/content","Config":"{"},` + consistentPayload[2:]
+
+		ch.QueryReturns(channel.Response{Payload: []byte(payload)}, nil)
+		client := &mockHTTPClient{}
+		w := &mocks.Writer{}
+		c := newMockCmd(t, p, client, w, "--mspid", "Org1MSP", "--path", "/content", "--all-peers", "--url", "http://localhost/file/identifiers")
+		require.EqualError(t, c.Execute(), "found 1 inconsistencies in the file handler configuration for [/content]")
+		require.Contains(t, w.Written(), "peer0: invalid file handler configuration: invalid file handler config")
+		require.NotContains(t, w.Written(), "peer1:")
+		require.Equal(t, []string{"http://localhost/file/identifiers/file:idx:1234"}, client.urls)
+	})
+}
+
+type mockHTTPClient struct {
+	notFound map[string]bool
+	err      error
+	urls     []string
+}
+
+func (m *mockHTTPClient) Get(url string, _ ...httpclient.RequestOpt) (*httpclient.HTTPResponse, error) {
+	m.urls = append(m.urls, url)
+
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	for id := range m.notFound {
+		if strings.HasSuffix(url, id) {
+			return &httpclient.HTTPResponse{StatusCode: http.StatusNotFound, ErrorMsg: "not found"}, nil
+		}
+	}
+
+	return &httpclient.HTTPResponse{StatusCode: http.StatusOK}, nil
+}
+
+func newMockCmd(t *testing.T, p basecmd.FactoryProvider, client httpClient, w io.Writer, args ...string) *cobra.Command {
+	settings := environment.NewDefaultSettings()
+	settings.Streams.Out = w
+
+	settings.Config.CurrentContext = "testctx"
+	settings.Config.Contexts[settings.Config.CurrentContext] = &environment.Context{}
+
+	c := newCmd(settings, p, client)
+	require.NotNil(t, c)
+
+	c.SetArgs(args)
+
+	return c
+}
@@ -19,26 +19,36 @@ import (
 
 	"github.com/trustbloc/fabric-cli-ext/cmd/basecmd"
 	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/common"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/filehandler"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/signature"
 )
 
 const (
 	use      = "fileidxupdate"
 	desc     = "Update the ID of the file index document for a given path"
 	longDesc = `
-The fileidxupdate command allows a client to update the file handler configuration of a peer with an ID of a Sidetree file index document`
+The fileidxupdate command allows a client to update the file handler configuration of a peer with an ID of a Sidetree file index document.
+The peers may be specified explicitly (--peers) or, if --all-peers is specified, they are resolved using Fabric discovery for the channel
+of the current context and the given MSP.`
 	examples = `
 - Updates the ID of the file index Sidetree document in two peers in Org1MSP:
-    $ ./fabric ledgerconfig fileidxupdate --msp Org1MSP --peers peer0.org1.example.com;peer1.org1.example.com --path /content --idxid file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA== --noprompt
+    $ ./fabric ledgerconfig fileidxupdate --mspid Org1MSP --peers peer0.org1.example.com;peer1.org1.example.com --path /content --idxid file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA== --noprompt
+
+- Updates the ID of the file index Sidetree document in all peers in Org1MSP:
+    $ ./fabric ledgerconfig fileidxupdate --mspid Org1MSP --all-peers --path /content --idxid file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA==
 `
 )
 
 const (
-	mspIDFlag  = "msp"
-	mspIDUsage = `The ID of the MSP. Example: --msp Org1MSP`
+	mspIDFlag  = "mspid"
+	mspIDUsage = `The ID of the MSP. Example: --mspid Org1MSP`
 
 	peersFlag  = "peers"
 	peersUsage = "A semi-colon-separated list of peers. Example: --peers peer0.org1.com;peer1.org1.com"
 
+	allPeersFlag  = "all-peers"
+	allPeersUsage = "If specified then the peers of the MSP are resolved using Fabric discovery. Example: --all-peers"
+
 	basePathFlag  = "path"
 	basePathUsage = "The file handler path. Example: --path /schema"
 
@@ -53,17 +63,9 @@ const (
 	msgContinueOrAbort = "Enter Y to continue or N to abort "
 )
 
-const (
-	configSCC = "configscc"
-
-	fileHandlerAppName          = "file-handler"
-	fileHandlerAppVersion       = "1"
-	fileHandlerComponentVersion = "1"
-)
-
 var (
-	errMSPRequired         = errors.New("msp (--msp) is required")
-	errPeersRequired       = errors.New("peers (--peers) is required")
+	errMSPRequired         = errors.New("msp (--mspid) is required")
+	errPeersRequired       = errors.New("one of --peers or --all-peers must be specified")
 	errFileIndexIDRequired = errors.New("file index ID (--idxid) is required")
 	errBasePathRequired    = errors.New("base path (--path) is required")
 )
@@ -99,6 +101,7 @@ func newCmd(settings *environment.Settings, p basecmd.FactoryProvider) *cobra.Co
 
 	cmd.Flags().StringVar(&c.mspID, mspIDFlag, "", mspIDUsage)
 	cmd.Flags().StringVar(&c.peerID, peersFlag, "", peersUsage)
+	cmd.Flags().BoolVar(&c.allPeers, allPeersFlag, false, allPeersUsage)
 	cmd.Flags().StringVar(&c.basePath, basePathFlag, "", basePathUsage)
 	cmd.Flags().StringVar(&c.fileIndexID, fileIndexIDFlag, "", fileIndexIDUsage)
 	cmd.Flags().BoolVar(&c.noPrompt, noPromptFlag, false, noPromptUsage)
@@ -113,6 +116,7 @@ type command struct {
 	// Flags
 	mspID       string
 	peerID      string
+	allPeers    bool
 	basePath    string
 	fileIndexID string
 	noPrompt    bool
@@ -123,7 +127,7 @@ func (c *command) validate() error {
 		return errMSPRequired
 	}
 
-	if c.peerID == "" {
+	if (c.peerID == "") != c.allPeers {
 		return errPeersRequired
 	}
 
@@ -174,23 +178,6 @@ func (c *command) run() error {
 	return c.Fprintln(msgConfigUpdated)
 }
 
-type authConfig struct {
-	// ReadTokens contains a set of names of tokens for authorizing read requests
-	ReadTokens []string
-	// WriteTokens contains a set of names of tokens for authorizing write requests
-	WriteTokens []string
-}
-
-type fileHandlerConfig struct {
-	Authorization authConfig
-
-	BasePath       string
-	ChaincodeName  string
-	Collection     string
-	IndexNamespace string
-	IndexDocID     string
-}
-
 func (c *command) getConfigBytes() ([]byte, error) {
 	cfgMap, err := c.loadConfig()
 	if err != nil {
@@ -201,7 +188,8 @@ func (c *command) getConfigBytes() ([]byte, error) {
 		MspID: c.mspID,
 	}
 
-	for peerID, handlerCfg := range cfgMap {
+	for peerID, h := range cfgMap {
+		handlerCfg := h.Config
 		handlerCfg.IndexDocID = c.fileIndexID
 
 		cfgBytes, err := json.Marshal(handlerCfg)
@@ -213,14 +201,16 @@ func (c *command) getConfigBytes() ([]byte, error) {
 			PeerID: peerID,
 			Apps: []*common.App{
 				{
-					AppName: fileHandlerAppName,
-					Version: fileHandlerAppVersion,
+					AppName: filehandler.AppName,
+					Version: filehandler.AppVersion,
 					Components: []*common.Component{
 						{
 							Name:    c.basePath,
-							Version: fileHandlerComponentVersion,
-							Format:  "JSON",
+							Version: filehandler.ComponentVersion,
+							Format:  filehandler.Format,
 							Config:  string(cfgBytes),
+							// The signature of the existing config (if any) no longer applies
+							Tags: signature.RemoveTag(h.Tags),
 						},
 					},
 				},
@@ -233,18 +223,27 @@ func (c *command) getConfigBytes() ([]byte, error) {
 	return json.Marshal(cfg)
 }
 
-func (c *command) loadConfig() (map[string]*fileHandlerConfig, error) {
-	peers := strings.Split(c.peerID, ";")
+func (c *command) loadConfig() (map[string]*filehandler.Handler, error) {
+	peers, err := filehandler.ResolvePeers(c, c.mspID, c.peerID, c.allPeers)
+	if err != nil {
+		return nil, err
+	}
 
-	cfgMap := make(map[string]*fileHandlerConfig)
+	if c.allPeers {
+		if err := c.Fprintln(fmt.Sprintf("Resolved peers: %s", peers)); err != nil {
+			return nil, err
+		}
+	}
+
+	cfgMap := make(map[string]*filehandler.Handler)
 	for _, peerID := range peers {
 		cfg, err := c.loadPeerConfig(peerID)
 		if err != nil {
 			return nil, err
 		}
 
-		if !strings.HasPrefix(c.fileIndexID, cfg.IndexNamespace) {
-			return nil, errors.Errorf("file index ID must begin with [%s]", cfg.IndexNamespace)
+		if err := filehandler.ValidateIndexDocID(c.fileIndexID, cfg.IndexNamespace); err != nil {
+			return nil, err
 		}
 
 		if cfg.IndexDocID == c.fileIndexID {
@@ -262,39 +261,13 @@ func (c *command) loadConfig() (map[string]*fileHandlerConfig, error) {
 	return cfgMap, nil
 }
 
-func (c *command) loadPeerConfig(peerID string) (*fileHandlerConfig, error) {
-	criteria := &common.Criteria{
-		MspID:            c.mspID,
-		PeerID:           peerID,
-		AppName:          fileHandlerAppName,
-		AppVersion:       fileHandlerAppVersion,
-		ComponentName:    c.basePath,
-		ComponentVersion: fileHandlerComponentVersion,
-	}
-
-	criteriaBytes, err := json.Marshal(criteria)
-	if err != nil {
-		return nil, err
-	}
-
-	req := channel.Request{
-		ChaincodeID: configSCC,
-		Fcn:         "get",
-		Args:        [][]byte{criteriaBytes},
-	}
-
+func (c *command) loadPeerConfig(peerID string) (*filehandler.Handler, error) {
 	ch, err := c.Channel()
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := ch.Query(req)
-	if err != nil {
-		return nil, err
-	}
-
-	var queryResults []*common.KeyValue
-	err = json.Unmarshal(resp.Payload, &queryResults)
+	queryResults, err := filehandler.Query(ch, filehandler.Criteria(c.mspID, peerID, c.basePath))
 	if err != nil {
 		return nil, err
 	}
@@ -303,14 +276,7 @@ func (c *command) loadPeerConfig(peerID string) (*fileHandlerConfig, error) {
 		return nil, errors.Errorf("config not found for file handler [%s]", c.basePath)
 	}
 
-	cfg := &fileHandlerConfig{}
-
-	err = json.Unmarshal([]byte(queryResults[0].Value.Config), cfg)
-	if err != nil {
-		return nil, err
-	}
-
-	return cfg, nil
+	return filehandler.FromKeyValue(queryResults[0])
 }
 
 // confirmUpdate prompts the user for confirmation of the update
@@ -15,10 +15,13 @@ import (
 	"github.com/hyperledger/fabric-cli/pkg/environment"
 	"github.com/hyperledger/fabric-cli/pkg/fabric"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	fabmocks "github.com/hyperledger/fabric-sdk-go/pkg/fab/mocks"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
 	"github.com/trustbloc/fabric-cli-ext/cmd/basecmd"
 	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/common"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/filehandler"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/signature"
 	"github.com/trustbloc/fabric-cli-ext/cmd/mocks"
 )
 
@@ -28,7 +31,7 @@ func TestNew(t *testing.T) {
 
 func TestFileIDXUpdateCmd_InvalidOptions(t *testing.T) {
 	const (
-		mspFlag   = "--msp"
+		mspFlag   = "--mspid"
 		msp       = "Org1MSP"
 		peersFlag = "--peers"
 		peers     = "peer0.org1.example.com;peer1.org1.example.com"
@@ -44,6 +47,10 @@ func TestFileIDXUpdateCmd_InvalidOptions(t *testing.T) {
 		require.EqualError(t, newMockCmd(t, nil, mspFlag, msp).Execute(), errPeersRequired.Error())
 	})
 
+	t.Run("Peers and all peers", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, mspFlag, msp, peersFlag, peers, "--all-peers").Execute(), errPeersRequired.Error())
+	})
+
 	t.Run("No path", func(t *testing.T) {
 		require.EqualError(t, newMockCmd(t, nil, mspFlag, msp, peersFlag, peers).Execute(), errBasePathRequired.Error())
 	})
@@ -54,7 +61,7 @@ func TestFileIDXUpdateCmd_InvalidOptions(t *testing.T) {
 }
 
 func TestFileIDXUpdateCmd_InitializeError(t *testing.T) {
-	args := []string{"--msp", "Org1MSP", "--peers", "peer0.org1.example.com;peer1.org1.example.com", "--path", "/content", "--idxid", "file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA=="}
+	args := []string{"--mspid", "Org1MSP", "--peers", "peer0.org1.example.com;peer1.org1.example.com", "--path", "/content", "--idxid", "file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA=="}
 
 	t.Run("With channel error", func(t *testing.T) {
 		errExpected := errors.New("channel error")
@@ -76,7 +83,7 @@ func TestFileIDXUpdateCmd(t *testing.T) {
 		setHandlerCfg        = `{"BasePath":"/content","ChaincodeName":"files","Collection":"consortium","IndexNamespace":"file:idx","IndexDocID": "file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA=="}`
 	)
 
-	args := []string{"--msp", "Org1MSP", "--peers", "peer.org1.example.com;peer1.org1.example.com", "--path", "/content", "--idxid", "file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA=="}
+	args := []string{"--mspid", "Org1MSP", "--peers", "peer.org1.example.com;peer1.org1.example.com", "--path", "/content", "--idxid", "file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA=="}
 
 	factory := &mocks.Factory{}
 	c := &mocks.Channel{}
@@ -88,8 +95,8 @@ func TestFileIDXUpdateCmd(t *testing.T) {
 	key := &common.Key{
 		MspID:            msp,
 		PeerID:           peer,
-		AppName:          fileHandlerAppName,
-		AppVersion:       fileHandlerAppVersion,
+		AppName:          filehandler.AppName,
+		AppVersion:       filehandler.AppVersion,
 		ComponentName:    path,
 		ComponentVersion: "1",
 	}
@@ -110,6 +117,33 @@ func TestFileIDXUpdateCmd(t *testing.T) {
 		require.NoError(t, c.Execute())
 	})
 
+	t.Run("Tags retained", func(t *testing.T) {
+		signedCfg := &common.KeyValue{
+			Key: key,
+			Value: &common.Value{TxID: "tx1", Format: "json", Config: handlerCfg,
+				Tags: signature.SetTag([]string{"tag1"}, "eyJhbGciOiJFUzI1NiJ9..c2lnbmF0dXJl")},
+		}
+
+		signedCfgBytes, err := json.Marshal([]*common.KeyValue{signedCfg})
+		require.NoError(t, err)
+
+		c.QueryReturns(channel.Response{Payload: signedCfgBytes}, nil)
+		require.NoError(t, newMockCmd(t, p, append(args, "--noprompt")...).Execute())
+
+		req, _ := c.ExecuteArgsForCall(c.ExecuteCallCount() - 1)
+
+		cfg := &common.Config{}
+		require.NoError(t, json.Unmarshal(req.Args[0], cfg))
+		require.NotEmpty(t, cfg.Peers)
+
+		// The signature no longer applies to the updated config so only the other tags are retained
+		for _, peer := range cfg.Peers {
+			comp := peer.Apps[0].Components[0]
+			require.Contains(t, comp.Config, "file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA==")
+			require.Equal(t, []string{"tag1"}, comp.Tags)
+		}
+	})
+
 	t.Run("With prompt - Y", func(t *testing.T) {
 		c.QueryReturns(validResp, nil)
 		w := &mocks.Writer{}
@@ -137,6 +171,26 @@ func TestFileIDXUpdateCmd(t *testing.T) {
 		require.EqualError(t, c.Execute(), errExpected.Error())
 	})
 
+	t.Run("With --all-peers", func(t *testing.T) {
+		c.QueryReturns(validResp, nil)
+		factory.SDKReturns(mocks.NewSDK(&fabmocks.MockPeer{MockMSP: msp, MockURL: "grpcs://peer.org1.example.com:7051"}), nil)
+
+		w := &mocks.Writer{}
+		c := newMockCmdWithReaderWriter(t, &mocks.Reader{}, w, p, "--mspid", msp, "--all-peers", "--path", path, "--idxid", "file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA==", "--noprompt")
+		require.NoError(t, c.Execute())
+		require.Contains(t, w.Written(), "Resolved peers: [peer.org1.example.com]")
+		require.Contains(t, w.Written(), msgConfigUpdated)
+	})
+
+	t.Run("With --all-peers - discovery error", func(t *testing.T) {
+		factory.SDKReturns(mocks.NewSDK().WithDiscoveryError(errors.New("discovery error")), nil)
+
+		c := newMockCmd(t, p, "--mspid", msp, "--all-peers", "--path", path, "--idxid", "file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA==", "--noprompt")
+		err := c.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "discovery error")
+	})
+
 	t.Run("Invalid file index ID", func(t *testing.T) {
 		cfg := &common.KeyValue{
 			Key:   key,
@@ -10,15 +10,19 @@ import (
 	"github.com/hyperledger/fabric-cli/pkg/environment"
 	"github.com/spf13/cobra"
 	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/deletecmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/filehandlercmd"
 	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/fileidxupdatecmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/patchcmd"
 	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/querycmd"
 	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/updatecmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/verifycmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/watchcmd"
 )
 
 const (
 	use      = "ledgerconfig"
 	desc     = "Manages ledger configuration"
-	longDesc = "The ledgerconfig command allows you to update, delete, and query ledger configuration."
+	longDesc = "The ledgerconfig command allows you to update, patch, delete, query, verify, and watch ledger configuration, and to manage file handlers."
 )
 
 // New is the entry point to the ledgerconfig plugin
@@ -36,6 +40,10 @@ func New(settings *environment.Settings) *cobra.Command {
 		updatecmd.New(settings),
 		deletecmd.New(settings),
 		fileidxupdatecmd.New(settings),
+		filehandlercmd.New(settings),
+		patchcmd.New(settings),
+		verifycmd.New(settings),
+		watchcmd.New(settings),
 	)
 	return cmd
 }
@@ -34,4 +34,12 @@ func TestNew(t *testing.T) {
 	require.Contains(t, w.Written(), "Delete ledger configuration")
 	// Make sure that the fileidxupdate command was added
 	require.Contains(t, w.Written(), "fileidxupdate")
+	// Make sure that the filehandler command was added
+	require.Contains(t, w.Written(), "Manages file handler configuration")
+	// Make sure that the patch command was added
+	require.Contains(t, w.Written(), "Patch the JSON configuration of applications")
+	// Make sure that the verify command was added
+	require.Contains(t, w.Written(), "Verify the signatures of ledger configuration")
+	// Make sure that the watch command was added
+	require.Contains(t, w.Written(), "Watch ledger configuration changes")
 }
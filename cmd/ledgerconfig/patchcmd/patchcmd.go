@@ -0,0 +1,352 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package patchcmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/errors/retry"
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/basecmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/common"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/signature"
+)
+
+const (
+	use      = "patch"
+	desc     = "Patch the JSON configuration of applications"
+	longDesc = `
+The patch command applies a JSON Patch (RFC 6902) or a JSON merge patch (RFC 7386) to the Config of every
+configuration that matches the given search criteria. The criteria consists of:
+
+* MspID (mandatory)           - The MSP ID of the organization
+* PeerID (optional)           - The ID of the peer
+* AppName (optional)          - The application name
+* AppVersion (optional)       - The application version
+* ComponentName (optional)    - The component name
+* ComponentVersion (optional) - The component version
+
+Criteria may be specified as a JSON string (using the --criteria option) or it may be specified using the options:
+	--mspid, --peerid, --appname, --appver, --componentname and --componentver
+
+The patch is specified using either the --patch or --patchfile option. The type of patch may be specified using
+the --type option; if not specified then a JSON array is treated as a JSON Patch and a JSON object is treated as a
+merge patch. The Config of each matching configuration must be in JSON format.
+
+A before/after diff is displayed for each configuration that changes. Configurations that are already up to date
+are skipped. All of the changes are saved in a single transaction. Since the patched config no longer matches its
+signature, any signature tag is removed from the patched configuration.
+`
+	examples = `
+- Update the IndexDocID of the file-handler config for /content on all peers in Org1MSP using a merge patch:
+    $ ./fabric ledgerconfig patch --mspid Org1MSP --appname file-handler --appver 1 --componentname /content --patch '{"IndexDocID":"file:idx:EiAuN66iEpuRt6IIu-2sO3bRM74sS_AIuY6jTbtFUsqAaA"}'
+
+- Add a read token to the file-handler config for /content on a given peer using a JSON Patch:
+    $ ./fabric ledgerconfig patch --mspid Org1MSP --peerid peer0.org1.example.com --appname file-handler --componentname /content --patch '[{"op":"add","path":"/Authorization/ReadTokens/-","value":"content_read"}]'
+`
+)
+
+const (
+	patchFlag  = "patch"
+	patchUsage = `The patch in JSON format. Example: --patch '{"Key1":"value1"}'`
+
+	patchFileFlag  = "patchfile"
+	patchFileUsage = "The path to a file containing the patch. Example: --patchfile ./patch.json"
+
+	typeFlag  = "type"
+	typeUsage = "The type of patch - 'json' (JSON Patch) or 'merge' (JSON merge patch). If not specified then the type is deduced from the patch. Example: --type merge"
+
+	noPromptFlag  = "noprompt"
+	noPromptUsage = "If specified then the patch operation will not prompt for confirmation. Example: --noprompt"
+
+	patchTypeJSON  = "json"
+	patchTypeMerge = "merge"
+
+	msgConfigUpdated   = "Configuration successfully patched!"
+	msgNoConfig        = "No configuration matches the given criteria"
+	msgUpToDate        = "All configuration is already up to date"
+	msgAborted         = "Operation aborted"
+	msgContinueOrAbort = "Enter Y to continue or N to abort "
+)
+
+var (
+	errPatchOrPatchFileRequired = errors.New("one of --patch or --patchfile must be specified")
+	errInvalidPatchType         = errors.New("invalid patch type (--type) - must be 'json' or 'merge'")
+)
+
+// New returns the ledgerconfig patch sub-command
+func New(settings *environment.Settings) *cobra.Command {
+	return newCmd(settings, nil)
+}
+
+func newCmd(settings *environment.Settings, p basecmd.FactoryProvider) *cobra.Command {
+	c := &command{}
+
+	cmd := &cobra.Command{
+		Use:     use,
+		Short:   desc,
+		Long:    longDesc,
+		Example: examples,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return c.validate()
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return c.run()
+		},
+	}
+	c.CriteriaBaseCommand = common.NewCriteriaBaseCommand(settings, p, cmd)
+
+	cmd.Flags().StringVar(&c.patch, patchFlag, "", patchUsage)
+	cmd.Flags().StringVar(&c.patchFile, patchFileFlag, "", patchFileUsage)
+	cmd.Flags().StringVar(&c.patchType, typeFlag, "", typeUsage)
+	cmd.Flags().BoolVar(&c.noPrompt, noPromptFlag, false, noPromptUsage)
+
+	return cmd
+}
+
+// command implements the patch command
+type command struct {
+	*common.CriteriaBaseCommand
+
+	// Flags
+	patch     string
+	patchFile string
+	patchType string
+	noPrompt  bool
+}
+
+func (c *command) validate() error {
+	if (c.patch == "") == (c.patchFile == "") {
+		return errPatchOrPatchFileRequired
+	}
+
+	if c.patchType != "" && c.patchType != patchTypeJSON && c.patchType != patchTypeMerge {
+		return errInvalidPatchType
+	}
+
+	return c.Validate()
+}
+
+func (c *command) run() error {
+	patcher, err := c.getPatcher()
+	if err != nil {
+		return err
+	}
+
+	kvs, err := c.getKeyValues()
+	if err != nil {
+		return err
+	}
+
+	if len(kvs) == 0 {
+		return c.Fprintln(msgNoConfig)
+	}
+
+	changes, err := c.applyPatch(patcher, kvs)
+	if err != nil {
+		return err
+	}
+
+	if len(changes) == 0 {
+		return c.Fprintln(msgUpToDate)
+	}
+
+	// Get confirmation from the user
+	if !c.noPrompt {
+		confirmed, e := c.confirm()
+		if e != nil {
+			return e
+		}
+		if !confirmed {
+			return c.Fprintln(msgAborted)
+		}
+	}
+
+	if err := c.save(changes); err != nil {
+		return err
+	}
+
+	return c.Fprintln(msgConfigUpdated)
+}
+
+func (c *command) getKeyValues() ([]*common.KeyValue, error) {
+	criteriaBytes, err := c.GetCriteriaBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := c.GetConfig(criteriaBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	var kvs []*common.KeyValue
+	if err := json.Unmarshal(config, &kvs); err != nil {
+		return nil, errors.WithMessage(err, "invalid config returned from query")
+	}
+
+	return kvs, nil
+}
+
+// applyPatch applies the patch to each of the given key-values, displays the diffs, and returns
+// the key-values that changed
+func (c *command) applyPatch(patcher patcher, kvs []*common.KeyValue) ([]*common.KeyValue, error) {
+	var changes []*common.KeyValue
+	for _, kv := range kvs {
+		if kv.Value == nil {
+			continue
+		}
+
+		if kv.Format == common.FormatEncrypted {
+			return nil, errors.Errorf("unable to patch encrypted config %s", kv.Key)
+		}
+
+		patched, err := patcher([]byte(kv.Config))
+		if err != nil {
+			return nil, errors.WithMessagef(err, "error applying patch to config %s", kv.Key)
+		}
+
+		if jsonpatch.Equal([]byte(kv.Config), patched) {
+			if err := c.Fprintln(fmt.Sprintf("%s: up to date", kv.Key)); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		diff, err := getDiff([]byte(kv.Config), patched)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := c.Fprintln(fmt.Sprintf("%s:\n%s", kv.Key, diff)); err != nil {
+			return nil, err
+		}
+
+		changes = append(changes, &common.KeyValue{
+			Key: kv.Key,
+			Value: &common.Value{
+				Format: kv.Format,
+				Config: string(patched),
+				Tags:   signature.RemoveTag(kv.Tags),
+			},
+		})
+	}
+
+	return changes, nil
+}
+
+func (c *command) save(changes []*common.KeyValue) error {
+	cfg, err := common.NewConfigFromKeyValues(changes)
+	if err != nil {
+		return err
+	}
+
+	cfgBytes, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+
+	ch, err := c.Channel()
+	if err != nil {
+		return err
+	}
+
+	req := channel.Request{
+		ChaincodeID: common.ConfigSCC,
+		Fcn:         "save",
+		Args:        [][]byte{cfgBytes},
+	}
+
+	_, err = ch.Execute(req, channel.WithRetry(retry.DefaultChannelOpts))
+
+	return err
+}
+
+func (c *command) getPatchBytes() ([]byte, error) {
+	if c.patch != "" {
+		return []byte(c.patch), nil
+	}
+
+	return ioutil.ReadFile(filepath.Clean(c.patchFile))
+}
+
+// patcher applies a patch to the given JSON document
+type patcher func(doc []byte) ([]byte, error)
+
+func (c *command) getPatcher() (patcher, error) {
+	patchBytes, err := c.getPatchBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	patchType := c.patchType
+	if patchType == "" {
+		patchType = patchTypeMerge
+		if bytes.HasPrefix(bytes.TrimSpace(patchBytes), []byte("[")) {
+			patchType = patchTypeJSON
+		}
+	}
+
+	if patchType == patchTypeMerge {
+		if !json.Valid(patchBytes) {
+			return nil, errors.New("invalid merge patch")
+		}
+
+		return func(doc []byte) ([]byte, error) {
+			return jsonpatch.MergePatch(doc, patchBytes)
+		}, nil
+	}
+
+	p, err := jsonpatch.DecodePatch(patchBytes)
+	if err != nil {
+		return nil, errors.WithMessage(err, "invalid JSON patch")
+	}
+
+	return p.Apply, nil
+}
+
+// confirm prompts the user for confirmation of the update
+func (c *command) confirm() (bool, error) {
+	if err := c.Fprintln(msgContinueOrAbort); err != nil {
+		return false, err
+	}
+
+	return strings.ToLower(c.Prompt()) == "y", nil
+}
+
+// getDiff returns a unified diff of the formatted before and after JSON
+func getDiff(before, after []byte) (string, error) {
+	formattedBefore, err := common.FormatJSON(before)
+	if err != nil {
+		return "", err
+	}
+
+	formattedAfter, err := common.FormatJSON(after)
+	if err != nil {
+		return "", err
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(formattedBefore) + "\n"),
+		B:        difflib.SplitLines(string(formattedAfter) + "\n"),
+		FromFile: "before",
+		ToFile:   "after",
+		Context:  3,
+	})
+}
@@ -0,0 +1,211 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package patchcmd
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/hyperledger/fabric-cli/pkg/fabric"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/basecmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/common"
+	"github.com/trustbloc/fabric-cli-ext/cmd/mocks"
+)
+
+const (
+	payload = `[
+{"MspID":"msp1","PeerID":"peer1","AppName":"file-handler","AppVersion":"1","ComponentName":"/content","ComponentVersion":"1","TxID":"tx1","Format":"json","Config":"{\"BasePath\":\"/content\",\"IndexDocID\":\"file:idx:1234\"}","Tags":["tag1","jws=abc..def"]},
+{"MspID":"msp1","PeerID":"peer2","AppName":"file-handler","AppVersion":"1","ComponentName":"/content","ComponentVersion":"1","TxID":"tx1","Format":"json","Config":"{\"BasePath\":\"/content\",\"IndexDocID\":\"file:idx:5678\"}"}
+]`
+
+	encryptedPayload = `[{"MspID":"msp1","PeerID":"peer1","AppName":"app1","AppVersion":"1","TxID":"tx1","Format":"ENCRYPTED","Config":"xxx"}]`
+
+	mergePatch = `{"IndexDocID":"file:idx:5678"}`
+	jsonPatch  = `[{"op":"replace","path":"/IndexDocID","value":"file:idx:5678"}]`
+)
+
+func TestPatchCmd_Validate(t *testing.T) {
+	t.Run("No patch", func(t *testing.T) {
+		c := newMockCmd(t, nil, "--mspid", "msp1")
+		require.EqualError(t, c.Execute(), errPatchOrPatchFileRequired.Error())
+	})
+	t.Run("Patch and patch file", func(t *testing.T) {
+		c := newMockCmd(t, nil, "--mspid", "msp1", "--patch", mergePatch, "--patchfile", "patch.json")
+		require.EqualError(t, c.Execute(), errPatchOrPatchFileRequired.Error())
+	})
+	t.Run("Invalid type", func(t *testing.T) {
+		c := newMockCmd(t, nil, "--mspid", "msp1", "--patch", mergePatch, "--type", "xxx")
+		require.EqualError(t, c.Execute(), errInvalidPatchType.Error())
+	})
+	t.Run("No criteria", func(t *testing.T) {
+		c := newMockCmd(t, nil, "--patch", mergePatch)
+		require.Error(t, c.Execute())
+	})
+	t.Run("Invalid merge patch", func(t *testing.T) {
+		c := newMockCmd(t, nil, "--mspid", "msp1", "--patch", "{xxx")
+		require.EqualError(t, c.Execute(), "invalid merge patch")
+	})
+	t.Run("Invalid JSON patch", func(t *testing.T) {
+		c := newMockCmd(t, nil, "--mspid", "msp1", "--patch", mergePatch, "--type", patchTypeJSON)
+		err := c.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid JSON patch")
+	})
+	t.Run("Patch file not found", func(t *testing.T) {
+		c := newMockCmd(t, nil, "--mspid", "msp1", "--patchfile", "./invalid.json")
+		require.Error(t, c.Execute())
+	})
+}
+
+func TestPatchCmd(t *testing.T) {
+	factory := &mocks.Factory{}
+	ch := &mocks.Channel{}
+	factory.ChannelReturns(ch, nil)
+	p := func(config *environment.Config) (fabric.Factory, error) { return factory, nil }
+
+	t.Run("Merge patch", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: []byte(payload)}, nil)
+		w := &mocks.Writer{}
+		c := newMockCmdWithReaderWriter(t, &mocks.Reader{}, w, p, "--mspid", "msp1", "--appname", "file-handler", "--patch", mergePatch, "--noprompt")
+		require.NoError(t, c.Execute())
+
+		written := w.Written()
+		require.Contains(t, written, "-  \"IndexDocID\": \"file:idx:1234\"")
+		require.Contains(t, written, "+  \"IndexDocID\": \"file:idx:5678\"")
+		require.Contains(t, written, "peer2")
+		require.Contains(t, written, "up to date")
+		require.Contains(t, written, msgConfigUpdated)
+
+		cfg := getSavedConfig(t, ch)
+		require.Equal(t, "msp1", cfg.MspID)
+		require.Len(t, cfg.Peers, 1)
+		require.Equal(t, "peer1", cfg.Peers[0].PeerID)
+		require.Len(t, cfg.Peers[0].Apps, 1)
+
+		app := cfg.Peers[0].Apps[0]
+		require.Equal(t, "file-handler", app.AppName)
+		require.Len(t, app.Components, 1)
+		require.Equal(t, `{"BasePath":"/content","IndexDocID":"file:idx:5678"}`, app.Components[0].Config)
+		require.Equal(t, []string{"tag1"}, app.Components[0].Tags) // The signature tag should be removed
+	})
+	t.Run("JSON patch from file", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: []byte(payload)}, nil)
+
+		dir, err := ioutil.TempDir("", "patchcmd")
+		require.NoError(t, err)
+		defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+		patchFile := filepath.Join(dir, "patch.json")
+		require.NoError(t, ioutil.WriteFile(patchFile, []byte(jsonPatch), 0600))
+
+		w := &mocks.Writer{}
+		c := newMockCmdWithReaderWriter(t, &mocks.Reader{}, w, p, "--mspid", "msp1", "--patchfile", patchFile, "--noprompt")
+		require.NoError(t, c.Execute())
+		require.Contains(t, w.Written(), msgConfigUpdated)
+	})
+	t.Run("Up to date", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: []byte(payload)}, nil)
+		w := &mocks.Writer{}
+		c := newMockCmdWithReaderWriter(t, &mocks.Reader{}, w, p, "--mspid", "msp1", "--patch", `{"BasePath":"/content"}`, "--noprompt")
+		require.NoError(t, c.Execute())
+		require.Contains(t, w.Written(), msgUpToDate)
+		require.NotContains(t, w.Written(), msgConfigUpdated)
+	})
+	t.Run("No config", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: []byte("null")}, nil)
+		w := &mocks.Writer{}
+		c := newMockCmdWithReaderWriter(t, &mocks.Reader{}, w, p, "--mspid", "msp1", "--patch", mergePatch, "--noprompt")
+		require.NoError(t, c.Execute())
+		require.Equal(t, msgNoConfig, w.Written())
+	})
+	t.Run("With prompt - Y", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: []byte(payload)}, nil)
+		w := &mocks.Writer{}
+		c := newMockCmdWithReaderWriter(t, &mocks.Reader{Bytes: []byte("Y\n")}, w, p, "--mspid", "msp1", "--patch", mergePatch)
+		require.NoError(t, c.Execute())
+		require.Contains(t, w.Written(), msgContinueOrAbort)
+		require.Contains(t, w.Written(), msgConfigUpdated)
+	})
+	t.Run("With prompt - N", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: []byte(payload)}, nil)
+		w := &mocks.Writer{}
+		c := newMockCmdWithReaderWriter(t, &mocks.Reader{Bytes: []byte("N\n")}, w, p, "--mspid", "msp1", "--patch", mergePatch)
+		require.NoError(t, c.Execute())
+		require.Contains(t, w.Written(), msgAborted)
+		require.NotContains(t, w.Written(), msgConfigUpdated)
+	})
+	t.Run("Encrypted config", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: []byte(encryptedPayload)}, nil)
+		c := newMockCmd(t, p, "--mspid", "msp1", "--patch", mergePatch, "--noprompt")
+		err := c.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unable to patch encrypted config")
+	})
+	t.Run("Patch error", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: []byte(payload)}, nil)
+		c := newMockCmd(t, p, "--mspid", "msp1", "--patch", `[{"op":"remove","path":"/xxx"}]`, "--noprompt")
+		err := c.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "error applying patch to config")
+	})
+	t.Run("Query error", func(t *testing.T) {
+		errExpected := errors.New("query error")
+		ch.QueryReturns(channel.Response{}, errExpected)
+		c := newMockCmd(t, p, "--mspid", "msp1", "--patch", mergePatch, "--noprompt")
+		require.EqualError(t, c.Execute(), errExpected.Error())
+	})
+	t.Run("Execute error", func(t *testing.T) {
+		errExpected := errors.New("execute error")
+		ch.QueryReturns(channel.Response{Payload: []byte(payload)}, nil)
+		ch.ExecuteReturns(channel.Response{}, errExpected)
+		defer ch.ExecuteReturns(channel.Response{}, nil)
+
+		c := newMockCmd(t, p, "--mspid", "msp1", "--patch", mergePatch, "--noprompt")
+		require.EqualError(t, c.Execute(), errExpected.Error())
+	})
+}
+
+func getSavedConfig(t *testing.T, ch *mocks.Channel) *common.Config {
+	req, _ := ch.ExecuteArgsForCall(ch.ExecuteCallCount() - 1)
+	require.Equal(t, "save", req.Fcn)
+	require.Len(t, req.Args, 1)
+
+	cfg := &common.Config{}
+	require.NoError(t, json.Unmarshal(req.Args[0], cfg))
+
+	return cfg
+}
+
+func newMockCmd(t *testing.T, p basecmd.FactoryProvider, args ...string) *cobra.Command {
+	return newMockCmdWithReaderWriter(t, &mocks.Reader{}, &mocks.Writer{}, p, args...)
+}
+
+func newMockCmdWithReaderWriter(t *testing.T, in io.Reader, w io.Writer, p basecmd.FactoryProvider, args ...string) *cobra.Command {
+	settings := environment.NewDefaultSettings()
+	settings.Streams.Out = w
+	settings.Streams.In = in
+
+	settings.Config.CurrentContext = "testctx"
+	settings.Config.Contexts[settings.Config.CurrentContext] = &environment.Context{}
+
+	c := newCmd(settings, p)
+	require.NotNil(t, c)
+
+	c.SetArgs(args)
+
+	return c
+}
@@ -7,10 +7,16 @@ SPDX-License-Identifier: Apache-2.0
 package querycmd
 
 import (
+	"encoding/json"
+	"fmt"
+
 	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/trustbloc/fabric-cli-ext/cmd/basecmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/keyutil"
 	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/common"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/envelope"
 )
 
 const (
@@ -30,6 +36,11 @@ Criteria may be specified as a JSON string (using the --criteria option) or it m
 	--mspid, --peerid, --appname, --appver, --componentname and --componentver
 
 If PeerID and AppName are not specified then all of the MSP's configuration is returned.
+
+Configuration that was encrypted by the update command (i.e. with Format "ENCRYPTED") may be decrypted for display
+by specifying the recipient's private key using the --decrypt-key option. The decrypted config is displayed with its
+original format. Encrypted config that cannot be decrypted with the given key is displayed as is and a warning is
+written to the error stream. The query fails if none of the encrypted config can be decrypted with the given key.
 `
 	examples = `
 - Query configuration of a particular application on a specified peer:
@@ -54,6 +65,9 @@ If PeerID and AppName are not specified then all of the MSP's configuration is r
 
 - Query for configuration using JSON criteria:
     $ ./fabric ledgerconfig query --criteria '{"MspID":"Org1MSP","PeerID":"peer0.org1.com","AppName":"app1","AppVersion":"v1"}'
+
+- Query configuration of an application and decrypt any encrypted config:
+    $ ./fabric ledgerconfig query --mspid Org1MSP --appname app1 --appver v1 --decrypt-key ./admin-key.pem
 `
 )
 
@@ -61,7 +75,11 @@ const (
 	formatFlag  = "format"
 	formatUsage = "If specified then displayed JSON will be formatted. Example: --format"
 
-	msgNoConfig = "No configuration matches the given criteria"
+	decryptKeyFlag  = "decrypt-key"
+	decryptKeyUsage = "The path to a PEM file containing the private key used to decrypt encrypted config. Example: --decrypt-key ./keys/admin-key.pem"
+
+	msgNoConfig     = "No configuration matches the given criteria"
+	msgNotDecrypted = "Warning: unable to decrypt the config of %s: %s"
 )
 
 // New returns the ledger config query command
@@ -85,6 +103,7 @@ func newCmd(settings *environment.Settings, p basecmd.FactoryProvider) *cobra.Co
 		},
 	}
 	cmd.Flags().BoolVar(&c.formatJSON, formatFlag, false, formatUsage)
+	cmd.Flags().StringVar(&c.decryptKey, decryptKeyFlag, "", decryptKeyUsage)
 	c.CriteriaBaseCommand = common.NewCriteriaBaseCommand(settings, p, cmd)
 	return cmd
 }
@@ -95,6 +114,7 @@ type command struct {
 
 	// Flags
 	formatJSON bool
+	decryptKey string
 }
 
 func (c *command) run() error {
@@ -108,6 +128,13 @@ func (c *command) run() error {
 		return err
 	}
 
+	if c.decryptKey != "" {
+		config, err = c.decrypt(config)
+		if err != nil {
+			return err
+		}
+	}
+
 	var displayedJSON []byte
 	if c.formatJSON {
 		if string(config) == "null" {
@@ -123,3 +150,55 @@ func (c *command) run() error {
 
 	return c.Fprintln(string(displayedJSON))
 }
+
+// decrypt decrypts all of the encrypted config in the given key-values. Config that cannot be decrypted with the
+// given key is left as is and a warning is written to the error stream. An error is returned if none of the
+// encrypted config can be decrypted.
+func (c *command) decrypt(config []byte) ([]byte, error) {
+	key, err := keyutil.PrivateKeyFromFile(c.decryptKey)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "error loading private key from [%s]", c.decryptKey)
+	}
+
+	var kvs []*common.KeyValue
+	if err := json.Unmarshal(config, &kvs); err != nil {
+		return nil, errors.WithMessage(err, "invalid config returned from query")
+	}
+
+	if kvs == nil {
+		return config, nil
+	}
+
+	encrypted, decrypted := 0, 0
+
+	for _, kv := range kvs {
+		if kv.Value == nil || kv.Format != common.FormatEncrypted {
+			continue
+		}
+
+		encrypted++
+
+		content, format, err := envelope.Open([]byte(kv.Config), key)
+		if err != nil {
+			// The config may have been encrypted for a different recipient, or it may be corrupt
+			c.warnNotDecrypted(kv.Key, err)
+			continue
+		}
+
+		kv.Config = string(content)
+		kv.Format = common.Format(format)
+		decrypted++
+	}
+
+	if encrypted > 0 && decrypted == 0 {
+		return nil, errors.Errorf("none of the encrypted config could be decrypted with the key in [%s]", c.decryptKey)
+	}
+
+	return json.Marshal(kvs)
+}
+
+// warnNotDecrypted writes a warning about config that couldn't be decrypted to the error stream so
+// that it doesn't interfere with the JSON output
+func (c *command) warnNotDecrypted(key *common.Key, err error) {
+	_, _ = fmt.Fprintf(c.Settings.Streams.Err, msgNotDecrypted+"\n", key, err)
+}
@@ -7,8 +7,10 @@ SPDX-License-Identifier: Apache-2.0
 package querycmd
 
 import (
+	"encoding/json"
 	"errors"
 	"io"
+	"io/ioutil"
 	"testing"
 
 	"github.com/hyperledger/fabric-cli/pkg/environment"
@@ -17,6 +19,9 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
 	"github.com/trustbloc/fabric-cli-ext/cmd/basecmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/keyutil"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/common"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/envelope"
 	"github.com/trustbloc/fabric-cli-ext/cmd/mocks"
 )
 
@@ -104,9 +109,129 @@ func TestQueryCmd(t *testing.T) {
 	})
 }
 
+func TestQueryCmd_Decrypt(t *testing.T) {
+	const keyFile = "../testdata/ec_private.key"
+
+	recipient, err := keyutil.PublicKeyFromFile("../testdata/ec_cert.pem")
+	require.NoError(t, err)
+
+	sealed, err := envelope.Seal([]byte(`{"password":"secret"}`), "JSON", recipient)
+	require.NoError(t, err)
+
+	otherRecipient, err := keyutil.PublicKeyFromFile("../testdata/rsa_public.key")
+	require.NoError(t, err)
+
+	sealedForOther, err := envelope.Seal([]byte("other"), "Other", otherRecipient)
+	require.NoError(t, err)
+
+	kvs := []*common.KeyValue{
+		{
+			Key:   &common.Key{MspID: "msp1", AppName: "app1", AppVersion: "v1"},
+			Value: &common.Value{TxID: "tx1", Format: common.FormatEncrypted, Config: string(sealed)},
+		},
+		{
+			Key:   &common.Key{MspID: "msp1", AppName: "app2", AppVersion: "v1"},
+			Value: &common.Value{TxID: "tx1", Format: common.FormatEncrypted, Config: string(sealedForOther)},
+		},
+		{
+			Key:   &common.Key{MspID: "msp1", AppName: "app3", AppVersion: "v1"},
+			Value: &common.Value{TxID: "tx1", Format: "Other", Config: "plain"},
+		},
+	}
+
+	kvsBytes, err := json.Marshal(kvs)
+	require.NoError(t, err)
+
+	factory := &mocks.Factory{}
+	ch := &mocks.Channel{}
+	ch.QueryReturns(channel.Response{Payload: kvsBytes}, nil)
+	factory.ChannelReturns(ch, nil)
+
+	p := func(config *environment.Config) (fabric.Factory, error) { return factory, nil }
+
+	t.Run("Success", func(t *testing.T) {
+		w := &mocks.Writer{}
+		errOut := &mocks.Writer{}
+		c := newMockCmdWithStreams(t, w, errOut, p, "--mspid", "msp1", "--decrypt-key", keyFile)
+		require.NoError(t, c.Execute())
+
+		var results []*common.KeyValue
+		require.NoError(t, json.Unmarshal(w.Bytes, &results))
+		require.Len(t, results, 3)
+
+		require.Equal(t, common.Format("JSON"), results[0].Format)
+		require.Equal(t, `{"password":"secret"}`, results[0].Config)
+
+		require.Equal(t, common.FormatEncrypted, results[1].Format)
+		require.Equal(t, string(sealedForOther), results[1].Config)
+
+		require.Equal(t, common.Format("Other"), results[2].Format)
+		require.Equal(t, "plain", results[2].Config)
+
+		// A warning is displayed for the config that couldn't be decrypted
+		require.Contains(t, errOut.Written(), "Warning: unable to decrypt the config of "+kvs[1].Key.String())
+		require.NotContains(t, errOut.Written(), kvs[0].Key.String())
+	})
+
+	t.Run("Nothing decrypted", func(t *testing.T) {
+		corrupt := []byte(string(sealed[:len(sealed)-4]) + "AAAA")
+
+		for _, config := range [][]byte{sealedForOther, corrupt} {
+			payload, err := json.Marshal([]*common.KeyValue{{
+				Key:   &common.Key{MspID: "msp1", AppName: "app1", AppVersion: "v1"},
+				Value: &common.Value{TxID: "tx1", Format: common.FormatEncrypted, Config: string(config)},
+			}})
+			require.NoError(t, err)
+
+			ch.QueryReturns(channel.Response{Payload: payload}, nil)
+
+			errOut := &mocks.Writer{}
+			c := newMockCmdWithStreams(t, &mocks.Writer{}, errOut, p, "--mspid", "msp1", "--decrypt-key", keyFile)
+			err = c.Execute()
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "none of the encrypted config could be decrypted with the key in ["+keyFile+"]")
+			require.Contains(t, errOut.Written(), "Warning: unable to decrypt the config of")
+		}
+
+		ch.QueryReturns(channel.Response{Payload: kvsBytes}, nil)
+	})
+
+	t.Run("No config", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: []byte("null")}, nil)
+		defer ch.QueryReturns(channel.Response{Payload: kvsBytes}, nil)
+
+		w := &mocks.Writer{}
+		c := newMockCmd(t, w, p, "--mspid", "msp1", "--decrypt-key", keyFile, "--format")
+		require.NoError(t, c.Execute())
+		require.Equal(t, msgNoConfig, w.Written())
+	})
+
+	t.Run("Invalid key file", func(t *testing.T) {
+		c := newMockCmd(t, &mocks.Writer{}, p, "--mspid", "msp1", "--decrypt-key", "./notthere.key")
+		err := c.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "error loading private key")
+	})
+
+	t.Run("Invalid payload", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: []byte("invalid JSON")}, nil)
+		defer ch.QueryReturns(channel.Response{Payload: kvsBytes}, nil)
+
+		c := newMockCmd(t, &mocks.Writer{}, p, "--mspid", "msp1", "--decrypt-key", keyFile)
+		err := c.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid config returned from query")
+	})
+}
+
 func newMockCmd(t *testing.T, out io.Writer, p basecmd.FactoryProvider, args ...string) *cobra.Command {
+	return newMockCmdWithStreams(t, out, ioutil.Discard, p, args...)
+}
+
+func newMockCmdWithStreams(t *testing.T, out, errOut io.Writer, p basecmd.FactoryProvider, args ...string) *cobra.Command {
 	settings := environment.NewDefaultSettings()
 	settings.Streams.Out = out
+	settings.Streams.Err = errOut
 
 	settings.Config.CurrentContext = "testctx"
 	settings.Config.Contexts[settings.Config.CurrentContext] = &environment.Context{}
@@ -0,0 +1,279 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package signature
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/trustbloc/sidetree-core-go/pkg/jws"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/ecsigner"
+	"github.com/trustbloc/sidetree-core-go/pkg/util/edsigner"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/client"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/keyutil"
+)
+
+const (
+	// TagPrefix is the prefix of the reserved tag that holds the detached JWS of a config
+	TagPrefix = "jws="
+
+	// HeaderConfigKey is the protected header that holds the key under which the config is stored (see SignConfig)
+	HeaderConfigKey = "cfgkey"
+)
+
+var (
+	// ErrInvalidSignature indicates that the signature does not match the payload for the given key
+	ErrInvalidSignature = errors.New("invalid signature")
+
+	// ErrUnsupportedKey indicates that the key type is not supported
+	ErrUnsupportedKey = keyutil.ErrUnsupportedKey
+)
+
+// NewSigner returns a signer for the given private key. The JWS algorithm is deduced from the key type.
+func NewSigner(privateKey crypto.PrivateKey, kid string) (client.Signer, error) {
+	switch key := privateKey.(type) {
+	case *ecdsa.PrivateKey:
+		alg, err := keyutil.ECAlgorithm(key.Curve)
+		if err != nil {
+			return nil, err
+		}
+
+		return ecsigner.New(key, alg, kid), nil
+	case ed25519.PrivateKey:
+		return edsigner.New(key, keyutil.AlgEdDSA, kid), nil
+	case *rsa.PrivateKey:
+		return &rsaSigner{privateKey: key, kid: kid}, nil
+	default:
+		return nil, ErrUnsupportedKey
+	}
+}
+
+// Sign returns a detached JWS (in the form <protected header>..<signature>) over the given payload
+func Sign(payload []byte, signer client.Signer) (string, error) {
+	return sign(payload, signer, nil)
+}
+
+// SignConfig returns a detached JWS over the given config whose protected headers also contain the key under which
+// the config is stored (i.e. MspID!PeerID!AppName!AppVersion!ComponentName!ComponentVersion), so that a signed
+// config can't be copied to a different key without invalidating the signature (see ConfigKey)
+func SignConfig(configKey string, config []byte, signer client.Signer) (string, error) {
+	return sign(config, signer, jws.Headers{HeaderConfigKey: configKey})
+}
+
+// ConfigKey returns the config key from the given protected headers or false if the headers contain no config key
+func ConfigKey(headers jws.Headers) (string, bool) {
+	configKey, ok := headers[HeaderConfigKey].(string)
+
+	return configKey, ok
+}
+
+func sign(payload []byte, signer client.Signer, extraHeaders jws.Headers) (string, error) {
+	headers := make(jws.Headers)
+	for k, v := range signer.Headers() {
+		headers[k] = v
+	}
+
+	for k, v := range extraHeaders {
+		headers[k] = v
+	}
+
+	headerBytes, err := json.Marshal(headers)
+	if err != nil {
+		return "", err
+	}
+
+	header := base64.RawURLEncoding.EncodeToString(headerBytes)
+
+	sig, err := signer.Sign([]byte(signingInput(header, payload)))
+	if err != nil {
+		return "", errors.WithMessage(err, "error signing payload")
+	}
+
+	return header + ".." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// Verify verifies the given detached JWS over the payload using the given public key
+func Verify(detachedJWS string, payload []byte, publicKey crypto.PublicKey) error {
+	headers, sig, err := Parse(detachedJWS)
+	if err != nil {
+		return err
+	}
+
+	alg, ok := headers.Algorithm()
+	if !ok {
+		return errors.New("algorithm not found in JWS header")
+	}
+
+	header := detachedJWS[:strings.Index(detachedJWS, ".")]
+
+	return verify(alg, []byte(signingInput(header, payload)), sig, publicKey)
+}
+
+// Parse parses the given detached JWS and returns the protected headers and the signature
+func Parse(detachedJWS string) (jws.Headers, []byte, error) {
+	parts := strings.Split(detachedJWS, ".")
+	if len(parts) != 3 || parts[1] != "" {
+		return nil, nil, errors.New("invalid detached JWS")
+	}
+
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "invalid JWS header")
+	}
+
+	headers := make(jws.Headers)
+	if err := json.Unmarshal(headerBytes, &headers); err != nil {
+		return nil, nil, errors.WithMessage(err, "invalid JWS header")
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "invalid JWS signature")
+	}
+
+	return headers, sig, nil
+}
+
+// GetTag returns the detached JWS from the reserved tag or false if the tags contain no signature
+func GetTag(tags []string) (string, bool) {
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, TagPrefix) {
+			return tag[len(TagPrefix):], true
+		}
+	}
+
+	return "", false
+}
+
+// SetTag returns the tags with the reserved signature tag set to the given detached JWS.
+// Any existing signature tag is replaced.
+func SetTag(tags []string, detachedJWS string) []string {
+	return append(RemoveTag(tags), TagPrefix+detachedJWS)
+}
+
+// RemoveTag returns the tags without the reserved signature tag
+func RemoveTag(tags []string) []string {
+	var newTags []string
+	for _, tag := range tags {
+		if !strings.HasPrefix(tag, TagPrefix) {
+			newTags = append(newTags, tag)
+		}
+	}
+
+	return newTags
+}
+
+func signingInput(header string, payload []byte) string {
+	return header + "." + base64.RawURLEncoding.EncodeToString(payload)
+}
+
+func verify(alg string, input, sig []byte, publicKey crypto.PublicKey) error {
+	switch key := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		return verifyEC(alg, input, sig, key)
+	case ed25519.PublicKey:
+		if alg != keyutil.AlgEdDSA {
+			return errors.Errorf("algorithm [%s] does not match the key type", alg)
+		}
+
+		if !ed25519.Verify(key, input, sig) {
+			return ErrInvalidSignature
+		}
+
+		return nil
+	case *rsa.PublicKey:
+		if alg != keyutil.AlgRS256 {
+			return errors.Errorf("algorithm [%s] does not match the key type", alg)
+		}
+
+		digest := sha256Digest(input)
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest, sig); err != nil {
+			return ErrInvalidSignature
+		}
+
+		return nil
+	default:
+		return ErrUnsupportedKey
+	}
+}
+
+func verifyEC(alg string, input, sig []byte, key *ecdsa.PublicKey) error {
+	expectedAlg, err := keyutil.ECAlgorithm(key.Curve)
+	if err != nil {
+		return err
+	}
+
+	if alg != expectedAlg {
+		return errors.Errorf("algorithm [%s] does not match the key type", alg)
+	}
+
+	keySize := (key.Curve.Params().BitSize + 7) / 8
+	if len(sig) != 2*keySize {
+		return ErrInvalidSignature
+	}
+
+	r := new(big.Int).SetBytes(sig[:keySize])
+	s := new(big.Int).SetBytes(sig[keySize:])
+
+	if !ecdsa.Verify(key, ecDigest(key.Curve, input), r, s) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// ecDigest returns the digest of the input using the hash algorithm that corresponds to the curve
+func ecDigest(curve elliptic.Curve, input []byte) []byte {
+	switch curve {
+	case elliptic.P384():
+		digest := sha512.Sum384(input)
+		return digest[:]
+	case elliptic.P521():
+		digest := sha512.Sum512(input)
+		return digest[:]
+	default:
+		return sha256Digest(input)
+	}
+}
+
+func sha256Digest(input []byte) []byte {
+	digest := sha256.Sum256(input)
+	return digest[:]
+}
+
+// rsaSigner signs using RSASSA-PKCS1-v1_5 with SHA-256
+type rsaSigner struct {
+	privateKey *rsa.PrivateKey
+	kid        string
+}
+
+// Headers returns the JWS protected headers
+func (s *rsaSigner) Headers() jws.Headers {
+	headers := jws.Headers{jws.HeaderAlgorithm: keyutil.AlgRS256}
+	if s.kid != "" {
+		headers[jws.HeaderKeyID] = s.kid
+	}
+
+	return headers
+}
+
+// Sign signs the given message
+func (s *rsaSigner) Sign(msg []byte) ([]byte, error) {
+	return rsa.SignPKCS1v15(rand.Reader, s.privateKey, crypto.SHA256, sha256Digest(msg))
+}
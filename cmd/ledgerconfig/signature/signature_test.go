@@ -0,0 +1,150 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package signature
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/keyutil"
+)
+
+const payload = `{"Key1":"value1"}`
+
+func TestSignVerify(t *testing.T) {
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	secp256k1Key, err := ecdsa.GenerateKey(btcec.S256(), rand.Reader)
+	require.NoError(t, err)
+
+	edPublicKey, edPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		privateKey crypto.PrivateKey
+		publicKey  crypto.PublicKey
+		alg        string
+	}{
+		{name: "P-256", privateKey: p256Key, publicKey: &p256Key.PublicKey, alg: keyutil.AlgES256},
+		{name: "P-384", privateKey: p384Key, publicKey: &p384Key.PublicKey, alg: keyutil.AlgES384},
+		{name: "secp256k1", privateKey: secp256k1Key, publicKey: &secp256k1Key.PublicKey, alg: keyutil.AlgES256K},
+		{name: "Ed25519", privateKey: edPrivateKey, publicKey: edPublicKey, alg: keyutil.AlgEdDSA},
+		{name: "RSA", privateKey: rsaKey, publicKey: &rsaKey.PublicKey, alg: keyutil.AlgRS256},
+	}
+
+	for _, test := range tests {
+		tc := test
+		t.Run(tc.name, func(t *testing.T) {
+			signer, err := NewSigner(tc.privateKey, "key1")
+			require.NoError(t, err)
+
+			jws, err := Sign([]byte(payload), signer)
+			require.NoError(t, err)
+			require.Contains(t, jws, "..")
+
+			headers, _, err := Parse(jws)
+			require.NoError(t, err)
+
+			alg, ok := headers.Algorithm()
+			require.True(t, ok)
+			require.Equal(t, tc.alg, alg)
+
+			kid, ok := headers.KeyID()
+			require.True(t, ok)
+			require.Equal(t, "key1", kid)
+
+			require.NoError(t, Verify(jws, []byte(payload), tc.publicKey))
+			require.Error(t, Verify(jws, []byte("tampered"), tc.publicKey))
+		})
+	}
+
+	t.Run("Wrong key", func(t *testing.T) {
+		signer, err := NewSigner(p256Key, "")
+		require.NoError(t, err)
+
+		jws, err := Sign([]byte(payload), signer)
+		require.NoError(t, err)
+
+		otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		require.Equal(t, ErrInvalidSignature, Verify(jws, []byte(payload), &otherKey.PublicKey))
+		require.EqualError(t, Verify(jws, []byte(payload), &p384Key.PublicKey), "algorithm [ES256] does not match the key type")
+		require.EqualError(t, Verify(jws, []byte(payload), edPublicKey), "algorithm [ES256] does not match the key type")
+		require.EqualError(t, Verify(jws, []byte(payload), &rsaKey.PublicKey), "algorithm [ES256] does not match the key type")
+	})
+
+	t.Run("Config key", func(t *testing.T) {
+		signer, err := NewSigner(p256Key, "kid1")
+		require.NoError(t, err)
+
+		jws, err := SignConfig("msp1!peer1!app1!v1!comp1!v2", []byte(payload), signer)
+		require.NoError(t, err)
+		require.NoError(t, Verify(jws, []byte(payload), &p256Key.PublicKey))
+
+		headers, _, err := Parse(jws)
+		require.NoError(t, err)
+
+		configKey, ok := ConfigKey(headers)
+		require.True(t, ok)
+		require.Equal(t, "msp1!peer1!app1!v1!comp1!v2", configKey)
+
+		kid, _ := headers.KeyID()
+		require.Equal(t, "kid1", kid)
+
+		jws, err = Sign([]byte(payload), signer)
+		require.NoError(t, err)
+
+		headers, _, err = Parse(jws)
+		require.NoError(t, err)
+
+		_, ok = ConfigKey(headers)
+		require.False(t, ok)
+	})
+
+	t.Run("Unsupported key", func(t *testing.T) {
+		_, err := NewSigner("invalid", "")
+		require.Equal(t, ErrUnsupportedKey, err)
+	})
+
+	t.Run("Invalid JWS", func(t *testing.T) {
+		require.EqualError(t, Verify("invalid", []byte(payload), &p256Key.PublicKey), "invalid detached JWS")
+		require.Error(t, Verify("!!..abc", []byte(payload), &p256Key.PublicKey))
+		require.Error(t, Verify("e30..!!", []byte(payload), &p256Key.PublicKey))
+		require.EqualError(t, Verify("e30..abc", []byte(payload), &p256Key.PublicKey), "algorithm not found in JWS header")
+	})
+}
+
+func TestTags(t *testing.T) {
+	_, ok := GetTag([]string{"tag1"})
+	require.False(t, ok)
+
+	tags := SetTag([]string{"tag1", TagPrefix + "old"}, "new")
+	require.Equal(t, []string{"tag1", TagPrefix + "new"}, tags)
+
+	jws, ok := GetTag(tags)
+	require.True(t, ok)
+	require.Equal(t, "new", jws)
+
+	require.Equal(t, []string{"tag1"}, RemoveTag(tags))
+}
@@ -0,0 +1,97 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package updatecmd
+
+import (
+	"crypto"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/common"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/envelope"
+)
+
+// configEncryptor replaces the Config of the selected apps and components with an
+// encrypted envelope and sets the format to ENCRYPTED
+type configEncryptor struct {
+	recipient crypto.PublicKey
+	selected  map[string]struct{}
+}
+
+// newConfigEncryptor returns a new config encryptor. The selection is a list of app names
+// and/or app:component names. If the selection is empty then all config is encrypted.
+func newConfigEncryptor(recipient crypto.PublicKey, selection []string) *configEncryptor {
+	selected := make(map[string]struct{})
+	for _, s := range selection {
+		selected[s] = struct{}{}
+	}
+
+	return &configEncryptor{
+		recipient: recipient,
+		selected:  selected,
+	}
+}
+
+func (ce *configEncryptor) encrypt(cfg *common.Config) error {
+	for _, p := range cfg.Peers {
+		if err := ce.visitApps(p.Apps); err != nil {
+			return err
+		}
+	}
+
+	return ce.visitApps(cfg.Apps)
+}
+
+func (ce *configEncryptor) visitApps(apps []*common.App) error {
+	for _, a := range apps {
+		if a.Config != "" && ce.isSelected(a.AppName) {
+			if err := ce.seal(&a.Config, &a.Format); err != nil {
+				return errors.WithMessagef(err, "error encrypting config of app [%s]", a.AppName)
+			}
+		}
+
+		for _, c := range a.Components {
+			if !ce.isSelected(a.AppName) && !ce.isSelected(a.AppName+":"+c.Name) {
+				continue
+			}
+
+			if err := ce.seal(&c.Config, &c.Format); err != nil {
+				return errors.WithMessagef(err, "error encrypting config of component [%s:%s]", a.AppName, c.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (ce *configEncryptor) seal(config *string, format *common.Format) error {
+	if *format == common.FormatEncrypted {
+		// Already encrypted
+		return nil
+	}
+
+	sealed, err := envelope.Seal([]byte(*config), string(*format), ce.recipient)
+	if err != nil {
+		return err
+	}
+
+	*config = string(sealed)
+	*format = common.FormatEncrypted
+
+	return nil
+}
+
+func (ce *configEncryptor) isSelected(name string) bool {
+	if len(ce.selected) == 0 {
+		return true
+	}
+
+	_, ok := ce.selected[strings.TrimSpace(name)]
+
+	return ok
+}
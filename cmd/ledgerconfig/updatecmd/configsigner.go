@@ -0,0 +1,74 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package updatecmd
+
+import (
+	"github.com/pkg/errors"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/client"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/common"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/signature"
+)
+
+// configSigner attaches a detached JWS over the Config of each app and component. The signature
+// also covers the key under which the config is stored and is stored in a reserved tag.
+type configSigner struct {
+	signer client.Signer
+}
+
+func newConfigSigner(signer client.Signer) *configSigner {
+	return &configSigner{signer: signer}
+}
+
+func (cs *configSigner) sign(cfg *common.Config) error {
+	for _, p := range cfg.Peers {
+		if err := cs.visitApps(cfg.MspID, p.PeerID, p.Apps); err != nil {
+			return err
+		}
+	}
+
+	return cs.visitApps(cfg.MspID, "", cfg.Apps)
+}
+
+func (cs *configSigner) visitApps(mspID, peerID string, apps []*common.App) error {
+	for _, a := range apps {
+		key := &common.Key{MspID: mspID, PeerID: peerID, AppName: a.AppName, AppVersion: a.Version}
+
+		if a.Config != "" {
+			tags, err := cs.signConfig(key, a.Config, a.Tags)
+			if err != nil {
+				return errors.WithMessagef(err, "error signing config of app [%s]", a.AppName)
+			}
+
+			a.Tags = tags
+		}
+
+		for _, c := range a.Components {
+			compKey := *key
+			compKey.ComponentName = c.Name
+			compKey.ComponentVersion = c.Version
+
+			tags, err := cs.signConfig(&compKey, c.Config, c.Tags)
+			if err != nil {
+				return errors.WithMessagef(err, "error signing config of component [%s:%s]", a.AppName, c.Name)
+			}
+
+			c.Tags = tags
+		}
+	}
+
+	return nil
+}
+
+func (cs *configSigner) signConfig(key *common.Key, config string, tags []string) ([]string, error) {
+	jws, err := signature.SignConfig(key.Marshal(), []byte(config), cs.signer)
+	if err != nil {
+		return nil, err
+	}
+
+	return signature.SetTag(tags, jws), nil
+}
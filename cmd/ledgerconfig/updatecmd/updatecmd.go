@@ -7,12 +7,14 @@ SPDX-License-Identifier: Apache-2.0
 package updatecmd
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/hyperledger/fabric-cli/pkg/environment"
 	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
@@ -21,7 +23,9 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/trustbloc/fabric-cli-ext/cmd/basecmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/keyutil"
 	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/common"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/signature"
 )
 
 const (
@@ -32,7 +36,28 @@ The update command allows a client to add/update the configuration of one or mor
 Configuration can be specified directly on the command-line as a JSON string using the --config option,
 or the path of a configuration file may be specified using the --configfile option. The configuration
 string may be embedded directly in the "Config" element or the Config element may reference a file
-containing the configuration.
+containing the configuration. A JSON array of configurations (for example, one for each MSP) may also
+be specified, in which case each configuration is sent in a separate transaction.
+
+By default the update is sent to the channel of the current context. The --contexts and --channels options
+allow the same update to be sent to multiple contexts (e.g. one for each organization) and/or channels. Every
+configuration is sent to every context/channel combination, regardless of its MspID. For example, given the
+configs of Org1MSP and Org2MSP and --contexts org1-context,org2-context, the Org1MSP config is also sent through
+org2-context. Run the command once per context to restrict a config to the context of its own organization. The
+updates are sent in parallel (bounded by --concurrency) and a success/failure report is displayed for each
+target.
+
+The Config of selected apps and/or components may be encrypted before it is saved by specifying the certificate
+or public key of the recipient using the --encrypt-for option. The --encrypt option selects which apps (app1) or
+components (app1:comp1) are encrypted; if not specified then all config is encrypted. Encrypted config is stored
+with Format "ENCRYPTED" as a compact JWE (ECDH-ES for EC keys or RSA-OAEP-256 for RSA keys, with A256GCM) and may be
+decrypted using the --decrypt-key option of the query command or any JWE implementation.
+
+The Config of each app and component may be signed by specifying a private key using the --sign-key option. A
+detached JWS over the stored config (i.e. after encryption) is added to the tags of the app/component as the
+reserved tag "jws=<JWS>". The protected "cfgkey" header of the JWS contains the key under which the config is stored
+(MspID!PeerID!AppName!AppVersion!ComponentName!ComponentVersion) so that the signature is only valid for that key.
+The signatures may be checked using the verify command.
 
 The format of the configuration for config with peer is as follows:
 
@@ -119,6 +144,15 @@ The format of the configuration for peer-less config is displayed below:
 
 - Send an update using a peer-less configuration string specified in the command-line:
     $ ./fabric ledgerconfig update --config '{"MspID":"general", "Apps": [{"AppName": "publickey", "Version": "v1", "Components": [{"Name":"comp1","Format":"Other","Config":"config1"}] }]}'
+
+- Send the configuration of two MSPs to two contexts and two channels:
+    $ ./fabric ledgerconfig update --config '[{"MspID":"Org1MSP","Apps":[{"AppName":"app1","Version":"v1","Format":"Other","Config":"config1"}]},{"MspID":"Org2MSP","Apps":[{"AppName":"app1","Version":"v1","Format":"Other","Config":"config2"}]}]' --contexts org1-context,org2-context --channels channel1,channel2
+
+- Send the update, encrypting the config of app1 and of component comp1 of app2 for the holder of the given certificate:
+    $ ./fabric ledgerconfig update --configfile ./sampleconfig/org1-config.json --encrypt-for ./admin-cert.pem --encrypt app1,app2:comp1
+
+- Send the update, signing the config of each app and component:
+    $ ./fabric ledgerconfig update --configfile ./sampleconfig/org1-config.json --sign-key ./admin-key.pem --sign-kid org1-admin
 `
 )
 
@@ -129,12 +163,37 @@ const (
 	configFileFlag  = "configfile"
 	configFileUsage = `The path to the config file. Example: --configfile "./configs/msp1_config.json"`
 
+	contextsFlag  = "contexts"
+	contextsUsage = "A comma-separated list of fabric-cli contexts to which the update is sent. Each config is sent through every context, regardless of its MspID. If not specified then the current context is used. Example: --contexts org1-context,org2-context"
+
+	channelsFlag  = "channels"
+	channelsUsage = "A comma-separated list of channels to which the update is sent. If not specified then the channel of each context is used. Example: --channels channel1,channel2"
+
+	concurrencyFlag  = "concurrency"
+	concurrencyUsage = "The maximum number of updates that are sent in parallel. Example: --concurrency 4"
+
+	defaultConcurrency = 4
+
+	encryptForFlag  = "encrypt-for"
+	encryptForUsage = "The path to a PEM file containing the certificate or public key (EC or RSA) of the recipient for which the config is encrypted. Example: --encrypt-for ./keys/admin-cert.pem"
+
+	encryptFlag  = "encrypt"
+	encryptUsage = "A comma-separated list of apps and/or app:component names whose config is to be encrypted. If not specified then all config is encrypted. Requires --encrypt-for. Example: --encrypt app1,app2:comp1"
+
+	signKeyFlag  = "sign-key"
+	signKeyUsage = "The path to a PEM file containing the private key (EC, Ed25519 or RSA) used to sign the config. A detached JWS over each app and component config is stored in the reserved tag 'jws='. Example: --sign-key ./keys/admin-key.pem"
+
+	signKIDFlag  = "sign-kid"
+	signKIDUsage = "The (optional) key ID that is included in the header of each JWS. Requires --sign-key. Example: --sign-kid org1-admin"
+
 	noPromptFlag  = "noprompt"
 	noPromptUsage = "If specified then update operation will not prompt for confirmation. Example: --noprompt"
 
 	errConfigOrConfigFileRequired = "one of --config or --configfile must be specified"
 	errInvalidJSONConfig          = "invalid JSON config"
 	errFileNotFound               = "file not found"
+	errEncryptForRequired         = "--encrypt-for must be specified when --encrypt is specified"
+	errSignKeyRequired            = "--sign-key must be specified when --sign-kid is specified"
 
 	msgConfigUpdated   = "Configuration successfully updated!"
 	msgAborted         = "Operation aborted"
@@ -172,6 +231,13 @@ func newCmd(settings *environment.Settings, p basecmd.FactoryProvider) *cobra.Co
 
 	cmd.Flags().StringVar(&c.config, configFlag, "", configUsage)
 	cmd.Flags().StringVar(&c.configFile, configFileFlag, "", configFileUsage)
+	cmd.Flags().StringVar(&c.contexts, contextsFlag, "", contextsUsage)
+	cmd.Flags().StringVar(&c.channels, channelsFlag, "", channelsUsage)
+	cmd.Flags().IntVar(&c.concurrency, concurrencyFlag, defaultConcurrency, concurrencyUsage)
+	cmd.Flags().StringVar(&c.encryptFor, encryptForFlag, "", encryptForUsage)
+	cmd.Flags().StringVar(&c.encrypt, encryptFlag, "", encryptUsage)
+	cmd.Flags().StringVar(&c.signKey, signKeyFlag, "", signKeyUsage)
+	cmd.Flags().StringVar(&c.signKID, signKIDFlag, "", signKIDUsage)
 	cmd.Flags().BoolVar(&c.noPrompt, noPromptFlag, false, noPromptUsage)
 
 	return cmd
@@ -182,47 +248,68 @@ type command struct {
 	*basecmd.Command
 
 	// Flags
-	config     string
-	configFile string
-	noPrompt   bool
+	config      string
+	configFile  string
+	contexts    string
+	channels    string
+	concurrency int
+	encryptFor  string
+	encrypt     string
+	signKey     string
+	signKID     string
+	noPrompt    bool
 }
 
 func (c *command) validate() error {
 	if (c.config == "" && c.configFile == "") || (c.config != "" && c.configFile != "") {
 		return errors.New(errConfigOrConfigFileRequired)
 	}
+	if err := c.validateEncryption(); err != nil {
+		return err
+	}
+	if err := c.validateSigning(); err != nil {
+		return err
+	}
 	if c.config != "" {
 		return validateConfig(c.config)
 	}
 	return validateConfigFile(c.configFile)
 }
 
-func (c *command) run() error {
-	configBytes, err := c.getConfigBytes()
-	if err != nil {
-		return err
+func (c *command) validateEncryption() error {
+	if c.encryptFor == "" {
+		if c.encrypt != "" {
+			return errors.New(errEncryptForRequired)
+		}
+		return nil
 	}
+	return validateConfigFile(c.encryptFor)
+}
 
-	cfg := &common.Config{}
-	err = json.Unmarshal(configBytes, cfg)
-	if err != nil {
-		return err
+func (c *command) validateSigning() error {
+	if c.signKey == "" {
+		if c.signKID != "" {
+			return errors.New(errSignKeyRequired)
+		}
+		return nil
 	}
+	return validateConfigFile(c.signKey)
+}
 
-	// Replace all of the file references with actual config
-	newCfg, err := newConfigPreProcessor(c.configFile).preProcess(cfg)
+func (c *command) run() error {
+	configs, err := c.getConfigs()
 	if err != nil {
 		return err
 	}
 
-	configBytes, err = json.Marshal(newCfg)
+	targets, err := c.getTargets()
 	if err != nil {
 		return err
 	}
 
 	// Get confirmation from the user
 	if !c.noPrompt {
-		confirmed, e := c.confirmUpdate(configBytes)
+		confirmed, e := c.confirmUpdate(configs, targets)
 		if e != nil {
 			return e
 		}
@@ -231,20 +318,218 @@ func (c *command) run() error {
 		}
 	}
 
+	return c.report(c.submit(configs, targets))
+}
+
+// getConfigs loads the configuration(s) and replaces all of the file references with actual config
+func (c *command) getConfigs() ([]*configUpdate, error) {
+	configBytes, err := c.getConfigBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	cfgs, err := unmarshalConfigs(configBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	preProcessor := newConfigPreProcessor(c.configFile)
+
+	transformers, err := c.getTransformers()
+	if err != nil {
+		return nil, err
+	}
+
+	configs := make([]*configUpdate, len(cfgs))
+	for i, cfg := range cfgs {
+		newCfg, e := preProcessor.preProcess(cfg)
+		if e != nil {
+			return nil, e
+		}
+
+		for _, transform := range transformers {
+			if e := transform(newCfg); e != nil {
+				return nil, e
+			}
+		}
+
+		cfgBytes, e := json.Marshal(newCfg)
+		if e != nil {
+			return nil, e
+		}
+
+		configs[i] = &configUpdate{mspID: newCfg.MspID, bytes: cfgBytes}
+	}
+
+	return configs, nil
+}
+
+// getTransformers returns the functions that are applied (in order) to each config after
+// the file references have been resolved
+func (c *command) getTransformers() ([]func(cfg *common.Config) error, error) {
+	var transformers []func(cfg *common.Config) error
+
+	encryptor, err := c.getEncryptor()
+	if err != nil {
+		return nil, err
+	}
+
+	if encryptor != nil {
+		transformers = append(transformers, encryptor.encrypt)
+	}
+
+	signer, err := c.getSigner()
+	if err != nil {
+		return nil, err
+	}
+
+	// The signature is computed over the stored (possibly encrypted) config
+	if signer != nil {
+		transformers = append(transformers, signer.sign)
+	}
+
+	return transformers, nil
+}
+
+// getEncryptor returns the config encryptor or nil if encryption was not requested
+func (c *command) getEncryptor() (*configEncryptor, error) {
+	if c.encryptFor == "" {
+		return nil, nil
+	}
+
+	recipient, err := keyutil.PublicKeyFromFile(c.encryptFor)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "error loading recipient key from [%s]", c.encryptFor)
+	}
+
+	return newConfigEncryptor(recipient, splitList(c.encrypt)), nil
+}
+
+// getSigner returns the config signer or nil if signing was not requested
+func (c *command) getSigner() (*configSigner, error) {
+	if c.signKey == "" {
+		return nil, nil
+	}
+
+	privateKey, err := keyutil.PrivateKeyFromFile(c.signKey)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "error loading signing key from [%s]", c.signKey)
+	}
+
+	signer, err := signature.NewSigner(privateKey, c.signKID)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "invalid signing key [%s]", c.signKey)
+	}
+
+	return newConfigSigner(signer), nil
+}
+
+// getTargets returns the context/channel combinations to which the configuration is to be sent
+func (c *command) getTargets() ([]*target, error) {
+	contexts := splitList(c.contexts)
+	if len(contexts) == 0 {
+		contexts = []string{c.Settings.Config.CurrentContext}
+	}
+
+	var targets []*target
+	for _, contextName := range contexts {
+		context, ok := c.Settings.Config.Contexts[contextName]
+		if !ok {
+			return nil, errors.Errorf("context [%s] not found", contextName)
+		}
+
+		channels := splitList(c.channels)
+		if len(channels) == 0 {
+			channels = []string{context.Channel}
+		}
+
+		for _, channelID := range channels {
+			targets = append(targets, &target{context: contextName, channel: channelID})
+		}
+	}
+
+	return targets, nil
+}
+
+// submit sends each of the configs to each of the targets, i.e. a config is also sent through the contexts
+// of other MSPs. Updates are sent in parallel
+// with at most --concurrency updates outstanding at any given time.
+func (c *command) submit(configs []*configUpdate, targets []*target) []*result {
+	var results []*result
+	for _, t := range targets {
+		for _, cfg := range configs {
+			results = append(results, &result{target: t, config: cfg})
+		}
+	}
+
+	concurrency := c.concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	semaphore := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for _, r := range results {
+		wg.Add(1)
+		semaphore <- struct{}{}
+
+		go func(r *result) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+
+			r.err = c.save(r.target, r.config.bytes)
+		}(r)
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+func (c *command) save(t *target, config []byte) error {
 	req := channel.Request{
 		ChaincodeID: common.ConfigSCC,
 		Fcn:         "save",
-		Args:        [][]byte{configBytes},
+		Args:        [][]byte{config},
 	}
 
-	ch, err := c.Channel()
+	ch, err := c.ChannelForTarget(t.context, t.channel)
 	if err != nil {
 		return err
 	}
 
 	_, err = ch.Execute(req, channel.WithRetry(retry.DefaultChannelOpts))
-	if err != nil {
-		return err
+
+	return err
+}
+
+// report displays the outcome of each update. If only a single update was sent then the
+// error (if any) is returned as is.
+func (c *command) report(results []*result) error {
+	if len(results) == 1 {
+		if results[0].err != nil {
+			return results[0].err
+		}
+
+		return c.Fprintln(msgConfigUpdated)
+	}
+
+	numFailed := 0
+	for _, r := range results {
+		if r.err != nil {
+			numFailed++
+		}
+
+		if err := c.Fprintln(r.String()); err != nil {
+			return err
+		}
+	}
+
+	if numFailed > 0 {
+		return errors.Errorf("%d of %d configuration updates failed", numFailed, len(results))
 	}
 
 	return c.Fprintln(msgConfigUpdated)
@@ -258,12 +543,29 @@ func (c *command) getConfigBytes() ([]byte, error) {
 }
 
 // confirmUpdate prompts the user for confirmation of the update
-func (c *command) confirmUpdate(config []byte) (bool, error) {
+func (c *command) confirmUpdate(configs []*configUpdate, targets []*target) (bool, error) {
+	config := configs[0].bytes
+	if len(configs) > 1 {
+		cfgBytes := make([][]byte, len(configs))
+		for i, cfg := range configs {
+			cfgBytes[i] = cfg.bytes
+		}
+
+		config = []byte(fmt.Sprintf("[%s]", bytes.Join(cfgBytes, []byte(","))))
+	}
+
 	displayedJSON, err := common.FormatJSON(config)
 	if err != nil {
 		return false, err
 	}
-	prompt := fmt.Sprintf("Updating the configuration with:\n\n%s\n\n%s", displayedJSON, msgContinueOrAbort)
+
+	targetStrs := make([]string, len(targets))
+	for i, t := range targets {
+		targetStrs[i] = t.String()
+	}
+
+	prompt := fmt.Sprintf("Updating the configuration with:\n\n%s\n\nTargets:\n  %s\n\n%s",
+		displayedJSON, strings.Join(targetStrs, "\n  "), msgContinueOrAbort)
 	err = c.Fprintln(prompt)
 	if err != nil {
 		return false, err
@@ -272,13 +574,77 @@ func (c *command) confirmUpdate(config []byte) (bool, error) {
 }
 
 func validateConfig(cfg string) error {
-	config := &common.Config{}
-	if err := json.Unmarshal([]byte(cfg), config); err != nil {
+	if _, err := unmarshalConfigs([]byte(cfg)); err != nil {
 		return errors.WithMessagef(err, errInvalidJSONConfig)
 	}
 	return nil
 }
 
+// unmarshalConfigs unmarshals either a single config or an array of configs
+func unmarshalConfigs(configBytes []byte) ([]*common.Config, error) {
+	trimmed := bytes.TrimSpace(configBytes)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var configs []*common.Config
+		if err := json.Unmarshal(trimmed, &configs); err != nil {
+			return nil, err
+		}
+		if len(configs) == 0 {
+			return nil, errors.New("no configuration specified")
+		}
+		return configs, nil
+	}
+
+	config := &common.Config{}
+	if err := json.Unmarshal(trimmed, config); err != nil {
+		return nil, err
+	}
+
+	return []*common.Config{config}, nil
+}
+
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// target is the context and channel to which an update is sent
+type target struct {
+	context string
+	channel string
+}
+
+// String returns a readable string for the target
+func (t *target) String() string {
+	return fmt.Sprintf("%s:%s", t.context, t.channel)
+}
+
+// configUpdate contains the marshalled config for a given MSP
+type configUpdate struct {
+	mspID string
+	bytes []byte
+}
+
+// result contains the outcome of sending a config to a target
+type result struct {
+	target *target
+	config *configUpdate
+	err    error
+}
+
+// String returns a readable string for the result
+func (r *result) String() string {
+	if r.err != nil {
+		return fmt.Sprintf("[%s] %s: FAILED - %s", r.target, r.config.mspID, r.err)
+	}
+
+	return fmt.Sprintf("[%s] %s: SUCCESS", r.target, r.config.mspID)
+}
+
 func validateConfigFile(file string) error {
 	_, err := os.Stat(file)
 	if os.IsNotExist(err) {
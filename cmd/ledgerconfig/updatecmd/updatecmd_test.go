@@ -7,8 +7,10 @@ SPDX-License-Identifier: Apache-2.0
 package updatecmd
 
 import (
+	"encoding/json"
 	"errors"
 	"io"
+	"sync"
 	"testing"
 
 	"github.com/hyperledger/fabric-cli/pkg/environment"
@@ -17,6 +19,10 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
 	"github.com/trustbloc/fabric-cli-ext/cmd/basecmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/keyutil"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/common"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/envelope"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/signature"
 	"github.com/trustbloc/fabric-cli-ext/cmd/mocks"
 )
 
@@ -124,6 +130,266 @@ func TestUpdateCmd(t *testing.T) {
 	})
 }
 
+func TestUpdateCmd_MultipleTargets(t *testing.T) {
+	const configs = `[{"MspID":"Org1MSP"},{"MspID":"Org2MSP"}]`
+
+	t.Run("Invalid config array", func(t *testing.T) {
+		err := newMockCmd(t, nil, "--config", "[]").Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errInvalidJSONConfig)
+	})
+
+	t.Run("Context not found", func(t *testing.T) {
+		err := newMockCmd(t, nil, "--config", configs, "--contexts", "ctx1,xxx", "--noprompt").Execute()
+		require.EqualError(t, err, "context [xxx] not found")
+	})
+
+	t.Run("Multiple configs, contexts and channels", func(t *testing.T) {
+		factory := &mocks.Factory{}
+		ch := &mocks.Channel{}
+		factory.ChannelReturns(ch, nil)
+
+		var mutex sync.Mutex
+		targets := make(map[string]int)
+
+		p := func(config *environment.Config) (fabric.Factory, error) {
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			targets[config.CurrentContext+":"+config.Contexts[config.CurrentContext].Channel]++
+
+			return factory, nil
+		}
+
+		w := &mocks.Writer{}
+		c := newMockCmdWithReaderWriter(t, &mocks.Reader{Bytes: []byte("Y\n")}, w, p, "--config", configs,
+			"--contexts", "ctx1, ctx2", "--channels", "channel1,channel2", "--concurrency", "2")
+		require.NoError(t, c.Execute())
+		require.Equal(t, 8, ch.ExecuteCallCount())
+		require.Len(t, targets, 4)
+		require.Equal(t, 2, targets["ctx1:channel1"])
+		require.Equal(t, 2, targets["ctx2:channel2"])
+		require.Contains(t, w.Written(), "ctx2:channel1")
+		require.Contains(t, w.Written(), "[ctx1:channel2] Org2MSP: SUCCESS")
+		require.Contains(t, w.Written(), msgConfigUpdated)
+	})
+
+	t.Run("Every config is sent through every context", func(t *testing.T) {
+		channels := map[string]*mocks.Channel{"ctx1": {}, "ctx2": {}}
+
+		p := func(config *environment.Config) (fabric.Factory, error) {
+			factory := &mocks.Factory{}
+			factory.ChannelReturns(channels[config.CurrentContext], nil)
+
+			return factory, nil
+		}
+
+		c := newMockCmd(t, p, "--config", configs, "--contexts", "ctx1,ctx2", "--concurrency", "1", "--noprompt")
+		require.NoError(t, c.Execute())
+
+		for contextName, ch := range channels {
+			require.Equal(t, 2, ch.ExecuteCallCount())
+
+			var mspIDs []string
+			for i := 0; i < ch.ExecuteCallCount(); i++ {
+				req, _ := ch.ExecuteArgsForCall(i)
+
+				cfg := &common.Config{}
+				require.NoError(t, json.Unmarshal(req.Args[0], cfg))
+
+				mspIDs = append(mspIDs, cfg.MspID)
+			}
+
+			require.ElementsMatchf(t, []string{"Org1MSP", "Org2MSP"}, mspIDs, "context [%s]", contextName)
+		}
+	})
+
+	t.Run("Partial failure", func(t *testing.T) {
+		errExpected := errors.New("channel execute error")
+
+		ch1 := &mocks.Channel{}
+		factory1 := &mocks.Factory{}
+		factory1.ChannelReturns(ch1, nil)
+
+		ch2 := &mocks.Channel{}
+		ch2.ExecuteReturns(channel.Response{}, errExpected)
+		factory2 := &mocks.Factory{}
+		factory2.ChannelReturns(ch2, nil)
+
+		p := func(config *environment.Config) (fabric.Factory, error) {
+			if config.CurrentContext == "ctx2" {
+				return factory2, nil
+			}
+			return factory1, nil
+		}
+
+		w := &mocks.Writer{}
+		c := newMockCmdWithReaderWriter(t, &mocks.Reader{}, w, p, "--config", `{"MspID":"Org1MSP"}`, "--contexts", "ctx1,ctx2", "--noprompt")
+		err := c.Execute()
+		require.EqualError(t, err, "1 of 2 configuration updates failed")
+		require.Contains(t, w.Written(), "[ctx1:channel1] Org1MSP: SUCCESS")
+		require.Contains(t, w.Written(), "[ctx2:channel2] Org1MSP: FAILED - "+errExpected.Error())
+		require.NotContains(t, w.Written(), msgConfigUpdated)
+	})
+}
+
+func TestUpdateCmd_Encrypt(t *testing.T) {
+	const (
+		certFile = "../testdata/ec_cert.pem"
+		keyFile  = "../testdata/ec_private.key"
+		cfg      = `{"MspID":"msp1","Apps":[{"AppName":"app1","Version":"v1","Format":"JSON","Config":"{\"password\":\"secret1\"}"},{"AppName":"app2","Version":"v1","Components":[{"Name":"comp1","Version":"v1","Format":"Other","Config":"secret2"},{"Name":"comp2","Version":"v1","Format":"Other","Config":"public"}]}]}`
+	)
+
+	t.Run("--encrypt without --encrypt-for", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, "--config", cfg, "--encrypt", "app1").Execute(), errEncryptForRequired)
+	})
+
+	t.Run("--encrypt-for file not found", func(t *testing.T) {
+		err := newMockCmd(t, nil, "--config", cfg, "--encrypt-for", "./notthere.pem").Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errFileNotFound)
+	})
+
+	t.Run("Invalid --encrypt-for key", func(t *testing.T) {
+		err := newMockCmd(t, nil, "--config", cfg, "--encrypt-for", "./updatecmd.go", "--noprompt").Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "error loading recipient key")
+	})
+
+	t.Run("Selected apps and components", func(t *testing.T) {
+		factory := &mocks.Factory{}
+		ch := &mocks.Channel{}
+		factory.ChannelReturns(ch, nil)
+
+		p := func(config *environment.Config) (fabric.Factory, error) { return factory, nil }
+
+		c := newMockCmd(t, p, "--config", cfg, "--encrypt-for", certFile, "--encrypt", "app1,app2:comp1", "--noprompt")
+		require.NoError(t, c.Execute())
+		require.Equal(t, 1, ch.ExecuteCallCount())
+
+		req, _ := ch.ExecuteArgsForCall(0)
+
+		saved := &common.Config{}
+		require.NoError(t, json.Unmarshal(req.Args[0], saved))
+		require.NotContains(t, string(req.Args[0]), "secret")
+
+		key, err := keyutil.PrivateKeyFromFile(keyFile)
+		require.NoError(t, err)
+
+		app1 := saved.Apps[0]
+		require.Equal(t, common.FormatEncrypted, app1.Format)
+
+		content, format, err := envelope.Open([]byte(app1.Config), key)
+		require.NoError(t, err)
+		require.Equal(t, `{"password":"secret1"}`, string(content))
+		require.Equal(t, "JSON", format)
+
+		comp1 := saved.Apps[1].Components[0]
+		require.Equal(t, common.FormatEncrypted, comp1.Format)
+
+		content, format, err = envelope.Open([]byte(comp1.Config), key)
+		require.NoError(t, err)
+		require.Equal(t, "secret2", string(content))
+		require.Equal(t, "Other", format)
+
+		comp2 := saved.Apps[1].Components[1]
+		require.Equal(t, common.Format("Other"), comp2.Format)
+		require.Equal(t, "public", comp2.Config)
+	})
+
+	t.Run("All config", func(t *testing.T) {
+		factory := &mocks.Factory{}
+		ch := &mocks.Channel{}
+		factory.ChannelReturns(ch, nil)
+
+		p := func(config *environment.Config) (fabric.Factory, error) { return factory, nil }
+
+		c := newMockCmd(t, p, "--config", cfg, "--encrypt-for", certFile, "--noprompt")
+		require.NoError(t, c.Execute())
+
+		req, _ := ch.ExecuteArgsForCall(0)
+
+		saved := &common.Config{}
+		require.NoError(t, json.Unmarshal(req.Args[0], saved))
+		require.Equal(t, common.FormatEncrypted, saved.Apps[0].Format)
+		require.Equal(t, common.FormatEncrypted, saved.Apps[1].Components[0].Format)
+		require.Equal(t, common.FormatEncrypted, saved.Apps[1].Components[1].Format)
+	})
+}
+
+func TestUpdateCmd_Sign(t *testing.T) {
+	const (
+		keyFile  = "../testdata/ec_private.key"
+		certFile = "../testdata/ec_cert.pem"
+		cfg      = `{"MspID":"msp1","Apps":[{"AppName":"app1","Version":"v1","Format":"Other","Config":"config1","Tags":["tag1"]},{"AppName":"app2","Version":"v1","Components":[{"Name":"comp1","Version":"v1","Format":"Other","Config":"config2"}]}]}`
+	)
+
+	t.Run("--sign-kid without --sign-key", func(t *testing.T) {
+		require.EqualError(t, newMockCmd(t, nil, "--config", cfg, "--sign-kid", "kid1").Execute(), errSignKeyRequired)
+	})
+
+	t.Run("--sign-key file not found", func(t *testing.T) {
+		err := newMockCmd(t, nil, "--config", cfg, "--sign-key", "./notthere.pem").Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errFileNotFound)
+	})
+
+	t.Run("Invalid --sign-key", func(t *testing.T) {
+		err := newMockCmd(t, nil, "--config", cfg, "--sign-key", certFile, "--noprompt").Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "error loading signing key")
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		factory := &mocks.Factory{}
+		ch := &mocks.Channel{}
+		factory.ChannelReturns(ch, nil)
+
+		p := func(config *environment.Config) (fabric.Factory, error) { return factory, nil }
+
+		c := newMockCmd(t, p, "--config", cfg, "--sign-key", keyFile, "--sign-kid", "kid1", "--encrypt-for", certFile, "--encrypt", "app2", "--noprompt")
+		require.NoError(t, c.Execute())
+
+		req, _ := ch.ExecuteArgsForCall(0)
+
+		saved := &common.Config{}
+		require.NoError(t, json.Unmarshal(req.Args[0], saved))
+
+		publicKey, err := keyutil.PublicKeyFromFile(certFile)
+		require.NoError(t, err)
+
+		app1 := saved.Apps[0]
+		require.Len(t, app1.Tags, 2)
+		require.Equal(t, "tag1", app1.Tags[0])
+
+		jws, ok := signature.GetTag(app1.Tags)
+		require.True(t, ok)
+		require.NoError(t, signature.Verify(jws, []byte(app1.Config), publicKey))
+
+		headers, _, err := signature.Parse(jws)
+		require.NoError(t, err)
+		kid, _ := headers.KeyID()
+		require.Equal(t, "kid1", kid)
+		configKey, _ := signature.ConfigKey(headers)
+		require.Equal(t, (&common.Key{MspID: saved.MspID, AppName: app1.AppName, AppVersion: app1.Version}).Marshal(), configKey)
+
+		comp1 := saved.Apps[1].Components[0]
+		require.Equal(t, common.FormatEncrypted, comp1.Format)
+
+		jws, ok = signature.GetTag(comp1.Tags)
+		require.True(t, ok)
+		require.NoError(t, signature.Verify(jws, []byte(comp1.Config), publicKey))
+
+		headers, _, err = signature.Parse(jws)
+		require.NoError(t, err)
+		configKey, _ = signature.ConfigKey(headers)
+		require.Equal(t, (&common.Key{
+			MspID: saved.MspID, AppName: saved.Apps[1].AppName, AppVersion: saved.Apps[1].Version,
+			ComponentName: comp1.Name, ComponentVersion: comp1.Version,
+		}).Marshal(), configKey)
+	})
+}
+
 func newMockCmd(t *testing.T, p basecmd.FactoryProvider, args ...string) *cobra.Command {
 	return newMockCmdWithReaderWriter(t, &mocks.Reader{}, &mocks.Writer{}, p, args...)
 }
@@ -135,6 +401,8 @@ func newMockCmdWithReaderWriter(t *testing.T, in io.Reader, w io.Writer, p basec
 
 	settings.Config.CurrentContext = "testctx"
 	settings.Config.Contexts[settings.Config.CurrentContext] = &environment.Context{}
+	settings.Config.Contexts["ctx1"] = &environment.Context{Channel: "channel1"}
+	settings.Config.Contexts["ctx2"] = &environment.Context{Channel: "channel2"}
 
 	c := newCmd(settings, p)
 	require.NotNil(t, c)
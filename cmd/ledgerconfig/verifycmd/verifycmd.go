@@ -0,0 +1,215 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifycmd
+
+import (
+	"crypto"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/basecmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/keyutil"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/common"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/signature"
+)
+
+const (
+	use      = "verify"
+	desc     = "Verify the signatures of ledger configuration"
+	longDesc = `
+The verify command queries an MSP's configuration using search criteria and checks the detached JWS (stored in the
+reserved tag "jws=") of each configuration against a set of trusted public keys. A configuration is verified if its
+signature was produced by one of the trusted keys for the key under which the configuration is stored (i.e. the
+MSP ID, peer ID, app/component name and version in the protected "cfgkey" header of the JWS must match), so that
+a signed configuration that was copied to a different key fails verification. The criteria consists of:
+
+* MspID (mandatory)           - The MSP ID of the organization
+* PeerID (optional)           - The ID of the peer
+* AppName (optional)          - The application name
+* AppVersion (optional)       - The application version
+* ComponentName (optional)    - The component name
+* ComponentVersion (optional) - The component version
+
+Criteria may be specified as a JSON string (using the --criteria option) or it may be specified using the options:
+	--mspid, --peerid, --appname, --appver, --componentname and --componentver
+
+Trusted keys may be specified as PEM-encoded public keys or certificates, or as public keys in JWK format.
+An error is returned if any of the configurations is unsigned or its signature cannot be verified.
+`
+	examples = `
+- Verify the configuration of a particular application:
+    $ ./fabric ledgerconfig verify --mspid Org1MSP --appname app1 --appver v1 --trusted-keys ./org1-admin-cert.pem
+
+- Verify all configuration in Org1MSP against two trusted keys:
+    $ ./fabric ledgerconfig verify --mspid Org1MSP --trusted-keys ./org1-admin-cert.pem,./org1-ops-key.jwk
+`
+)
+
+const (
+	trustedKeysFlag  = "trusted-keys"
+	trustedKeysUsage = "A comma-separated list of files containing trusted public keys (PEM public key, PEM certificate or JWK). Example: --trusted-keys ./org1-admin-cert.pem,./org1-ops-key.jwk"
+
+	msgNoConfig = "No configuration matches the given criteria"
+	msgVerified = "All configuration verified successfully!"
+)
+
+var errTrustedKeysRequired = errors.New("trusted keys (--trusted-keys) is required")
+
+// New returns the ledger config verify command
+func New(settings *environment.Settings) *cobra.Command {
+	return newCmd(settings, nil)
+}
+
+func newCmd(settings *environment.Settings, p basecmd.FactoryProvider) *cobra.Command {
+	c := &command{}
+
+	cmd := &cobra.Command{
+		Use:     use,
+		Short:   desc,
+		Long:    longDesc,
+		Example: examples,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return c.validate()
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return c.run()
+		},
+	}
+	c.CriteriaBaseCommand = common.NewCriteriaBaseCommand(settings, p, cmd)
+
+	cmd.Flags().StringVar(&c.trustedKeys, trustedKeysFlag, "", trustedKeysUsage)
+
+	return cmd
+}
+
+// command implements the verify command
+type command struct {
+	*common.CriteriaBaseCommand
+
+	// Flags
+	trustedKeys string
+}
+
+func (c *command) validate() error {
+	if strings.TrimSpace(c.trustedKeys) == "" {
+		return errTrustedKeysRequired
+	}
+
+	return c.Validate()
+}
+
+func (c *command) run() error {
+	keys, err := c.loadTrustedKeys()
+	if err != nil {
+		return err
+	}
+
+	criteriaBytes, err := c.GetCriteriaBytes()
+	if err != nil {
+		return err
+	}
+
+	config, err := c.GetConfig(criteriaBytes)
+	if err != nil {
+		return err
+	}
+
+	var kvs []*common.KeyValue
+	if err := json.Unmarshal(config, &kvs); err != nil {
+		return errors.WithMessage(err, "invalid config returned from query")
+	}
+
+	if len(kvs) == 0 {
+		return c.Fprintln(msgNoConfig)
+	}
+
+	numFailed := 0
+	for _, kv := range kvs {
+		status, ok := verify(kv, keys)
+		if !ok {
+			numFailed++
+		}
+
+		if err := c.Fprintln(fmt.Sprintf("%s: %s", kv.Key, status)); err != nil {
+			return err
+		}
+	}
+
+	if numFailed > 0 {
+		return errors.Errorf("%d of %d configurations failed verification", numFailed, len(kvs))
+	}
+
+	return c.Fprintln(msgVerified)
+}
+
+func (c *command) loadTrustedKeys() ([]*trustedKey, error) {
+	var keys []*trustedKey
+	for _, file := range strings.Split(c.trustedKeys, ",") {
+		file = strings.TrimSpace(file)
+		if file == "" {
+			continue
+		}
+
+		publicKey, err := keyutil.PublicKeyFromFile(file)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "error loading trusted key from [%s]", file)
+		}
+
+		keys = append(keys, &trustedKey{file: file, publicKey: publicKey})
+	}
+
+	return keys, nil
+}
+
+// verify verifies the signature of the given key-value against the trusted keys and returns
+// a readable status along with true if the signature was verified
+func verify(kv *common.KeyValue, keys []*trustedKey) (string, bool) {
+	if kv.Value == nil {
+		return "UNSIGNED", false
+	}
+
+	jws, ok := signature.GetTag(kv.Tags)
+	if !ok {
+		return "UNSIGNED", false
+	}
+
+	headers, _, err := signature.Parse(jws)
+	if err != nil {
+		return fmt.Sprintf("INVALID - %s", err), false
+	}
+
+	configKey, ok := signature.ConfigKey(headers)
+	if !ok {
+		return "INVALID - signature does not cover the config key", false
+	}
+
+	if configKey != kv.Key.Marshal() {
+		return fmt.Sprintf("INVALID - signature was produced for config key [%s]", configKey), false
+	}
+
+	for _, key := range keys {
+		if signature.Verify(jws, []byte(kv.Config), key.publicKey) == nil {
+			if kid, ok := headers.KeyID(); ok {
+				return fmt.Sprintf("VERIFIED (trusted key: %s, kid: %s)", key.file, kid), true
+			}
+
+			return fmt.Sprintf("VERIFIED (trusted key: %s)", key.file), true
+		}
+	}
+
+	return "INVALID - signature does not match any of the trusted keys", false
+}
+
+type trustedKey struct {
+	file      string
+	publicKey crypto.PublicKey
+}
@@ -0,0 +1,191 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifycmd
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/hyperledger/fabric-cli/pkg/fabric"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/channel"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/basecmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/keyutil"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/common"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/signature"
+	"github.com/trustbloc/fabric-cli-ext/cmd/mocks"
+)
+
+const (
+	ecKeyFile  = "../testdata/ec_private.key"
+	ecCertFile = "../testdata/ec_cert.pem"
+	ecJWKFile  = "../testdata/ec_public.jwk"
+	rsaKeyFile = "../testdata/rsa_private.key"
+	rsaPubFile = "../testdata/rsa_public.key"
+)
+
+func TestVerifyCmd_InvalidOptions(t *testing.T) {
+	t.Run("No trusted keys", func(t *testing.T) {
+		c := newMockCmd(t, &mocks.Writer{}, nil, "--mspid", "msp1")
+		require.EqualError(t, c.Execute(), errTrustedKeysRequired.Error())
+	})
+
+	t.Run("No criteria", func(t *testing.T) {
+		c := newMockCmd(t, &mocks.Writer{}, nil, "--trusted-keys", ecCertFile)
+		require.Error(t, c.Execute())
+	})
+
+	t.Run("Invalid trusted key", func(t *testing.T) {
+		c := newMockCmd(t, &mocks.Writer{}, nil, "--mspid", "msp1", "--trusted-keys", "./notthere.pem")
+		err := c.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "error loading trusted key from [./notthere.pem]")
+	})
+}
+
+func TestVerifyCmd(t *testing.T) {
+	kvSignedEC := newKeyValue(t, "app1", "config1", ecKeyFile, "kid1")
+	kvSignedRSA := newKeyValue(t, "app2", "config2", rsaKeyFile, "")
+	kvUnsigned := newKeyValue(t, "app3", "config3", "", "")
+
+	kvTampered := newKeyValue(t, "app4", "config4", ecKeyFile, "")
+	kvTampered.Config = "tampered"
+
+	// A signed config that was copied to a different key
+	kvCopied := newKeyValue(t, "app5", "config1", ecKeyFile, "kid1")
+	kvCopied.Tags = kvSignedEC.Tags
+
+	// A config whose signature doesn't cover the config key
+	kvNoKey := newKeyValue(t, "app6", "config6", "", "")
+	kvNoKey.Tags = signature.SetTag(kvNoKey.Tags, sign(t, "config6", ecKeyFile))
+
+	factory := &mocks.Factory{}
+	ch := &mocks.Channel{}
+	factory.ChannelReturns(ch, nil)
+
+	p := func(config *environment.Config) (fabric.Factory, error) { return factory, nil }
+
+	t.Run("Verified", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: marshal(t, kvSignedEC, kvSignedRSA)}, nil)
+
+		w := &mocks.Writer{}
+		c := newMockCmd(t, w, p, "--mspid", "msp1", "--trusted-keys", ecJWKFile+","+rsaPubFile)
+		require.NoError(t, c.Execute())
+		require.Contains(t, w.Written(), "VERIFIED (trusted key: ../testdata/ec_public.jwk, kid: kid1)")
+		require.Contains(t, w.Written(), "VERIFIED (trusted key: ../testdata/rsa_public.key)")
+		require.Contains(t, w.Written(), msgVerified)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: marshal(t, kvSignedEC, kvSignedRSA, kvUnsigned, kvTampered)}, nil)
+
+		w := &mocks.Writer{}
+		c := newMockCmd(t, w, p, "--mspid", "msp1", "--trusted-keys", ecCertFile)
+		require.EqualError(t, c.Execute(), "3 of 4 configurations failed verification")
+		require.Contains(t, w.Written(), "(AppName:app1)")
+		require.Contains(t, w.Written(), "UNSIGNED")
+		require.Contains(t, w.Written(), "INVALID - signature does not match any of the trusted keys")
+	})
+
+	t.Run("Wrong config key", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: marshal(t, kvCopied, kvNoKey)}, nil)
+
+		w := &mocks.Writer{}
+		c := newMockCmd(t, w, p, "--mspid", "msp1", "--trusted-keys", ecCertFile)
+		require.EqualError(t, c.Execute(), "2 of 2 configurations failed verification")
+		require.Contains(t, w.Written(), "INVALID - signature was produced for config key [msp1!!app1!v1!!]")
+		require.Contains(t, w.Written(), "INVALID - signature does not cover the config key")
+	})
+
+	t.Run("No config", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: []byte("null")}, nil)
+
+		w := &mocks.Writer{}
+		c := newMockCmd(t, w, p, "--mspid", "msp1", "--trusted-keys", ecCertFile)
+		require.NoError(t, c.Execute())
+		require.Equal(t, msgNoConfig, w.Written())
+	})
+
+	t.Run("Invalid payload", func(t *testing.T) {
+		ch.QueryReturns(channel.Response{Payload: []byte("invalid")}, nil)
+
+		c := newMockCmd(t, &mocks.Writer{}, p, "--mspid", "msp1", "--trusted-keys", ecCertFile)
+		require.Error(t, c.Execute())
+	})
+
+	t.Run("Query error", func(t *testing.T) {
+		errExpected := errors.New("query error")
+		ch.QueryReturns(channel.Response{}, errExpected)
+
+		c := newMockCmd(t, &mocks.Writer{}, p, "--mspid", "msp1", "--trusted-keys", ecCertFile)
+		require.EqualError(t, c.Execute(), errExpected.Error())
+	})
+}
+
+func newKeyValue(t *testing.T, appName, config, keyFile, kid string) *common.KeyValue {
+	kv := &common.KeyValue{
+		Key:   &common.Key{MspID: "msp1", AppName: appName, AppVersion: "v1"},
+		Value: &common.Value{TxID: "tx1", Format: "Other", Config: config, Tags: []string{"tag1"}},
+	}
+
+	if keyFile == "" {
+		return kv
+	}
+
+	privateKey, err := keyutil.PrivateKeyFromFile(keyFile)
+	require.NoError(t, err)
+
+	signer, err := signature.NewSigner(privateKey, kid)
+	require.NoError(t, err)
+
+	jws, err := signature.SignConfig(kv.Key.Marshal(), []byte(config), signer)
+	require.NoError(t, err)
+
+	kv.Tags = signature.SetTag(kv.Tags, jws)
+
+	return kv
+}
+
+func sign(t *testing.T, config, keyFile string) string {
+	privateKey, err := keyutil.PrivateKeyFromFile(keyFile)
+	require.NoError(t, err)
+
+	signer, err := signature.NewSigner(privateKey, "")
+	require.NoError(t, err)
+
+	jws, err := signature.Sign([]byte(config), signer)
+	require.NoError(t, err)
+
+	return jws
+}
+
+func marshal(t *testing.T, kvs ...*common.KeyValue) []byte {
+	kvsBytes, err := json.Marshal(kvs)
+	require.NoError(t, err)
+
+	return kvsBytes
+}
+
+func newMockCmd(t *testing.T, out io.Writer, p basecmd.FactoryProvider, args ...string) *cobra.Command {
+	settings := environment.NewDefaultSettings()
+	settings.Streams.Out = out
+
+	settings.Config.CurrentContext = "testctx"
+	settings.Config.Contexts[settings.Config.CurrentContext] = &environment.Context{}
+
+	c := newCmd(settings, p)
+	require.NotNil(t, c)
+
+	c.SetArgs(args)
+
+	return c
+}
@@ -0,0 +1,197 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package watchcmd
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/pkg/errors"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/common"
+)
+
+const (
+	// compositeKeyNamespace is the prefix of composite (index) keys which are ignored
+	compositeKeyNamespace = "\x00"
+
+	opSave   = "save"
+	opDelete = "delete"
+)
+
+// change is a single configuration change that was committed to the ledger
+type change struct {
+	BlockNum  uint64        `json:"blockNum"`
+	TxID      string        `json:"txId"`
+	Operation string        `json:"operation"`
+	Key       *common.Key   `json:"key"`
+	Value     *common.Value `json:"value,omitempty"`
+}
+
+// decodeBlock returns the configuration changes in the valid transactions of the given block. A transaction that
+// can't be decoded is passed to the given skip function, along with its index in the block, and is otherwise ignored
+// so that one malformed transaction doesn't prevent the changes in the others from being reported.
+func decodeBlock(block *cb.Block, skip func(txNum int, err error)) ([]*change, error) {
+	if block.Header == nil || block.Data == nil {
+		return nil, errors.New("invalid block")
+	}
+
+	var changes []*change
+	for i, envBytes := range block.Data.Data {
+		if !isValid(block, i) {
+			continue
+		}
+
+		txChanges, err := decodeTransaction(envBytes)
+		if err != nil {
+			skip(i, err)
+			continue
+		}
+
+		for _, c := range txChanges {
+			c.BlockNum = block.Header.Number
+			changes = append(changes, c)
+		}
+	}
+
+	return changes, nil
+}
+
+// isValid returns true if the transaction at the given index was marked valid by the committer
+func isValid(block *cb.Block, txIndex int) bool {
+	if block.Metadata == nil || len(block.Metadata.Metadata) <= int(cb.BlockMetadataIndex_TRANSACTIONS_FILTER) {
+		return true
+	}
+
+	txFilter := block.Metadata.Metadata[cb.BlockMetadataIndex_TRANSACTIONS_FILTER]
+	if txIndex >= len(txFilter) {
+		return true
+	}
+
+	return pb.TxValidationCode(txFilter[txIndex]) == pb.TxValidationCode_VALID
+}
+
+func decodeTransaction(envBytes []byte) ([]*change, error) {
+	env := &cb.Envelope{}
+	if err := proto.Unmarshal(envBytes, env); err != nil {
+		return nil, err
+	}
+
+	payload := &cb.Payload{}
+	if err := proto.Unmarshal(env.Payload, payload); err != nil {
+		return nil, err
+	}
+
+	if payload.Header == nil {
+		return nil, errors.New("payload header is missing")
+	}
+
+	chdr := &cb.ChannelHeader{}
+	if err := proto.Unmarshal(payload.Header.ChannelHeader, chdr); err != nil {
+		return nil, err
+	}
+
+	if cb.HeaderType(chdr.Type) != cb.HeaderType_ENDORSER_TRANSACTION {
+		return nil, nil
+	}
+
+	tx := &pb.Transaction{}
+	if err := proto.Unmarshal(payload.Data, tx); err != nil {
+		return nil, err
+	}
+
+	var changes []*change
+	for _, action := range tx.Actions {
+		actionChanges, err := decodeAction(chdr.TxId, action)
+		if err != nil {
+			return nil, err
+		}
+
+		changes = append(changes, actionChanges...)
+	}
+
+	return changes, nil
+}
+
+func decodeAction(txID string, action *pb.TransactionAction) ([]*change, error) {
+	ccActionPayload := &pb.ChaincodeActionPayload{}
+	if err := proto.Unmarshal(action.Payload, ccActionPayload); err != nil {
+		return nil, err
+	}
+
+	if ccActionPayload.Action == nil {
+		return nil, errors.New("chaincode endorsed action is missing")
+	}
+
+	prp := &pb.ProposalResponsePayload{}
+	if err := proto.Unmarshal(ccActionPayload.Action.ProposalResponsePayload, prp); err != nil {
+		return nil, err
+	}
+
+	ccAction := &pb.ChaincodeAction{}
+	if err := proto.Unmarshal(prp.Extension, ccAction); err != nil {
+		return nil, err
+	}
+
+	txRWSet := &rwset.TxReadWriteSet{}
+	if err := proto.Unmarshal(ccAction.Results, txRWSet); err != nil {
+		return nil, err
+	}
+
+	var changes []*change
+	for _, nsRWSet := range txRWSet.NsRwset {
+		if nsRWSet.Namespace != common.ConfigSCC {
+			continue
+		}
+
+		kvRWSet := &kvrwset.KVRWSet{}
+		if err := proto.Unmarshal(nsRWSet.Rwset, kvRWSet); err != nil {
+			return nil, err
+		}
+
+		for _, w := range kvRWSet.Writes {
+			c, err := decodeWrite(txID, w)
+			if err != nil {
+				return nil, err
+			}
+
+			if c != nil {
+				changes = append(changes, c)
+			}
+		}
+	}
+
+	return changes, nil
+}
+
+func decodeWrite(txID string, w *kvrwset.KVWrite) (*change, error) {
+	if strings.HasPrefix(w.Key, compositeKeyNamespace) {
+		// Index entry
+		return nil, nil
+	}
+
+	key, err := common.UnmarshalKey(w.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	if w.IsDelete {
+		return &change{TxID: txID, Operation: opDelete, Key: key}, nil
+	}
+
+	value := &common.Value{}
+	if err := json.Unmarshal(w.Value, value); err != nil {
+		return nil, errors.WithMessagef(err, "invalid config value for key [%s]", w.Key)
+	}
+
+	return &change{TxID: txID, Operation: opSave, Key: key, Value: value}, nil
+}
@@ -0,0 +1,267 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package watchcmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/hyperledger/fabric-cli/pkg/fabric"
+	"github.com/hyperledger/fabric-sdk-go/pkg/client/event"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/basecmd"
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/common"
+)
+
+const (
+	use      = "watch"
+	desc     = "Watch ledger configuration changes"
+	longDesc = `
+The watch command subscribes to block events on the channel of the current context and displays the ledger
+configuration changes (saves and deletes) as they are committed. The write-set of each valid configscc
+transaction is decoded into configuration keys and values. A transaction that can't be decoded is skipped and a
+warning is written to the error stream. Changes may be filtered using search criteria which consists of:
+
+* MspID (optional)            - The MSP ID of the organization
+* PeerID (optional)           - The ID of the peer
+* AppName (optional)          - The application name
+* AppVersion (optional)       - The application version
+* ComponentName (optional)    - The component name
+* ComponentVersion (optional) - The component version
+
+Criteria may be specified as a JSON string (using the --criteria option) or it may be specified using the options:
+	--mspid, --peerid, --appname, --appver, --componentname and --componentver
+
+If no criteria is specified then all configuration changes are displayed. Changes are displayed as human-readable
+lines or, if --ndjson is specified, as newline-delimited JSON. The command runs until it is interrupted.
+
+Note that the user of the current context must be authorized to receive (full) block events.
+`
+	examples = `
+- Watch all configuration changes:
+    $ ./fabric ledgerconfig watch
+
+- Watch configuration changes of the file-handler app in Org1MSP:
+    $ ./fabric ledgerconfig watch --mspid Org1MSP --appname file-handler
+
+... results in output such as:
+
+	[Block 12] SAVE (MSP:Org1MSP),(Peer:peer0.org1.example.com),(AppName:file-handler),(AppVersion:1),(Comp:),(CompVersion:) - TxID: 9730813e..., Format: JSON, Config: {"BasePath":"/content"}
+	[Block 13] DELETE (MSP:Org1MSP),(Peer:peer0.org1.example.com),(AppName:file-handler),(AppVersion:1),(Comp:),(CompVersion:) - TxID: 5a3b9e0c...
+
+- Watch configuration changes and output newline-delimited JSON:
+    $ ./fabric ledgerconfig watch --mspid Org1MSP --ndjson
+`
+)
+
+const (
+	ndjsonFlag  = "ndjson"
+	ndjsonUsage = "If specified then each change is displayed as a single line of JSON. Example: --ndjson"
+
+	msgWatching  = "Watching for configuration changes. Press Ctrl+C to stop..."
+	msgSkippedTx = "Warning: skipping transaction %d in block %d since it couldn't be decoded: %s"
+)
+
+// eventProvider creates a new event client
+type eventProvider func() (fabric.Event, error)
+
+// New returns the ledger config watch command
+func New(settings *environment.Settings) *cobra.Command {
+	return newCmd(settings, nil, nil, nil)
+}
+
+func newCmd(settings *environment.Settings, p basecmd.FactoryProvider, ep eventProvider, done <-chan struct{}) *cobra.Command {
+	c := &command{done: done}
+
+	cmd := &cobra.Command{
+		Use:     use,
+		Short:   desc,
+		Long:    longDesc,
+		Example: examples,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			// All of the criteria fields are optional
+			return c.ValidateCriteria()
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return c.run()
+		},
+	}
+	c.CriteriaBaseCommand = common.NewCriteriaBaseCommand(settings, p, cmd)
+
+	if ep == nil {
+		ep = c.newEventClient
+	}
+
+	c.eventProvider = ep
+
+	cmd.Flags().BoolVar(&c.ndjson, ndjsonFlag, false, ndjsonUsage)
+
+	return cmd
+}
+
+// command implements the watch command
+type command struct {
+	*common.CriteriaBaseCommand
+
+	eventProvider eventProvider
+	done          <-chan struct{}
+
+	// Flags
+	ndjson bool
+}
+
+func (c *command) run() error {
+	criteria := &common.Criteria{}
+	if c.IsCriteriaSpecified() {
+		var err error
+		criteria, err = c.GetCriteria()
+		if err != nil {
+			return err
+		}
+	}
+
+	client, err := c.eventProvider()
+	if err != nil {
+		return errors.WithMessage(err, "error creating event client")
+	}
+
+	reg, eventch, err := client.RegisterBlockEvent()
+	if err != nil {
+		return errors.WithMessage(err, "error registering for block events")
+	}
+	defer client.Unregister(reg)
+
+	done := c.done
+	if done == nil {
+		done = interrupted()
+	}
+
+	if !c.ndjson {
+		if err := c.Fprintln(msgWatching); err != nil {
+			return err
+		}
+	}
+
+	return c.watch(criteria, eventch, done)
+}
+
+func (c *command) watch(criteria *common.Criteria, eventch <-chan *fab.BlockEvent, done <-chan struct{}) error {
+	for {
+		select {
+		case <-done:
+			return nil
+		case e, ok := <-eventch:
+			if !ok {
+				return errors.New("event channel closed")
+			}
+
+			if err := c.handleBlock(criteria, e); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (c *command) handleBlock(criteria *common.Criteria, e *fab.BlockEvent) error {
+	changes, err := decodeBlock(e.Block, func(txNum int, err error) {
+		c.warnSkipped(e.Block.Header.Number, txNum, err)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, ch := range changes {
+		if !criteria.Matches(ch.Key) {
+			continue
+		}
+
+		if err := c.display(ch); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// warnSkipped writes a warning about a transaction that couldn't be decoded to the error stream so that
+// it doesn't interfere with the output (which may be NDJSON)
+func (c *command) warnSkipped(blockNum uint64, txNum int, err error) {
+	// The warning is best effort and a failure to write it mustn't stop the watch
+	_, _ = fmt.Fprintf(c.Settings.Streams.Err, msgSkippedTx+"\n", txNum, blockNum, err)
+}
+
+func (c *command) display(ch *change) error {
+	if c.ndjson {
+		changeBytes, err := json.Marshal(ch)
+		if err != nil {
+			return err
+		}
+
+		return c.Fprintln(string(changeBytes))
+	}
+
+	line := fmt.Sprintf("[Block %d] %s %s - TxID: %s", ch.BlockNum, strings.ToUpper(ch.Operation), ch.Key, ch.TxID)
+	if ch.Value != nil {
+		line = fmt.Sprintf("%s, Format: %s, Config: %s", line, ch.Value.Format, ch.Value.Config)
+		if len(ch.Value.Tags) > 0 {
+			line = fmt.Sprintf("%s, Tags: %s", line, ch.Value.Tags)
+		}
+	}
+
+	return c.Fprintln(line)
+}
+
+// newEventClient creates an event client for the channel of the current context. The client returned by
+// fabric.Factory.Event() only permits filtered block events which do not contain the write-sets, so the
+// client is created from the factory's SDK (using the same context) with block events enabled.
+func (c *command) newEventClient() (fabric.Event, error) {
+	factory, err := c.FactoryProvider(c.Settings.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	sdk, err := factory.SDK()
+	if err != nil {
+		return nil, err
+	}
+
+	context := c.Context()
+
+	client, err := event.New(
+		sdk.ChannelContext(context.Channel, fabsdk.WithUser(context.User), fabsdk.WithOrg(context.Organization)),
+		event.WithBlockEvents(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// interrupted returns a channel that is closed when the process receives an interrupt or terminate signal
+func interrupted() <-chan struct{} {
+	sigch := make(chan os.Signal, 1)
+	signal.Notify(sigch, os.Interrupt, syscall.SIGTERM)
+
+	done := make(chan struct{})
+	go func() {
+		<-sigch
+		signal.Stop(sigch)
+		close(done)
+	}()
+
+	return done
+}
@@ -0,0 +1,265 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package watchcmd
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-cli/pkg/environment"
+	"github.com/hyperledger/fabric-cli/pkg/fabric"
+	cb "github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset"
+	"github.com/hyperledger/fabric-protos-go/ledger/rwset/kvrwset"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/fabric-cli-ext/cmd/ledgerconfig/common"
+	"github.com/trustbloc/fabric-cli-ext/cmd/mocks"
+)
+
+const (
+	key1 = "Org1MSP!peer0.org1.com!file-handler!1!!"
+	key2 = "Org2MSP!!app2!v1!comp1!v1"
+)
+
+func TestWatchCmd(t *testing.T) {
+	value1 := &common.Value{TxID: "tx1", Format: "JSON", Config: `{"BasePath":"/content"}`, Tags: []string{"tag1"}}
+
+	block := newBlock(t, 5,
+		newTx(t, "tx1", common.ConfigSCC, write(t, key1, value1), indexWrite(key1)),
+		newTx(t, "tx2", common.ConfigSCC, deleteWrite(key2)),
+		newTx(t, "tx3", "othercc", write(t, key1, value1)),
+	)
+
+	t.Run("Human-readable", func(t *testing.T) {
+		w := &mocks.Writer{}
+		runWatch(t, w, block, nil)
+
+		out := string(w.Bytes)
+		require.Contains(t, out, msgWatching)
+		require.Contains(t, out, "[Block 5] SAVE (MSP:Org1MSP),(Peer:peer0.org1.com),(AppName:file-handler),(AppVersion:1),(Comp:),(CompVersion:) - TxID: tx1, Format: JSON, Config: {\"BasePath\":\"/content\"}, Tags: [tag1]")
+		require.Contains(t, out, "[Block 5] DELETE (MSP:Org2MSP),(Peer:),(AppName:app2),(AppVersion:v1),(Comp:comp1),(CompVersion:v1) - TxID: tx2")
+		require.Equal(t, 3, strings.Count(out, "\n"))
+	})
+
+	t.Run("NDJSON with criteria", func(t *testing.T) {
+		w := &mocks.Writer{}
+		runWatch(t, w, block, nil, "--mspid", "Org1MSP", "--ndjson")
+
+		lines := strings.Split(strings.TrimSpace(string(w.Bytes)), "\n")
+		require.Len(t, lines, 1)
+
+		ch := &change{}
+		require.NoError(t, json.Unmarshal([]byte(lines[0]), ch))
+		require.Equal(t, uint64(5), ch.BlockNum)
+		require.Equal(t, "tx1", ch.TxID)
+		require.Equal(t, opSave, ch.Operation)
+		require.Equal(t, "file-handler", ch.Key.AppName)
+		require.Equal(t, value1.Config, ch.Value.Config)
+	})
+
+	t.Run("Invalid transaction", func(t *testing.T) {
+		invalidBlock := newBlock(t, 6, newTx(t, "tx1", common.ConfigSCC, write(t, key1, value1)))
+		invalidBlock.Metadata.Metadata[cb.BlockMetadataIndex_TRANSACTIONS_FILTER] = []byte{byte(pb.TxValidationCode_MVCC_READ_CONFLICT)}
+
+		w := &mocks.Writer{}
+		runWatch(t, w, invalidBlock, nil)
+		require.Equal(t, msgWatching, w.Written())
+	})
+
+	t.Run("Undecodable transactions", func(t *testing.T) {
+		malformedBlock := newBlock(t, 6,
+			newTx(t, "tx1", common.ConfigSCC, write(t, "invalid", value1)),
+			[]byte("malformed"),
+			newTx(t, "tx3", common.ConfigSCC, deleteWrite(key2)),
+		)
+
+		w := &mocks.Writer{}
+		require.NoError(t, runWatch(t, w, malformedBlock, nil, "--ndjson"))
+
+		lines := strings.Split(strings.TrimSpace(string(w.Bytes)), "\n")
+		require.Len(t, lines, 3)
+		require.Contains(t, lines[0], "Warning: skipping transaction 0 in block 6 since it couldn't be decoded: invalid config key [invalid]")
+		require.Contains(t, lines[1], "Warning: skipping transaction 1 in block 6 since it couldn't be decoded")
+
+		ch := &change{}
+		require.NoError(t, json.Unmarshal([]byte(lines[2]), ch))
+		require.Equal(t, "tx3", ch.TxID)
+		require.Equal(t, opDelete, ch.Operation)
+	})
+
+	t.Run("App name only", func(t *testing.T) {
+		w := &mocks.Writer{}
+		require.NoError(t, runWatch(t, w, block, nil, "--appname", "app2"))
+
+		out := string(w.Bytes)
+		require.Contains(t, out, "[Block 5] DELETE (MSP:Org2MSP),(Peer:),(AppName:app2)")
+		require.NotContains(t, out, "file-handler")
+	})
+
+	t.Run("Invalid criteria", func(t *testing.T) {
+		err := runWatch(t, &mocks.Writer{}, block, nil, "--criteria", "{")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid criteria")
+
+		err = runWatch(t, &mocks.Writer{}, block, nil, "--criteria", `{"AppName":"app2"}`, "--mspid", "Org1MSP")
+		require.EqualError(t, err, "other options cannot be used along with --criteria")
+	})
+
+	t.Run("Event client error", func(t *testing.T) {
+		errExpected := errors.New("event client error")
+		err := runWatch(t, &mocks.Writer{}, block, errExpected)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+
+	t.Run("Register error", func(t *testing.T) {
+		errExpected := errors.New("register error")
+
+		client := &mocks.Event{}
+		client.RegisterBlockEventReturns(nil, nil, errExpected)
+
+		ep := func() (fabric.Event, error) { return client, nil }
+
+		c := newMockCmd(t, &mocks.Writer{}, ep, make(chan struct{}))
+		err := c.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+
+	t.Run("Event channel closed", func(t *testing.T) {
+		eventch := make(chan *fab.BlockEvent)
+		close(eventch)
+
+		client := &mocks.Event{}
+		client.RegisterBlockEventReturns(nil, eventch, nil)
+
+		ep := func() (fabric.Event, error) { return client, nil }
+
+		c := newMockCmd(t, &mocks.Writer{}, ep, make(chan struct{}))
+		require.EqualError(t, c.Execute(), "event channel closed")
+		require.Equal(t, 1, client.UnregisterCallCount())
+	})
+}
+
+// runWatch delivers the given block and then stops the watch command
+func runWatch(t *testing.T, w io.Writer, block *cb.Block, errClient error, args ...string) error {
+	eventch := make(chan *fab.BlockEvent)
+
+	client := &mocks.Event{}
+	client.RegisterBlockEventReturns(nil, eventch, nil)
+
+	ep := func() (fabric.Event, error) {
+		if errClient != nil {
+			return nil, errClient
+		}
+
+		return client, nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		// Sending an empty block after the given block ensures that the given block was handled
+		for _, b := range []*cb.Block{block, newBlock(t, 0)} {
+			select {
+			case eventch <- &fab.BlockEvent{Block: b}:
+			case <-time.After(time.Second):
+				return
+			}
+		}
+	}()
+
+	return newMockCmd(t, w, ep, done, args...).Execute()
+}
+
+func newMockCmd(t *testing.T, out io.Writer, ep eventProvider, done <-chan struct{}, args ...string) *cobra.Command {
+	settings := environment.NewDefaultSettings()
+	settings.Streams.Out = out
+	settings.Streams.Err = out
+
+	settings.Config.CurrentContext = "testctx"
+	settings.Config.Contexts[settings.Config.CurrentContext] = &environment.Context{}
+
+	c := newCmd(settings, nil, ep, done)
+	require.NotNil(t, c)
+
+	c.SetArgs(args)
+
+	return c
+}
+
+func newBlock(t *testing.T, blockNum uint64, txs ...[]byte) *cb.Block {
+	metadata := make([][]byte, len(cb.BlockMetadataIndex_name))
+	metadata[cb.BlockMetadataIndex_TRANSACTIONS_FILTER] = make([]byte, len(txs))
+
+	return &cb.Block{
+		Header:   &cb.BlockHeader{Number: blockNum},
+		Data:     &cb.BlockData{Data: txs},
+		Metadata: &cb.BlockMetadata{Metadata: metadata},
+	}
+}
+
+func newTx(t *testing.T, txID, ns string, writes ...*kvrwset.KVWrite) []byte {
+	kvRWSet, err := proto.Marshal(&kvrwset.KVRWSet{Writes: writes})
+	require.NoError(t, err)
+
+	results, err := proto.Marshal(&rwset.TxReadWriteSet{
+		DataModel: rwset.TxReadWriteSet_KV,
+		NsRwset:   []*rwset.NsReadWriteSet{{Namespace: ns, Rwset: kvRWSet}},
+	})
+	require.NoError(t, err)
+
+	ccAction, err := proto.Marshal(&pb.ChaincodeAction{Results: results})
+	require.NoError(t, err)
+
+	prp, err := proto.Marshal(&pb.ProposalResponsePayload{Extension: ccAction})
+	require.NoError(t, err)
+
+	ccActionPayload, err := proto.Marshal(&pb.ChaincodeActionPayload{
+		Action: &pb.ChaincodeEndorsedAction{ProposalResponsePayload: prp},
+	})
+	require.NoError(t, err)
+
+	tx, err := proto.Marshal(&pb.Transaction{Actions: []*pb.TransactionAction{{Payload: ccActionPayload}}})
+	require.NoError(t, err)
+
+	chdr, err := proto.Marshal(&cb.ChannelHeader{Type: int32(cb.HeaderType_ENDORSER_TRANSACTION), TxId: txID})
+	require.NoError(t, err)
+
+	payload, err := proto.Marshal(&cb.Payload{Header: &cb.Header{ChannelHeader: chdr}, Data: tx})
+	require.NoError(t, err)
+
+	env, err := proto.Marshal(&cb.Envelope{Payload: payload})
+	require.NoError(t, err)
+
+	return env
+}
+
+func write(t *testing.T, key string, value *common.Value) *kvrwset.KVWrite {
+	valueBytes, err := json.Marshal(value)
+	require.NoError(t, err)
+
+	return &kvrwset.KVWrite{Key: key, Value: valueBytes}
+}
+
+func deleteWrite(key string) *kvrwset.KVWrite {
+	return &kvrwset.KVWrite{Key: key, IsDelete: true}
+}
+
+func indexWrite(key string) *kvrwset.KVWrite {
+	return &kvrwset.KVWrite{Key: compositeKeyNamespace + "cfgmgmt-mspid\x00" + key + "\x00", Value: []byte("{}")}
+}
@@ -0,0 +1,152 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mocks
+
+import (
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/context"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/core"
+	"github.com/hyperledger/fabric-sdk-go/pkg/common/providers/fab"
+	"github.com/hyperledger/fabric-sdk-go/pkg/fabsdk"
+)
+
+// SDK implements a mock SDK whose channel context provides a mock discovery service
+type SDK struct {
+	Peers          []fab.Peer
+	DiscoveryErr   error
+	ContextErr     error
+	EndpointConfig *EndpointConfig
+}
+
+// NewSDK returns a mock SDK whose discovery service returns the given peers
+func NewSDK(peers ...fab.Peer) *SDK {
+	return &SDK{Peers: peers}
+}
+
+// WithDiscoveryError injects an error into the discovery service
+func (m *SDK) WithDiscoveryError(err error) *SDK {
+	m.DiscoveryErr = err
+	return m
+}
+
+// WithEndpointConfig sets the endpoint config of the channel context
+func (m *SDK) WithEndpointConfig(cfg *EndpointConfig) *SDK {
+	m.EndpointConfig = cfg
+	return m
+}
+
+// WithContextError injects an error into the channel context provider
+func (m *SDK) WithContextError(err error) *SDK {
+	m.ContextErr = err
+	return m
+}
+
+// ChannelContext returns a mock channel context provider
+func (m *SDK) ChannelContext(channelID string, options ...fabsdk.ContextOption) context.ChannelProvider {
+	return func() (context.Channel, error) {
+		if m.ContextErr != nil {
+			return nil, m.ContextErr
+		}
+
+		endpointConfig := m.EndpointConfig
+		if endpointConfig == nil {
+			endpointConfig = &EndpointConfig{}
+		}
+
+		return &channelContext{
+			channelService: &channelService{
+				discovery: &discoveryService{peers: m.Peers, err: m.DiscoveryErr},
+			},
+			endpointConfig: endpointConfig,
+		}, nil
+	}
+}
+
+// Context is not implemented
+func (m *SDK) Context(options ...fabsdk.ContextOption) context.ClientProvider {
+	panic("not implemented")
+}
+
+// Config is not implemented
+func (m *SDK) Config() (core.ConfigBackend, error) {
+	panic("not implemented")
+}
+
+// CloseContext does nothing
+func (m *SDK) CloseContext(ctxt fab.ClientContext) {
+}
+
+// Close does nothing
+func (m *SDK) Close() {
+}
+
+type channelContext struct {
+	context.Channel
+
+	channelService fab.ChannelService
+	endpointConfig fab.EndpointConfig
+}
+
+func (c *channelContext) ChannelService() fab.ChannelService {
+	return c.channelService
+}
+
+func (c *channelContext) EndpointConfig() fab.EndpointConfig {
+	return c.endpointConfig
+}
+
+type channelService struct {
+	fab.ChannelService
+
+	discovery fab.DiscoveryService
+}
+
+func (s *channelService) Discovery() (fab.DiscoveryService, error) {
+	return s.discovery, nil
+}
+
+type discoveryService struct {
+	peers []fab.Peer
+	err   error
+}
+
+func (s *discoveryService) GetPeers() ([]fab.Peer, error) {
+	return s.peers, s.err
+}
+
+// EndpointConfig implements a mock endpoint config that contains the peers of the given network config.
+// MatchedPeers simulates entity matchers by mapping a URL to the config of a peer.
+type EndpointConfig struct {
+	fab.EndpointConfig
+
+	Network      fab.NetworkConfig
+	MatchedPeers map[string]*fab.PeerConfig
+}
+
+// NetworkConfig returns the network config
+func (c *EndpointConfig) NetworkConfig() *fab.NetworkConfig {
+	return &c.Network
+}
+
+// PeerConfig returns the config of the peer with the given name or URL
+func (c *EndpointConfig) PeerConfig(nameOrURL string) (*fab.PeerConfig, bool) {
+	if peerCfg, ok := c.MatchedPeers[nameOrURL]; ok {
+		return peerCfg, true
+	}
+
+	if peerCfg, ok := c.Network.Peers[nameOrURL]; ok {
+		return &peerCfg, true
+	}
+
+	for _, peerCfg := range c.Network.Peers {
+		if peerCfg.URL == nameOrURL {
+			peerCfg := peerCfg
+			return &peerCfg, true
+		}
+	}
+
+	return nil, false
+}
@@ -5,11 +5,18 @@
 module github.com/trustbloc/fabric-cli-ext
 
 require (
+	github.com/btcsuite/btcd v0.20.1-beta
+	github.com/evanphx/json-patch v4.1.0+incompatible
+	github.com/golang/protobuf v1.3.3
 	github.com/hyperledger/fabric-cli v0.0.0-20201005191300-d9e3966b20eb
 	github.com/hyperledger/fabric-protos-go v0.0.0-20200707132912-fee30f3ccd23
 	github.com/hyperledger/fabric-sdk-go v1.0.0-beta3.0.20201002210629-a64e1ef9f926
+	github.com/miekg/pkcs11 v1.0.3
 	github.com/pkg/errors v0.9.1
+	github.com/pmezard/go-difflib v1.0.0
 	github.com/spf13/cobra v0.0.6
+	github.com/spf13/pflag v1.0.5
+	github.com/square/go-jose/v3 v3.0.0-20191119004800-96c717272387
 	github.com/stretchr/testify v1.5.1
 	github.com/trustbloc/sidetree-core-go v0.1.6-0.20210301232849-50c4792e1ca1
 )
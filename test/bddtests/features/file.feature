@@ -69,14 +69,14 @@ Feature: Upload files to DCAS which are backed by a Sidetree file index document
   @sidetree_file_s1
   Scenario: Test the file command
     # Create a file index document
-    When fabric-cli is executed with args "file createidx --path /content --url http://localhost:48326/file/operations --recoverykeyfile ./fixtures/testdata/keys/recover/public.key --updatekeyfile ./fixtures/testdata/keys/update/public.key --authtoken ${token_fileidx_w} --noprompt"
+    When fabric-cli is executed with args "file createidx --path /content --url http://localhost:48326/file/operations --recoverykeyfile ./fixtures/testdata/keys/recover/public.key --updatekeyfile ./fixtures/testdata/keys/update/public.key --authtoken ${token_fileidx_w} --wait --noprompt"
     And the JSON path "id" of the response is saved to variable "fileIdxID"
 
     # Update the file handler configuration for the '/content' path with the ID of the file index document
     Given fabric-cli context "org1-context" is used
-    Then fabric-cli is executed with args "ledgerconfig fileidxupdate --msp Org1MSP --peers peer0.org1.example.com;peer1.org1.example.com --path /content --idxid ${fileIdxID} --noprompt"
+    Then fabric-cli is executed with args "ledgerconfig fileidxupdate --mspid Org1MSP --peers peer0.org1.example.com;peer1.org1.example.com --path /content --idxid ${fileIdxID} --noprompt"
     Given fabric-cli context "org2-context" is used
-    And fabric-cli is executed with args "ledgerconfig fileidxupdate --msp Org2MSP --peers peer0.org2.example.com;peer1.org2.example.com --path /content --idxid ${fileIdxID} --noprompt"
+    And fabric-cli is executed with args "ledgerconfig fileidxupdate --mspid Org2MSP --peers peer0.org2.example.com;peer1.org2.example.com --path /content --idxid ${fileIdxID} --noprompt"
 
     Then we wait 10 seconds
 
@@ -87,15 +87,13 @@ Feature: Upload files to DCAS which are backed by a Sidetree file index document
     # Upload a couple of files and add them to the file index document
     # NOTE: Use an explicit --contentauthtoken to test the case where the auth token for /file and /content are different. Otherwise,
     # if they're the same, we don't need to specify --contentauthtoken.
-    When fabric-cli is executed with args "file upload --url http://localhost:48326/content --files ./fixtures/testdata/v1/arrays.schema.json;./fixtures/testdata/v1/geographical-location.schema.json --idxurl http://localhost:48326/file/identifiers/${fileIdxID} --nextupdatekeyfile ./fixtures/testdata/keys/update2/public.key --signingkeyfile ./fixtures/testdata/keys/update/private.key --authtoken ${token_fileidx_w} --contentauthtoken ${token_content_w} --noprompt"
+    When fabric-cli is executed with args "file upload --url http://localhost:48326/content --files ./fixtures/testdata/v1/arrays.schema.json;./fixtures/testdata/v1/geographical-location.schema.json --idxurl http://localhost:48326/file/identifiers/${fileIdxID} --nextupdatekeyfile ./fixtures/testdata/keys/update2/public.key --signingkeyfile ./fixtures/testdata/keys/update/private.key --authtoken ${token_fileidx_w} --contentauthtoken ${token_content_w} --wait --noprompt"
     Then the JSON path "#" of the response has 2 items
     And the JSON path "0.Name" of the response equals "arrays.schema.json"
     And the JSON path "0.ContentType" of the response equals "application/json"
     And the JSON path "1.Name" of the response equals "geographical-location.schema.json"
     And the JSON path "1.ContentType" of the response equals "application/json"
 
-    Then we wait 10 seconds
-
     When an HTTP GET is sent to "http://localhost:48326/content/arrays.schema.json"
     Then the JSON path "$id" of the response equals "https://example.com/arrays.schema.json"
 
@@ -119,7 +117,7 @@ Feature: Upload files to DCAS which are backed by a Sidetree file index document
 
     # Upload more files and add them to the file index document. Note that arrays.schema.json is updated to v2
     # NOTE: Don't use an explicit --contentauthtoken to test the case where the auth token for /file and /content are the same
-    When fabric-cli is executed with args "file upload --url http://localhost:48326/content --files ./fixtures/testdata/v1/person.schema.json;./fixtures/testdata/v1/raised-hand.png;./fixtures/testdata/v1/text1.txt;./fixtures/testdata/v2/arrays.schema.json --idxurl http://localhost:48326/file/identifiers/${fileIdxID} --nextupdatekeyfile ./fixtures/testdata/keys/update3/public.key --signingkeyfile ./fixtures/testdata/keys/update2/private.key --authtoken ${token_fileidx_w} --noprompt"
+    When fabric-cli is executed with args "file upload --url http://localhost:48326/content --files ./fixtures/testdata/v1/person.schema.json;./fixtures/testdata/v1/raised-hand.png;./fixtures/testdata/v1/text1.txt;./fixtures/testdata/v2/arrays.schema.json --idxurl http://localhost:48326/file/identifiers/${fileIdxID} --nextupdatekeyfile ./fixtures/testdata/keys/update3/public.key --signingkeyfile ./fixtures/testdata/keys/update2/private.key --authtoken ${token_fileidx_w} --wait --noprompt"
     Then the JSON path "#" of the response has 4 items
     And the JSON path "0.Name" of the response equals "person.schema.json"
     And the JSON path "0.ContentType" of the response equals "application/json"
@@ -130,8 +128,6 @@ Feature: Upload files to DCAS which are backed by a Sidetree file index document
     And the JSON path "3.Name" of the response equals "arrays.schema.json"
     And the JSON path "3.ContentType" of the response equals "application/json"
 
-    Then we wait 10 seconds
-
     When an HTTP GET is sent to "http://localhost:48326/content/person.schema.json"
     Then the JSON path "$id" of the response equals "https://example.com/person.schema.json"
     When an HTTP GET is sent to "http://localhost:48426/content/raised-hand.png"